@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Show the signed in user's app passwords along with the URL that CalDAV clients sync with.
+func (s *Server) getAppPasswords(w http.ResponseWriter, r *http.Request) {
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	body, err := s.renderer.RenderAppPasswords(AppPasswordsPage{
+		User:         user.Email,
+		AppPasswords: user.AppPasswords,
+		CalDAVURL:    baseURL(r) + caldavPrefix,
+	})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Make an app password for the signed in user with the name in the "name" field of the form, e.g., "Phone". The
+// password is only shown in the response, since only its hash is kept.
+func (s *Server) addAppPassword(w http.ResponseWriter, r *http.Request) {
+	userStore := s.users(w, r)
+
+	if userStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+
+	if name == "" {
+		http.Error(w, "an app password needs a name", http.StatusBadRequest)
+		return
+	}
+
+	password, err := models.GenerateAppPassword()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(err.Error())
+		return
+	}
+
+	hash, err := models.NewPasswordHash(password, bcrypt.DefaultCost)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not hash the app password for %q: %v", user.Email, err))
+		return
+	}
+
+	appPassword, err := userStore.AddAppPassword(user.Email, name, hash)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add an app password for %q", user.Email))
+		return
+	}
+
+	body, err := s.renderer.RenderAppPasswords(AppPasswordsPage{
+		User:            user.Email,
+		AppPasswords:    append(user.AppPasswords, *appPassword),
+		CalDAVURL:       baseURL(r) + caldavPrefix,
+		NewPassword:     password,
+		NewPasswordName: name,
+	})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Delete one of the signed in user's app passwords, signing out the app that used it.
+func (s *Server) deleteAppPassword(w http.ResponseWriter, r *http.Request) {
+	userStore := s.users(w, r)
+
+	if userStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := userStore.DeleteAppPassword(user.Email, id); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete app password %d of %q", id, user.Email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
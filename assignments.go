@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// Assign the task with the ID in the path to the users in the "assignee" fields of the form, replacing its current
+// assignees. Users that are newly assigned to the task, other than whoever assigned them, are notified.
+func (s *Server) setAssignees(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	added, err := taskStore.SetAssignees(id, r.Form["assignee"])
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not assign task %d", id))
+		return
+	}
+
+	if len(added) > 0 {
+		task, err := taskStore.GetTask(id)
+
+		if err != nil || task == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get task %d after assigning it: %v", id, err))
+			return
+		}
+
+		notification := models.Notification{Kind: models.NotificationAssigned, TaskID: id, Task: task.Description}
+
+		if user != nil {
+			notification.Actor = user.Email
+		}
+
+		for _, assignee := range added {
+			if assignee == notification.Actor {
+				continue
+			}
+
+			if err := taskStore.AddNotification(assignee, notification); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				slog.Error(fmt.Sprintf("could not notify %q that they were assigned task %d: %v", assignee, id, err))
+				return
+			}
+		}
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Show the open tasks that are assigned to the signed in user in the lists they can see.
+func (s *Server) getAssigned(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	tasks, err := taskStore.GetAssignedTasks(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks assigned to %q: %v", user.Email, err))
+		return
+	}
+
+	// Leave out tasks in lists that the user has since been removed from.
+	var visible []models.Task
+
+	for _, task := range tasks {
+		ok, err := s.canView(user, task.ID)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not check who can see task %d: %v", task.ID, err))
+			return
+		}
+
+		if ok {
+			visible = append(visible, task)
+		}
+	}
+
+	body, err := s.renderer.RenderAssigned(AssignedPage{User: user.Email, Tasks: visible})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Get the users that tasks in the task list of `owner` can be assigned to: the owner followed by the members.
+func (s *Server) assignable(owner string) ([]string, error) {
+	members, err := s.taskStore.GetMembers(owner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	users := []string{owner}
+
+	for _, member := range members {
+		users = append(users, member.Email)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,1057 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/ical"
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+const (
+	// The path that the CalDAV tree is served under.
+	caldavPrefix = "/caldav/"
+	// The XML namespaces of WebDAV, CalDAV and the extensions made by Apple's calendar server, which is where the ctag
+	// that clients use to check for changes comes from.
+	davNamespace            = "DAV:"
+	caldavNamespace         = "urn:ietf:params:xml:ns:caldav"
+	calendarServerNamespace = "http://calendarserver.org/ns/"
+	// The content type of calendar objects.
+	calendarContentType = "text/calendar; charset=utf-8"
+	// The largest calendar object or request body that CalDAV clients may send.
+	maxCalendarObjectSize = 1 << 20
+	// The description of tasks made from to-dos without a summary.
+	untitledTodo = "(untitled)"
+)
+
+// The methods that the CalDAV tree is served for. HEAD requests are served as GET requests.
+var caldavMethods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodPut, http.MethodDelete, "PROPFIND", "REPORT",
+}
+
+// The kinds of resources in the CalDAV tree.
+type caldavResource int
+
+const (
+	// The root, `/caldav/`, which points clients to the principal of the signed in user.
+	caldavRoot caldavResource = iota
+	// The principal of a user, `/caldav/principals/{user}/`, which points clients to their calendar home.
+	caldavPrincipal
+	// The calendar home of a user, `/caldav/calendars/{user}/`, which has a calendar for each list that they can see.
+	caldavHome
+	// The calendar of a task list, `/caldav/calendars/{user}/{owner}/`.
+	caldavCalendar
+	// A task in a calendar, `/caldav/calendars/{user}/{owner}/{name}`.
+	caldavObject
+)
+
+// A resource in the CalDAV tree.
+type caldavPath struct {
+	Resource caldavResource
+	// The user whose principal or calendar home the resource is in.
+	User string
+	// The owner of the task list of a calendar or calendar object.
+	Owner string
+	// The name of a calendar object, e.g., "12.ics".
+	Name string
+}
+
+// Parse the path of a resource in the CalDAV tree, e.g., "/caldav/calendars/bob@example.com/".
+//
+// Returns false if the path is not in the tree.
+func parseCalDAVPath(path string) (caldavPath, bool) {
+	rest, ok := strings.CutPrefix(path, caldavPrefix)
+
+	if !ok {
+		return caldavPath{}, false
+	}
+
+	// Collections may be requested with or without a trailing slash.
+	rest = strings.TrimSuffix(rest, "/")
+
+	if rest == "" {
+		return caldavPath{Resource: caldavRoot}, true
+	}
+
+	segments := strings.Split(rest, "/")
+
+	for _, segment := range segments {
+		if segment == "" {
+			return caldavPath{}, false
+		}
+	}
+
+	switch {
+	case segments[0] == "principals" && len(segments) == 2:
+		return caldavPath{Resource: caldavPrincipal, User: segments[1]}, true
+	case segments[0] == "calendars" && len(segments) == 2:
+		return caldavPath{Resource: caldavHome, User: segments[1]}, true
+	case segments[0] == "calendars" && len(segments) == 3:
+		return caldavPath{Resource: caldavCalendar, User: segments[1], Owner: segments[2]}, true
+	case segments[0] == "calendars" && len(segments) == 4:
+		return caldavPath{Resource: caldavObject, User: segments[1], Owner: segments[2], Name: segments[3]}, true
+	default:
+		return caldavPath{}, false
+	}
+}
+
+// Get the path of the resource, escaping the emails and names in it.
+func (p caldavPath) href() string {
+	switch p.Resource {
+	case caldavPrincipal:
+		return caldavPrefix + "principals/" + url.PathEscape(p.User) + "/"
+	case caldavHome:
+		return caldavPrefix + "calendars/" + url.PathEscape(p.User) + "/"
+	case caldavCalendar:
+		return caldavPrefix + "calendars/" + url.PathEscape(p.User) + "/" + url.PathEscape(p.Owner) + "/"
+	case caldavObject:
+		return caldavPrefix + "calendars/" + url.PathEscape(p.User) + "/" + url.PathEscape(p.Owner) + "/" +
+			url.PathEscape(p.Name)
+	default:
+		return caldavPrefix
+	}
+}
+
+// Get the path of a calendar object in the calendar at `p`.
+func (p caldavPath) object(name string) caldavPath {
+	return caldavPath{Resource: caldavObject, User: p.User, Owner: p.Owner, Name: name}
+}
+
+// Get the path of the calendar that the calendar object at `p` is in.
+func (p caldavPath) calendar() caldavPath {
+	return caldavPath{Resource: caldavCalendar, User: p.User, Owner: p.Owner}
+}
+
+// Send CalDAV clients that look for the server at the well-known URL (RFC 6764) to the root of the CalDAV tree.
+func (s *Server) redirectCalDAV(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, caldavPrefix, http.StatusMovedPermanently)
+}
+
+// Serve the CalDAV tree (RFC 4791), which syncs the task lists that the user can see with the to-dos of CalDAV clients
+// such as Apple Reminders, Thunderbird and DAVx⁵.
+//
+// Clients sign in with the user's email and one of their app passwords. Each user only sees their own calendar home,
+// which has a calendar for their own list and for each list that is shared with them.
+func (s *Server) serveCalDAV(w http.ResponseWriter, r *http.Request) {
+	allow := strings.Join(caldavMethods, ", ")
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, err := s.authenticateApp(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	if user == nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="yatta", charset="UTF-8"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := parseCalDAVPath(r.URL.Path)
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	taskStore, err := s.tasksOf(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace of %q: %v", user.Email, err))
+		return
+	}
+
+	if path.Resource != caldavRoot && path.User != user.Email {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	role := models.RoleAdmin
+
+	if path.Resource == caldavCalendar || path.Resource == caldavObject {
+		var found bool
+		role, found, err = s.calendarRole(taskStore, user, path.Owner)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the calendars of %q: %v", user.Email, err))
+			return
+		}
+
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	switch {
+	case r.Method == "PROPFIND":
+		s.propfind(w, r, taskStore, user, path, role)
+	case r.Method == "REPORT" && path.Resource == caldavCalendar:
+		s.report(w, r, taskStore, user, path, role)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && path.Resource == caldavObject:
+		s.getCalendarObject(w, r, taskStore, path)
+	case (r.Method == http.MethodPut || r.Method == http.MethodDelete) && path.Resource == caldavObject:
+		if !role.Allows(models.RoleEditor) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			s.putCalendarObject(w, r, taskStore, path)
+		} else {
+			s.deleteCalendarObject(w, r, taskStore, path)
+		}
+	default:
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Get the user that signed in to the request with one of their app passwords, or nil if the request was not signed in
+// with an app password.
+func (s *Server) authenticateApp(r *http.Request) (*models.User, error) {
+	email, password, ok := r.BasicAuth()
+
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := s.userStore.GetUserByEmail(email)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get user %q: %v", email, err)
+	}
+
+	if user == nil || user.MatchAppPassword(password) == nil {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// Get the lists that are synced with the user's CalDAV clients, which are their own list, followed by the lists in
+// `taskStore` that are shared with them.
+func (s *Server) calendars(taskStore stores.TaskStore, user *models.User) ([]models.SharedList, error) {
+	shared, err := taskStore.GetSharedLists(user.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]models.SharedList{{Owner: user.Email, Role: models.RoleAdmin}}, shared...), nil
+}
+
+// Get the role of the user in the calendar of the list of `owner`.
+//
+// Returns false if the list is not one of the user's calendars.
+func (s *Server) calendarRole(taskStore stores.TaskStore, user *models.User, owner string) (models.Role, bool, error) {
+	calendars, err := s.calendars(taskStore, user)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, calendar := range calendars {
+		if calendar.Owner == owner {
+			return calendar.Role, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// Get the name of the calendar object of `task`, which is the name that the client that made it chose, if any.
+func calendarObjectName(task models.Task) string {
+	if task.CalendarObject != nil && task.CalendarObject.Name != "" {
+		return task.CalendarObject.Name
+	}
+
+	return fmt.Sprintf("%d.ics", task.ID)
+}
+
+// Find the task with the calendar object called `name` in `tasks`, or nil if there is none.
+func findCalendarObject(tasks []models.Task, name string) *models.Task {
+	for i := range tasks {
+		if calendarObjectName(tasks[i]) == name {
+			return &tasks[i]
+		}
+	}
+
+	return nil
+}
+
+// Get the entity tag of the calendar object of `task`, which changes whenever the task does.
+func calendarObjectETag(task models.Task) (string, error) {
+	data, err := json.Marshal(task)
+
+	if err != nil {
+		return "", fmt.Errorf("could not encode task %d: %w", task.ID, err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// Get the ctag of a calendar with `tasks`, which changes whenever a task is added, changed or removed.
+func calendarTag(tasks []models.Task) (string, error) {
+	hash := sha256.New()
+
+	for _, task := range tasks {
+		tag, err := calendarObjectETag(task)
+
+		if err != nil {
+			return "", err
+		}
+
+		hash.Write([]byte(calendarObjectName(task) + tag))
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:8]) + `"`, nil
+}
+
+// Write the calendar object of `task`.
+func writeCalendarObject(w io.Writer, r *http.Request, task models.Task) error {
+	return ical.Write(w, ical.Feed{
+		BaseURL:   baseURL(r),
+		Component: ical.VTodo,
+		Now:       time.Now(),
+		Tasks:     []models.Task{task},
+		Undated:   true,
+	})
+}
+
+// Check the If-Match and If-None-Match headers of a request that changes the calendar object of `task`, which is nil if
+// the object does not exist yet.
+//
+// Returns false if a precondition failed, after writing the response.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, task *models.Task) bool {
+	ifMatch := r.Header.Get("If-Match")
+	ifNoneMatch := r.Header.Get("If-None-Match")
+
+	switch {
+	case ifNoneMatch == "*" && task != nil,
+		ifMatch != "" && task == nil:
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	case ifMatch == "" || ifMatch == "*":
+		return true
+	}
+
+	tag, err := calendarObjectETag(*task)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the entity tag of task %d: %v", task.ID, err))
+		return false
+	}
+
+	if ifMatch != tag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	}
+
+	return true
+}
+
+// Get the task of the calendar object at `path`.
+func (s *Server) getCalendarObject(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, path caldavPath) {
+	tasks, err := taskStore.GetTasks(path.Owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks of %q: %v", path.Owner, err))
+		return
+	}
+
+	task := findCalendarObject(tasks, path.Name)
+
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	tag, err := calendarObjectETag(*task)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the entity tag of task %d: %v", task.ID, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", calendarContentType)
+	w.Header().Set("ETag", tag)
+
+	if err := writeCalendarObject(w, r, *task); err != nil {
+		slog.Error(fmt.Sprintf("could not write the calendar object of task %d: %v", task.ID, err))
+	}
+}
+
+// Add or update the task of the calendar object at `path` from the to-do in the body of the request.
+//
+// The response does not have an ETag, since the task is stored differently than the client sent it, e.g., without
+// alarms, which tells clients to get the object again.
+func (s *Server) putCalendarObject(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, path caldavPath) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "text/calendar" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	todo, err := ical.ParseTodo(http.MaxBytesReader(w, r.Body, maxCalendarObjectSize))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(path.Owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks of %q: %v", path.Owner, err))
+		return
+	}
+
+	task := findCalendarObject(tasks, path.Name)
+
+	if !checkPreconditions(w, r, task) {
+		return
+	}
+
+	for _, other := range tasks {
+		if ical.UID(other) == todo.UID && (task == nil || other.ID != task.ID) {
+			http.Error(w, fmt.Sprintf("the UID %q is already used by another to-do", todo.UID), http.StatusConflict)
+			return
+		}
+	}
+
+	description := todo.Summary
+
+	if strings.TrimSpace(description) == "" {
+		description = untitledTodo
+	}
+
+	tags := todo.Categories
+
+	if tags == nil {
+		// Tags are only changed when they are not nil, and to-dos without categories have no tags.
+		tags = []string{}
+	}
+
+	recurrence := todo.Recurrence
+
+	// Recurring tasks need a due date to repeat from.
+	if todo.Due == nil {
+		recurrence = nil
+	}
+
+	details := models.TaskUpdate{
+		Notes:           &todo.Description,
+		Tags:            tags,
+		Due:             todo.Due,
+		ClearDue:        todo.Due == nil,
+		Recurrence:      recurrence,
+		ClearRecurrence: recurrence == nil,
+	}
+
+	if task == nil {
+		details.CalendarObject = &models.CalendarObject{Name: path.Name, UID: todo.UID}
+		id, err := taskStore.AddTaskWithDetails(path.Owner, description, details)
+
+		if err == nil && todo.Completed {
+			err = taskStore.SetDone(id, true)
+		}
+
+		if err != nil {
+			writeTaskStoreError(w, err, fmt.Sprintf("could not add a task for %q from a CalDAV client", path.Owner))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	details.Description = &description
+	err = taskStore.UpdateTask(task.ID, details)
+
+	if err == nil && todo.Completed != task.Done {
+		err = taskStore.SetDone(task.ID, todo.Completed)
+	}
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not update task %d from a CalDAV client", task.ID))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete the task of the calendar object at `path`.
+func (s *Server) deleteCalendarObject(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, path caldavPath) {
+	tasks, err := taskStore.GetTasks(path.Owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks of %q: %v", path.Owner, err))
+		return
+	}
+
+	task := findCalendarObject(tasks, path.Name)
+
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !checkPreconditions(w, r, task) {
+		return
+	}
+
+	if err := taskStore.DeleteTask(task.ID); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete task %d from a CalDAV client", task.ID))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// The body of a PROPFIND request.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *davNames `xml:"DAV: prop"`
+}
+
+// The body of a REPORT request, which is a calendar-query or a calendar-multiget.
+type reportRequest struct {
+	XMLName xml.Name
+	AllProp *struct{}       `xml:"DAV: allprop"`
+	Prop    *davNames       `xml:"DAV: prop"`
+	Filter  *calendarFilter `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	Hrefs   []string        `xml:"DAV: href"`
+}
+
+// The names of the properties that a client asked for.
+type davNames struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+// The filter of a calendar-query, which has a comp-filter for the VCALENDAR component.
+type calendarFilter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// A filter on components, e.g., the VTODOs in a calendar. Time ranges are not supported, so they match every component.
+type compFilter struct {
+	Name        string       `xml:"name,attr"`
+	CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// A filter on the properties of a component. Only the filters that clients use to find the to-dos that are not done are
+// supported: COMPLETED is-not-defined and STATUS not matching COMPLETED.
+type propFilter struct {
+	Name         string    `xml:"name,attr"`
+	IsNotDefined *struct{} `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *struct {
+		Text            string `xml:",chardata"`
+		NegateCondition string `xml:"negate-condition,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// Whether the to-do of `task` matches the comp-filter of a calendar-query, which filters the VCALENDAR component.
+func (c compFilter) matches(task models.Task) bool {
+	if !strings.EqualFold(c.Name, "VCALENDAR") {
+		return false
+	}
+
+	if len(c.CompFilters) == 0 {
+		return true
+	}
+
+	for _, filter := range c.CompFilters {
+		if !strings.EqualFold(filter.Name, string(ical.VTodo)) {
+			continue
+		}
+
+		matches := true
+
+		for _, prop := range filter.PropFilters {
+			matches = matches && prop.matches(task)
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether the to-do of `task` matches the prop-filter. Unsupported filters match every to-do.
+func (p propFilter) matches(task models.Task) bool {
+	switch {
+	case strings.EqualFold(p.Name, "COMPLETED") && p.IsNotDefined != nil:
+		return !task.Done
+	case strings.EqualFold(p.Name, "STATUS") && p.TextMatch != nil:
+		completed := strings.EqualFold(strings.TrimSpace(p.TextMatch.Text), "COMPLETED")
+		negate := p.TextMatch.NegateCondition == "yes"
+
+		if !completed {
+			return true
+		}
+
+		return task.Done != negate
+	default:
+		return true
+	}
+}
+
+// The body of a 207 Multi-Status response.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"response"`
+}
+
+// The status or properties of a resource in a multistatus.
+type davResponse struct {
+	Href      string        `xml:"href"`
+	Status    string        `xml:"status,omitempty"`
+	Propstats []davPropstat `xml:"propstat"`
+}
+
+// Properties with the same status, e.g., the properties that were found.
+type davPropstat struct {
+	Prop   davProp `xml:"prop"`
+	Status string  `xml:"status"`
+}
+
+type davProp struct {
+	Properties []davProperty
+}
+
+// A property, with a value that is already XML.
+type davProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",innerxml"`
+}
+
+// The properties of a resource, keyed by name, with values that are already XML.
+type davProperties map[xml.Name]string
+
+// Get the status line of `code` that is used in multistatus responses, e.g., "HTTP/1.1 404 Not Found".
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// Make a multistatus response for the resource at `href` with the properties in `names`, putting the properties that
+// the resource does not have in a propstat with 404. All the properties are given if `names` is nil.
+func (p davProperties) response(href string, names []xml.Name) davResponse {
+	var found, missing davProp
+
+	if names == nil {
+		for name := range p {
+			names = append(names, name)
+		}
+
+		slices.SortFunc(names, func(a, b xml.Name) int {
+			return cmp.Or(strings.Compare(a.Space, b.Space), strings.Compare(a.Local, b.Local))
+		})
+	}
+
+	for _, name := range names {
+		if value, ok := p[name]; ok {
+			found.Properties = append(found.Properties, davProperty{XMLName: name, Value: value})
+		} else {
+			missing.Properties = append(missing.Properties, davProperty{XMLName: name})
+		}
+	}
+
+	response := davResponse{Href: href}
+
+	if len(found.Properties) > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: found, Status: davStatus(http.StatusOK)})
+	}
+
+	if len(missing.Properties) > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: missing, Status: davStatus(http.StatusNotFound)})
+	}
+
+	return response
+}
+
+// Escape the characters in `text` that are special in XML.
+func escapeXML(text string) string {
+	var escaped bytes.Buffer
+	// Writing to a buffer never fails.
+	_ = xml.EscapeText(&escaped, []byte(text))
+
+	return escaped.String()
+}
+
+// Get the XML of an href element that links to `href`.
+func hrefXML(href string) string {
+	return `<href xmlns="DAV:">` + escapeXML(href) + `</href>`
+}
+
+// Get the XML of an element with no content, e.g., `<collection xmlns="DAV:"/>`.
+func emptyXML(namespace string, name string) string {
+	return fmt.Sprintf(`<%s xmlns="%s"/>`, name, namespace)
+}
+
+// Get the properties of the resource at `path`, where `tasks` are the tasks of a calendar and `task` is the task of a
+// calendar object.
+func (s *Server) davProperties(r *http.Request, user *models.User, path caldavPath, role models.Role, tasks []models.Task, task *models.Task) (davProperties, error) {
+	principal := caldavPath{Resource: caldavPrincipal, User: user.Email}
+	properties := davProperties{
+		{Space: davNamespace, Local: "current-user-principal"}: hrefXML(principal.href()),
+	}
+
+	switch path.Resource {
+	case caldavRoot:
+		properties[xml.Name{Space: davNamespace, Local: "resourcetype"}] = emptyXML(davNamespace, "collection")
+		properties[xml.Name{Space: davNamespace, Local: "displayname"}] = "Yatta"
+	case caldavPrincipal:
+		home := caldavPath{Resource: caldavHome, User: user.Email}
+		properties[xml.Name{Space: davNamespace, Local: "resourcetype"}] = emptyXML(davNamespace, "principal")
+		properties[xml.Name{Space: davNamespace, Local: "displayname"}] = escapeXML(user.Email)
+		properties[xml.Name{Space: davNamespace, Local: "principal-URL"}] = hrefXML(principal.href())
+		properties[xml.Name{Space: caldavNamespace, Local: "calendar-home-set"}] = hrefXML(home.href())
+		properties[xml.Name{Space: caldavNamespace, Local: "calendar-user-address-set"}] = hrefXML("mailto:" + user.Email)
+	case caldavHome:
+		properties[xml.Name{Space: davNamespace, Local: "resourcetype"}] = emptyXML(davNamespace, "collection")
+		properties[xml.Name{Space: davNamespace, Local: "displayname"}] = escapeXML(user.Email)
+	case caldavCalendar:
+		privileges := []string{"read"}
+
+		if role.Allows(models.RoleEditor) {
+			privileges = append(privileges, "write", "write-content", "bind", "unbind")
+		}
+
+		var privilegeSet strings.Builder
+
+		for _, privilege := range privileges {
+			privilegeSet.WriteString(`<privilege xmlns="DAV:">` + emptyXML(davNamespace, privilege) + `</privilege>`)
+		}
+
+		var reportSet strings.Builder
+
+		for _, report := range []string{"calendar-query", "calendar-multiget"} {
+			reportSet.WriteString(`<supported-report xmlns="DAV:"><report>` + emptyXML(caldavNamespace, report) +
+				`</report></supported-report>`)
+		}
+
+		tag, err := calendarTag(tasks)
+
+		if err != nil {
+			return nil, err
+		}
+
+		properties[xml.Name{Space: davNamespace, Local: "resourcetype"}] = emptyXML(davNamespace, "collection") +
+			emptyXML(caldavNamespace, "calendar")
+		properties[xml.Name{Space: davNamespace, Local: "displayname"}] = escapeXML(path.Owner)
+		properties[xml.Name{Space: davNamespace, Local: "current-user-privilege-set"}] = privilegeSet.String()
+		properties[xml.Name{Space: davNamespace, Local: "supported-report-set"}] = reportSet.String()
+		properties[xml.Name{Space: davNamespace, Local: "getetag"}] = escapeXML(tag)
+		properties[xml.Name{Space: calendarServerNamespace, Local: "getctag"}] = escapeXML(tag)
+		properties[xml.Name{Space: caldavNamespace, Local: "supported-calendar-component-set"}] =
+			`<comp xmlns="` + caldavNamespace + `" name="VTODO"/>`
+	case caldavObject:
+		var data bytes.Buffer
+
+		if err := writeCalendarObject(&data, r, *task); err != nil {
+			return nil, err
+		}
+
+		tag, err := calendarObjectETag(*task)
+
+		if err != nil {
+			return nil, err
+		}
+
+		properties[xml.Name{Space: davNamespace, Local: "resourcetype"}] = ""
+		properties[xml.Name{Space: davNamespace, Local: "getetag"}] = escapeXML(tag)
+		properties[xml.Name{Space: davNamespace, Local: "getcontenttype"}] =
+			escapeXML(calendarContentType + "; component=vtodo")
+		properties[xml.Name{Space: caldavNamespace, Local: "calendar-data"}] = escapeXML(data.String())
+	}
+
+	return properties, nil
+}
+
+// Read the XML body of a CalDAV request into `v`.
+//
+// Returns false if the body is empty.
+func readDAVBody(w http.ResponseWriter, r *http.Request, v any) (bool, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalendarObjectSize))
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return false, nil
+	}
+
+	return true, xml.Unmarshal(body, v)
+}
+
+// Get the names of the properties in `prop`, or nil if all properties were asked for.
+func (d *davNames) names() []xml.Name {
+	if d == nil {
+		return nil
+	}
+
+	names := make([]xml.Name, len(d.Names))
+
+	for i, name := range d.Names {
+		names[i] = name.XMLName
+	}
+
+	return names
+}
+
+// Write a 207 Multi-Status response with `responses`.
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	body, err := xml.Marshal(davMultistatus{Responses: responses})
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not write a multistatus response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
+
+// Get the properties of the resource at `path`, and of its members if the Depth header is not 0.
+func (s *Server) propfind(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, user *models.User, path caldavPath, role models.Role) {
+	var request propfindRequest
+	hasBody, err := readDAVBody(w, r, &request)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the PROPFIND request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var names []xml.Name
+
+	switch {
+	case hasBody && request.PropName != nil:
+		http.Error(w, "propname is not supported", http.StatusBadRequest)
+		return
+	case hasBody && request.AllProp == nil:
+		names = request.Prop.names()
+
+		if names == nil {
+			names = []xml.Name{}
+		}
+	}
+
+	// Members are only listed one level deep, even when the client asks for an infinite depth.
+	depth := r.Header.Get("Depth")
+	members := []caldavPath{path}
+
+	if depth != "0" && path.Resource == caldavHome {
+		calendars, err := s.calendars(taskStore, user)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the calendars of %q: %v", user.Email, err))
+			return
+		}
+
+		for _, calendar := range calendars {
+			members = append(members, caldavPath{Resource: caldavCalendar, User: user.Email, Owner: calendar.Owner})
+		}
+	}
+
+	var tasks []models.Task
+
+	if path.Resource == caldavCalendar || path.Resource == caldavObject {
+		if tasks, err = taskStore.GetTasks(path.Owner); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the tasks of %q: %v", path.Owner, err))
+			return
+		}
+	}
+
+	if path.Resource == caldavObject && findCalendarObject(tasks, path.Name) == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if path.Resource == caldavCalendar && depth != "0" {
+		for _, task := range tasks {
+			members = append(members, path.object(calendarObjectName(task)))
+		}
+	}
+
+	responses := make([]davResponse, 0, len(members))
+
+	for _, member := range members {
+		memberRole := role
+		memberTasks := tasks
+
+		if path.Resource == caldavHome && member.Resource == caldavCalendar {
+			if memberRole, _, err = s.calendarRole(taskStore, user, member.Owner); err == nil {
+				memberTasks, err = taskStore.GetTasks(member.Owner)
+			}
+		}
+
+		var properties davProperties
+
+		if err == nil {
+			properties, err = s.davProperties(r, user, member, memberRole, memberTasks, findCalendarObject(tasks, member.Name))
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the properties of %q: %v", member.href(), err))
+			return
+		}
+
+		// Calendar data is only given when it is asked for, since it is much larger than the other properties.
+		if names == nil {
+			delete(properties, xml.Name{Space: caldavNamespace, Local: "calendar-data"})
+		}
+
+		responses = append(responses, properties.response(member.href(), names))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// Find the calendar objects in the calendar at `path` that match a calendar-query, or that are listed in a
+// calendar-multiget, and get their properties.
+func (s *Server) report(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, user *models.User, path caldavPath, role models.Role) {
+	var request reportRequest
+	hasBody, err := readDAVBody(w, r, &request)
+
+	if err != nil || !hasBody {
+		http.Error(w, fmt.Sprintf("could not read the REPORT request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(path.Owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks of %q: %v", path.Owner, err))
+		return
+	}
+
+	var names []xml.Name
+
+	if request.AllProp == nil {
+		names = request.Prop.names()
+	}
+
+	// The calendar objects to get the properties of, in the order they are listed in the response.
+	var objects []caldavPath
+	// The hrefs of the objects, which are the hrefs that the client sent in a calendar-multiget.
+	var hrefs []string
+
+	switch request.XMLName {
+	case xml.Name{Space: caldavNamespace, Local: "calendar-query"}:
+		if request.Filter == nil {
+			http.Error(w, "a calendar-query needs a filter", http.StatusBadRequest)
+			return
+		}
+
+		for _, task := range tasks {
+			if request.Filter.CompFilter.matches(task) {
+				object := path.object(calendarObjectName(task))
+				objects = append(objects, object)
+				hrefs = append(hrefs, object.href())
+			}
+		}
+	case xml.Name{Space: caldavNamespace, Local: "calendar-multiget"}:
+		for _, href := range request.Hrefs {
+			// Objects that are not in the calendar are answered as if they did not exist.
+			object, err := parseHref(href)
+
+			if err != nil || object.Resource != caldavObject || object.calendar() != path {
+				object = path.object("")
+			}
+
+			objects = append(objects, object)
+			hrefs = append(hrefs, href)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("the %s report is not supported", request.XMLName.Local), http.StatusForbidden)
+		return
+	}
+
+	responses := make([]davResponse, 0, len(objects))
+
+	for i, object := range objects {
+		task := findCalendarObject(tasks, object.Name)
+
+		if task == nil {
+			responses = append(responses, davResponse{Href: hrefs[i], Status: davStatus(http.StatusNotFound)})
+			continue
+		}
+
+		properties, err := s.davProperties(r, user, object, role, tasks, task)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the properties of %q: %v", hrefs[i], err))
+			return
+		}
+
+		responses = append(responses, properties.response(hrefs[i], names))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// Parse an href in a REPORT request, which may be a path or a full URL, and has escaped emails and names.
+func parseHref(href string) (caldavPath, error) {
+	parsed, err := url.Parse(href)
+
+	if err != nil {
+		return caldavPath{}, err
+	}
+
+	path, ok := parseCalDAVPath(parsed.Path)
+
+	if !ok {
+		return caldavPath{}, errors.New("the href is not in the CalDAV tree")
+	}
+
+	return path, nil
+}
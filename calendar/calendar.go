@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// An Entry is a task shown on the day it is due.
+type Entry struct {
+	Task models.Task
+	// When this occurrence of the task is due.
+	Due time.Time
+	// Whether the entry is a later occurrence of a recurring task rather than the task's own due date.
+	Repeat bool
+}
+
+// A Day is a day on a calendar along with the tasks due that day.
+type Day struct {
+	// Midnight at the start of the day.
+	Date    time.Time
+	Entries []Entry
+}
+
+// Month gets the first and last days shown on a calendar of the month containing `date`, which are the Monday on or
+// before the first day of the month and the Sunday on or after the last day of the month.
+//
+// Days start at midnight in the location of `date`.
+func Month(date time.Time) (time.Time, time.Time) {
+	year, month, _ := date.Date()
+	first := time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+	// Day zero of the following month is the last day of the month.
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, date.Location())
+
+	return startOfWeek(first), startOfWeek(last).AddDate(0, 0, 6)
+}
+
+// Week gets the Monday and Sunday of the week containing `date`.
+//
+// Days start at midnight in the location of `date`.
+func Week(date time.Time) (time.Time, time.Time) {
+	monday := startOfWeek(date)
+
+	return monday, monday.AddDate(0, 0, 6)
+}
+
+// Days places `tasks` on the days that they are due from `from` to `to`, inclusive.
+//
+// Open recurring tasks also appear on each of their later occurrences. Tasks without a due date are left out.
+// Days start at midnight in the location of `from`, and due dates are converted to that location before they are
+// placed on a day.
+func Days(tasks []models.Task, from time.Time, to time.Time) []Day {
+	from = startOfDay(from)
+	days := []Day{}
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: date})
+	}
+
+	if len(days) == 0 {
+		return days
+	}
+
+	end := days[len(days)-1].Date.AddDate(0, 0, 1)
+
+	add := func(entry Entry) {
+		due := startOfDay(entry.Due.In(from.Location()))
+
+		// Count the days rather than the hours since days are not always 24 hours long.
+		for i := range days {
+			if days[i].Date.Equal(due) {
+				days[i].Entries = append(days[i].Entries, entry)
+				return
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		if task.Due == nil {
+			continue
+		}
+
+		if task.Recurrence == nil || task.Done {
+			add(Entry{Task: task, Due: *task.Due})
+			continue
+		}
+
+		for _, due := range task.Recurrence.Occurrences(*task.Due, from, end) {
+			add(Entry{Task: task, Due: due, Repeat: !due.Equal(*task.Due)})
+		}
+	}
+
+	return days
+}
+
+// Get midnight at the start of the day of `t`.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// Get midnight at the start of the Monday on or before `t`.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
@@ -0,0 +1,159 @@
+package calendar_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/calendar"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestMonth(t *testing.T) {
+	cases := []struct {
+		name     string
+		date     time.Time
+		from, to time.Time
+	}{
+		{
+			// March 2025 starts on a Saturday and ends on a Monday.
+			name: "weeks overlap the previous and next months",
+			date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			from: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// September 2025 starts on a Monday and ends on a Tuesday.
+			name: "month starting on a Monday",
+			date: time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC),
+			from: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// February 2027 starts on a Monday and ends on a Sunday.
+			name: "month of exactly four weeks",
+			date: time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC),
+			from: time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "month across a year boundary",
+			date: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			from: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			from, to := calendar.Month(test.date)
+
+			if !from.Equal(test.from) || !to.Equal(test.to) {
+				t.Errorf("got days %v to %v, want %v to %v", from, to, test.from, test.to)
+			}
+		})
+	}
+}
+
+func TestWeek(t *testing.T) {
+	sunday := time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC)
+	from, to := calendar.Week(sunday)
+
+	if want := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("got week starting %v, want %v", from, want)
+	}
+
+	if want := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("got week ending %v, want %v", to, want)
+	}
+}
+
+// Get the IDs of the tasks on each day that has tasks, keyed by the day of the month.
+func entriesByDay(days []calendar.Day) map[int][]uint64 {
+	ids := make(map[int][]uint64)
+
+	for _, day := range days {
+		for _, entry := range day.Entries {
+			ids[day.Date.Day()] = append(ids[day.Date.Day()], entry.Task.ID)
+		}
+	}
+
+	return ids
+}
+
+func TestDays(t *testing.T) {
+	at := func(month time.Month, day int, hour int) *time.Time {
+		due := time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+		return &due
+	}
+
+	t.Run("place tasks on their due dates", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 1, Due: at(3, 3, 0)},
+			{ID: 2, Due: at(3, 3, 23)},
+			{ID: 3},
+			{ID: 4, Due: at(3, 9, 12), Done: true},
+			// Outside the days shown.
+			{ID: 5, Due: at(3, 10, 0)},
+			{ID: 6, Due: at(3, 2, 23)},
+		}
+
+		days := calendar.Days(tasks, *at(3, 3, 0), *at(3, 9, 0))
+
+		if len(days) != 7 {
+			t.Fatalf("got %d days, want 7", len(days))
+		}
+
+		want := map[int][]uint64{3: {1, 2}, 9: {4}}
+
+		if got := entriesByDay(days); !reflect.DeepEqual(got, want) {
+			t.Errorf("got tasks on days %v, want %v", got, want)
+		}
+	})
+
+	t.Run("due dates are placed in the time zone of the calendar", func(t *testing.T) {
+		auckland := time.FixedZone("NZDT", 13*60*60)
+		tasks := []models.Task{
+			// 1 April in Auckland.
+			{ID: 1, Due: at(3, 31, 12)},
+			// 31 March in Auckland.
+			{ID: 2, Due: at(3, 31, 10)},
+		}
+		from := time.Date(2025, 3, 31, 0, 0, 0, 0, auckland)
+		days := calendar.Days(tasks, from, from.AddDate(0, 0, 1))
+
+		want := map[int][]uint64{31: {2}, 1: {1}}
+
+		if got := entriesByDay(days); !reflect.DeepEqual(got, want) {
+			t.Errorf("got tasks on days %v, want %v", got, want)
+		}
+
+		if days[1].Date.Location() != auckland {
+			t.Errorf("got days in %v, want %v", days[1].Date.Location(), auckland)
+		}
+	})
+
+	t.Run("expand the occurrences of open recurring tasks", func(t *testing.T) {
+		weekly := &models.Recurrence{Frequency: models.Weekly}
+		tasks := []models.Task{
+			{ID: 1, Due: at(2, 24, 9), Recurrence: weekly},
+			{ID: 2, Due: at(3, 12, 9), Recurrence: &models.Recurrence{Frequency: models.Monthly}},
+			{ID: 3, Due: at(3, 5, 9), Recurrence: weekly, Done: true},
+		}
+
+		days := calendar.Days(tasks, *at(3, 1, 0), *at(3, 31, 0))
+		want := map[int][]uint64{3: {1}, 5: {3}, 10: {1}, 12: {2}, 17: {1}, 24: {1}, 31: {1}}
+
+		if got := entriesByDay(days); !reflect.DeepEqual(got, want) {
+			t.Errorf("got tasks on days %v, want %v", got, want)
+		}
+
+		for _, day := range days {
+			for _, entry := range day.Entries {
+				if entry.Repeat != (entry.Task.ID == 1) {
+					t.Errorf("got repeat %t for task %d on %v", entry.Repeat, entry.Task.ID, day.Date)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// The size of the charts on the report page in SVG user units.
+const (
+	chartWidth  = 600
+	chartHeight = 240
+	// The space around the plot for the axis labels.
+	chartMargin = 40
+)
+
+// A chartLabel is a piece of text on a chart, e.g., the value of a tick on an axis.
+type chartLabel struct {
+	X    float64
+	Y    float64
+	Text string
+	// How the text is aligned to the point, either "start", "middle" or "end".
+	Anchor string
+}
+
+// A chartFrame is the area of a chart that the data is plotted in, along with the labels on its axes.
+type chartFrame struct {
+	Width  int
+	Height int
+	// The edges of the plot area.
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+	Labels []chartLabel
+}
+
+// A lineChart is the geometry of a chart that plots values over time as a line.
+type lineChart struct {
+	chartFrame
+	// The points on the line, formatted for the points attribute of an SVG polyline.
+	Points string
+	// The points on a straight line from the first value down to zero, which shows the ideal progress.
+	Ideal string
+}
+
+// A barChart is the geometry of a chart that plots a bar for each value.
+type barChart struct {
+	chartFrame
+	Bars []chartBar
+}
+
+// A chartBar is one of the bars in a [barChart].
+type chartBar struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	// The value of the bar, shown when hovering over the bar.
+	Title string
+}
+
+// Create the frame for a chart where the y-axis goes from zero up to at least `max`.
+//
+// Returns the frame and the value at the top of the y-axis.
+func newChartFrame(max float64) (chartFrame, float64) {
+	frame := chartFrame{
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartMargin,
+		Right:  chartWidth - chartMargin/2,
+		Top:    chartMargin / 2,
+		Bottom: chartHeight - chartMargin,
+	}
+
+	// Leave room above the largest value, and keep the axis from collapsing when every value is zero.
+	top := math.Max(1, math.Ceil(max))
+	frame.Labels = []chartLabel{
+		{X: frame.Left - 6, Y: frame.Bottom, Text: "0", Anchor: "end"},
+		{X: frame.Left - 6, Y: frame.Top, Text: formatChartValue(top), Anchor: "end"},
+	}
+
+	return frame, top
+}
+
+// Get the y-coordinate of `value` on a y-axis that goes from zero up to `top`.
+func (f chartFrame) y(value float64, top float64) float64 {
+	return roundChartCoordinate(f.Bottom - value/top*(f.Bottom-f.Top))
+}
+
+// Create a burndown chart plotting `values`, where `labels` holds the label of each point on the x-axis.
+// Only the first and last points are labelled to keep the labels from overlapping.
+func newBurndownChart(values []float64, labels []string) lineChart {
+	frame, top := newChartFrame(maxValue(values))
+	chart := lineChart{chartFrame: frame}
+
+	if len(values) == 0 {
+		return chart
+	}
+
+	// Spread the points across the width of the plot, keeping a single point on the left edge.
+	x := func(i int) float64 {
+		if len(values) == 1 {
+			return frame.Left
+		}
+
+		return roundChartCoordinate(frame.Left + float64(i)/float64(len(values)-1)*(frame.Right-frame.Left))
+	}
+
+	points := make([]string, len(values))
+
+	for i, value := range values {
+		points[i] = fmt.Sprintf("%v,%v", x(i), frame.y(value, top))
+	}
+
+	last := len(values) - 1
+	chart.Points = strings.Join(points, " ")
+	chart.Ideal = fmt.Sprintf("%v,%v %v,%v", x(0), frame.y(values[0], top), x(last), frame.Bottom)
+	chart.Labels = append(chart.Labels, chartLabel{X: x(0), Y: frame.Bottom + 16, Text: labels[0], Anchor: "start"})
+
+	if last > 0 {
+		chart.Labels = append(chart.Labels, chartLabel{X: x(last), Y: frame.Bottom + 16, Text: labels[last], Anchor: "end"})
+	}
+
+	return chart
+}
+
+// Create a bar chart with a bar for each of `values`, where `labels` holds the label under each bar.
+func newBarChart(values []float64, labels []string) barChart {
+	frame, top := newChartFrame(maxValue(values))
+	chart := barChart{chartFrame: frame}
+
+	if len(values) == 0 {
+		return chart
+	}
+
+	slot := (frame.Right - frame.Left) / float64(len(values))
+
+	for i, value := range values {
+		x := frame.Left + float64(i)*slot
+		y := frame.y(value, top)
+		chart.Bars = append(chart.Bars, chartBar{
+			// Leave a gap between bars.
+			X:      roundChartCoordinate(x + slot*0.1),
+			Y:      y,
+			Width:  roundChartCoordinate(slot * 0.8),
+			Height: roundChartCoordinate(frame.Bottom - y),
+			Title:  fmt.Sprintf("%s: %s", labels[i], formatChartValue(value)),
+		})
+		chart.Labels = append(chart.Labels, chartLabel{
+			X:      roundChartCoordinate(x + slot/2),
+			Y:      frame.Bottom + 16,
+			Text:   labels[i],
+			Anchor: "middle",
+		})
+	}
+
+	return chart
+}
+
+// Get the largest of `values`, or zero if there are no values.
+func maxValue(values []float64) float64 {
+	largest := 0.0
+
+	for _, value := range values {
+		largest = math.Max(largest, value)
+	}
+
+	return largest
+}
+
+// Round a coordinate to one decimal place to keep the SVG small.
+func roundChartCoordinate(coordinate float64) float64 {
+	return math.Round(coordinate*10) / 10
+}
+
+// Format a value on a chart without trailing zeros, e.g., "2.5" or "3".
+func formatChartValue(value float64) string {
+	return fmt.Sprintf("%g", math.Round(value*10)/10)
+}
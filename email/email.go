@@ -0,0 +1,150 @@
+// Package email reads the emails that are sent to Yatta to be turned into tasks, which are in the Internet Message
+// Format (RFC 5322) with MIME parts (RFC 2045-2049), e.g., the .eml files that a mail server pipes to Yatta.
+package email
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+)
+
+// Message is the parts of an email that make up a task.
+type Message struct {
+	// The decoded subject of the email.
+	Subject string
+	// The first plain text part of the email, or empty if it has no plain text part.
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	// The name of the file, or empty if the sender did not name it.
+	Name string
+	Data []byte
+}
+
+// The headers of a message or of a part of a message.
+type header interface {
+	Get(key string) string
+}
+
+// Parse reads an email from `r`.
+//
+// Returns an error if the email, or one of its parts, is malformed.
+func Parse(r io.Reader) (*Message, error) {
+	message, err := mail.ReadMessage(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the email: %v", err)
+	}
+
+	var decoder mime.WordDecoder
+	subject, err := decoder.DecodeHeader(message.Header.Get("Subject"))
+
+	// Keep the subject as it was sent rather than rejecting the email over an encoding that Go does not know.
+	if err != nil {
+		subject = message.Header.Get("Subject")
+	}
+
+	parsed := &Message{Subject: strings.TrimSpace(subject)}
+
+	if err := parsed.readPart(message.Header, message.Body); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+// Read the part with `header` and `body`, and the parts nested in it, into the message.
+func (m *Message) readPart(header header, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+
+	// Parts without a (valid) type are plain text.
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	body = decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+
+		for {
+			part, err := parts.NextRawPart()
+
+			if err == io.EOF {
+				return nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("could not read the parts of the email: %v", err)
+			}
+
+			if err := m.readPart(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := dispositionParams["filename"]
+
+	if name == "" {
+		name = params["name"]
+	}
+
+	if mediaType == "text/plain" && disposition != "attachment" && name == "" {
+		if m.Body != "" {
+			return nil
+		}
+
+		text, err := io.ReadAll(body)
+
+		if err != nil {
+			return fmt.Errorf("could not read the body of the email: %v", err)
+		}
+
+		m.Body = strings.TrimSpace(strings.ReplaceAll(string(text), "\r\n", "\n"))
+
+		return nil
+	}
+
+	// Other inline parts, such as HTML versions of the body, are not needed for the task.
+	if disposition != "attachment" && name == "" {
+		return nil
+	}
+
+	data, err := io.ReadAll(body)
+
+	if err != nil {
+		return fmt.Errorf("could not read the attachment %q: %v", name, err)
+	}
+
+	var decoder mime.WordDecoder
+
+	if decoded, err := decoder.DecodeHeader(name); err == nil {
+		name = decoded
+	}
+
+	m.Attachments = append(m.Attachments, Attachment{Name: name, Data: data})
+
+	return nil
+}
+
+// Undo the `encoding` of `body`, e.g., base64.
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
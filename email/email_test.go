@@ -0,0 +1,101 @@
+package email_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/email"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("plain text email", func(t *testing.T) {
+		message := "From: alerts@example.com\r\n" +
+			"To: tasks@example.com\r\n" +
+			"Subject: =?UTF-8?Q?Disk_almost_full_=E2=80=94_db1?=\r\n" +
+			"\r\n" +
+			"Usage is at 95%.\r\n" +
+			"Free up some space.\r\n"
+
+		got, err := email.Parse(strings.NewReader(message))
+
+		if err != nil {
+			t.Fatalf("could not parse the email: %v", err)
+		}
+
+		want := &email.Message{Subject: "Disk almost full — db1", Body: "Usage is at 95%.\nFree up some space."}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("multipart email with attachments", func(t *testing.T) {
+		message := "From: alice@example.com\r\n" +
+			"Subject: Review the report\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: multipart/mixed; boundary=outer\r\n" +
+			"\r\n" +
+			"--outer\r\n" +
+			"Content-Type: multipart/alternative; boundary=inner\r\n" +
+			"\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/plain; charset=utf-8\r\n" +
+			"Content-Transfer-Encoding: quoted-printable\r\n" +
+			"\r\n" +
+			"See the attached caf=C3=A9 report.\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/html; charset=utf-8\r\n" +
+			"\r\n" +
+			"<p>See the attached report.</p>\r\n" +
+			"--inner--\r\n" +
+			"--outer\r\n" +
+			"Content-Type: text/plain; name=notes.txt\r\n" +
+			"\r\n" +
+			"first draft\r\n" +
+			"--outer\r\n" +
+			"Content-Type: application/octet-stream\r\n" +
+			"Content-Disposition: attachment; filename=\"report.bin\"\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"\r\n" +
+			"AAEC\r\n" +
+			"Aw==\r\n" +
+			"--outer--\r\n"
+
+		got, err := email.Parse(strings.NewReader(message))
+
+		if err != nil {
+			t.Fatalf("could not parse the email: %v", err)
+		}
+
+		want := &email.Message{
+			Subject: "Review the report",
+			Body:    "See the attached café report.",
+			Attachments: []email.Attachment{
+				{Name: "notes.txt", Data: []byte("first draft")},
+				{Name: "report.bin", Data: []byte{0, 1, 2, 3}},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("malformed emails are rejected", func(t *testing.T) {
+		message := "Subject: broken\r\n" +
+			"Content-Type: multipart/mixed; boundary=b\r\n" +
+			"\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"no closing boundary"
+
+		if _, err := email.Parse(strings.NewReader(message)); err == nil {
+			t.Error("got no error for an email with a truncated part")
+		}
+
+		if _, err := email.Parse(strings.NewReader("not an email")); err == nil {
+			t.Error("got no error for text without headers")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Package events tells whoever is listening about changes to task lists as they happen, e.g., so that the pages of a
+// shared list that are open in other browsers can show a task as soon as it is added.
+package events
+
+import (
+	"sync"
+)
+
+// Kind is what happened to a task.
+type Kind string
+
+const (
+	// The task was added to the list.
+	TaskAdded Kind = "task-added"
+	// The task was changed, e.g., completed, edited or moved.
+	TaskChanged Kind = "task-changed"
+	// The task was taken out of the list, e.g., deleted or archived.
+	TaskRemoved Kind = "task-removed"
+)
+
+// Event is a change to a task in a user's task list.
+type Event struct {
+	// Starts at one and goes up by one with each event published to a bus, so listeners that lost their connection
+	// can ask for the events they missed.
+	ID uint64
+	// The email of the user whose task list changed.
+	List   string
+	Kind   Kind
+	TaskID uint64
+}
+
+// How many events a subscriber may fall behind by before it is unsubscribed.
+const subscriberBuffer = 64
+
+// Bus passes the events published to it on to the subscribers of the list that the event is about.
+//
+// A bus remembers the most recent events so that subscribers can catch up on the events they missed. It is safe to
+// use a bus from multiple goroutines.
+type Bus struct {
+	// Guards all other fields.
+	mu sync.Mutex
+	// The ID of the last published event.
+	lastID uint64
+	// The most recent events, oldest first.
+	history []Event
+	// The maximum number of events in the history.
+	historySize int
+	// The channels of the subscribers of each list.
+	subscribers map[string]map[chan Event]struct{}
+}
+
+// Create a bus that remembers the last `historySize` events.
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		historySize: historySize,
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Publish tells the subscribers of the task list of `list` that the task with `taskID` changed.
+//
+// Publishing never waits for subscribers. A subscriber that has fallen too far behind is unsubscribed instead, which
+// closes its channel, so that it can subscribe again and catch up from the history.
+func (b *Bus) Publish(list string, kind Kind, taskID uint64) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, List: list, Kind: kind, TaskID: taskID}
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = b.history[1:]
+		}
+
+		b.history = append(b.history, event)
+	}
+
+	for events := range b.subscribers[list] {
+		select {
+		case events <- event:
+		default:
+			b.unsubscribe(list, events)
+		}
+	}
+
+	return event
+}
+
+// Subscribe listens for changes to the task list of `list`.
+//
+// The events in the history after the event with `lastEventID` are sent first, so pass the ID of the last event that
+// was received when subscribing again, or zero to only get new events. Call the returned function to stop listening.
+func (b *Bus) Subscribe(list string, lastEventID uint64) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+
+	if lastEventID != 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID && event.List == list {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	events := make(chan Event, len(missed)+subscriberBuffer)
+
+	for _, event := range missed {
+		events <- event
+	}
+
+	if b.subscribers[list] == nil {
+		b.subscribers[list] = make(map[chan Event]struct{})
+	}
+
+	b.subscribers[list][events] = struct{}{}
+
+	return events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.unsubscribe(list, events)
+	}
+}
+
+// Stop sending events to `events` and close it, unless that was already done. The caller must hold the lock.
+func (b *Bus) unsubscribe(list string, events chan Event) {
+	if _, ok := b.subscribers[list][events]; !ok {
+		return
+	}
+
+	delete(b.subscribers[list], events)
+	close(events)
+
+	if len(b.subscribers[list]) == 0 {
+		delete(b.subscribers, list)
+	}
+}
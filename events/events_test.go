@@ -0,0 +1,98 @@
+package events_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/events"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("subscribers only get the events of their list", func(t *testing.T) {
+		bus := events.NewBus(10)
+		alice, stop := bus.Subscribe("alice@example.com", 0)
+		defer stop()
+
+		bus.Publish("bob@example.com", events.TaskAdded, 1)
+		bus.Publish("alice@example.com", events.TaskChanged, 2)
+
+		want := events.Event{ID: 2, List: "alice@example.com", Kind: events.TaskChanged, TaskID: 2}
+
+		if got := <-alice; got != want {
+			t.Errorf("got event %+v, want %+v", got, want)
+		}
+
+		if len(alice) != 0 {
+			t.Errorf("got %d more events, want none", len(alice))
+		}
+	})
+
+	t.Run("subscribers catch up on the events they missed", func(t *testing.T) {
+		bus := events.NewBus(2)
+
+		bus.Publish("alice@example.com", events.TaskAdded, 1)
+		bus.Publish("alice@example.com", events.TaskAdded, 2)
+		bus.Publish("bob@example.com", events.TaskAdded, 3)
+		bus.Publish("alice@example.com", events.TaskRemoved, 1)
+
+		alice, stop := bus.Subscribe("alice@example.com", 1)
+		defer stop()
+
+		// Only the last two events are remembered, so the second event is lost.
+		assertEvents(t, alice, []uint64{4})
+
+		bob, stop := bus.Subscribe("bob@example.com", 0)
+		defer stop()
+
+		assertEvents(t, bob, nil)
+	})
+
+	t.Run("unsubscribing closes the channel", func(t *testing.T) {
+		bus := events.NewBus(0)
+		alice, stop := bus.Subscribe("alice@example.com", 0)
+
+		stop()
+		stop()
+
+		if _, ok := <-alice; ok {
+			t.Error("got an event, want the channel to be closed")
+		}
+
+		bus.Publish("alice@example.com", events.TaskAdded, 1)
+	})
+
+	t.Run("subscribers that fall behind are unsubscribed", func(t *testing.T) {
+		bus := events.NewBus(0)
+		alice, stop := bus.Subscribe("alice@example.com", 0)
+		defer stop()
+
+		received := 0
+
+		for id := uint64(1); id <= 100; id++ {
+			bus.Publish("alice@example.com", events.TaskAdded, id)
+		}
+
+		for range alice {
+			received++
+		}
+
+		if received == 0 || received == 100 {
+			t.Errorf("got %d events before the channel was closed, want some but not all", received)
+		}
+	})
+}
+
+// Check that the events waiting in `events` have the IDs in `want`.
+func assertEvents(t testing.TB, events <-chan events.Event, want []uint64) {
+	t.Helper()
+
+	var got []uint64
+
+	for len(events) > 0 {
+		got = append(got, (<-events).ID)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/ical"
+)
+
+// Show the URL that calendar apps can subscribe to for the tasks in the user's task list. Only the owner and admins of
+// the list may see the URL, since anyone who knows it can see the tasks, even after they stop being a member.
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	token, err := taskStore.GetFeedToken(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the feed token of the task list of %q: %v", owner, err))
+		return
+	}
+
+	page := FeedPage{User: owner}
+
+	if token != "" {
+		page.URL = fmt.Sprintf("%s/feeds/%s/tasks.ics", baseURL(r), token)
+	}
+
+	body, err := s.renderer.RenderFeed(page)
+	writeResponse(w, body, err, r.URL)
+}
+
+// Give the user's task list a new feed token, which stops the old feed URL from working.
+func (s *Server) resetFeedToken(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	if _, err := taskStore.ResetFeedToken(owner); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not reset the feed token of the task list of %q", owner))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Serve the tasks with due dates in the task list with the feed token in the path as an iCalendar feed. Tasks are
+// written as events unless the `type` query parameter is "todo", which writes them as to-dos for task apps.
+//
+// The feed is not authenticated since calendar apps cannot log in, so the token is the only thing protecting it.
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	owner, err := s.taskStore.GetFeedOwner(r.PathValue("token"))
+
+	if err != nil {
+		writeTaskStoreError(w, err, "could not get the task list of a feed token")
+		return
+	}
+
+	taskStore := s.tasksForSecretLink(w, r, owner)
+
+	if taskStore == nil {
+		return
+	}
+
+	component := ical.VEvent
+
+	switch r.URL.Query().Get("type") {
+	case "", "event":
+	case "todo":
+		component = ical.VTodo
+	default:
+		http.Error(w, fmt.Sprintf(`invalid type %q, expected "event" or "todo"`, r.URL.Query().Get("type")), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks for %q: %v", owner, err))
+		return
+	}
+
+	feed := ical.Feed{
+		Name:      fmt.Sprintf("%s's tasks", owner),
+		BaseURL:   baseURL(r),
+		Component: component,
+		Now:       time.Now(),
+		Tasks:     tasks,
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+
+	if err := ical.Write(w, feed); err != nil {
+		slog.Error(fmt.Sprintf("an error occurred while writing the calendar feed for %q: %v", owner, err))
+	}
+}
@@ -4,4 +4,13 @@ go 1.22.7
 
 require golang.org/x/net v0.33.0
 
-require golang.org/x/crypto v0.31.0
+require (
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/yuin/goldmark v1.8.6
+	golang.org/x/crypto v0.31.0
+)
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+)
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// How long a blob may go without being attached to a task before it is deleted. This gives uploads time to finish
+// attaching the blob after storing it.
+const blobGracePeriod = time.Hour
+
+// Housekeeping is a background job that archives old completed tasks, empties old tasks from the trash and deletes
+// the contents of files that are no longer attached to any task.
+type Housekeeping struct {
+	Store stores.TaskStore
+	// Where the contents of attachments are stored, or nil if attachments are disabled.
+	Blobs stores.BlobStore
+	// How long tasks stay in the trash before they are permanently deleted.
+	TrashRetention time.Duration
+	// How long completed tasks stay in the task list before they are archived. Zero disables archiving.
+	ArchiveAfter time.Duration
+}
+
+// Run tidies up the task store once, treating `now` as the current time.
+//
+// Returns an error if the task store could not be updated.
+func (h Housekeeping) Run(now time.Time) error {
+	if err := h.Store.PurgeTrash(now.Add(-h.TrashRetention)); err != nil {
+		return fmt.Errorf("could not empty the trash: %v", err)
+	}
+
+	if h.ArchiveAfter > 0 {
+		if err := h.Store.ArchiveCompletedTasks(now.Add(-h.ArchiveAfter)); err != nil {
+			return fmt.Errorf("could not archive completed tasks: %v", err)
+		}
+	}
+
+	if h.Blobs != nil {
+		if err := h.collectGarbage(now); err != nil {
+			return fmt.Errorf("could not delete unused blobs: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Delete the blobs that are not attached to any task, ignoring blobs that were stored recently.
+func (h Housekeeping) collectGarbage(now time.Time) error {
+	// Get the keys of the blobs first, so that blobs attached after the attachments are listed are not deleted.
+	keys, err := h.Blobs.GetKeys(now.Add(-blobGracePeriod))
+
+	if err != nil {
+		return err
+	}
+
+	attached, err := h.Store.GetAttachmentKeys()
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if slices.Contains(attached, key) {
+			continue
+		}
+
+		if err := h.Blobs.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start runs the job immediately and then every `interval` until `ctx` is cancelled.
+//
+// Errors are logged rather than stopping the job, so that a failed run is retried on the next tick.
+func (h Housekeeping) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.Run(time.Now()); err != nil {
+			slog.Error(fmt.Sprintf("housekeeping failed: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
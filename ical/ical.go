@@ -0,0 +1,251 @@
+// Package ical reads and writes tasks as iCalendar (RFC 5545) data, such as the feed that calendar apps subscribe to
+// and the to-dos that CalDAV clients sync.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A Component is the kind of calendar component that tasks are written as.
+type Component string
+
+const (
+	// VEvent writes tasks as events on the day, or at the time, they are due. Most calendar apps only show events.
+	VEvent Component = "VEVENT"
+	// VTodo writes tasks as to-dos that are due on the day, or at the time, they are due, which task apps such as
+	// reminders show along with whether the tasks are done.
+	VTodo Component = "VTODO"
+)
+
+const (
+	// The longest a content line can be, in octets, before it is folded onto the next line.
+	maxLineLength = 75
+	// The format of DATE values.
+	dateFormat = "20060102"
+	// The format of DATE-TIME values in UTC.
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// A Feed is a calendar of the tasks in a task list.
+type Feed struct {
+	// The name of the calendar shown by calendar apps.
+	Name string
+	// The URL of the Yatta server, e.g., "https://yatta.example.com", which is used to link to tasks.
+	BaseURL string
+	// The kind of component that tasks are written as.
+	Component Component
+	// When the feed was made.
+	Now time.Time
+	// The tasks to write. Tasks without a due date are left out unless Undated is set, and done tasks are left out when
+	// writing events.
+	Tasks []models.Task
+	// Whether to write to-dos for tasks without a due date, e.g., when syncing every task with a task app.
+	Undated bool
+}
+
+// Write the calendar of `feed` to `w`.
+func Write(w io.Writer, feed Feed) error {
+	writer := &contentWriter{w: bufio.NewWriter(w)}
+	stamp := feed.Now.UTC().Format(dateTimeFormat)
+
+	writer.line("BEGIN", "VCALENDAR")
+	writer.line("VERSION", "2.0")
+	writer.line("PRODID", "-//Yatta//Yatta//EN")
+	writer.line("CALSCALE", "GREGORIAN")
+
+	if feed.Name != "" {
+		writer.line("X-WR-CALNAME", escapeText(feed.Name))
+	}
+
+	for _, task := range feed.Tasks {
+		if task.Due == nil && (feed.Component == VEvent || !feed.Undated) {
+			continue
+		}
+
+		if feed.Component == VEvent && task.Done {
+			continue
+		}
+
+		writer.line("BEGIN", string(feed.Component))
+		writer.line("UID", escapeText(UID(task)))
+		writer.line("DTSTAMP", stamp)
+
+		switch {
+		case task.Due == nil:
+		case feed.Component == VTodo:
+			// A recurring to-do repeats from its start, which is when it is first due.
+			if task.Recurrence != nil {
+				writer.date("DTSTART", *task.Due)
+			}
+
+			writer.date("DUE", *task.Due)
+		default:
+			writer.date("DTSTART", *task.Due)
+		}
+
+		if task.Recurrence != nil && task.Due != nil {
+			if rule := recurrenceRule(*task.Recurrence, *task.Due); rule != "" {
+				writer.line("RRULE", rule)
+			}
+		}
+
+		writer.line("SUMMARY", escapeText(task.Description))
+
+		if task.Notes != "" {
+			writer.line("DESCRIPTION", escapeText(task.Notes))
+		}
+
+		if len(task.Tags) > 0 {
+			categories := make([]string, len(task.Tags))
+
+			for i, tag := range task.Tags {
+				categories[i] = escapeText(tag)
+			}
+
+			writer.line("CATEGORIES", strings.Join(categories, ","))
+		}
+
+		writer.line("URL", fmt.Sprintf("%s/tasks/%d", feed.BaseURL, task.ID))
+
+		if feed.Component == VTodo {
+			if task.Done {
+				writer.line("STATUS", "COMPLETED")
+
+				if task.CompletedAt != nil {
+					writer.line("COMPLETED", task.CompletedAt.UTC().Format(dateTimeFormat))
+				}
+			} else {
+				writer.line("STATUS", "NEEDS-ACTION")
+			}
+		}
+
+		writer.line("END", string(feed.Component))
+	}
+
+	writer.line("END", "VCALENDAR")
+
+	if writer.err != nil {
+		return writer.err
+	}
+
+	return writer.w.Flush()
+}
+
+// UID gets the iCalendar UID of `task`, which is the UID given by the CalDAV client that made the task, if any, and is
+// made from the ID of the task otherwise, so that it stays the same whichever host name the server is reached by.
+func UID(task models.Task) string {
+	if task.CalendarObject != nil && task.CalendarObject.UID != "" {
+		return task.CalendarObject.UID
+	}
+
+	return fmt.Sprintf("task-%d@yatta", task.ID)
+}
+
+// Writes content lines, keeping the first error so that it only needs to be checked once.
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// Write the property `name` with `value`, which must already be escaped, folding the line so that no line is longer
+// than 75 octets.
+func (c *contentWriter) line(name string, value string) {
+	if c.err != nil {
+		return
+	}
+
+	_, c.err = c.w.WriteString(fold(name + ":" + value))
+}
+
+// Write the property `name` with the date of `t` if it is at midnight, which is how tasks due on a day rather than at a
+// time are stored, and with the time of `t` in UTC otherwise.
+func (c *contentWriter) date(name string, t time.Time) {
+	if hour, minute, second := t.Clock(); hour == 0 && minute == 0 && second == 0 {
+		c.line(name+";VALUE=DATE", t.Format(dateFormat))
+	} else {
+		c.line(name, t.UTC().Format(dateTimeFormat))
+	}
+}
+
+// Split `line` into lines of at most 75 octets, where each line after the first starts with a space, without
+// splitting UTF-8 characters. Each line ends with CRLF.
+func fold(line string) string {
+	var folded strings.Builder
+	limit := maxLineLength
+
+	for len(line) > limit {
+		end := limit
+
+		for end > 0 && !utf8.RuneStart(line[end]) {
+			end--
+		}
+
+		folded.WriteString(line[:end])
+		folded.WriteString("\r\n ")
+		line = line[end:]
+		// The space that starts a folded line counts towards its length.
+		limit = maxLineLength - 1
+	}
+
+	folded.WriteString(line)
+	folded.WriteString("\r\n")
+
+	return folded.String()
+}
+
+// Escape the backslashes, semicolons, commas and line breaks in a TEXT value.
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// Get the RRULE value for a task that repeats with `recurrence` from `start`, or an empty string if the recurrence has
+// an unknown frequency.
+//
+// Occurrences that would land past the end of a month are moved back to its last day, as done by
+// [models.Recurrence.Occurrence], rather than being skipped as RFC 5545 does by default.
+func recurrenceRule(recurrence models.Recurrence, start time.Time) string {
+	var rule string
+
+	switch recurrence.Frequency {
+	case models.Daily:
+		rule = "FREQ=DAILY"
+	case models.Weekly:
+		rule = "FREQ=WEEKLY"
+	case models.Monthly:
+		rule = "FREQ=MONTHLY"
+	case models.Yearly:
+		rule = "FREQ=YEARLY"
+	default:
+		return ""
+	}
+
+	if recurrence.Interval > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", recurrence.Interval)
+	}
+
+	// Take the first of the day and the last day of the month, which is the day itself in months that have it.
+	_, month, day := start.Date()
+
+	switch {
+	case recurrence.Frequency == models.Monthly && day > 28:
+		rule += fmt.Sprintf(";BYMONTHDAY=%d,-1;BYSETPOS=1", day)
+	case recurrence.Frequency == models.Yearly && month == time.February && day == 29:
+		rule += ";BYMONTH=2;BYMONTHDAY=29,-1;BYSETPOS=1"
+	}
+
+	return rule
+}
@@ -0,0 +1,194 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AnthonyDickson/yatta/ical"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestWrite(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+
+	if err != nil {
+		t.Fatalf("could not load the time zone: %v", err)
+	}
+
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, auckland)
+	// 17:00 in Auckland is 04:00 UTC during daylight saving time.
+	evening := time.Date(2025, 3, 14, 17, 0, 0, 0, auckland)
+	completed := time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{ID: 1, Description: "Pay rent; water, power", Notes: "Account: 12-3456\nRef: \\rent", Due: &day, Tags: []string{"home", "bills,monthly"}, Recurrence: &models.Recurrence{Frequency: models.Monthly, Interval: 1}},
+		{ID: 2, Description: "Call Bob", Due: &evening},
+		{ID: 3, Description: "Someday"},
+		{ID: 4, Description: "File taxes", Due: &day, Done: true, CompletedAt: &completed},
+	}
+
+	write := func(t *testing.T, component ical.Component) string {
+		t.Helper()
+
+		var out strings.Builder
+		err := ical.Write(&out, ical.Feed{Name: "Alice's tasks", BaseURL: "https://yatta.example.com", Component: component, Now: now, Tasks: tasks})
+
+		if err != nil {
+			t.Fatalf("could not write the feed: %v", err)
+		}
+
+		return out.String()
+	}
+
+	t.Run("events", func(t *testing.T) {
+		want := "BEGIN:VCALENDAR\r\n" +
+			"VERSION:2.0\r\n" +
+			"PRODID:-//Yatta//Yatta//EN\r\n" +
+			"CALSCALE:GREGORIAN\r\n" +
+			"X-WR-CALNAME:Alice's tasks\r\n" +
+			"BEGIN:VEVENT\r\n" +
+			"UID:task-1@yatta\r\n" +
+			"DTSTAMP:20250301T093000Z\r\n" +
+			"DTSTART;VALUE=DATE:20250314\r\n" +
+			"RRULE:FREQ=MONTHLY\r\n" +
+			"SUMMARY:Pay rent\\; water\\, power\r\n" +
+			"DESCRIPTION:Account: 12-3456\\nRef: \\\\rent\r\n" +
+			"CATEGORIES:home,bills\\,monthly\r\n" +
+			"URL:https://yatta.example.com/tasks/1\r\n" +
+			"END:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\n" +
+			"UID:task-2@yatta\r\n" +
+			"DTSTAMP:20250301T093000Z\r\n" +
+			"DTSTART:20250314T040000Z\r\n" +
+			"SUMMARY:Call Bob\r\n" +
+			"URL:https://yatta.example.com/tasks/2\r\n" +
+			"END:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"
+
+		if got := write(t, ical.VEvent); got != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("to-dos", func(t *testing.T) {
+		got := write(t, ical.VTodo)
+
+		for _, want := range []string{
+			"BEGIN:VTODO\r\nUID:task-1@yatta\r\nDTSTAMP:20250301T093000Z\r\nDTSTART;VALUE=DATE:20250314\r\nDUE;VALUE=DATE:20250314\r\nRRULE:FREQ=MONTHLY\r\n",
+			"UID:task-2@yatta\r\nDTSTAMP:20250301T093000Z\r\nDUE:20250314T040000Z\r\nSUMMARY:Call Bob\r\n",
+			"URL:https://yatta.example.com/tasks/2\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\n",
+			"URL:https://yatta.example.com/tasks/4\r\nSTATUS:COMPLETED\r\nCOMPLETED:20250302T080000Z\r\nEND:VTODO\r\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("could not find %q in\n%s", want, got)
+			}
+		}
+
+		if strings.Count(got, "BEGIN:VTODO") != 3 || strings.Contains(got, "Someday") {
+			t.Errorf("got\n%s\nwant a to-do for each task with a due date", got)
+		}
+	})
+}
+
+func TestWrite_Undated(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Description: "Someday"},
+		{ID: 2, Description: "From my phone", CalendarObject: &models.CalendarObject{Name: "A1B2.ics", UID: "A1B2-C3D4"}},
+	}
+
+	var out strings.Builder
+
+	if err := ical.Write(&out, ical.Feed{BaseURL: "http://localhost", Component: ical.VTodo, Tasks: tasks, Undated: true}); err != nil {
+		t.Fatalf("could not write the feed: %v", err)
+	}
+
+	got := out.String()
+
+	for _, want := range []string{
+		"BEGIN:VTODO\r\nUID:task-1@yatta\r\nDTSTAMP:",
+		"BEGIN:VTODO\r\nUID:A1B2-C3D4\r\nDTSTAMP:",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("could not find %q in\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "DUE") || strings.Contains(got, "X-WR-CALNAME") {
+		t.Errorf("got\n%s\nwant no due dates or name", got)
+	}
+}
+
+func TestWrite_Folding(t *testing.T) {
+	due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	// Each "é" is two octets, so a naive fold would split one of them.
+	description := "R" + strings.Repeat("é", 100)
+	tasks := []models.Task{{ID: 1, Description: description, Due: &due}}
+
+	var out strings.Builder
+
+	if err := ical.Write(&out, ical.Feed{BaseURL: "http://localhost", Component: ical.VEvent, Tasks: tasks}); err != nil {
+		t.Fatalf("could not write the feed: %v", err)
+	}
+
+	var unfolded strings.Builder
+
+	for _, line := range strings.SplitAfter(out.String(), "\r\n") {
+		if len(line) > 77 {
+			t.Errorf("got a line of %d octets, want at most 75 plus CRLF: %q", len(line), line)
+		}
+
+		if strings.HasPrefix(line, " ") {
+			// Unfold by removing the CRLF and the space that starts the continued line.
+			line = line[1:]
+			current := unfolded.String()
+			unfolded.Reset()
+			unfolded.WriteString(strings.TrimSuffix(current, "\r\n"))
+		}
+
+		unfolded.WriteString(line)
+	}
+
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+description+"\r\n") {
+		t.Errorf("got\n%s\nwant the summary to unfold to %q", unfolded.String(), description)
+	}
+
+	for _, line := range strings.Split(out.String(), "\r\n") {
+		if !utf8.ValidString(line) {
+			t.Errorf("got a line that splits a UTF-8 character: %q", line)
+		}
+	}
+}
+
+func TestWrite_Recurrence(t *testing.T) {
+	cases := []struct {
+		name       string
+		due        time.Time
+		recurrence models.Recurrence
+		want       string
+	}{
+		{"daily", time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), models.Recurrence{Frequency: models.Daily}, "FREQ=DAILY"},
+		{"every 2 weeks", time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), models.Recurrence{Frequency: models.Weekly, Interval: 2}, "FREQ=WEEKLY;INTERVAL=2"},
+		{"yearly", time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), models.Recurrence{Frequency: models.Yearly, Interval: 1}, "FREQ=YEARLY"},
+		// Yatta moves occurrences past the end of a month back to its last day rather than skipping the month.
+		{"monthly from the 31st", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), models.Recurrence{Frequency: models.Monthly}, "FREQ=MONTHLY;BYMONTHDAY=31,-1;BYSETPOS=1"},
+		{"yearly from a leap day", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), models.Recurrence{Frequency: models.Yearly}, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29,-1;BYSETPOS=1"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tasks := []models.Task{{ID: 1, Description: "repeat", Due: &test.due, Recurrence: &test.recurrence}}
+			var out strings.Builder
+
+			if err := ical.Write(&out, ical.Feed{BaseURL: "http://localhost", Component: ical.VEvent, Tasks: tasks}); err != nil {
+				t.Fatalf("could not write the feed: %v", err)
+			}
+
+			if want := "\r\nRRULE:" + test.want + "\r\n"; !strings.Contains(out.String(), want) {
+				t.Errorf("could not find %q in\n%s", want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A Todo is the parts of a VTODO component that Yatta keeps, e.g., from a to-do made in a CalDAV client.
+type Todo struct {
+	UID     string
+	Summary string
+	// The DESCRIPTION of the to-do, which becomes the notes of the task.
+	Description string
+	// When the to-do is due, or nil if it has no due date. Dates without a time are midnight in the local time zone.
+	Due        *time.Time
+	Categories []string
+	// How often the to-do repeats, or nil if it does not repeat. Rule parts that Yatta cannot represent, such as
+	// BYDAY, are ignored.
+	Recurrence *models.Recurrence
+	// Whether the to-do is done.
+	Completed bool
+}
+
+// A content line, e.g., "DUE;VALUE=DATE:20250314".
+type contentLine struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// ErrNoTodo is returned by [ParseTodo] when the calendar does not have a VTODO component.
+var ErrNoTodo = errors.New("the calendar does not have a to-do")
+
+// ParseTodo reads the to-do in the calendar object in `r`, e.g., the body of a CalDAV PUT request. Recurring to-dos may
+// be followed by overrides of single occurrences, which are ignored.
+//
+// Returns [ErrNoTodo] if the calendar does not have a to-do, or an error if the calendar is malformed or has more than
+// one to-do.
+func ParseTodo(r io.Reader) (*Todo, error) {
+	lines, err := readLines(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 || lines[0].Name != "BEGIN" || !strings.EqualFold(lines[0].Value, "VCALENDAR") {
+		return nil, errors.New("the data does not start with BEGIN:VCALENDAR")
+	}
+
+	// The properties of each VTODO, leaving out those of the components nested in them, such as alarms.
+	var blocks [][]contentLine
+	// The components that the current line is nested in, e.g., VCALENDAR, VTODO and VALARM.
+	var components []string
+
+	for _, line := range lines {
+		switch line.Name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(line.Value))
+
+			if len(components) == 2 && components[1] == string(VTodo) {
+				blocks = append(blocks, nil)
+			}
+		case "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(line.Value) {
+				return nil, fmt.Errorf("END:%s does not match the component it ends", line.Value)
+			}
+
+			components = components[:len(components)-1]
+		default:
+			if len(components) == 2 && components[1] == string(VTodo) {
+				blocks[len(blocks)-1] = append(blocks[len(blocks)-1], line)
+			}
+		}
+	}
+
+	if len(components) != 0 {
+		return nil, fmt.Errorf("the %s component is not ended", components[len(components)-1])
+	}
+
+	var todo *Todo
+	uids := map[string]bool{}
+
+	for _, block := range blocks {
+		parsed := &Todo{}
+		override := false
+
+		for _, line := range block {
+			override = override || line.Name == "RECURRENCE-ID"
+
+			if err := parsed.set(line); err != nil {
+				return nil, err
+			}
+		}
+
+		if parsed.UID == "" {
+			return nil, errors.New("a to-do does not have a UID")
+		}
+
+		uids[parsed.UID] = true
+
+		if len(uids) > 1 || (!override && todo != nil) {
+			return nil, errors.New("a calendar object can only have one to-do")
+		}
+
+		if !override {
+			todo = parsed
+		}
+	}
+
+	if todo == nil {
+		return nil, ErrNoTodo
+	}
+
+	return todo, nil
+}
+
+// Set the field of the to-do for the property on `line`.
+func (t *Todo) set(line contentLine) error {
+	switch line.Name {
+	case "UID":
+		t.UID = unescapeText(line.Value)
+	case "SUMMARY":
+		t.Summary = unescapeText(line.Value)
+	case "DESCRIPTION":
+		t.Description = unescapeText(line.Value)
+	case "CATEGORIES":
+		for _, category := range splitText(line.Value) {
+			if category != "" {
+				t.Categories = append(t.Categories, category)
+			}
+		}
+	case "DUE":
+		due, err := parseDate(line)
+
+		if err != nil {
+			return err
+		}
+
+		t.Due = &due
+	case "RRULE":
+		t.Recurrence = parseRecurrenceRule(line.Value)
+	case "STATUS":
+		t.Completed = strings.EqualFold(line.Value, "COMPLETED")
+	case "COMPLETED":
+		t.Completed = true
+	}
+
+	return nil
+}
+
+// Read the content lines of an iCalendar object, unfolding lines that continue onto the next line.
+func readLines(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var unfolded []string
+
+	for scanner.Scan() {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			if len(unfolded) == 0 {
+				return nil, errors.New("the data starts with a folded line")
+			}
+
+			unfolded[len(unfolded)-1] += text[1:]
+			continue
+		}
+
+		if text != "" {
+			unfolded = append(unfolded, text)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the calendar: %v", err)
+	}
+
+	lines := make([]contentLine, len(unfolded))
+
+	for i, text := range unfolded {
+		line, err := parseLine(text)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lines[i] = line
+	}
+
+	return lines, nil
+}
+
+// Parse a content line, e.g., `DTSTART;TZID="Europe/Berlin":20250314T170000`. Names of properties and parameters are
+// made upper case, and quotes around parameter values are removed.
+func parseLine(text string) (contentLine, error) {
+	line := contentLine{Params: map[string]string{}}
+	quoted := false
+	// The start of the name or parameter that is being read.
+	start := 0
+	end := -1
+
+	for i := 0; i < len(text) && end == -1; i++ {
+		switch {
+		case text[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case text[i] == ';' || text[i] == ':':
+			if err := line.addNameOrParam(text[start:i]); err != nil {
+				return line, err
+			}
+
+			start = i + 1
+
+			if text[i] == ':' {
+				end = i
+			}
+		}
+	}
+
+	if end == -1 {
+		return line, fmt.Errorf("the line %q does not have a value", text)
+	}
+
+	line.Value = text[end+1:]
+
+	return line, nil
+}
+
+// Set the name of the line if it does not have one yet, and add the parameter in `part`, e.g., "VALUE=DATE",
+// otherwise.
+func (c *contentLine) addNameOrParam(part string) error {
+	if c.Name == "" {
+		if part == "" {
+			return errors.New("a line does not have a name")
+		}
+
+		c.Name = strings.ToUpper(part)
+
+		return nil
+	}
+
+	name, value, ok := strings.Cut(part, "=")
+
+	if !ok {
+		return fmt.Errorf("the parameter %q of %s does not have a value", part, c.Name)
+	}
+
+	c.Params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+
+	return nil
+}
+
+// Parse a DATE or DATE-TIME value. Dates and floating times are in the local time zone, as are times in a time zone
+// that Go does not know.
+func parseDate(line contentLine) (time.Time, error) {
+	value := line.Value
+
+	if line.Params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		date, err := time.ParseInLocation(dateFormat, value, time.Local)
+
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s has the invalid date %q", line.Name, value)
+		}
+
+		return date, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		date, err := time.Parse(dateTimeFormat, value)
+
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s has the invalid time %q", line.Name, value)
+		}
+
+		return date, nil
+	}
+
+	location := time.Local
+
+	if name := line.Params["TZID"]; name != "" {
+		if loaded, err := time.LoadLocation(name); err == nil {
+			location = loaded
+		}
+	}
+
+	date, err := time.ParseInLocation(strings.TrimSuffix(dateTimeFormat, "Z"), value, location)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s has the invalid time %q", line.Name, value)
+	}
+
+	return date, nil
+}
+
+// Parse the frequency and interval of an RRULE value, e.g., "FREQ=WEEKLY;INTERVAL=2".
+//
+// Returns nil if the rule repeats more often than daily.
+func parseRecurrenceRule(value string) *models.Recurrence {
+	recurrence := models.Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		name, value, _ := strings.Cut(part, "=")
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			recurrence.Frequency = models.Frequency(strings.ToLower(value))
+		case "INTERVAL":
+			if interval, err := strconv.Atoi(value); err == nil && interval > 0 {
+				recurrence.Interval = interval
+			}
+		}
+	}
+
+	switch recurrence.Frequency {
+	case models.Daily, models.Weekly, models.Monthly, models.Yearly:
+		return &recurrence
+	default:
+		return nil
+	}
+}
+
+// Undo [escapeText].
+func unescapeText(text string) string {
+	var unescaped strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i == len(text)-1 {
+			unescaped.WriteByte(text[i])
+			continue
+		}
+
+		i++
+
+		switch text[i] {
+		case 'n', 'N':
+			unescaped.WriteByte('\n')
+		default:
+			unescaped.WriteByte(text[i])
+		}
+	}
+
+	return unescaped.String()
+}
+
+// Split a list of TEXT values on the commas that are not escaped, unescaping each value.
+func splitText(text string) []string {
+	var values []string
+	start := 0
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			// Skip the escaped character.
+			i++
+		case ',':
+			values = append(values, unescapeText(text[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(values, unescapeText(text[start:]))
+}
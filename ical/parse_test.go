@@ -0,0 +1,191 @@
+package ical_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/ical"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestParseTodo(t *testing.T) {
+	t.Run("to-do with every property that is kept", func(t *testing.T) {
+		calendar := "BEGIN:VCALENDAR\r\n" +
+			"VERSION:2.0\r\n" +
+			"PRODID:-//Apple Inc.//iOS 17.4//EN\r\n" +
+			"BEGIN:VTIMEZONE\r\n" +
+			"TZID:Europe/Berlin\r\n" +
+			"END:VTIMEZONE\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:0D5B6E3C-1F2A-4B7C-9E8D-2A1B3C4D5E6F\r\n" +
+			"DTSTAMP:20250301T093000Z\r\n" +
+			"SUMMARY:Pay rent\\; water\\, power\r\n" +
+			"DESCRIPTION:Account: 12-3456\\nRef: \\\\rent and a note that is long enough \r\n" +
+			" to be folded\r\n" +
+			"DUE;TZID=\"Europe/Berlin\":20250314T170000\r\n" +
+			"RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=14\r\n" +
+			"CATEGORIES:home,bills\\,monthly\r\n" +
+			"CATEGORIES:errands\r\n" +
+			"STATUS:NEEDS-ACTION\r\n" +
+			"BEGIN:VALARM\r\n" +
+			"ACTION:DISPLAY\r\n" +
+			"DESCRIPTION:Reminder\r\n" +
+			"TRIGGER:-PT15M\r\n" +
+			"END:VALARM\r\n" +
+			"END:VTODO\r\n" +
+			"END:VCALENDAR\r\n"
+
+		got, err := ical.ParseTodo(strings.NewReader(calendar))
+
+		if err != nil {
+			t.Fatalf("could not parse the to-do: %v", err)
+		}
+
+		berlin, err := time.LoadLocation("Europe/Berlin")
+
+		if err != nil {
+			t.Fatalf("could not load the time zone: %v", err)
+		}
+
+		due := time.Date(2025, 3, 14, 17, 0, 0, 0, berlin)
+		want := &ical.Todo{
+			UID:         "0D5B6E3C-1F2A-4B7C-9E8D-2A1B3C4D5E6F",
+			Summary:     "Pay rent; water, power",
+			Description: "Account: 12-3456\nRef: \\rent and a note that is long enough to be folded",
+			Due:         &due,
+			Categories:  []string{"home", "bills,monthly", "errands"},
+			Recurrence:  &models.Recurrence{Frequency: models.Monthly, Interval: 2},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("dates, times and completion", func(t *testing.T) {
+		cases := []struct {
+			name      string
+			lines     string
+			due       time.Time
+			completed bool
+		}{
+			{"date", "DUE;VALUE=DATE:20250314\nSTATUS:COMPLETED\n", time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local), true},
+			{"time in UTC", "DUE:20250314T040000Z\nCOMPLETED:20250315T080000Z\n", time.Date(2025, 3, 14, 4, 0, 0, 0, time.UTC), true},
+			{"floating time", "DUE:20250314T170000\nSTATUS:IN-PROCESS\n", time.Date(2025, 3, 14, 17, 0, 0, 0, time.Local), false},
+		}
+
+		for _, test := range cases {
+			t.Run(test.name, func(t *testing.T) {
+				// Some clients end lines with LF rather than CRLF.
+				calendar := "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nSUMMARY:call Bob\n" + test.lines + "END:VTODO\nEND:VCALENDAR\n"
+				got, err := ical.ParseTodo(strings.NewReader(calendar))
+
+				if err != nil {
+					t.Fatalf("could not parse the to-do: %v", err)
+				}
+
+				if got.Due == nil || !got.Due.Equal(test.due) || got.Completed != test.completed {
+					t.Errorf("got due %v and completed %t, want %v and %t", got.Due, got.Completed, test.due, test.completed)
+				}
+			})
+		}
+	})
+
+	t.Run("overrides of recurring to-dos are ignored", func(t *testing.T) {
+		calendar := "BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:water-plants\r\n" +
+			"DUE;VALUE=DATE:20250320\r\n" +
+			"RECURRENCE-ID;VALUE=DATE:20250320\r\n" +
+			"SUMMARY:Water the plants on the balcony\r\n" +
+			"END:VTODO\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:water-plants\r\n" +
+			"DUE;VALUE=DATE:20250313\r\n" +
+			"RRULE:FREQ=WEEKLY\r\n" +
+			"SUMMARY:Water the plants\r\n" +
+			"END:VTODO\r\n" +
+			"END:VCALENDAR\r\n"
+
+		got, err := ical.ParseTodo(strings.NewReader(calendar))
+
+		if err != nil {
+			t.Fatalf("could not parse the to-do: %v", err)
+		}
+
+		if got.Summary != "Water the plants" || got.Due.Day() != 13 || got.Recurrence == nil {
+			t.Errorf("got %+v, want the to-do that is not an override", got)
+		}
+	})
+
+	t.Run("reject calendars without exactly one to-do", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			calendar string
+		}{
+			{"not a calendar", "BEGIN:VCARD\r\nEND:VCARD\r\n"},
+			{"no to-do", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+			{"two to-dos", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1\r\nEND:VTODO\r\nBEGIN:VTODO\r\nUID:2\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"},
+			{"no UID", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:a\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"},
+			{"unended component", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1\r\nEND:VCALENDAR\r\n"},
+			{"line without a value", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"},
+			{"invalid date", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1\r\nDUE:tomorrow\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"},
+		}
+
+		for _, test := range cases {
+			t.Run(test.name, func(t *testing.T) {
+				if got, err := ical.ParseTodo(strings.NewReader(test.calendar)); err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+			})
+		}
+
+		calendar := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+		if _, err := ical.ParseTodo(strings.NewReader(calendar)); !errors.Is(err, ical.ErrNoTodo) {
+			t.Errorf("got error %v, want %v", err, ical.ErrNoTodo)
+		}
+	})
+
+	t.Run("written to-dos can be read back", func(t *testing.T) {
+		due := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)
+		task := models.Task{
+			ID:          7,
+			Description: "Pay rent; water, power",
+			Notes:       "line one\nline two",
+			Tags:        []string{"home", "bills"},
+			Due:         &due,
+			Recurrence:  &models.Recurrence{Frequency: models.Monthly, Interval: 1},
+			Done:        true,
+		}
+
+		var out strings.Builder
+
+		if err := ical.Write(&out, ical.Feed{BaseURL: "https://yatta.example.com", Component: ical.VTodo, Tasks: []models.Task{task}}); err != nil {
+			t.Fatalf("could not write the to-do: %v", err)
+		}
+
+		got, err := ical.ParseTodo(strings.NewReader(out.String()))
+
+		if err != nil {
+			t.Fatalf("could not parse the to-do: %v", err)
+		}
+
+		want := &ical.Todo{
+			UID:         "task-7@yatta",
+			Summary:     task.Description,
+			Description: task.Notes,
+			Due:         &due,
+			Categories:  task.Tags,
+			Recurrence:  task.Recurrence,
+			Completed:   true,
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
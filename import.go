@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// The largest file that tasks can be imported from.
+const maxImportSize = 10 << 20
+
+// Show the form for importing tasks into a user's task list from other apps.
+func (s *Server) getImport(w http.ResponseWriter, r *http.Request) {
+	body, err := s.renderer.RenderImport(ImportPage{User: r.PathValue("user"), TimeZone: r.URL.Query().Get("tz")})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Import the tasks in the file in the `file` field of a multipart form into a user's task list. The `format` field
+// gives the format of the file, see [importer.Formats], and the `column-{field}` fields give the CSV column to read
+// each field from, see [importer.Fields]. Dates without a time zone are in the time zone given by the `tz` query
+// parameter.
+//
+// If the `dry-run` field is set, the tasks are shown rather than imported.
+func (s *Server) importTasks(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if err := r.ParseMultipartForm(maxImportSize); errors.Is(err, http.ErrNotMultipart) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the uploaded file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+
+	if err != nil {
+		http.Error(w, "choose a file to import", http.StatusBadRequest)
+		return
+	}
+
+	defer file.Close()
+
+	options := importer.Options{Columns: make(map[importer.Field]string), Location: location}
+
+	for _, field := range importer.Fields {
+		options.Columns[field] = strings.TrimSpace(r.FormValue("column-" + string(field)))
+	}
+
+	tasks, err := importer.Parse(importer.Format(r.FormValue("format")), file, options)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := checkImportDepth(tasks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page := ImportPage{User: user, TimeZone: r.URL.Query().Get("tz")}
+
+	if r.FormValue("dry-run") != "" {
+		page.Preview = tasks
+	} else if page.Imported, err = addImportedTasks(taskStore, user, tasks); err != nil && page.Imported == 0 {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not import tasks into the list of %q", user))
+		return
+	} else if err != nil {
+		// Tasks that were added stay in the list, so the user needs to know to remove them before trying again.
+		slog.Error(fmt.Sprintf("could not import tasks into the list of %q after adding %d of them: %v", user, page.Imported, err))
+		http.Error(w, fmt.Sprintf("only %d of %d tasks were imported before an error occurred, check the task list before importing the file again",
+			page.Imported, importer.Count(tasks)), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := s.renderer.RenderImport(page)
+	writeResponse(w, body, err, r.URL)
+}
+
+// Check that `tasks` are not nested deeper than [stores.MaxTaskDepth], so that they can be added without stopping part
+// way through.
+func checkImportDepth(tasks []importer.Task) error {
+	if depth := importer.Depth(tasks); depth > stores.MaxTaskDepth {
+		return fmt.Errorf("%w: the tasks are nested %d levels deep, but tasks can only be nested %d levels deep",
+			stores.ErrMaxDepthExceeded, depth, stores.MaxTaskDepth)
+	}
+
+	return nil
+}
+
+// Add `tasks`, along with their subtasks, to the task list of `user`.
+//
+// Returns how many tasks were added, which is less than the number of tasks if an error occurred part way through.
+func addImportedTasks(store stores.TaskStore, user string, tasks []importer.Task) (int, error) {
+	added := 0
+
+	// Add `task` with its subtasks, as a subtask of the task with the ID `parent` if it is not nil.
+	var add func(task importer.Task, parent *uint64) error
+	add = func(task importer.Task, parent *uint64) error {
+		var id uint64
+		var err error
+
+		if parent == nil {
+			id, err = store.AddTaskWithDetails(user, task.Description, task.Details())
+		} else if id, err = store.AddSubtask(*parent, task.Description); err == nil {
+			err = store.UpdateTask(id, task.Details())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		added++
+
+		for _, subtask := range task.Subtasks {
+			if err := add(subtask, &id); err != nil {
+				return err
+			}
+		}
+
+		// Tasks are done after their subtasks are added, since completing a task completes its subtasks.
+		if task.Done {
+			return store.SetDone(id, true)
+		}
+
+		return nil
+	}
+
+	for _, task := range tasks {
+		if err := add(task, nil); err != nil {
+			return added, err
+		}
+	}
+
+	return added, nil
+}
+
+// RunImport runs the import command, which imports the tasks in a file into a user's task list, e.g.,
+// "yatta import -user alice@example.com -format todotxt todo.txt". The server should not be running, since it would
+// not see the imported tasks and would overwrite them when it next saves.
+func RunImport(args []string, taskStore stores.TaskStore, userStore stores.UserStore, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	user := flags.String("user", "", "the email of the user whose task list the tasks are added to")
+	format := flags.String("format", "", fmt.Sprintf("the format of the file, one of %v", importer.Formats))
+	columns := flags.String("columns", "", "the CSV column that each field is read from, e.g., \"description=Title,due=Due Date\"")
+	timeZone := flags.String("tz", "", "the time zone of dates without one, e.g., Pacific/Auckland, instead of the local time zone")
+	dryRun := flags.Bool("dry-run", false, "show the tasks in the file rather than importing them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yatta import -user EMAIL -format FORMAT [flags] FILE")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *user == "" {
+		flags.Usage()
+		return errors.New("the import command needs a user and a file")
+	}
+
+	if found, err := userStore.GetUserByEmail(*user); err != nil {
+		return fmt.Errorf("could not get user %q: %v", *user, err)
+	} else if found == nil {
+		return fmt.Errorf("there is no user %q", *user)
+	}
+
+	options := importer.Options{Columns: make(map[importer.Field]string), Location: time.Local}
+
+	if *timeZone != "" {
+		location, err := time.LoadLocation(*timeZone)
+
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q, use a time zone such as Pacific/Auckland", *timeZone)
+		}
+
+		options.Location = location
+	}
+
+	for _, column := range strings.Split(*columns, ",") {
+		if column == "" {
+			continue
+		}
+
+		field, header, ok := strings.Cut(column, "=")
+
+		if !ok {
+			return fmt.Errorf("the column %q is not in the form field=header", column)
+		}
+
+		options.Columns[importer.Field(strings.TrimSpace(field))] = strings.TrimSpace(header)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+
+	if err != nil {
+		return fmt.Errorf("could not open the file to import: %v", err)
+	}
+
+	defer file.Close()
+
+	tasks, err := importer.Parse(importer.Format(*format), file, options)
+
+	if err != nil {
+		return fmt.Errorf("could not read %s: %v", flags.Arg(0), err)
+	}
+
+	if err := checkImportDepth(tasks); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		if err := writeImportPreview(stdout, tasks, 0); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintf(stdout, "%d tasks would be imported into the list of %s\n", importer.Count(tasks), *user)
+
+		return err
+	}
+
+	added, err := addImportedTasks(taskStore, *user, tasks)
+
+	if err != nil {
+		return fmt.Errorf("could not import the tasks after adding %d of them: %v", added, err)
+	}
+
+	_, err = fmt.Fprintf(stdout, "imported %d tasks into the list of %s\n", added, *user)
+
+	return err
+}
+
+// Write a line for each task in `tasks`, e.g., "[x] Pay rent (due 2025-03-14, priority 3, #home)", indenting subtasks
+// under their parents.
+func writeImportPreview(w io.Writer, tasks []importer.Task, depth int) error {
+	for _, task := range tasks {
+		box := "[ ]"
+
+		if task.Done {
+			box = "[x]"
+		}
+
+		var details []string
+
+		if task.Due != nil {
+			layout := "2006-01-02 15:04"
+
+			if hour, minute, _ := task.Due.Clock(); hour == 0 && minute == 0 {
+				layout = time.DateOnly
+			}
+
+			details = append(details, "due "+task.Due.Format(layout))
+		}
+
+		if task.Recurrence != nil {
+			details = append(details, task.Recurrence.String())
+		}
+
+		if task.Priority > 0 {
+			details = append(details, fmt.Sprintf("priority %d", task.Priority))
+		}
+
+		for _, tag := range task.Tags {
+			details = append(details, "#"+tag)
+		}
+
+		line := strings.Repeat("  ", depth) + box + " " + task.Description
+
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+
+		if err := writeImportPreview(w, task.Subtasks, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
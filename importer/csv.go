@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A Field is a detail of a task that a CSV column can be read into.
+type Field string
+
+const (
+	FieldDescription Field = "description"
+	FieldNotes       Field = "notes"
+	FieldTags        Field = "tags"
+	FieldPriority    Field = "priority"
+	FieldDue         Field = "due"
+	FieldDone        Field = "done"
+)
+
+// Fields is every field that a CSV column can be read into, in the order that columns are matched to them.
+var Fields = []Field{FieldDescription, FieldNotes, FieldTags, FieldPriority, FieldDue, FieldDone}
+
+// The headers of the columns that each field is read from when it is not mapped to a column, in the order they are
+// tried. Headers that name the title of a task come before "description", which other apps often use for notes.
+var fieldHeaders = map[Field][]string{
+	FieldDescription: {"title", "name", "task", "content", "summary", "subject", "description"},
+	FieldNotes:       {"notes", "note", "details", "description", "comments"},
+	FieldTags:        {"tags", "tag", "labels", "label", "categories", "category", "projects", "project"},
+	FieldPriority:    {"priority"},
+	FieldDue:         {"due", "due date", "due_date", "deadline", "date"},
+	FieldDone:        {"done", "completed", "complete", "status", "checked", "is_completed"},
+}
+
+// Read a CSV file with a header row, where each row is a task.
+//
+// Tags are separated by commas. Priorities are numbers or low, medium and high. Due dates are dates, e.g.,
+// "2025-03-14", or times, e.g., "2025-03-14 17:00". Tasks are done if the done column is x, yes, true, 1, done or
+// completed.
+func parseCSV(r io.Reader, options Options) ([]Task, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the CSV file: %v", err)
+	}
+
+	// Spreadsheets often start UTF-8 files with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	columns, err := mapColumns(header, options.Columns)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CSV file: %v", err)
+		}
+
+		// Get the value of a field in the row, or an empty string if the field is not in the file or the row is short.
+		value := func(field Field) string {
+			if column, ok := columns[field]; ok && column < len(record) {
+				return strings.TrimSpace(record[column])
+			}
+
+			return ""
+		}
+
+		task := Task{Description: value(FieldDescription), Notes: value(FieldNotes)}
+
+		if task.Description == "" {
+			// Blank rows, which spreadsheets often end with, are skipped.
+			if strings.TrimSpace(strings.Join(record, "")) == "" {
+				continue
+			}
+
+			return nil, fmt.Errorf("row %d: the task does not have a description", row)
+		}
+
+		for _, tag := range strings.Split(value(FieldTags), ",") {
+			if tag = strings.TrimLeft(strings.TrimSpace(tag), "#@"); tag != "" {
+				task.Tags = append(task.Tags, tag)
+			}
+		}
+
+		if task.Priority, err = parsePriority(value(FieldPriority)); err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+
+		if due := value(FieldDue); due != "" {
+			date, err := parseDate(due, options.Location)
+
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+
+			task.Due = &date
+		}
+
+		switch strings.ToLower(value(FieldDone)) {
+		case "x", "yes", "y", "true", "1", "done", "completed", "complete":
+			task.Done = true
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// Get the index of the column that each field is read from, which is the column given in `mapping`, if any, and the
+// first column with a matching header otherwise. Each column is read into at most one field.
+//
+// Returns an error if a mapped column is not in the header, or if no column is read into the description.
+func mapColumns(header []string, mapping map[Field]string) (map[Field]int, error) {
+	columns := make(map[Field]int)
+	used := make(map[int]bool)
+
+	for field, name := range mapping {
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q, use one of %v", field, Fields)
+		}
+
+		if name == "" {
+			continue
+		}
+
+		column := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+
+		if column == -1 {
+			return nil, fmt.Errorf("the CSV file does not have the column %q for %s", name, field)
+		}
+
+		columns[field] = column
+		used[column] = true
+	}
+
+	for _, field := range Fields {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+
+		for _, name := range fieldHeaders[field] {
+			column := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+
+			if column != -1 && !used[column] {
+				columns[field] = column
+				used[column] = true
+				break
+			}
+		}
+	}
+
+	if _, ok := columns[FieldDescription]; !ok {
+		return nil, fmt.Errorf("could not find the column for the description in %q, choose the column to use", header)
+	}
+
+	return columns, nil
+}
+
+// Parse a priority, which is a number or low, medium or high. An empty priority is no priority.
+func parsePriority(value string) (int, error) {
+	switch strings.ToLower(value) {
+	case "", "none":
+		return 0, nil
+	case "low":
+		return 1, nil
+	case "medium":
+		return 2, nil
+	case "high":
+		return 3, nil
+	}
+
+	priority, err := strconv.Atoi(value)
+
+	if err != nil || priority < 0 {
+		return 0, fmt.Errorf("%q is not a priority, use a number or low, medium or high", value)
+	}
+
+	return priority, nil
+}
@@ -0,0 +1,83 @@
+package importer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+)
+
+func TestParse_CSV(t *testing.T) {
+	t.Run("columns matched by header", func(t *testing.T) {
+		// Spreadsheets often start files with a byte order mark.
+		file := "\ufeffTitle,Description,Labels,Priority,Due Date,Completed\n" +
+			"Pay rent,\"Account 12, ref 3\",\"home, #bills\",high,2025-03-14,no\n" +
+			",,,,,\n" +
+			"Call Mum,,,2,2025-03-15 17:30,yes\n"
+
+		got, err := importer.Parse(importer.CSV, strings.NewReader(file), importer.Options{Location: time.UTC})
+
+		if err != nil {
+			t.Fatalf("could not parse the file: %v", err)
+		}
+
+		rent := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+		call := time.Date(2025, 3, 15, 17, 30, 0, 0, time.UTC)
+		want := []importer.Task{
+			{Description: "Pay rent", Notes: "Account 12, ref 3", Tags: []string{"home", "bills"}, Priority: 3, Due: &rent},
+			{Description: "Call Mum", Priority: 2, Due: &call, Done: true},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("columns mapped by the user", func(t *testing.T) {
+		file := "Item,Comment,When\nPay rent,by card,2025-03-14T09:00:00Z\n"
+		options := importer.Options{Columns: map[importer.Field]string{
+			importer.FieldDescription: "item",
+			importer.FieldNotes:       "Comment",
+			importer.FieldDue:         "When",
+		}}
+
+		got, err := importer.Parse(importer.CSV, strings.NewReader(file), options)
+
+		if err != nil {
+			t.Fatalf("could not parse the file: %v", err)
+		}
+
+		due := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+
+		if len(got) != 1 || got[0].Description != "Pay rent" || got[0].Notes != "by card" || !got[0].Due.Equal(due) {
+			t.Errorf("got %+v, want the task to pay rent", got)
+		}
+	})
+
+	t.Run("reject files that cannot be read", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			file    string
+			columns map[importer.Field]string
+		}{
+			{"no description column", "Item,When\nPay rent,2025-03-14\n", nil},
+			{"missing mapped column", "Title\nPay rent\n", map[importer.Field]string{importer.FieldDue: "When"}},
+			{"unknown field", "Title\nPay rent\n", map[importer.Field]string{"colour": "Title"}},
+			{"invalid date", "Title,Due\nPay rent,tomorrow\n", nil},
+			{"invalid priority", "Title,Priority\nPay rent,urgent\n", nil},
+			{"no description", "Title,Due\n,2025-03-14\n", nil},
+		}
+
+		for _, test := range cases {
+			t.Run(test.name, func(t *testing.T) {
+				got, err := importer.Parse(importer.CSV, strings.NewReader(test.file), importer.Options{Columns: test.columns})
+
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+			})
+		}
+	})
+}
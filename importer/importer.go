@@ -0,0 +1,128 @@
+// Package importer reads tasks from the files that other to-do apps export, so that existing lists can be moved to
+// Yatta.
+//
+// The formats that are read are:
+//
+//   - todo.txt, with completion, priorities, +projects, @contexts and due:YYYY-MM-DD.
+//   - CSV with a header row, where each column can be mapped to a detail of a task.
+//   - The JSON export of a Trello board.
+//   - The JSON export of Todoist tasks, from either the REST API or a Sync API backup.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A Format is a kind of file that tasks can be imported from.
+type Format string
+
+const (
+	TodoTxt Format = "todotxt"
+	CSV     Format = "csv"
+	Trello  Format = "trello"
+	Todoist Format = "todoist"
+)
+
+// Formats is every format that tasks can be imported from.
+var Formats = []Format{TodoTxt, CSV, Trello, Todoist}
+
+// Options change how files are read.
+type Options struct {
+	// The CSV column that each field is read from, by header. Fields that are not mapped are read from the column with
+	// a matching header, if any, see [Fields].
+	Columns map[Field]string
+	// The time zone of dates and times that do not have one, or the local time zone if nil.
+	Location *time.Location
+}
+
+// A Task is a task read from an export, which has not been added to a task list yet.
+type Task struct {
+	Description string
+	Notes       string
+	Tags        []string
+	Priority    int
+	// When the task is due, or nil if it has no due date. Tasks due on a day rather than at a time are due at
+	// midnight.
+	Due        *time.Time
+	Recurrence *models.Recurrence
+	Done       bool
+	Subtasks   []Task
+}
+
+// Details gets the details of the task to add it to a task list with.
+func (t Task) Details() models.TaskUpdate {
+	notes := t.Notes
+	priority := t.Priority
+
+	return models.TaskUpdate{
+		Notes:      &notes,
+		Tags:       t.Tags,
+		Priority:   &priority,
+		Due:        t.Due,
+		Recurrence: t.Recurrence,
+	}
+}
+
+// Count gets how many tasks there are in `tasks`, including their subtasks.
+func Count(tasks []Task) int {
+	count := len(tasks)
+
+	for _, task := range tasks {
+		count += Count(task.Subtasks)
+	}
+
+	return count
+}
+
+// Depth gets how many levels of tasks there are in `tasks`, where tasks without subtasks have one level.
+func Depth(tasks []Task) int {
+	depth := 0
+
+	for _, task := range tasks {
+		depth = max(depth, 1+Depth(task.Subtasks))
+	}
+
+	return depth
+}
+
+// Parse reads the tasks in the file in `r`, which is in `format`.
+//
+// Returns an error if the format is unknown or the file is malformed.
+func Parse(format Format, r io.Reader, options Options) ([]Task, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	switch format {
+	case TodoTxt:
+		return parseTodoTxt(r, options)
+	case CSV:
+		return parseCSV(r, options)
+	case Trello:
+		return parseTrello(r, options)
+	case Todoist:
+		return parseTodoist(r, options)
+	default:
+		return nil, fmt.Errorf("unknown format %q, use one of %v", format, Formats)
+	}
+}
+
+// Parse a date, e.g., "2025-03-14", or a time, e.g., "2025-03-14 17:00" or "2025-03-14T17:00:00Z", in `location`
+// unless it has a time zone.
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.DateTime, "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if date, err := time.ParseInLocation(layout, value, location); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a date, use a date such as 2025-03-14 or 2025-03-14 17:00", value)
+}
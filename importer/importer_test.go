@@ -0,0 +1,59 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("reject unknown formats", func(t *testing.T) {
+		if tasks, err := importer.Parse("ics", strings.NewReader(""), importer.Options{}); err == nil {
+			t.Errorf("got %+v, want an error", tasks)
+		}
+	})
+}
+
+func TestCount(t *testing.T) {
+	tasks := []importer.Task{
+		{Description: "Plan trip", Subtasks: []importer.Task{
+			{Description: "Book flights"},
+			{Description: "Pack", Subtasks: []importer.Task{{Description: "Socks"}}},
+		}},
+		{Description: "Water plants"},
+	}
+
+	if got := importer.Count(tasks); got != 5 {
+		t.Errorf("got %d, want 5", got)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	tasks := []importer.Task{
+		{Description: "Water plants"},
+		{Description: "Plan trip", Subtasks: []importer.Task{
+			{Description: "Book flights"},
+			{Description: "Pack", Subtasks: []importer.Task{{Description: "Socks"}}},
+		}},
+	}
+
+	if got := importer.Depth(tasks); got != 3 {
+		t.Errorf("got %d, want 3", got)
+	}
+
+	if got := importer.Depth(nil); got != 0 {
+		t.Errorf("got %d for no tasks, want 0", got)
+	}
+}
+
+func TestTask_Details(t *testing.T) {
+	due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	task := importer.Task{Description: "Pay rent", Notes: "ref 12", Tags: []string{"home"}, Priority: 3, Due: &due}
+	details := task.Details()
+
+	if *details.Notes != "ref 12" || details.Tags[0] != "home" || *details.Priority != 3 || !details.Due.Equal(due) {
+		t.Errorf("got %+v, want the details of %+v", details, task)
+	}
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/quickadd"
+)
+
+// A task in a Todoist export. The REST API and the Sync API name some fields differently.
+type todoistItem struct {
+	ID          todoistID   `json:"id"`
+	ParentID    todoistID   `json:"parent_id"`
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	Labels      []string    `json:"labels"`
+	Priority    int         `json:"priority"`
+	Due         *todoistDue `json:"due"`
+	IsCompleted bool        `json:"is_completed"`
+	Checked     bool        `json:"checked"`
+	IsDeleted   bool        `json:"is_deleted"`
+}
+
+type todoistDue struct {
+	// The day the task is due, e.g., "2025-03-14", which the Sync API follows with the time, if any, e.g.,
+	// "2025-03-14T17:00:00".
+	Date string `json:"date"`
+	// The time the task is due, which is only given by the REST API.
+	Datetime    string `json:"datetime"`
+	IsRecurring bool   `json:"is_recurring"`
+	// How the due date was written, e.g., "every 2 weeks".
+	String string `json:"string"`
+}
+
+// The ID of a Todoist item, which older exports write as a number rather than a string.
+type todoistID string
+
+func (t *todoistID) UnmarshalJSON(data []byte) error {
+	*t = todoistID(strings.Trim(string(data), `"`))
+
+	if *t == "null" {
+		*t = ""
+	}
+
+	return nil
+}
+
+// Read Todoist tasks, which are either the array of tasks from the REST API or a backup from the Sync API, which is
+// an object with the tasks in "items".
+//
+// Todoist priorities 4 to 2 (p1 to p3) become high, medium and low. Labels become tags, and subtasks are kept. The
+// recurrence of recurring tasks is read from how their due date was written, e.g., "every 2 weeks".
+func parseTodoist(r io.Reader, options Options) ([]Task, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the Todoist export: %v", err)
+	}
+
+	var items []todoistItem
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &items)
+	} else {
+		var backup struct {
+			Items []todoistItem `json:"items"`
+		}
+
+		err = json.Unmarshal(data, &backup)
+		items = backup.Items
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the Todoist export: %v", err)
+	}
+
+	tasks := make(map[todoistID]Task)
+	// The items in the order they are in the export, and the IDs of the subtasks of each item.
+	var order []todoistItem
+	subtasks := make(map[todoistID][]todoistID)
+
+	for i, item := range items {
+		if item.IsDeleted || item.Content == "" {
+			continue
+		}
+
+		// IDs are only needed to find subtasks, so tasks without one are given one that no other task has.
+		if item.ID == "" {
+			item.ID = todoistID(fmt.Sprintf("#%d", i))
+		}
+
+		task := Task{
+			Description: item.Content,
+			Notes:       item.Description,
+			Tags:        item.Labels,
+			Done:        item.IsCompleted || item.Checked,
+		}
+
+		if item.Priority > 1 {
+			task.Priority = item.Priority - 1
+		}
+
+		if item.Due != nil {
+			if task.Due, err = parseTodoistDue(*item.Due, options.Location); err != nil {
+				return nil, fmt.Errorf("task %q: %v", item.Content, err)
+			}
+
+			if item.Due.IsRecurring {
+				// Tasks that repeat from when they are done, e.g., "every! week", repeat from when they are due instead.
+				rule := strings.ReplaceAll(item.Due.String, "every!", "every")
+				// Input where every word is recognised is kept as the description, so a word is put in front of it.
+				task.Recurrence = quickadd.Parse("task "+rule, *task.Due).Recurrence
+			}
+		}
+
+		tasks[item.ID] = task
+		order = append(order, item)
+		subtasks[item.ParentID] = append(subtasks[item.ParentID], item.ID)
+	}
+
+	// Get the task with `id` along with its subtasks, leaving out subtasks that have already been added in case the
+	// parents in the export form a cycle.
+	added := make(map[todoistID]bool)
+	var build func(id todoistID) Task
+	build = func(id todoistID) Task {
+		added[id] = true
+		task := tasks[id]
+
+		for _, subtaskID := range subtasks[id] {
+			if !added[subtaskID] {
+				task.Subtasks = append(task.Subtasks, build(subtaskID))
+			}
+		}
+
+		return task
+	}
+
+	var imported []Task
+
+	for _, item := range order {
+		// Subtasks whose parent is not in the export are imported as tasks.
+		if _, hasParent := tasks[item.ParentID]; !hasParent && !added[item.ID] {
+			imported = append(imported, build(item.ID))
+		}
+	}
+
+	return imported, nil
+}
+
+// Get when a Todoist task is due, in `location` unless the due date has a time zone.
+func parseTodoistDue(due todoistDue, location *time.Location) (*time.Time, error) {
+	value := due.Date
+
+	if due.Datetime != "" {
+		value = due.Datetime
+	}
+
+	date, err := parseDate(value, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
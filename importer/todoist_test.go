@@ -0,0 +1,71 @@
+package importer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestParse_Todoist(t *testing.T) {
+	t.Run("tasks from the REST API", func(t *testing.T) {
+		export := `[
+			{"id": "7", "content": "Pack", "description": "", "labels": [], "priority": 1, "due": null, "is_completed": false, "parent_id": "5"},
+			{"id": "5", "content": "Plan trip", "description": "Rome", "labels": ["travel"], "priority": 4, "due": {"date": "2025-03-14", "datetime": "2025-03-14T09:00:00Z", "is_recurring": false, "string": "14 Mar 9am"}, "is_completed": false, "parent_id": null},
+			{"id": "8", "content": "Socks", "priority": 1, "is_completed": true, "parent_id": "7"},
+			{"id": "6", "content": "Water plants", "priority": 2, "due": {"date": "2025-03-10", "is_recurring": true, "string": "every! 2 weeks"}, "parent_id": null},
+			{"id": "9", "content": "Orphan", "priority": 3, "parent_id": "404"}
+		]`
+
+		got, err := importer.Parse(importer.Todoist, strings.NewReader(export), importer.Options{Location: time.UTC})
+
+		if err != nil {
+			t.Fatalf("could not parse the export: %v", err)
+		}
+
+		trip := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+		plants := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+		want := []importer.Task{
+			{Description: "Plan trip", Notes: "Rome", Tags: []string{"travel"}, Priority: 3, Due: &trip, Subtasks: []importer.Task{
+				{Description: "Pack", Tags: []string{}, Subtasks: []importer.Task{{Description: "Socks", Done: true}}},
+			}},
+			{Description: "Water plants", Priority: 1, Due: &plants, Recurrence: &models.Recurrence{Frequency: models.Weekly, Interval: 2}},
+			{Description: "Orphan", Priority: 2},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("tasks from a Sync API backup", func(t *testing.T) {
+		export := `{"projects": [], "items": [
+			{"id": 1, "content": "Call Mum", "checked": true, "priority": 3, "due": {"date": "2025-03-15T17:30:00", "is_recurring": false}},
+			{"id": 2, "content": "Deleted", "is_deleted": true}
+		]}`
+
+		got, err := importer.Parse(importer.Todoist, strings.NewReader(export), importer.Options{Location: time.UTC})
+
+		if err != nil {
+			t.Fatalf("could not parse the export: %v", err)
+		}
+
+		due := time.Date(2025, 3, 15, 17, 30, 0, 0, time.UTC)
+		want := []importer.Task{{Description: "Call Mum", Priority: 2, Due: &due, Done: true}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("reject invalid exports", func(t *testing.T) {
+		for _, export := range []string{"not json", `[{"id": "1", "content": "a", "due": {"date": "soon"}}]`} {
+			if got, err := importer.Parse(importer.Todoist, strings.NewReader(export), importer.Options{}); err == nil {
+				t.Errorf("got %+v for %q, want an error", got, export)
+			}
+		}
+	})
+}
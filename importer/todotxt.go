@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Read a todo.txt file (https://github.com/todotxt/todo.txt), where each line is a task, e.g.,
+// "(A) 2025-03-01 Call Mum +family @phone due:2025-03-14".
+//
+// Priorities A and B become high and medium, and the other letters become low. Projects and contexts become tags and
+// are taken out of the description, as is the due date. Other key:value pairs are kept in the description.
+func parseTodoTxt(r io.Reader, options Options) ([]Task, error) {
+	scanner := bufio.NewScanner(r)
+	var tasks []Task
+
+	for number := 1; scanner.Scan(); number++ {
+		words := strings.Fields(scanner.Text())
+
+		if len(words) == 0 {
+			continue
+		}
+
+		task, err := parseTodoTxtLine(words, options.Location)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the todo.txt file: %v", err)
+	}
+
+	return tasks, nil
+}
+
+// Parse the `words` of a line of a todo.txt file.
+func parseTodoTxtLine(words []string, location *time.Location) (Task, error) {
+	var task Task
+
+	if words[0] == "x" {
+		task.Done = true
+		words = words[1:]
+
+		// Done tasks start with the date they were done, which is not kept.
+		if len(words) > 0 && isTodoTxtDate(words[0]) {
+			words = words[1:]
+		}
+	}
+
+	if len(words) > 0 && len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' &&
+		words[0][1] >= 'A' && words[0][1] <= 'Z' {
+		task.Priority = todoTxtPriority(words[0][1])
+		words = words[1:]
+	}
+
+	// The date the task was made, which is not kept.
+	if len(words) > 0 && isTodoTxtDate(words[0]) {
+		words = words[1:]
+	}
+
+	var description []string
+
+	for _, word := range words {
+		key, value, isPair := strings.Cut(word, ":")
+
+		switch {
+		case len(word) > 1 && (word[0] == '+' || word[0] == '@'):
+			task.Tags = append(task.Tags, word[1:])
+		case isPair && key == "due":
+			due, err := time.ParseInLocation(time.DateOnly, value, location)
+
+			if err != nil {
+				return task, fmt.Errorf("%q is not a date, use a date such as due:2025-03-14", word)
+			}
+
+			task.Due = &due
+		// Done tasks keep their priority as a pri:A pair, since the priority cannot come before the "x".
+		case isPair && key == "pri" && len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z':
+			task.Priority = todoTxtPriority(value[0])
+		default:
+			description = append(description, word)
+		}
+	}
+
+	task.Description = strings.Join(description, " ")
+
+	if task.Description == "" {
+		return task, fmt.Errorf("the task does not have a description")
+	}
+
+	return task, nil
+}
+
+// Whether `word` is a date in the format used by todo.txt, e.g., "2025-03-14".
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse(time.DateOnly, word)
+
+	return err == nil
+}
+
+// Get the priority of a task with the todo.txt priority `letter`, from A to Z.
+func todoTxtPriority(letter byte) int {
+	switch letter {
+	case 'A':
+		return 3
+	case 'B':
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,44 @@
+package importer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+)
+
+func TestParse_TodoTxt(t *testing.T) {
+	t.Run("tasks with priorities, projects, contexts and due dates", func(t *testing.T) {
+		file := "(A) 2025-03-01 Call Mum +family @phone due:2025-03-14\n" +
+			"\n" +
+			"x 2025-03-05 2025-03-01 Pay rent +home pri:B\n" +
+			"(D) Read book:Dune  url:https://example.com\n"
+
+		got, err := importer.Parse(importer.TodoTxt, strings.NewReader(file), importer.Options{Location: time.UTC})
+
+		if err != nil {
+			t.Fatalf("could not parse the file: %v", err)
+		}
+
+		due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+		want := []importer.Task{
+			{Description: "Call Mum", Tags: []string{"family", "phone"}, Priority: 3, Due: &due},
+			{Description: "Pay rent", Tags: []string{"home"}, Priority: 2, Done: true},
+			{Description: "Read book:Dune url:https://example.com", Priority: 1},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("reject invalid lines", func(t *testing.T) {
+		for _, file := range []string{"Call Mum due:tomorrow", "x 2025-03-05 +home @phone"} {
+			if _, err := importer.Parse(importer.TodoTxt, strings.NewReader("Pay rent\n"+file), importer.Options{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+				t.Errorf("got error %v for %q, want an error for line 2", err, file)
+			}
+		}
+	})
+}
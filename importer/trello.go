@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// The parts of the JSON export of a Trello board that are imported.
+type trelloBoard struct {
+	Lists      []trelloList      `json:"lists"`
+	Labels     []trelloLabel     `json:"labels"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloCard struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Desc        string     `json:"desc"`
+	Closed      bool       `json:"closed"`
+	Due         *time.Time `json:"due"`
+	DueComplete bool       `json:"dueComplete"`
+	IDList      string     `json:"idList"`
+	IDLabels    []string   `json:"idLabels"`
+	Pos         float64    `json:"pos"`
+}
+
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// Read the JSON export of a Trello board, where each card is a task.
+//
+// The name of the list that a card is in and the names of its labels, or their colours if they do not have a name,
+// become tags. Cards are done if their due date is marked complete, and the items of their checklists become subtasks.
+// Archived cards and the cards in archived lists are left out.
+func parseTrello(r io.Reader, options Options) ([]Task, error) {
+	var board trelloBoard
+
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("could not read the Trello board: %v", err)
+	}
+
+	// Cards are imported in the order they appear on the board, from the first list to the last.
+	slices.SortStableFunc(board.Lists, func(a, b trelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	lists := make(map[string]int)
+
+	for i, list := range board.Lists {
+		if !list.Closed {
+			lists[list.ID] = i
+		}
+	}
+
+	slices.SortStableFunc(board.Cards, func(a, b trelloCard) int {
+		return cmp.Or(cmp.Compare(lists[a.IDList], lists[b.IDList]), cmp.Compare(a.Pos, b.Pos))
+	})
+	slices.SortStableFunc(board.Checklists, func(a, b trelloChecklist) int { return cmp.Compare(a.Pos, b.Pos) })
+
+	labels := make(map[string]string)
+
+	for _, label := range board.Labels {
+		labels[label.ID] = cmp.Or(label.Name, label.Color)
+	}
+
+	var tasks []Task
+
+	for _, card := range board.Cards {
+		list, ok := lists[card.IDList]
+
+		if card.Closed || !ok || card.Name == "" {
+			continue
+		}
+
+		task := Task{Description: card.Name, Notes: card.Desc, Done: card.DueComplete}
+		task.Tags = append(task.Tags, board.Lists[list].Name)
+
+		for _, id := range card.IDLabels {
+			if label := labels[id]; label != "" {
+				task.Tags = append(task.Tags, label)
+			}
+		}
+
+		if card.Due != nil {
+			due := card.Due.In(options.Location)
+			task.Due = &due
+		}
+
+		for _, checklist := range board.Checklists {
+			if checklist.IDCard != card.ID {
+				continue
+			}
+
+			slices.SortStableFunc(checklist.CheckItems, func(a, b trelloCheckItem) int { return cmp.Compare(a.Pos, b.Pos) })
+
+			for _, item := range checklist.CheckItems {
+				task.Subtasks = append(task.Subtasks, Task{Description: item.Name, Done: item.State == "complete"})
+			}
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,60 @@
+package importer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/importer"
+)
+
+func TestParse_Trello(t *testing.T) {
+	board := `{
+		"name": "Home",
+		"lists": [
+			{"id": "l2", "name": "Doing", "closed": false, "pos": 2},
+			{"id": "l1", "name": "To Do", "closed": false, "pos": 1},
+			{"id": "l3", "name": "Old", "closed": true, "pos": 3}
+		],
+		"labels": [
+			{"id": "b1", "name": "urgent", "color": "red"},
+			{"id": "b2", "name": "", "color": "green"}
+		],
+		"cards": [
+			{"id": "c1", "name": "Paint fence", "desc": "White", "closed": false, "due": null, "dueComplete": false, "idList": "l2", "idLabels": [], "pos": 1},
+			{"id": "c2", "name": "Plan trip", "desc": "", "closed": false, "due": "2025-03-14T09:00:00.000Z", "dueComplete": true, "idList": "l1", "idLabels": ["b1", "b2"], "pos": 2},
+			{"id": "c3", "name": "Archived", "closed": true, "idList": "l1", "pos": 3},
+			{"id": "c4", "name": "In an archived list", "closed": false, "idList": "l3", "pos": 1}
+		],
+		"checklists": [
+			{"id": "k1", "idCard": "c2", "pos": 1, "checkItems": [
+				{"name": "Pack", "state": "incomplete", "pos": 2},
+				{"name": "Book flights", "state": "complete", "pos": 1}
+			]}
+		]
+	}`
+
+	got, err := importer.Parse(importer.Trello, strings.NewReader(board), importer.Options{Location: time.UTC})
+
+	if err != nil {
+		t.Fatalf("could not parse the board: %v", err)
+	}
+
+	due := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	want := []importer.Task{
+		{Description: "Plan trip", Tags: []string{"To Do", "urgent", "green"}, Due: &due, Done: true, Subtasks: []importer.Task{
+			{Description: "Book flights", Done: true},
+			{Description: "Pack"},
+		}},
+		{Description: "Paint fence", Notes: "White", Tags: []string{"Doing"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := importer.Parse(importer.Trello, strings.NewReader("Title,Due\n"), importer.Options{}); err == nil {
+		t.Errorf("got no error, want an error for a file that is not JSON")
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/AnthonyDickson/yatta/email"
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// The largest task, in bytes, that can be sent to an inbound URL as JSON or a form.
+const maxInboundTaskSize = 1 << 20
+
+// The largest email, in bytes, that can be sent to an inbound URL, including its attachments.
+const maxInboundEmailSize = 25 << 20
+
+// The description of tasks made from emails without a subject.
+const noSubject = "(no subject)"
+
+// Show the inbound URLs of the user's task list, which let other services add tasks to the list. Only the owner and
+// admins of the list may see the URLs, since anyone who knows them can add tasks.
+func (s *Server) getInbound(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	token, err := taskStore.GetInboundToken(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the inbound token of the task list of %q: %v", owner, err))
+		return
+	}
+
+	page := InboundPage{User: owner}
+
+	if token != "" {
+		page.URL = fmt.Sprintf("%s/inbound/%s", baseURL(r), token)
+	}
+
+	body, err := s.renderer.RenderInbound(page)
+	writeResponse(w, body, err, r.URL)
+}
+
+// Give the user's task list a new inbound token, which stops the old inbound URLs from working.
+func (s *Server) resetInboundToken(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	if _, err := taskStore.ResetInboundToken(owner); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not reset the inbound token of the task list of %q", owner))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// The fields of a task sent to an inbound URL.
+type inboundTask struct {
+	Description string
+	// Markdown notes for the task, which may be left out.
+	Notes string
+}
+
+// Add a task to the task list with the inbound token in the path. The task is sent either as JSON, e.g.,
+// {"description": "Disk almost full", "notes": "Usage is at 95%"}, or as a form with the same fields.
+//
+// The Location header of the response is the path of the new task.
+func (s *Server) receiveTask(w http.ResponseWriter, r *http.Request) {
+	owner, taskStore := s.inboundList(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	r.Body = http.MaxBytesReader(w, r.Body, maxInboundTaskSize)
+	var task inboundTask
+
+	switch mediaType {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+			http.Error(w, fmt.Sprintf("could not parse the task: %v", err), http.StatusBadRequest)
+			return
+		}
+	case formContentType:
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		task = inboundTask{Description: r.PostForm.Get("description"), Notes: r.PostForm.Get("notes")}
+	default:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	task.Description = strings.TrimSpace(task.Description)
+
+	if task.Description == "" {
+		http.Error(w, "a task needs a description", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.addInboundTask(w, taskStore, owner, task); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not add the inbound task %q to the task list of %q: %v", task.Description, owner, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Add a task to the task list with the inbound token in the path from an email in the Internet Message Format (RFC
+// 5322), e.g., piped to curl by an alias on a mail server, see the README. The subject becomes the description of the
+// task, the plain text body becomes its notes and attached files are attached to the task if attachments are enabled.
+//
+// The Location header of the response is the path of the new task.
+func (s *Server) receiveEmail(w http.ResponseWriter, r *http.Request) {
+	owner, taskStore := s.inboundList(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "message/rfc822" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	message, err := email.Parse(http.MaxBytesReader(w, r.Body, maxInboundEmailSize))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task := inboundTask{Description: message.Subject, Notes: message.Body}
+
+	if task.Description == "" {
+		task.Description = noSubject
+	}
+
+	id, err := s.addInboundTask(w, taskStore, owner, task)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not add a task to the task list of %q from an email: %v", owner, err))
+		return
+	}
+
+	if s.blobStore == nil && len(message.Attachments) > 0 {
+		slog.Warn(fmt.Sprintf("ignored the attachments of the email for task %d since attachments are disabled", id))
+	}
+
+	for _, attachment := range message.Attachments {
+		if s.blobStore == nil {
+			break
+		}
+
+		_, err := s.storeAttachment(taskStore, id, attachment.Name, bytes.NewReader(attachment.Data))
+
+		// The task has been added, so failing the request would only make the mail server send the email again and
+		// add the task twice.
+		switch {
+		case errors.Is(err, stores.ErrBlobTooLarge):
+			slog.Warn(fmt.Sprintf("did not attach %q to task %d since it is too large", attachment.Name, id))
+		case err != nil:
+			slog.Error(fmt.Sprintf("could not attach %q to task %d: %v", attachment.Name, id, err))
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Get the user whose task list has the inbound token in the path of `r` and the task lists in their workspace,
+// writing a 404 response if there is no such list, see [Server.tasksForSecretLink].
+//
+// Returns a nil store if a response has already been written.
+func (s *Server) inboundList(w http.ResponseWriter, r *http.Request) (string, stores.TaskStore) {
+	owner, err := s.taskStore.GetInboundOwner(r.PathValue("token"))
+
+	if err != nil {
+		writeTaskStoreError(w, err, "could not get the task list of an inbound token")
+		return "", nil
+	}
+
+	return owner, s.tasksForSecretLink(w, r, owner)
+}
+
+// Add `task` to the task list of `owner` in `taskStore`, pointing the Location header of the response at the new task.
+func (s *Server) addInboundTask(w http.ResponseWriter, taskStore stores.TaskStore, owner string, task inboundTask) (uint64, error) {
+	var details models.TaskUpdate
+
+	if task.Notes != "" {
+		details.Notes = &task.Notes
+	}
+
+	id, err := taskStore.AddTaskWithDetails(owner, task.Description, details)
+
+	if err != nil {
+		return 0, err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", id))
+
+	return id, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// How long browsers should wait before connecting again after the event stream is closed.
+const reconnectDelay = 3 * time.Second
+
+// Stream the changes to the tasks in a user's task list as server-sent events.
+//
+// Each event is named after the kind of change and holds the ID of the task that changed. Browsers that reconnect with
+// the Last-Event-ID header are sent the events they missed, as long as the event bus still remembers them.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	user := r.PathValue("user")
+	var lastEventID uint64
+
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		var err error
+
+		if lastEventID, err = strconv.ParseUint(header, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("the last event ID %q is not a valid event ID", header), http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not stream the events of %q since the response cannot be flushed", user))
+		return
+	}
+
+	changes, unsubscribe := s.events.Subscribe(user, lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay.Milliseconds())
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-changes:
+			// The subscription is closed if the browser falls too far behind, in which case it reconnects and catches up.
+			if !ok {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %d\n\n", event.ID, event.Kind, event.TaskID); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
@@ -1,43 +1,96 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/AnthonyDickson/yatta/events"
 	"github.com/AnthonyDickson/yatta/stores"
 )
 
 const taskDBFileName = "todos.db.json"
 const userDBFileName = "users.db.json"
+const workspaceDBFileName = "workspaces.db.json"
+const blobDirName = "blobs"
+
+// How often to check for tasks to archive or permanently delete.
+const housekeepingInterval = time.Hour
+
+// How often to send the deliveries of webhooks that are due.
+const webhookInterval = 10 * time.Second
+
+// How long to wait for a webhook to respond before treating the delivery as failed.
+const webhookTimeout = 10 * time.Second
+
+// How many changes to task lists are remembered for browsers that reconnect after losing their connection.
+const eventHistorySize = 1000
+
+// How often to send a heartbeat to browsers that are listening for changes to task lists.
+const heartbeatInterval = 15 * time.Second
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := RunImport(os.Args[2:], createTaskStore(events.NewBus(eventHistorySize)), createUserStore(), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted tasks are kept in the trash")
+	archiveAfter := flag.Duration("archive-after", 14*24*time.Hour, "how long completed tasks are kept in the task list before they are archived, or 0 to never archive tasks")
+	maxAttachmentSize := flag.Int64("max-attachment-size", 10<<20, "the maximum size of an attached file in bytes")
+	flag.Parse()
+
 	userStore := createUserStore()
-	taskStore := createTaskStore()
+	bus := events.NewBus(eventHistorySize)
+	taskStore := createTaskStore(bus)
+	workspaceStore := createWorkspaceStore()
+	blobStore, err := stores.NewFileBlobStore(blobDirName, *maxAttachmentSize)
+
+	if err != nil {
+		log.Fatalf("could not create the blob store: %v", err)
+	}
+
 	renderer, err := NewHTMLRenderer()
 
 	if err != nil {
 		log.Fatalf("an error occurred while creating the HTML renderer: %v", err)
 	}
 
-	server, err := NewServer(taskStore, userStore, renderer)
+	server, err := NewServer(taskStore, userStore, renderer, WithBlobStore(blobStore), WithWorkspaceStore(workspaceStore), WithEventBus(bus, heartbeatInterval))
 
 	if err != nil {
 		log.Fatalf("an error occurred while creating the server: %v", err)
 	}
 
+	housekeeping := Housekeeping{
+		Store:          taskStore,
+		Blobs:          blobStore,
+		TrashRetention: *trashRetention,
+		ArchiveAfter:   *archiveAfter,
+	}
+	go housekeeping.Start(context.Background(), housekeepingInterval)
+
+	dispatcher := WebhookDispatcher{Store: taskStore, Client: &http.Client{Timeout: webhookTimeout}}
+	go dispatcher.Start(context.Background(), webhookInterval)
+
 	handler := http.Handler(server)
 	log.Fatal(http.ListenAndServe(":8000", handler))
 }
 
-func createTaskStore() *stores.FileTaskStore {
+func createTaskStore(bus *events.Bus) *stores.FileTaskStore {
 	database, err := os.OpenFile(taskDBFileName, os.O_RDWR|os.O_CREATE, 0666)
 
 	if err != nil {
 		log.Fatalf("could not open file %s: %v", taskDBFileName, err)
 	}
 
-	store, err := stores.NewFileTaskStore(database)
+	store, err := stores.NewFileTaskStore(database, stores.WithEventBus(bus))
 
 	if err != nil {
 		log.Fatalf("could not load the file task store: %v", err)
@@ -61,3 +114,19 @@ func createUserStore() *stores.FileUserStore {
 
 	return store
 }
+
+func createWorkspaceStore() *stores.FileWorkspaceStore {
+	database, err := os.OpenFile(workspaceDBFileName, os.O_RDWR|os.O_CREATE, 0666)
+
+	if err != nil {
+		log.Fatalf("could not open file %s: %v", workspaceDBFileName, err)
+	}
+
+	store, err := stores.NewFileWorkspaceStore(database)
+
+	if err != nil {
+		log.Fatalf("could not load the workspace store: %v", err)
+	}
+
+	return store
+}
@@ -0,0 +1,53 @@
+// Package markdown renders long-form text written in CommonMark as HTML that is safe to embed in a page.
+//
+// GitHub-style task lists, tables and strikethrough are supported, and bare URLs are turned into links.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+)
+
+// The sanitisation policy for the generated HTML.
+//
+// Raw HTML in the source is already escaped by the converter, so this is a second line of defence against markup
+// that could run scripts, e.g., links with the "javascript:" scheme.
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+
+	// Checkboxes for task lists.
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	// The language of code blocks, e.g., "language-go", so that they can be highlighted.
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return policy
+}
+
+// Render converts the Markdown in `source` to sanitised HTML.
+//
+// Returns an error if the Markdown could not be converted.
+func Render(source string) (template.HTML, error) {
+	var buffer bytes.Buffer
+
+	if err := converter.Convert([]byte(source), &buffer); err != nil {
+		return "", fmt.Errorf("could not convert Markdown to HTML: %v", err)
+	}
+
+	// The sanitised output is safe to include in a template without escaping.
+	return template.HTML(policy.SanitizeBytes(buffer.Bytes())), nil
+}
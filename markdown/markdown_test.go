@@ -0,0 +1,85 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/markdown"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("renders CommonMark", func(t *testing.T) {
+		cases := map[string]string{
+			"*emphasis* and **strong**":      "<p><em>emphasis</em> and <strong>strong</strong></p>",
+			"# Heading":                      "<h1>Heading</h1>",
+			"```go\nfmt.Println(1 < 2)\n```": `<pre><code class="language-go">fmt.Println(1 &lt; 2)`,
+			"[docs](/tasks/1)":               `<a href="/tasks/1" rel="nofollow">docs</a>`,
+			"~~cancelled~~":                  "<del>cancelled</del>",
+		}
+
+		for source, want := range cases {
+			assertRenderContains(t, source, want)
+		}
+	})
+
+	t.Run("renders checklists", func(t *testing.T) {
+		got := mustRender(t, "- [x] write tests\n- [ ] ship it")
+
+		for _, want := range []string{
+			`<input checked="" disabled="" type="checkbox"> write tests`,
+			`<input disabled="" type="checkbox"> ship it`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("got %q, want it to contain %q", got, want)
+			}
+		}
+	})
+
+	t.Run("links URLs", func(t *testing.T) {
+		assertRenderContains(t, "see https://example.com/docs for details",
+			`<a href="https://example.com/docs" rel="nofollow noopener" target="_blank">https://example.com/docs</a>`)
+	})
+
+	t.Run("neutralises script injection", func(t *testing.T) {
+		cases := []string{
+			"<script>alert(1)</script>",
+			"<img src=x onerror=alert(1)>",
+			"[click](javascript:alert(1))",
+			"<a href=\"javascript:alert(1)\">click</a>",
+			"![x](x \"a\\\" onerror=\\\"alert(1)\")",
+			"<iframe src=\"https://example.com\"></iframe>",
+			"<svg onload=alert(1)>",
+			"<input type=\"text\" onfocus=\"alert(1)\" autofocus>",
+		}
+
+		for _, source := range cases {
+			got := strings.ToLower(mustRender(t, source))
+
+			for _, unsafe := range []string{"<script", "javascript:", "onerror=", "onload=", "onfocus=", "<iframe", "<svg", `type="text"`} {
+				if strings.Contains(got, unsafe) {
+					t.Errorf("got %q for %q, want no %q", got, source, unsafe)
+				}
+			}
+		}
+	})
+}
+
+func mustRender(t *testing.T, source string) string {
+	t.Helper()
+
+	got, err := markdown.Render(source)
+
+	if err != nil {
+		t.Fatalf("could not render %q: %v", source, err)
+	}
+
+	return string(got)
+}
+
+func assertRenderContains(t *testing.T, source string, want string) {
+	t.Helper()
+
+	if got := mustRender(t, source); !strings.Contains(got, want) {
+		t.Errorf("got %q for %q, want it to contain %q", got, source, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Package mentions finds the users that are mentioned in text such as "@alice can you check this?".
+//
+// A user is mentioned either by their email, e.g., @alice@example.com, or by the part of their email before the @,
+// e.g., @alice, as long as no other user's email has the same part before the @, e.g., alice@example.org. Mentions are
+// not case-sensitive and must start the text or follow a character that is not part of a mention, so emails in the text
+// are not mistaken for mentions.
+package mentions
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A mention is an @ that does not follow a character that may be part of an email, followed by a name or an email.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.%+\-@])@([\w.%+\-]+(?:@[\w\-]+(?:\.[\w\-]+)*)?)`)
+
+// Find gets the emails of the `users` that are mentioned in `text`, in the order they are first mentioned.
+func Find(text string, users []models.User) []string {
+	var emails []string
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// Punctuation that ends a sentence is not part of the mention, e.g., "thanks @alice."
+		name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+
+		if email := resolve(name, users); email != "" && !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+
+	return emails
+}
+
+// Get the email of the user that `name` refers to, or an empty string if there is no such user or the name is
+// ambiguous.
+func resolve(name string, users []models.User) string {
+	var found string
+
+	for _, user := range users {
+		email := strings.ToLower(user.Email)
+
+		if email == name {
+			return user.Email
+		}
+
+		if local, _, ok := strings.Cut(email, "@"); ok && local == name {
+			if found != "" {
+				return ""
+			}
+
+			found = user.Email
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,42 @@
+package mentions_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/mentions"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestFind(t *testing.T) {
+	users := []models.User{
+		{ID: 1, Email: "alice@example.com"},
+		{ID: 2, Email: "Bob@example.com"},
+		{ID: 3, Email: "carol@example.com"},
+		{ID: 4, Email: "carol@example.org"},
+		{ID: 5, Email: "dave.smith@example.com"},
+	}
+
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"@alice can you check this", []string{"alice@example.com"}},
+		{"thanks @bob.", []string{"Bob@example.com"}},
+		{"(@dave.smith) and @ALICE, please review", []string{"dave.smith@example.com", "alice@example.com"}},
+		{"@alice @alice@example.com again", []string{"alice@example.com"}},
+		// Carol's name is ambiguous, so only her full email mentions her.
+		{"@carol or @carol@example.org?", []string{"carol@example.org"}},
+		// Emails in the text are not mentions.
+		{"email alice@example.com or bob@alice", nil},
+		{"@erin is not a user", nil},
+		{"line one\n@bob on the next line", []string{"Bob@example.com"}},
+		{"", nil},
+	}
+
+	for _, test := range cases {
+		if got := mentions.Find(test.text, users); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got mentions %q in %q, want %q", got, test.text, test.want)
+		}
+	}
+}
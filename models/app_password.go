@@ -0,0 +1,54 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// An AppPassword lets an app, such as a CalDAV client, sign in as a user without being given the user's password. Each
+// app gets its own password so that it can be revoked without signing out the others.
+type AppPassword struct {
+	ID uint64
+	// What the password is used for, e.g., "Phone".
+	Name      string
+	Hash      *PasswordHash
+	CreatedAt time.Time
+}
+
+// The encoding of app passwords, which leaves out letters and digits that look alike, such as "0" and "O".
+var appPasswordEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// GenerateAppPassword makes a random password for an app, e.g., "hq7c-s2mf-8kzb-xw4d-e9tn-3rpa", which is split into
+// groups of four so that it is easier to type on a phone.
+func GenerateAppPassword() (string, error) {
+	secret := make([]byte, 15)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate an app password: %v", err)
+	}
+
+	encoded := appPasswordEncoding.EncodeToString(secret)
+	var groups []string
+
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:min(i+4, len(encoded))])
+	}
+
+	return strings.Join(groups, "-"), nil
+}
+
+// MatchAppPassword finds the app password of the user that `password` is for.
+//
+// Returns nil if `password` is not one of the user's app passwords.
+func (u User) MatchAppPassword(password string) *AppPassword {
+	for i, appPassword := range u.AppPasswords {
+		if appPassword.Hash != nil && appPassword.Hash.Compare(password) == nil {
+			return &u.AppPasswords[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/yattatest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestGenerateAppPassword(t *testing.T) {
+	format := regexp.MustCompile(`^[a-km-np-z2-9]{4}(-[a-km-np-z2-9]{4}){5}$`)
+	first, err := models.GenerateAppPassword()
+	yattatest.AssertNoError(t, err)
+	second, err := models.GenerateAppPassword()
+	yattatest.AssertNoError(t, err)
+
+	for _, password := range []string{first, second} {
+		if !format.MatchString(password) {
+			t.Errorf("got password %q, want six groups of four letters and digits", password)
+		}
+	}
+
+	if first == second {
+		t.Errorf("got the same password %q twice", first)
+	}
+}
+
+func TestUser_MatchAppPassword(t *testing.T) {
+	hash := func(password string) *models.PasswordHash {
+		hashed, err := models.NewPasswordHash(password, bcrypt.MinCost)
+		yattatest.AssertNoError(t, err)
+
+		return hashed
+	}
+
+	user := models.User{
+		Email:    "alice@example.com",
+		Password: hash("alice"),
+		AppPasswords: []models.AppPassword{
+			{ID: 1, Name: "Phone", Hash: hash("phone-secret")},
+			{ID: 2, Name: "Laptop", Hash: hash("laptop-secret")},
+		},
+	}
+
+	if got := user.MatchAppPassword("laptop-secret"); got == nil || got.ID != 2 {
+		t.Errorf("got app password %+v, want the laptop's", got)
+	}
+
+	for _, password := range []string{"alice", "", "phone"} {
+		if got := user.MatchAppPassword(password); got != nil {
+			t.Errorf("got app password %+v for %q, want none", got, password)
+		}
+	}
+}
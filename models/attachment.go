@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// An Attachment is a file attached to a task, e.g., a screenshot.
+type Attachment struct {
+	ID     uint64
+	TaskID uint64
+	// The original name of the file.
+	Name string
+	// The media type of the file, e.g., "image/png", detected from its contents.
+	ContentType string
+	// The size of the file in bytes.
+	Size int64
+	// The key of the file's contents in the blob store.
+	Key        string
+	UploadedAt time.Time
+}
@@ -0,0 +1,44 @@
+package models
+
+// A Column is a stage in the workflow of a user's tasks, e.g., "Doing", shown as a column on their board.
+type Column struct {
+	ID   uint64
+	Name string
+	// The most tasks that can be in the column at once, or zero if there is no limit.
+	WIPLimit int `json:",omitempty"`
+	// Whether the tasks in the column are done. Moving a task into or out of a done column completes or reopens it.
+	Done bool `json:",omitempty"`
+}
+
+// The columns used by boards that have not been set up yet.
+var DefaultColumns = []Column{
+	{ID: 1, Name: "Backlog"},
+	{ID: 2, Name: "Doing"},
+	{ID: 3, Name: "Review"},
+	{ID: 4, Name: "Done", Done: true},
+}
+
+// Get the ID of the column in `columns` that `task` belongs in.
+//
+// A task belongs in the column given by its status, unless it has been completed or reopened since it was moved there,
+// in which case the task falls back to the first column that matches whether it is done.
+// Returns zero if there is no such column.
+func ColumnOf(task Task, columns []Column) uint64 {
+	var fallback uint64
+
+	for _, column := range columns {
+		if column.Done != task.Done {
+			continue
+		}
+
+		if column.ID == task.Status {
+			return column.ID
+		}
+
+		if fallback == 0 {
+			fallback = column.ID
+		}
+	}
+
+	return fallback
+}
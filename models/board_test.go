@@ -0,0 +1,41 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestColumnOf(t *testing.T) {
+	columns := []models.Column{
+		{ID: 1, Name: "Backlog"},
+		{ID: 2, Name: "Doing"},
+		{ID: 3, Name: "Done", Done: true},
+		{ID: 4, Name: "Released", Done: true},
+	}
+
+	cases := []struct {
+		name string
+		task models.Task
+		want uint64
+	}{
+		{"new tasks go in the first open column", models.Task{}, 1},
+		{"tasks go in their column", models.Task{Status: 2}, 2},
+		{"completed tasks go in their done column", models.Task{Status: 4, Done: true}, 4},
+		{"completed tasks leave open columns", models.Task{Status: 2, Done: true}, 3},
+		{"reopened tasks leave done columns", models.Task{Status: 4}, 1},
+		{"tasks in removed columns go back to the first column", models.Task{Status: 9}, 1},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if got := models.ColumnOf(test.task, columns); got != test.want {
+				t.Errorf("got column %d, want %d", got, test.want)
+			}
+		})
+	}
+
+	if got := models.ColumnOf(models.Task{Done: true}, columns[:2]); got != 0 {
+		t.Errorf("got column %d for a completed task on a board without a done column, want 0", got)
+	}
+}
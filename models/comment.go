@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Comment is a message about a task, e.g., as part of a discussion between the people working on it.
+type Comment struct {
+	ID     uint64
+	TaskID uint64
+	// The email of the user that wrote the comment.
+	Author string
+	// The message, written in Markdown.
+	Body      string
+	CreatedAt time.Time
+	// When the comment was last changed, or nil if it has not been edited.
+	EditedAt *time.Time `json:",omitempty"`
+}
+
+// An ActivityKind is a type of change to a task.
+type ActivityKind string
+
+const (
+	ActivityCreated   ActivityKind = "created"
+	ActivityRenamed   ActivityKind = "renamed"
+	ActivityCompleted ActivityKind = "completed"
+	ActivityReopened  ActivityKind = "reopened"
+	ActivityDeleted   ActivityKind = "deleted"
+	ActivityRestored  ActivityKind = "restored"
+	ActivityArchived  ActivityKind = "archived"
+	ActivityAssigned  ActivityKind = "assigned"
+)
+
+// An Activity records a change to a task.
+type Activity struct {
+	TaskID uint64
+	Kind   ActivityKind
+	// The old and new values for changes to a field, e.g., the old and new description when a task is renamed.
+	From string `json:",omitempty"`
+	To   string `json:",omitempty"`
+	At   time.Time
+}
+
+// String describes the change, e.g., `renamed from "foo" to "bar"`.
+func (a Activity) String() string {
+	switch a.Kind {
+	case ActivityRenamed:
+		return fmt.Sprintf("renamed from %q to %q", a.From, a.To)
+	case ActivityDeleted:
+		return "moved to the trash"
+	case ActivityRestored:
+		return "restored from the trash"
+	case ActivityAssigned:
+		if a.To == "" {
+			return "unassigned"
+		}
+
+		return "assigned to " + a.To
+	default:
+		return string(a.Kind)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A Role is what a member of a shared task list may do with the list.
+type Role string
+
+const (
+	// Viewers can see the tasks in the list.
+	RoleViewer Role = "viewer"
+	// Editors can also add, change and delete tasks.
+	RoleEditor Role = "editor"
+	// Admins can also change the settings of the list, such as its board, saved searches and templates, and who the
+	// list is shared with.
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists the roles from the least to the most that they allow.
+var Roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+// ParseRole parses the name of a role, e.g., "editor".
+//
+// Returns an error if `name` is not the name of a role.
+func ParseRole(name string) (Role, error) {
+	if role := Role(name); slices.Contains(Roles, role) {
+		return role, nil
+	}
+
+	return "", fmt.Errorf("invalid role %q, expected one of %q", name, Roles)
+}
+
+// Allows reports whether the role allows everything that `required` does.
+func (r Role) Allows(required Role) bool {
+	index := slices.Index(Roles, r)
+
+	return index != -1 && index >= slices.Index(Roles, required)
+}
+
+// A Member is a user that a task list is shared with.
+type Member struct {
+	Email string
+	Role  Role
+}
+
+// A SharedList is a task list that is shared with a user.
+type SharedList struct {
+	// The user that the list belongs to.
+	Owner string
+	// The role of the user that the list is shared with.
+	Role Role
+}
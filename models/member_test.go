@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestRole_Allows(t *testing.T) {
+	cases := []struct {
+		role     models.Role
+		required models.Role
+		want     bool
+	}{
+		{models.RoleViewer, models.RoleViewer, true},
+		{models.RoleViewer, models.RoleEditor, false},
+		{models.RoleViewer, models.RoleAdmin, false},
+		{models.RoleEditor, models.RoleViewer, true},
+		{models.RoleEditor, models.RoleEditor, true},
+		{models.RoleEditor, models.RoleAdmin, false},
+		{models.RoleAdmin, models.RoleViewer, true},
+		{models.RoleAdmin, models.RoleEditor, true},
+		{models.RoleAdmin, models.RoleAdmin, true},
+		{models.Role("owner"), models.RoleViewer, false},
+		{models.Role(""), models.RoleViewer, false},
+	}
+
+	for _, test := range cases {
+		if got := test.role.Allows(test.required); got != test.want {
+			t.Errorf("got %v for whether %q allows %q, want %v", got, test.role, test.required, test.want)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, want := range models.Roles {
+		got, err := models.ParseRole(string(want))
+
+		if err != nil || got != want {
+			t.Errorf("got role %q and error %v when parsing %q, want %q", got, err, want, want)
+		}
+	}
+
+	for _, name := range []string{"", "owner", "Admin"} {
+		if _, err := models.ParseRole(name); err == nil {
+			t.Errorf("got no error when parsing %q, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// A NotificationKind is a reason for telling a user about a change to a task.
+type NotificationKind string
+
+const (
+	// The user was made an assignee of a task.
+	NotificationAssigned NotificationKind = "assigned"
+	// The user was mentioned in the description or notes of a task, or in a comment on a task.
+	NotificationMentioned NotificationKind = "mentioned"
+)
+
+// A Notification tells a user about a change to a task that concerns them, e.g., being assigned to the task.
+type Notification struct {
+	ID     uint64
+	Kind   NotificationKind
+	TaskID uint64
+	// The ID of the comment that the notification is about, or zero if it is about the task itself.
+	CommentID uint64 `json:",omitempty"`
+	// The description of the task when the notification was made.
+	Task string
+	// The email of the user that made the change, or empty if they were not signed in.
+	Actor     string `json:",omitempty"`
+	CreatedAt time.Time
+	// Whether the user has seen the notification.
+	Read bool `json:",omitempty"`
+}
+
+// String describes the notification, e.g., `alice@example.com assigned you to "deploy"`.
+func (n Notification) String() string {
+	actor := n.Actor
+
+	if actor == "" {
+		actor = "Someone"
+	}
+
+	switch n.Kind {
+	case NotificationAssigned:
+		return fmt.Sprintf("%s assigned you to %q", actor, n.Task)
+	case NotificationMentioned:
+		if n.CommentID != 0 {
+			return fmt.Sprintf("%s mentioned you in a comment on %q", actor, n.Task)
+		}
+
+		return fmt.Sprintf("%s mentioned you in %q", actor, n.Task)
+	default:
+		return fmt.Sprintf("%s %s %q", actor, n.Kind, n.Task)
+	}
+}
+
+// Link gets the path of the page that the notification is about, e.g., /tasks/1#comment-2.
+func (n Notification) Link() string {
+	if n.CommentID != 0 {
+		return fmt.Sprintf("/tasks/%d#comment-%d", n.TaskID, n.CommentID)
+	}
+
+	return fmt.Sprintf("/tasks/%d", n.TaskID)
+}
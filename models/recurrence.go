@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Frequency is the unit of time between the occurrences of a recurring task.
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Yearly  Frequency = "yearly"
+)
+
+// The singular names of the units of each frequency, e.g., "every week".
+var frequencyUnits = map[Frequency]string{
+	Daily:   "day",
+	Weekly:  "week",
+	Monthly: "month",
+	Yearly:  "year",
+}
+
+// A Recurrence describes how often a task repeats, starting from its due date.
+type Recurrence struct {
+	Frequency Frequency
+	// The number of days, weeks, months or years between occurrences. Zero is treated as one.
+	Interval int `json:",omitempty"`
+}
+
+// ParseRecurrence parses how often a task repeats, e.g., "weekly", "every month" or "every 2 weeks".
+func ParseRecurrence(s string) (Recurrence, error) {
+	fields := strings.Fields(strings.ToLower(s))
+
+	if len(fields) == 1 {
+		if _, ok := frequencyUnits[Frequency(fields[0])]; ok {
+			return Recurrence{Frequency: Frequency(fields[0]), Interval: 1}, nil
+		}
+	}
+
+	if len(fields) < 2 || len(fields) > 3 || fields[0] != "every" {
+		return Recurrence{}, fmt.Errorf("%q is not a recurrence like \"weekly\" or \"every 2 weeks\"", s)
+	}
+
+	interval := 1
+	unit := fields[1]
+
+	if len(fields) == 3 {
+		var err error
+		interval, err = strconv.Atoi(fields[1])
+
+		if err != nil || interval < 1 {
+			return Recurrence{}, fmt.Errorf("%q is not a whole number of days, weeks, months or years", fields[1])
+		}
+
+		unit = strings.TrimSuffix(fields[2], "s")
+	}
+
+	for frequency, name := range frequencyUnits {
+		if unit == name {
+			return Recurrence{Frequency: frequency, Interval: interval}, nil
+		}
+	}
+
+	return Recurrence{}, fmt.Errorf("%q is not one of days, weeks, months or years", unit)
+}
+
+// String formats the recurrence so that it can be read back by [ParseRecurrence], e.g., "weekly" or "every 2 weeks".
+func (r Recurrence) String() string {
+	if r.interval() == 1 {
+		return string(r.Frequency)
+	}
+
+	return fmt.Sprintf("every %d %ss", r.interval(), frequencyUnits[r.Frequency])
+}
+
+func (r Recurrence) interval() int {
+	return max(r.Interval, 1)
+}
+
+// Occurrence gets the `n`th occurrence after `start`, where the zeroth occurrence is `start`.
+//
+// Months and years are added to the date of `start` rather than the previous occurrence, and occurrences that would
+// land past the end of a month are moved back to its last day, e.g., monthly from 31 January falls on 28 February
+// and then 31 March.
+func (r Recurrence) Occurrence(start time.Time, n int) time.Time {
+	steps := n * r.interval()
+
+	switch r.Frequency {
+	case Daily:
+		return start.AddDate(0, 0, steps)
+	case Weekly:
+		return start.AddDate(0, 0, 7*steps)
+	case Yearly:
+		steps *= 12
+	case Monthly:
+	default:
+		return start
+	}
+
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	// Day zero of the following month is the last day of the month.
+	lastDay := time.Date(year, month+time.Month(steps)+1, 0, 0, 0, 0, 0, start.Location()).Day()
+
+	return time.Date(year, month+time.Month(steps), min(day, lastDay), hour, minute, second, start.Nanosecond(), start.Location())
+}
+
+// Occurrences gets the occurrences of a task that repeats from `start` that fall on or after `from` and before `to`,
+// including `start` itself.
+func (r Recurrence) Occurrences(start time.Time, from time.Time, to time.Time) []time.Time {
+	if _, ok := frequencyUnits[r.Frequency]; !ok {
+		return nil
+	}
+
+	var occurrences []time.Time
+
+	for n := 0; ; n++ {
+		occurrence := r.Occurrence(start, n)
+
+		if !occurrence.Before(to) {
+			return occurrences
+		}
+
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+}
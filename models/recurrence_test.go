@@ -0,0 +1,128 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := map[string]models.Recurrence{
+		"daily":          {Frequency: models.Daily, Interval: 1},
+		"Weekly":         {Frequency: models.Weekly, Interval: 1},
+		"every month":    {Frequency: models.Monthly, Interval: 1},
+		"every 2 weeks":  {Frequency: models.Weekly, Interval: 2},
+		"every 1 year":   {Frequency: models.Yearly, Interval: 1},
+		" every 3 days ": {Frequency: models.Daily, Interval: 3},
+	}
+
+	for input, want := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := models.ParseRecurrence(input)
+
+			if err != nil {
+				t.Fatalf("got error %v, want %v", err, want)
+			}
+
+			if got != want {
+				t.Errorf("got recurrence %v, want %v", got, want)
+			}
+
+			// The formatted recurrence can be parsed again.
+			if again, err := models.ParseRecurrence(got.String()); err != nil || again != got {
+				t.Errorf("got %v, %v parsing %q, want %v", again, err, got.String(), got)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "sometimes", "every", "every 0 days", "every -1 weeks", "every 2 fortnights", "every other week"} {
+		t.Run(input, func(t *testing.T) {
+			if got, err := models.ParseRecurrence(input); err == nil {
+				t.Errorf("got recurrence %v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestRecurrence_String(t *testing.T) {
+	cases := map[models.Recurrence]string{
+		{Frequency: models.Daily}:                "daily",
+		{Frequency: models.Monthly, Interval: 1}: "monthly",
+		{Frequency: models.Weekly, Interval: 2}:  "every 2 weeks",
+		{Frequency: models.Yearly, Interval: 10}: "every 10 years",
+	}
+
+	for recurrence, want := range cases {
+		if got := recurrence.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestRecurrence_Occurrences(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name       string
+		recurrence models.Recurrence
+		start      time.Time
+		from       time.Time
+		to         time.Time
+		want       []time.Time
+	}{
+		{
+			name:       "every 2 days",
+			recurrence: models.Recurrence{Frequency: models.Daily, Interval: 2},
+			start:      date(2025, 3, 1),
+			from:       date(2025, 3, 4),
+			to:         date(2025, 3, 9),
+			want:       []time.Time{date(2025, 3, 5), date(2025, 3, 7)},
+		},
+		{
+			name:       "weekly including the start",
+			recurrence: models.Recurrence{Frequency: models.Weekly},
+			start:      date(2025, 3, 3),
+			from:       date(2025, 3, 1),
+			to:         date(2025, 3, 18),
+			want:       []time.Time{date(2025, 3, 3), date(2025, 3, 10), date(2025, 3, 17)},
+		},
+		{
+			name:       "monthly from the end of a month",
+			recurrence: models.Recurrence{Frequency: models.Monthly},
+			start:      date(2025, 1, 31),
+			from:       date(2025, 2, 1),
+			to:         date(2025, 5, 1),
+			want:       []time.Time{date(2025, 2, 28), date(2025, 3, 31), date(2025, 4, 30)},
+		},
+		{
+			name:       "yearly from a leap day",
+			recurrence: models.Recurrence{Frequency: models.Yearly},
+			start:      date(2024, 2, 29),
+			from:       date(2025, 1, 1),
+			to:         date(2029, 1, 1),
+			want:       []time.Time{date(2025, 2, 28), date(2026, 2, 28), date(2027, 2, 28), date(2028, 2, 29)},
+		},
+		{
+			name:       "nothing before the start",
+			recurrence: models.Recurrence{Frequency: models.Daily},
+			start:      date(2025, 3, 10),
+			from:       date(2025, 3, 1),
+			to:         date(2025, 3, 10),
+			want:       nil,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.recurrence.Occurrences(test.start, test.from, test.to)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got occurrences %v, want %v", got, test.want)
+			}
+		})
+	}
+}
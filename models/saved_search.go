@@ -0,0 +1,9 @@
+package models
+
+// A SavedSearch is a named task query that is shown as a smart list alongside regular task lists.
+type SavedSearch struct {
+	ID   uint64
+	Name string
+	// The query in the syntax of the [github.com/AnthonyDickson/yatta/query] package.
+	Query string
+}
@@ -1,6 +1,191 @@
 package models
 
+import "time"
+
 type Task struct {
 	ID          uint64
 	Description string
+	// Long-form details about the task, written in Markdown.
+	Notes string `json:",omitempty"`
+	// The ID of the task's parent, or zero if the task is a top-level task.
+	ParentID uint64 `json:",omitempty"`
+	// Whether the task has been completed.
+	Done bool `json:",omitempty"`
+	// When the task was completed, or nil if the task is open.
+	CompletedAt *time.Time `json:",omitempty"`
+	// When the task was moved to the trash, or nil if the task has not been deleted.
+	DeletedAt *time.Time `json:",omitempty"`
+	// The IDs of the tasks that must be done before this task can be worked on.
+	BlockedBy []uint64 `json:",omitempty"`
+	// Whether any of the tasks in BlockedBy are still open.
+	// This is computed by the task store and is not persisted.
+	Blocked bool `json:"-"`
+	// Labels for grouping related tasks, e.g., "work".
+	Tags []string `json:",omitempty"`
+	// How important the task is, where larger numbers are more important.
+	Priority int `json:",omitempty"`
+	// When the task should be done by, or nil if there is no due date.
+	Due *time.Time `json:",omitempty"`
+	// How often the task repeats from its due date, or nil if the task does not repeat.
+	Recurrence *Recurrence `json:",omitempty"`
+	// When the task should start showing up in the user's lists again, or nil if the task has not been snoozed.
+	HiddenUntil *time.Time `json:",omitempty"`
+	// How much work the task needs, in story points or hours, or zero if the task has not been estimated.
+	// Estimates of subtasks are counted separately from the estimates of their parents.
+	Estimate float64 `json:",omitempty"`
+	// The ID of the column on the user's board that the task was last moved to, or zero if it has not been moved.
+	// See [ColumnOf] for the column that the task is shown in.
+	Status uint64 `json:",omitempty"`
+	// The emails of the users responsible for doing the task, who may be different from the user that created it.
+	Assignees []string `json:",omitempty"`
+	// Where a CalDAV client stored the task, or nil if the task was not made by a CalDAV client.
+	CalendarObject *CalendarObject `json:",omitempty"`
+}
+
+// A CalendarObject is the iCalendar resource that a CalDAV client stored a task as, which is kept so that the client
+// can find the task again.
+type CalendarObject struct {
+	// The last segment of the resource's path, e.g., "0D5B6E3C.ics".
+	Name string
+	// The iCalendar UID of the task.
+	UID string
+}
+
+// A TaskUpdate describes changes to the details of a task. Only the non-nil fields are changed.
+type TaskUpdate struct {
+	Description *string
+	Notes       *string
+	Tags        []string
+	Priority    *int
+	Due         *time.Time
+	Estimate    *float64
+	Recurrence  *Recurrence
+	// Remove the due date. Takes precedence over Due.
+	ClearDue bool
+	// Stop the task from repeating. Takes precedence over Recurrence.
+	ClearRecurrence bool
+	// Snooze the task until the given time.
+	HiddenUntil *time.Time
+	// Show a snoozed task again. Takes precedence over HiddenUntil.
+	ClearHiddenUntil bool
+	CalendarObject   *CalendarObject
+}
+
+// Snoozed reports whether the task is hidden from the user's lists at `now`.
+func (t Task) Snoozed(now time.Time) bool {
+	return t.HiddenUntil != nil && now.Before(*t.HiddenUntil)
+}
+
+// HideSnoozed gets the tasks that are not snoozed at `now`, leaving out the subtasks of snoozed tasks as well.
+//
+// Only the parents that are in `tasks` are checked. The relative order of tasks is preserved.
+func HideSnoozed(tasks []Task, now time.Time) []Task {
+	parents := make(map[uint64]uint64, len(tasks))
+	snoozed := make(map[uint64]bool)
+
+	for _, task := range tasks {
+		parents[task.ID] = task.ParentID
+
+		if task.Snoozed(now) {
+			snoozed[task.ID] = true
+		}
+	}
+
+	visible := make([]Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		hidden := false
+
+		// Stop after visiting every task in case the parents form a loop.
+		for id, steps := task.ID, 0; id != 0 && steps <= len(tasks); id, steps = parents[id], steps+1 {
+			if snoozed[id] {
+				hidden = true
+				break
+			}
+		}
+
+		if !hidden {
+			visible = append(visible, task)
+		}
+	}
+
+	return visible
+}
+
+// A TaskNode is a task along with its subtasks.
+type TaskNode struct {
+	Task
+	Subtasks []TaskNode
+}
+
+// Progress summarises how many subtasks of a task have been completed.
+type Progress struct {
+	Completed int
+	Total     int
+}
+
+// Percent returns the percentage of completed subtasks rounded down to the nearest integer.
+//
+// Returns 0 if there are no subtasks.
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+
+	return p.Completed * 100 / p.Total
+}
+
+// NewTaskTree arranges a flat list of tasks into trees of subtasks.
+//
+// Tasks without a parent, or whose parent is not in `tasks`, become the roots
+// of the returned trees. The relative order of tasks is preserved.
+func NewTaskTree(tasks []Task) []TaskNode {
+	present := make(map[uint64]bool, len(tasks))
+
+	for _, task := range tasks {
+		present[task.ID] = true
+	}
+
+	children := make(map[uint64][]Task)
+	var roots []Task
+
+	for _, task := range tasks {
+		if task.ParentID == 0 || !present[task.ParentID] {
+			roots = append(roots, task)
+		} else {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+
+	var build func(tasks []Task) []TaskNode
+	build = func(tasks []Task) []TaskNode {
+		nodes := make([]TaskNode, 0, len(tasks))
+
+		for _, task := range tasks {
+			nodes = append(nodes, TaskNode{Task: task, Subtasks: build(children[task.ID])})
+		}
+
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// Progress counts the completed tasks out of all the tasks below this node.
+func (n TaskNode) Progress() Progress {
+	var progress Progress
+
+	for _, subtask := range n.Subtasks {
+		progress.Total++
+
+		if subtask.Done {
+			progress.Completed++
+		}
+
+		subtaskProgress := subtask.Progress()
+		progress.Total += subtaskProgress.Total
+		progress.Completed += subtaskProgress.Completed
+	}
+
+	return progress
 }
@@ -0,0 +1,125 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestNewTaskTree(t *testing.T) {
+	t.Run("nests subtasks under their parents", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 1, Description: "prepare release"},
+			{ID: 2, Description: "write changelog", ParentID: 1},
+			{ID: 3, Description: "water plants"},
+			{ID: 4, Description: "bump version", ParentID: 1},
+			{ID: 5, Description: "update go.mod", ParentID: 4},
+		}
+
+		got := models.NewTaskTree(tasks)
+		want := []models.TaskNode{
+			{Task: tasks[0], Subtasks: []models.TaskNode{
+				{Task: tasks[1], Subtasks: []models.TaskNode{}},
+				{Task: tasks[3], Subtasks: []models.TaskNode{
+					{Task: tasks[4], Subtasks: []models.TaskNode{}},
+				}},
+			}},
+			{Task: tasks[2], Subtasks: []models.TaskNode{}},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got tree %v, want %v", got, want)
+		}
+	})
+
+	t.Run("tasks with a missing parent become roots", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 2, Description: "write changelog", ParentID: 1},
+		}
+
+		got := models.NewTaskTree(tasks)
+
+		if len(got) != 1 || !reflect.DeepEqual(got[0].Task, tasks[0]) {
+			t.Errorf("got tree %v, want a single root for %v", got, tasks[0])
+		}
+	})
+}
+
+func TestTaskNode_Progress(t *testing.T) {
+	cases := []struct {
+		name  string
+		tasks []models.Task
+		want  models.Progress
+	}{
+		{
+			name:  "task without subtasks",
+			tasks: []models.Task{{ID: 1}},
+			want:  models.Progress{Completed: 0, Total: 0},
+		},
+		{
+			name: "counts direct subtasks",
+			tasks: []models.Task{
+				{ID: 1},
+				{ID: 2, ParentID: 1, Done: true},
+				{ID: 3, ParentID: 1},
+			},
+			want: models.Progress{Completed: 1, Total: 2},
+		},
+		{
+			name: "counts nested subtasks",
+			tasks: []models.Task{
+				{ID: 1},
+				{ID: 2, ParentID: 1, Done: true},
+				{ID: 3, ParentID: 2, Done: true},
+				{ID: 4, ParentID: 2, Done: true},
+				{ID: 5, ParentID: 1},
+			},
+			want: models.Progress{Completed: 3, Total: 4},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := models.NewTaskTree(c.tasks)[0].Progress()
+
+			if got != c.want {
+				t.Errorf("got progress %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("percent", func(t *testing.T) {
+		progress := models.Progress{Completed: 1, Total: 3}
+
+		if got := progress.Percent(); got != 33 {
+			t.Errorf("got %d%%, want 33%%", got)
+		}
+	})
+}
+
+func TestHideSnoozed(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+	tasks := []models.Task{
+		{ID: 1, Description: "prepare release", HiddenUntil: &later},
+		{ID: 2, Description: "write changelog", ParentID: 1},
+		{ID: 3, Description: "update go.mod", ParentID: 2},
+		{ID: 4, Description: "water plants", HiddenUntil: &earlier},
+		{ID: 5, Description: "pay rent", HiddenUntil: &now},
+		{ID: 6, Description: "book flights"},
+	}
+
+	got := models.HideSnoozed(tasks, now)
+	want := []models.Task{tasks[3], tasks[4], tasks[5]}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got visible tasks %v, want %v", got, want)
+	}
+
+	if got := models.HideSnoozed(tasks, later); len(got) != len(tasks) {
+		t.Errorf("got %d visible tasks once the snooze is over, want %d", len(got), len(tasks))
+	}
+}
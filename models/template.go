@@ -0,0 +1,142 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// A Template is a tree of tasks, such as a release checklist, that can be added to a task list over and over.
+type Template struct {
+	ID   uint64
+	Name string
+	// The top-level task of the template and its subtasks.
+	Task TemplateTask
+}
+
+// A TemplateTask is a task in a template.
+//
+// The description and notes may contain placeholders such as {{version}} that are filled in when the template is used.
+type TemplateTask struct {
+	Description string
+	Notes       string   `json:",omitempty"`
+	Tags        []string `json:",omitempty"`
+	Priority    int      `json:",omitempty"`
+	Estimate    float64  `json:",omitempty"`
+	// The number of days after the date the template is used for that the task is due, or nil if it has no due date.
+	DueOffset  *int           `json:",omitempty"`
+	Recurrence *Recurrence    `json:",omitempty"`
+	Subtasks   []TemplateTask `json:",omitempty"`
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// NewTemplate creates a template called `name` from `task` and `subtasks`, which should include the subtasks of
+// subtasks.
+//
+// Due dates become offsets from the due date of `task`, or from the earliest due date if `task` has none. Whether
+// the tasks are done and their dependencies are not kept.
+func NewTemplate(name string, task Task, subtasks []Task) Template {
+	anchor := task.Due
+
+	if anchor == nil {
+		for _, subtask := range subtasks {
+			if subtask.Due != nil && (anchor == nil || subtask.Due.Before(*anchor)) {
+				anchor = subtask.Due
+			}
+		}
+	}
+
+	var convert func(task Task) TemplateTask
+	convert = func(task Task) TemplateTask {
+		templateTask := TemplateTask{
+			Description: task.Description,
+			Notes:       task.Notes,
+			Tags:        slices.Clone(task.Tags),
+			Priority:    task.Priority,
+			Estimate:    task.Estimate,
+		}
+
+		if task.Recurrence != nil {
+			recurrence := *task.Recurrence
+			templateTask.Recurrence = &recurrence
+		}
+
+		if task.Due != nil {
+			offset := daysBetween(*anchor, *task.Due)
+			templateTask.DueOffset = &offset
+		}
+
+		for _, subtask := range subtasks {
+			if subtask.ParentID == task.ID {
+				templateTask.Subtasks = append(templateTask.Subtasks, convert(subtask))
+			}
+		}
+
+		return templateTask
+	}
+
+	return Template{Name: name, Task: convert(task)}
+}
+
+// Placeholders gets the names of the placeholders in the descriptions and notes of the tasks in the template, in the
+// order they first appear.
+func (t Template) Placeholders() []string {
+	var names []string
+
+	var visit func(task TemplateTask)
+	visit = func(task TemplateTask) {
+		for _, text := range []string{task.Description, task.Notes} {
+			for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+				if !slices.Contains(names, match[1]) {
+					names = append(names, match[1])
+				}
+			}
+		}
+
+		for _, subtask := range task.Subtasks {
+			visit(subtask)
+		}
+	}
+
+	visit(t.Task)
+
+	return names
+}
+
+// Due gets when the task is due if the template is used for `anchor`, or nil if the task has no due date.
+//
+// Tasks are due at midnight at the start of the day in the location of `anchor`.
+func (t TemplateTask) Due(anchor time.Time) *time.Time {
+	if t.DueOffset == nil {
+		return nil
+	}
+
+	year, month, day := anchor.Date()
+	due := time.Date(year, month, day+*t.DueOffset, 0, 0, 0, 0, anchor.Location())
+
+	return &due
+}
+
+// FillPlaceholders replaces the placeholders in `text` with their value in `values`. Placeholders without a value are
+// left as they are.
+func FillPlaceholders(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+
+		if value, ok := values[name]; ok {
+			return value
+		}
+
+		return placeholder
+	})
+}
+
+// Count the calendar days from `from` to `to` in the location of `from`.
+func daysBetween(from time.Time, to time.Time) int {
+	to = to.In(from.Location())
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(end.Sub(start).Hours() / 24)
+}
@@ -0,0 +1,111 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestNewTemplate(t *testing.T) {
+	day := func(day int) *time.Time {
+		due := time.Date(2025, time.March, day, 9, 0, 0, 0, time.UTC)
+		return &due
+	}
+	offset := func(days int) *int {
+		return &days
+	}
+
+	t.Run("keeps the tree and makes due dates relative", func(t *testing.T) {
+		weekly := models.Recurrence{Frequency: models.Weekly, Interval: 1}
+		release := models.Task{ID: 1, Description: "release {{version}}", Tags: []string{"work"}, Due: day(14), Done: true}
+		subtasks := []models.Task{
+			{ID: 2, Description: "write changelog", ParentID: 1, Due: day(12), Priority: 2},
+			{ID: 3, Description: "tag v{{version}}", ParentID: 1, Estimate: 0.5},
+			{ID: 4, Description: "check links", ParentID: 2, Due: day(17), Recurrence: &weekly},
+		}
+
+		got := models.NewTemplate("Release", release, subtasks)
+		want := models.Template{
+			Name: "Release",
+			Task: models.TemplateTask{
+				Description: "release {{version}}",
+				Tags:        []string{"work"},
+				DueOffset:   offset(0),
+				Subtasks: []models.TemplateTask{
+					{
+						Description: "write changelog",
+						Priority:    2,
+						DueOffset:   offset(-2),
+						Subtasks:    []models.TemplateTask{{Description: "check links", DueOffset: offset(3), Recurrence: &weekly}},
+					},
+					{Description: "tag v{{version}}", Estimate: 0.5},
+				},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got template %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("due dates are relative to the earliest due date if the task has none", func(t *testing.T) {
+		onboarding := models.Task{ID: 1, Description: "onboard {{name}}"}
+		subtasks := []models.Task{
+			{ID: 2, Description: "order laptop", ParentID: 1, Due: day(10)},
+			{ID: 3, Description: "first day", ParentID: 1, Due: day(17)},
+		}
+
+		got := models.NewTemplate("Onboarding", onboarding, subtasks).Task
+
+		if got.DueOffset != nil {
+			t.Errorf("got due offset %d for the top-level task, want none", *got.DueOffset)
+		}
+
+		if a, b := got.Subtasks[0].DueOffset, got.Subtasks[1].DueOffset; *a != 0 || *b != 7 {
+			t.Errorf("got due offsets %d and %d, want 0 and 7", *a, *b)
+		}
+	})
+}
+
+func TestTemplate_Placeholders(t *testing.T) {
+	template := models.Template{Task: models.TemplateTask{
+		Description: "release {{version}}",
+		Notes:       "ask {{ owner }} to approve {{version}}",
+		Subtasks:    []models.TemplateTask{{Description: "email {{team}}", Notes: "{{not a placeholder}}"}},
+	}}
+
+	if got, want := template.Placeholders(), []string{"version", "owner", "team"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got placeholders %q, want %q", got, want)
+	}
+
+	if got := (models.Template{Task: models.TemplateTask{Description: "water plants"}}).Placeholders(); got != nil {
+		t.Errorf("got placeholders %q, want none", got)
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{"version": "1.2.0", "owner": "Alice"}
+	got := models.FillPlaceholders("{{ owner }} releases {{version}} to {{team}}", values)
+
+	if want := "Alice releases 1.2.0 to {{team}}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateTask_Due(t *testing.T) {
+	location := time.FixedZone("NZDT", 13*60*60)
+	anchor := time.Date(2025, time.March, 30, 15, 0, 0, 0, location)
+	days := 3
+
+	got := models.TemplateTask{DueOffset: &days}.Due(anchor)
+
+	if want := time.Date(2025, time.April, 2, 0, 0, 0, 0, location); got == nil || !got.Equal(want) {
+		t.Errorf("got due date %v, want %v", got, want)
+	}
+
+	if got := (models.TemplateTask{}).Due(anchor); got != nil {
+		t.Errorf("got due date %v for a task without a due date, want nil", got)
+	}
+}
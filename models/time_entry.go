@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// A TimeEntry records time that a user spent working on a task, either with a timer or entered by hand.
+type TimeEntry struct {
+	ID     uint64
+	TaskID uint64
+	// The email of the user that spent the time.
+	User  string
+	Start time.Time
+	// When the user stopped working, or nil while the timer is running.
+	End *time.Time `json:",omitempty"`
+	// What the time was spent on, e.g., for an invoice.
+	Note string `json:",omitempty"`
+}
+
+// Running reports whether the entry is a timer that has not been stopped.
+func (e TimeEntry) Running() bool {
+	return e.End == nil
+}
+
+// Get the time spent, where the time for a running timer is counted up to `now`.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	end := now
+
+	if e.End != nil {
+		end = *e.End
+	}
+
+	return end.Sub(e.Start)
+}
+
+// Get the total time spent in `entries`, where the time for running timers is counted up to `now`.
+func TotalDuration(entries []TimeEntry, now time.Time) time.Duration {
+	var total time.Duration
+
+	for _, entry := range entries {
+		total += entry.Duration(now)
+	}
+
+	return total
+}
+
+// A TimesheetDay holds the time entries that started on a single day.
+type TimesheetDay struct {
+	// Midnight at the start of the day.
+	Date    time.Time
+	Entries []TimeEntry
+}
+
+// Get the total time spent on the day, where the time for running timers is counted up to `now`.
+func (d TimesheetDay) Total(now time.Time) time.Duration {
+	return TotalDuration(d.Entries, now)
+}
+
+// Group `entries`, sorted by start time, by the day that they started on.
+func GroupByDay(entries []TimeEntry) []TimesheetDay {
+	var days []TimesheetDay
+
+	for _, entry := range entries {
+		year, month, day := entry.Start.Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, entry.Start.Location())
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, TimesheetDay{Date: date})
+		}
+
+		days[len(days)-1].Entries = append(days[len(days)-1].Entries, entry)
+	}
+
+	return days
+}
@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestTimeEntry_Duration(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	now := start.Add(2 * time.Hour)
+
+	stopped := models.TimeEntry{Start: start, End: &end}
+	running := models.TimeEntry{Start: start}
+
+	if got := stopped.Duration(now); got != 90*time.Minute {
+		t.Errorf("got duration %v for a stopped timer, want %v", got, 90*time.Minute)
+	}
+
+	if got := running.Duration(now); got != 2*time.Hour {
+		t.Errorf("got duration %v for a running timer, want %v", got, 2*time.Hour)
+	}
+
+	if got := models.TotalDuration([]models.TimeEntry{stopped, running}, now); got != 210*time.Minute {
+		t.Errorf("got total %v, want %v", got, 210*time.Minute)
+	}
+}
+
+func TestGroupByDay(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	entries := []models.TimeEntry{
+		{ID: 1, Start: at(3, 9)},
+		{ID: 2, Start: at(3, 23)},
+		{ID: 3, Start: at(5, 0)},
+	}
+
+	got := models.GroupByDay(entries)
+	want := []models.TimesheetDay{
+		{Date: at(3, 0), Entries: entries[:2]},
+		{Date: at(5, 0), Entries: entries[2:]},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got days %v, want %v", got, want)
+	}
+}
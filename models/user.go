@@ -4,4 +4,6 @@ type User struct {
 	ID       uint64
 	Email    string
 	Password *PasswordHash
+	// The passwords that apps, such as CalDAV clients, sign in with instead of the user's password.
+	AppPasswords []AppPassword `json:",omitempty"`
 }
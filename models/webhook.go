@@ -0,0 +1,150 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// A WebhookEvent is a kind of change to a task that webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	// A task was added to the list, either as a new task or by restoring it from the trash.
+	WebhookTaskCreated WebhookEvent = "task.created"
+	// The details of a task changed, e.g., its description, due date, assignees or place in the task tree, or it was
+	// reopened.
+	WebhookTaskUpdated WebhookEvent = "task.updated"
+	// A task was marked as done.
+	WebhookTaskCompleted WebhookEvent = "task.completed"
+	// A task was moved to the trash.
+	WebhookTaskDeleted WebhookEvent = "task.deleted"
+)
+
+// WebhookEvents lists the events that webhooks can subscribe to.
+var WebhookEvents = []WebhookEvent{WebhookTaskCreated, WebhookTaskUpdated, WebhookTaskCompleted, WebhookTaskDeleted}
+
+// ParseWebhookEvent parses the name of a webhook event, e.g., "task.created".
+//
+// Returns an error if `name` is not the name of a webhook event.
+func ParseWebhookEvent(name string) (WebhookEvent, error) {
+	if event := WebhookEvent(name); slices.Contains(WebhookEvents, event) {
+		return event, nil
+	}
+
+	return "", fmt.Errorf("invalid webhook event %q, expected one of %q", name, WebhookEvents)
+}
+
+// A Webhook sends the changes to the tasks in a task list to another service as they happen.
+type Webhook struct {
+	ID uint64
+	// Where the changes are sent, e.g., https://example.com/hooks/yatta.
+	URL string
+	// The key that the deliveries are signed with, so that the receiver can check that they came from Yatta.
+	Secret string
+	// The kinds of changes that are sent.
+	Events []WebhookEvent
+}
+
+// Validate checks that the webhook has an HTTP or HTTPS URL, a secret and at least one event to subscribe to.
+func (w Webhook) Validate() error {
+	parsed, err := url.Parse(w.URL)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("the URL %q is not an HTTP or HTTPS URL", w.URL)
+	}
+
+	if w.Secret == "" {
+		return fmt.Errorf("the secret is empty")
+	}
+
+	if len(w.Events) == 0 {
+		return fmt.Errorf("the webhook does not subscribe to any events")
+	}
+
+	for _, event := range w.Events {
+		if _, err := ParseWebhookEvent(string(event)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The number of times a delivery is attempted before giving up on it.
+const MaxDeliveryAttempts = 8
+
+// How long to wait before retrying a failed delivery for the first time. The wait doubles after each failed attempt.
+const FirstRetryDelay = time.Minute
+
+// A WebhookPayload is the body of a delivery, which is sent as JSON.
+type WebhookPayload struct {
+	Event WebhookEvent
+	// The email of the user whose task list changed.
+	List string
+	// The task as it was right after the change.
+	Task       Task
+	OccurredAt time.Time
+}
+
+// A Delivery is the sending of an event to a webhook, which is retried until it succeeds or too many attempts fail.
+type Delivery struct {
+	ID        uint64
+	WebhookID uint64
+	// The email of the user whose task list has the webhook.
+	List   string
+	Event  WebhookEvent
+	TaskID uint64
+	// Where the delivery is sent.
+	URL string
+	// The JSON encoded [WebhookPayload] that is sent.
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	// The number of times sending the delivery was tried.
+	Attempts int `json:",omitempty"`
+	// When to try sending the delivery next.
+	NextAttemptAt time.Time
+	// When the delivery was received, or nil if it has not been received yet.
+	DeliveredAt *time.Time `json:",omitempty"`
+	// Whether the delivery was given up on after failing too many times.
+	Failed bool `json:",omitempty"`
+	// The HTTP status code of the response to the last attempt, or zero if there was no response.
+	StatusCode int `json:",omitempty"`
+	// Why the last attempt failed, or empty if it did not fail.
+	LastError string `json:",omitempty"`
+}
+
+// Pending reports whether the delivery is still waiting to be received.
+func (d Delivery) Pending() bool {
+	return d.DeliveredAt == nil && !d.Failed
+}
+
+// RecordAttempt updates the delivery after an attempt at `at` that got a response with `statusCode`, or that failed
+// with `err` before there was a response.
+//
+// Attempts succeed if the response has a 2xx status code. Failed attempts are retried with exponential backoff
+// starting at [FirstRetryDelay], until [MaxDeliveryAttempts] attempts have been made.
+func (d *Delivery) RecordAttempt(statusCode int, err error, at time.Time) {
+	d.Attempts++
+	d.StatusCode = statusCode
+	d.LastError = ""
+
+	switch {
+	case err != nil:
+		d.LastError = err.Error()
+	case statusCode < 200 || statusCode > 299:
+		d.LastError = fmt.Sprintf("the response had the status code %d", statusCode)
+	default:
+		d.DeliveredAt = &at
+		return
+	}
+
+	if d.Attempts >= MaxDeliveryAttempts {
+		d.Failed = true
+		return
+	}
+
+	d.NextAttemptAt = at.Add(FirstRetryDelay << (d.Attempts - 1))
+}
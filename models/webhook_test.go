@@ -0,0 +1,72 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestWebhook_Validate(t *testing.T) {
+	valid := models.Webhook{URL: "https://example.com/hooks", Secret: "s3cret", Events: []models.WebhookEvent{models.WebhookTaskCreated}}
+
+	if err := valid.Validate(); err != nil {
+		t.Errorf("got error %v for a valid webhook", err)
+	}
+
+	cases := map[string]func(w *models.Webhook){
+		"relative URL":   func(w *models.Webhook) { w.URL = "/hooks" },
+		"other scheme":   func(w *models.Webhook) { w.URL = "ftp://example.com/hooks" },
+		"empty secret":   func(w *models.Webhook) { w.Secret = "" },
+		"no events":      func(w *models.Webhook) { w.Events = nil },
+		"unknown events": func(w *models.Webhook) { w.Events = []models.WebhookEvent{"task.renamed"} },
+	}
+
+	for name, change := range cases {
+		t.Run(name, func(t *testing.T) {
+			webhook := valid
+			change(&webhook)
+
+			if err := webhook.Validate(); err == nil {
+				t.Errorf("got no error for %+v", webhook)
+			}
+		})
+	}
+}
+
+func TestDelivery_RecordAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("successful deliveries are done", func(t *testing.T) {
+		delivery := models.Delivery{NextAttemptAt: now}
+		delivery.RecordAttempt(204, nil, now)
+
+		if delivery.Pending() || delivery.DeliveredAt == nil || !delivery.DeliveredAt.Equal(now) || delivery.Attempts != 1 {
+			t.Errorf("got %+v, want a delivery received at %v after one attempt", delivery, now)
+		}
+	})
+
+	t.Run("failed deliveries are retried with exponential backoff", func(t *testing.T) {
+		delivery := models.Delivery{NextAttemptAt: now}
+		at := now
+
+		for attempt := 1; attempt < models.MaxDeliveryAttempts; attempt++ {
+			delivery.RecordAttempt(500, nil, at)
+
+			want := at.Add(models.FirstRetryDelay * time.Duration(1<<(attempt-1)))
+
+			if !delivery.Pending() || !delivery.NextAttemptAt.Equal(want) {
+				t.Fatalf("got next attempt %v after attempt %d, want %v", delivery.NextAttemptAt, attempt, want)
+			}
+
+			at = delivery.NextAttemptAt
+		}
+
+		delivery.RecordAttempt(0, errors.New("connection refused"), at)
+
+		if delivery.Pending() || !delivery.Failed || delivery.LastError != "connection refused" {
+			t.Errorf("got %+v, want the delivery to be given up on", delivery)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Workspace is a space on the server for one team. The task lists of its members, and everything in them, such as
+// boards, saved searches and templates, can only be seen by the other members of the workspace.
+//
+// A user belongs to at most one workspace. Users that do not belong to a workspace share the space outside of the
+// workspaces.
+type Workspace struct {
+	ID   uint64
+	Name string
+	// The users in the workspace. Admins can invite and remove members, while other members can only use the lists in
+	// the workspace.
+	Members []Member
+	// The users that have been asked to join the workspace and have not accepted yet, with the role they will have.
+	Invitations []Member `json:",omitempty"`
+	// The choices that the admins have made for everyone in the workspace.
+	Settings WorkspaceSettings
+}
+
+// WorkspaceSettings are the choices that the admins of a workspace make for everyone in it.
+type WorkspaceSettings struct {
+	// The time zone, e.g., Pacific/Auckland, that dates and times are in when the browser does not say which time zone
+	// it is in, or empty for the time zone of the server.
+	TimeZone string `json:",omitempty"`
+	// Whether the secret links that let other apps into the task lists in the workspace, i.e., calendar feeds and
+	// inbound URLs, are turned off.
+	DisableSecretLinks bool `json:",omitempty"`
+}
+
+// Validate checks that the time zone of the settings is a known time zone.
+func (s WorkspaceSettings) Validate() error {
+	if s.TimeZone == "" {
+		return nil
+	}
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q, use a time zone such as Pacific/Auckland", s.TimeZone)
+	}
+
+	return nil
+}
+
+// Location gets the time zone of the settings, or `fallback` if the settings do not have one.
+func (s WorkspaceSettings) Location(fallback *time.Location) *time.Location {
+	if location, err := time.LoadLocation(s.TimeZone); s.TimeZone != "" && err == nil {
+		return location
+	}
+
+	return fallback
+}
+
+// MemberRole gets the role of the member with `email`, or the empty role if they are not a member of the workspace.
+func (w Workspace) MemberRole(email string) Role {
+	for _, member := range w.Members {
+		if member.Email == email {
+			return member.Role
+		}
+	}
+
+	return ""
+}
+
+// IsInvited reports whether the user with `email` has been asked to join the workspace.
+func (w Workspace) IsInvited(email string) bool {
+	for _, invitation := range w.Invitations {
+		if invitation.Email == email {
+			return true
+		}
+	}
+
+	return false
+}
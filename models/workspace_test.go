@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+func TestWorkspace_MemberRole(t *testing.T) {
+	workspace := models.Workspace{
+		Members:     []models.Member{{Email: "alice@example.com", Role: models.RoleAdmin}, {Email: "bob@example.com", Role: models.RoleViewer}},
+		Invitations: []models.Member{{Email: "carol@example.com", Role: models.RoleEditor}},
+	}
+
+	cases := map[string]models.Role{
+		"alice@example.com": models.RoleAdmin,
+		"bob@example.com":   models.RoleViewer,
+		"carol@example.com": "",
+		"erin@example.com":  "",
+	}
+
+	for email, want := range cases {
+		if got := workspace.MemberRole(email); got != want {
+			t.Errorf("got role %q for %s, want %q", got, email, want)
+		}
+	}
+
+	if !workspace.IsInvited("carol@example.com") || workspace.IsInvited("bob@example.com") {
+		t.Errorf("got wrong invitations for workspace %+v, want only Carol to be invited", workspace)
+	}
+}
+
+func TestWorkspaceSettings(t *testing.T) {
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+
+	if err != nil {
+		t.Fatalf("could not load time zone: %v", err)
+	}
+
+	settings := models.WorkspaceSettings{TimeZone: "Pacific/Auckland"}
+
+	if err := settings.Validate(); err != nil {
+		t.Errorf("got error %v, want settings with a known time zone to be valid", err)
+	}
+
+	if got := settings.Location(time.UTC); got.String() != auckland.String() {
+		t.Errorf("got time zone %v, want %v", got, auckland)
+	}
+
+	if got := (models.WorkspaceSettings{}).Location(time.UTC); got != time.UTC {
+		t.Errorf("got time zone %v, want the fallback for settings without a time zone", got)
+	}
+
+	if err := (models.WorkspaceSettings{TimeZone: "Middle/Earth"}).Validate(); err == nil {
+		t.Error("got no error, want an error for an unknown time zone")
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AnthonyDickson/yatta/mentions"
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// Show the notifications of the signed in user.
+func (s *Server) getNotifications(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	notifications, err := taskStore.GetNotifications(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the notifications of %q: %v", user.Email, err))
+		return
+	}
+
+	body, err := s.renderer.RenderNotifications(NotificationsPage{User: user.Email, Notifications: notifications})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Mark all the notifications of the signed in user as read.
+func (s *Server) readNotifications(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if err := taskStore.MarkNotificationsRead(user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not mark the notifications of %q as read", user.Email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Show the link to the notifications page with how many notifications the signed in user has not read. Nothing is
+// shown if nobody is signed in.
+func (s *Server) getNotificationBell(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	if user == nil {
+		return
+	}
+
+	notifications, err := taskStore.GetNotifications(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the notifications of %q: %v", user.Email, err))
+		return
+	}
+
+	unread := 0
+
+	for _, notification := range notifications {
+		if !notification.Read {
+			unread++
+		}
+	}
+
+	body, err := s.renderer.RenderNotificationBell(unread)
+	writeResponse(w, body, err, r.URL)
+}
+
+// Tell the users that are mentioned in `text` that they were mentioned in the task with `id`, or in the comment with
+// `commentID` on that task if it is not zero.
+//
+// Users that cannot see the task, whoever made the request and users that have already been told about the mention,
+// e.g., before the text was edited, are not told.
+//
+// Handlers log the errors from this rather than failing the request: the task or comment has already been saved, so
+// a retried request would add it again.
+func (s *Server) notifyMentions(r *http.Request, id uint64, commentID uint64, text string) error {
+	workspace, err := s.requestWorkspaceID(r)
+
+	if err != nil {
+		return fmt.Errorf("could not get the workspace of the request: %v", err)
+	}
+
+	taskStore, userStore := s.storesOf(workspace)
+	users, err := userStore.GetUsers()
+
+	if err != nil {
+		return fmt.Errorf("could not get users: %v", err)
+	}
+
+	mentioned := mentions.Find(text, users)
+
+	if len(mentioned) == 0 {
+		return nil
+	}
+
+	actor, err := s.authenticate(r)
+
+	if err != nil {
+		return fmt.Errorf("could not authenticate the request: %v", err)
+	}
+
+	task, err := taskStore.GetTask(id)
+
+	if err != nil {
+		return fmt.Errorf("could not get task %d: %v", id, err)
+	}
+
+	if task == nil {
+		return fmt.Errorf("could not find task %d", id)
+	}
+
+	owner, err := taskStore.GetOwner(id)
+
+	if err != nil {
+		return fmt.Errorf("could not get the owner of task %d: %v", id, err)
+	}
+
+	mention := models.Notification{
+		Kind:      models.NotificationMentioned,
+		TaskID:    id,
+		Task:      task.Description,
+		CommentID: commentID,
+	}
+
+	if actor != nil {
+		mention.Actor = actor.Email
+	}
+
+	for _, email := range mentioned {
+		if email == mention.Actor {
+			continue
+		}
+
+		user, err := userStore.GetUserByEmail(email)
+
+		if err != nil {
+			return fmt.Errorf("could not get user %q: %v", email, err)
+		}
+
+		ok, err := s.canSeeList(user, owner)
+
+		if err != nil {
+			return fmt.Errorf("could not check whether %q can see the task list of %q: %v", email, owner, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		notifications, err := taskStore.GetNotifications(email)
+
+		if err != nil {
+			return fmt.Errorf("could not get the notifications of %q: %v", email, err)
+		}
+
+		if alreadyNotified(notifications, mention) {
+			continue
+		}
+
+		if err := taskStore.AddNotification(email, mention); err != nil {
+			return fmt.Errorf("could not notify %q: %v", email, err)
+		}
+	}
+
+	return nil
+}
+
+// Whether `notifications` includes a notification about the same mention as `mention`.
+func alreadyNotified(notifications []models.Notification, mention models.Notification) bool {
+	for _, notification := range notifications {
+		if notification.Kind == mention.Kind && notification.TaskID == mention.TaskID && notification.CommentID == mention.CommentID {
+			return true
+		}
+	}
+
+	return false
+}
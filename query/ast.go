@@ -0,0 +1,279 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/search"
+)
+
+// An Expr is a node in the syntax tree of a query.
+type Expr interface {
+	// Match reports whether `task` satisfies the expression, where relative dates are relative to `now`.
+	Match(task models.Task, now time.Time) bool
+
+	// String formats the expression in prefix notation, e.g., "(and tag:work (not done))".
+	String() string
+}
+
+// An Op compares a field of a task against a value.
+type Op string
+
+const (
+	OpEqual        Op = ":"
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+)
+
+// Whether the result of comparing a task's field against a value (see [cmp.Compare]) satisfies the operator.
+func (o Op) holds(comparison int) bool {
+	switch o {
+	case OpEqual:
+		return comparison == 0
+	case OpLess:
+		return comparison < 0
+	case OpLessEqual:
+		return comparison <= 0
+	case OpGreater:
+		return comparison > 0
+	case OpGreaterEqual:
+		return comparison >= 0
+	default:
+		return false
+	}
+}
+
+// All matches every task. It is the result of parsing an empty query.
+type All struct{}
+
+func (All) Match(task models.Task, now time.Time) bool {
+	return true
+}
+
+func (All) String() string {
+	return "all"
+}
+
+// And matches tasks that match all of its operands.
+type And struct {
+	Operands []Expr
+}
+
+func (a And) Match(task models.Task, now time.Time) bool {
+	for _, operand := range a.Operands {
+		if !operand.Match(task, now) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a And) String() string {
+	return formatOperation("and", a.Operands)
+}
+
+// Or matches tasks that match any of its operands.
+type Or struct {
+	Operands []Expr
+}
+
+func (o Or) Match(task models.Task, now time.Time) bool {
+	for _, operand := range o.Operands {
+		if operand.Match(task, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Or) String() string {
+	return formatOperation("or", o.Operands)
+}
+
+// Not matches tasks that do not match its operand.
+type Not struct {
+	Operand Expr
+}
+
+func (n Not) Match(task models.Task, now time.Time) bool {
+	return !n.Operand.Match(task, now)
+}
+
+func (n Not) String() string {
+	return formatOperation("not", []Expr{n.Operand})
+}
+
+func formatOperation(name string, operands []Expr) string {
+	parts := []string{name}
+
+	for _, operand := range operands {
+		parts = append(parts, operand.String())
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// Text matches tasks whose description contains a word, or a phrase if it was quoted.
+//
+// Words match other words with the same stem, e.g., "deploy" matches "deployed". Phrases match exactly, ignoring
+// case.
+type Text struct {
+	Text   string
+	Phrase bool
+}
+
+func (t Text) Match(task models.Task, now time.Time) bool {
+	if t.Phrase {
+		return strings.Contains(strings.ToLower(task.Description), strings.ToLower(t.Text))
+	}
+
+	stem := search.Stem(strings.ToLower(t.Text))
+
+	for _, token := range search.Tokenize(task.Description) {
+		if token.Stem == stem {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Text) String() string {
+	if t.Phrase {
+		return fmt.Sprintf("%q", t.Text)
+	}
+
+	return t.Text
+}
+
+// Tag matches tasks that have a tag, ignoring case.
+type Tag struct {
+	Tag string
+}
+
+func (t Tag) Match(task models.Task, now time.Time) bool {
+	return slices.ContainsFunc(task.Tags, func(tag string) bool {
+		return strings.EqualFold(tag, t.Tag)
+	})
+}
+
+func (t Tag) String() string {
+	return "tag:" + t.Tag
+}
+
+// Priority matches tasks by comparing their priority against a value.
+type Priority struct {
+	Op    Op
+	Value int
+}
+
+func (p Priority) Match(task models.Task, now time.Time) bool {
+	return p.Op.holds(cmp.Compare(task.Priority, p.Value))
+}
+
+func (p Priority) String() string {
+	return fmt.Sprintf("priority%s%d", p.Op, p.Value)
+}
+
+// Due matches tasks by comparing their due date against a date. Only the calendar date is compared, not the time.
+//
+// Tasks without a due date only match if None is set.
+type Due struct {
+	Op Op
+	// The date to compare against when it is relative to the current date, in days after today.
+	Days int
+	// The date to compare against when it is an absolute date. Takes precedence over Days.
+	Date time.Time
+	// Match tasks that do not have a due date instead.
+	None bool
+}
+
+func (d Due) Match(task models.Task, now time.Time) bool {
+	if d.None || task.Due == nil {
+		return d.None && task.Due == nil
+	}
+
+	location := now.Location()
+	target := d.Date
+
+	if target.IsZero() {
+		target = startOfDay(now).AddDate(0, 0, d.Days)
+	} else {
+		target = time.Date(target.Year(), target.Month(), target.Day(), 0, 0, 0, 0, location)
+	}
+
+	return d.Op.holds(startOfDay(task.Due.In(location)).Compare(target))
+}
+
+func (d Due) String() string {
+	switch {
+	case d.None:
+		return "due:none"
+	case !d.Date.IsZero():
+		return fmt.Sprintf("due%s%s", d.Op, d.Date.Format(time.DateOnly))
+	default:
+		return fmt.Sprintf("due%s%dd", d.Op, d.Days)
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// A Status of a task that can be searched for.
+type Status string
+
+const (
+	StatusDone    Status = "done"
+	StatusOpen    Status = "open"
+	StatusBlocked Status = "blocked"
+	StatusSnoozed Status = "snoozed"
+)
+
+// Is matches tasks that have a status.
+type Is struct {
+	Status Status
+}
+
+func (i Is) Match(task models.Task, now time.Time) bool {
+	switch i.Status {
+	case StatusDone:
+		return task.Done
+	case StatusOpen:
+		return !task.Done
+	case StatusBlocked:
+		return task.Blocked
+	case StatusSnoozed:
+		return task.Snoozed(now)
+	default:
+		return false
+	}
+}
+
+func (i Is) String() string {
+	return string(i.Status)
+}
+
+// Mentions reports whether `expr` contains a term that matches tasks with `status`, even if the term is negated.
+func Mentions(expr Expr, status Status) bool {
+	switch expr := expr.(type) {
+	case Is:
+		return expr.Status == status
+	case Not:
+		return Mentions(expr.Operand, status)
+	case And:
+		return slices.ContainsFunc(expr.Operands, func(operand Expr) bool { return Mentions(operand, status) })
+	case Or:
+		return slices.ContainsFunc(expr.Operands, func(operand Expr) bool { return Mentions(operand, status) })
+	default:
+		return false
+	}
+}
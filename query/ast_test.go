@@ -0,0 +1,125 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/query"
+)
+
+func TestExpr_Match(t *testing.T) {
+	location := time.FixedZone("NZDT", 13*60*60)
+	now := time.Date(2025, time.March, 10, 9, 30, 0, 0, location)
+	date := func(year int, month time.Month, day int, hour int) *time.Time {
+		d := time.Date(year, month, day, hour, 0, 0, 0, location)
+		return &d
+	}
+
+	deploy := models.Task{ID: 1, Description: "Deployed the website", Tags: []string{"Work"}, Priority: 2, Due: date(2025, time.March, 12, 17)}
+	approval := models.Task{ID: 2, Description: "Get approval", Tags: []string{"work"}, Priority: 3, Due: date(2025, time.March, 10, 23), Done: true, HiddenUntil: date(2025, time.March, 10, 9)}
+	plants := models.Task{ID: 3, Description: "Water the plants", Tags: []string{"home"}, Blocked: true, HiddenUntil: date(2025, time.March, 11, 0)}
+	tasks := []models.Task{deploy, approval, plants}
+
+	cases := []struct {
+		query string
+		want  []uint64
+	}{
+		{"", []uint64{1, 2, 3}},
+		{"tag:work", []uint64{1, 2}},
+		{"tag:WORK -done", []uint64{1}},
+		{"priority>=2", []uint64{1, 2}},
+		{"priority:3", []uint64{2}},
+		{"priority<1", []uint64{3}},
+		{"due:today", []uint64{2}},
+		{"due<=2d", []uint64{1, 2}},
+		{"due<2d", []uint64{2}},
+		{"due>today", []uint64{1}},
+		{"due:2025-03-12", []uint64{1}},
+		{"due:none", []uint64{3}},
+		{"-due:none", []uint64{1, 2}},
+		{"done", []uint64{2}},
+		{"open", []uint64{1, 3}},
+		{"blocked", []uint64{3}},
+		{"snoozed", []uint64{3}},
+		{"-is:snoozed", []uint64{1, 2}},
+		{"deploying", []uint64{1}},
+		{`"the WEBSITE"`, []uint64{1}},
+		{`"the plants website"`, []uint64{}},
+		{"tag:home OR priority>2", []uint64{2, 3}},
+		{"tag:work due<7d -done priority>=2", []uint64{1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			expr, err := query.Parse(c.query)
+
+			if err != nil {
+				t.Fatalf("got error %v, want no error", err)
+			}
+
+			got := []uint64{}
+
+			for _, task := range tasks {
+				if expr.Match(task, now) {
+					got = append(got, task.ID)
+				}
+			}
+
+			assertIDs(t, got, c.want)
+		})
+	}
+
+	t.Run("due dates are compared in the time zone of now", func(t *testing.T) {
+		// 11pm on the 9th of March in UTC is the 10th of March in New Zealand.
+		utcDue := time.Date(2025, time.March, 9, 23, 0, 0, 0, time.UTC)
+		task := models.Task{ID: 1, Due: &utcDue}
+
+		expr, err := query.Parse("due:today")
+
+		if err != nil {
+			t.Fatalf("got error %v, want no error", err)
+		}
+
+		if !expr.Match(task, now) {
+			t.Errorf("got no match for task due %v with query %q at %v, want match", utcDue, "due:today", now)
+		}
+	})
+}
+
+func assertIDs(t *testing.T, got []uint64, want []uint64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got tasks %v, want %v", got, want)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got tasks %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	cases := map[string]bool{
+		"":                           false,
+		"tag:work":                   false,
+		"snoozed":                    true,
+		"tag:work -is:snoozed":       true,
+		"tag:home OR (done snoozed)": true,
+		"blocked":                    false,
+	}
+
+	for input, want := range cases {
+		expr, err := query.Parse(input)
+
+		if err != nil {
+			t.Fatalf("got error %v parsing %q, want no error", err, input)
+		}
+
+		if got := query.Mentions(expr, query.StatusSnoozed); got != want {
+			t.Errorf("got %t for whether %q mentions snoozed tasks, want %t", got, input, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package query
+
+import (
+	"fmt"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+	// A minus sign at the start of a term, which negates the term.
+	tokenMinus
+	// A run of characters that are not spaces, parentheses or quotes, e.g., "tag:work" or "due<7d".
+	tokenWord
+	// Text in double quotes.
+	tokenPhrase
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenLeftParen:
+		return `"("`
+	case tokenRightParen:
+		return `")"`
+	case tokenMinus:
+		return `"-"`
+	case tokenWord:
+		return "word"
+	case tokenPhrase:
+		return "quoted text"
+	default:
+		return fmt.Sprintf("token(%d)", int(k))
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	// The byte offset of the token in the query.
+	pos int
+}
+
+// Split `input` into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	// Byte offsets are reported in errors, so keep track of them alongside the rune index.
+	offsets := make([]int, len(runes)+1)
+	offset := 0
+
+	for i, r := range runes {
+		offsets[i] = offset
+		offset += len(string(r))
+	}
+
+	offsets[len(runes)] = offset
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", offsets[i]})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", offsets[i]})
+			i++
+		case r == '-':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) || runes[i+1] == ')' {
+				return nil, &SyntaxError{Query: input, Offset: offsets[i], Message: `expected a term after "-"`}
+			}
+
+			tokens = append(tokens, token{tokenMinus, "-", offsets[i]})
+			i++
+		case r == '"':
+			end := i + 1
+
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, &SyntaxError{Query: input, Offset: offsets[i], Message: "missing closing quote"}
+			}
+
+			tokens = append(tokens, token{tokenPhrase, string(runes[i+1 : end]), offsets[i]})
+			i = end + 1
+		default:
+			end := i
+
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+
+			tokens = append(tokens, token{tokenWord, string(runes[i:end]), offsets[i]})
+			i = end
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", len(input)})
+
+	return tokens, nil
+}
@@ -0,0 +1,321 @@
+// Package query implements a small language for filtering tasks, e.g., `tag:work due<7d -done priority>=2`.
+//
+// A query is a list of terms that must all match. Terms can be combined with OR, grouped with parentheses and
+// negated with a leading minus sign or NOT. The terms are:
+//
+//   - tag:NAME matches tasks with the tag NAME.
+//   - priority OP N compares the priority of a task against the integer N.
+//   - due OP DATE compares the due date of a task against DATE, which is today, tomorrow, yesterday, a number of days
+//     or weeks from today (7d, 2w, -1d) or a date (2006-01-02). due:none matches tasks without a due date.
+//   - done, open, blocked and snoozed (or is:done, is:open, is:blocked and is:snoozed) match tasks with that status.
+//   - Any other word matches tasks whose description contains the word, and "quoted text" matches tasks whose
+//     description contains the text.
+//
+// OP is one of :, =, <, <=, > or >=.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A SyntaxError describes why a query could not be parsed.
+type SyntaxError struct {
+	Query string
+	// The byte offset in the query where the error was found.
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Offset+1, e.Message)
+}
+
+// Parse parses a query into a syntax tree.
+//
+// An empty query matches every task. Returns a [*SyntaxError] if the query is not valid.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{input: input, tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return All{}, nil
+	}
+
+	expr, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.errorAt(next, fmt.Sprintf("unexpected %s", describe(next)))
+	}
+
+	return expr, nil
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) errorAt(t token, message string) *SyntaxError {
+	return &SyntaxError{Query: p.input, Offset: t.pos, Message: message}
+}
+
+// Whether the next token is the keyword `keyword`, e.g., OR.
+func (p *parser) atKeyword(keyword string) bool {
+	next := p.peek()
+
+	return next.kind == tokenWord && next.text == keyword
+}
+
+// or = and { "OR" and }
+func (p *parser) parseOr() (Expr, error) {
+	first, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []Expr{first}
+
+	for p.atKeyword("OR") {
+		p.next()
+		operand, err := p.parseAnd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	return Or{operands}, nil
+}
+
+// and = unary { ["AND"] unary }
+func (p *parser) parseAnd() (Expr, error) {
+	first, err := p.parseUnary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []Expr{first}
+
+	for {
+		next := p.peek()
+
+		if next.kind == tokenEOF || next.kind == tokenRightParen || p.atKeyword("OR") {
+			break
+		}
+
+		if p.atKeyword("AND") {
+			p.next()
+		}
+
+		operand, err := p.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	return And{operands}, nil
+}
+
+// unary = ("-" | "NOT") unary | primary
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind == tokenMinus || p.atKeyword("NOT") {
+		p.next()
+		operand, err := p.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return Not{operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// primary = "(" or ")" | term
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenLeftParen:
+		if p.peek().kind == tokenRightParen {
+			return nil, p.errorAt(p.peek(), `expected a term inside "()"`)
+		}
+
+		expr, err := p.parseOr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if next := p.next(); next.kind != tokenRightParen {
+			return nil, p.errorAt(t, `missing closing ")"`)
+		}
+
+		return expr, nil
+	case tokenPhrase:
+		return Text{Text: t.text, Phrase: true}, nil
+	case tokenWord:
+		if t.text == "OR" || t.text == "AND" {
+			return nil, p.errorAt(t, fmt.Sprintf("expected a term before %s", t.text))
+		}
+
+		return p.parseTerm(t)
+	default:
+		return nil, p.errorAt(t, fmt.Sprintf("expected a term but got %s", describe(t)))
+	}
+}
+
+// Matches a field, an operator and a value, e.g., "priority>=2".
+var fieldPattern = regexp.MustCompile(`^([a-zA-Z]+)(<=|>=|:|=|<|>)(.*)$`)
+
+// Matches a number of days or weeks relative to today, e.g., "7d" or "-2w".
+var relativeDatePattern = regexp.MustCompile(`^([+-]?\d+)([dw])$`)
+
+// term = field op value | keyword | word
+func (p *parser) parseTerm(t token) (Expr, error) {
+	switch strings.ToLower(t.text) {
+	case "done":
+		return Is{StatusDone}, nil
+	case "open":
+		return Is{StatusOpen}, nil
+	case "blocked":
+		return Is{StatusBlocked}, nil
+	case "snoozed":
+		return Is{StatusSnoozed}, nil
+	}
+
+	match := fieldPattern.FindStringSubmatch(t.text)
+
+	if match == nil {
+		return Text{Text: t.text}, nil
+	}
+
+	field, op, value := strings.ToLower(match[1]), Op(match[2]), match[3]
+
+	if op == "=" {
+		op = OpEqual
+	}
+
+	if value == "" {
+		return nil, p.errorAt(t, fmt.Sprintf("expected a value after %q", match[1]+match[2]))
+	}
+
+	switch field {
+	case "tag":
+		if op != OpEqual {
+			return nil, p.errorAt(t, fmt.Sprintf(`tags can only be compared with ":", not %q`, op))
+		}
+
+		return Tag{value}, nil
+	case "priority":
+		priority, err := strconv.Atoi(value)
+
+		if err != nil {
+			return nil, p.errorAt(t, fmt.Sprintf("expected a whole number for the priority but got %q", value))
+		}
+
+		return Priority{op, priority}, nil
+	case "due":
+		return p.parseDue(t, op, value)
+	case "is":
+		switch status := Status(strings.ToLower(value)); status {
+		case StatusDone, StatusOpen, StatusBlocked, StatusSnoozed:
+			return Is{status}, nil
+		default:
+			return nil, p.errorAt(t, fmt.Sprintf(`unknown status %q, expected "done", "open", "blocked" or "snoozed"`, value))
+		}
+	default:
+		return nil, p.errorAt(t, fmt.Sprintf(`unknown field %q, expected "tag", "priority", "due" or "is" (use quotes to search for text)`, match[1]))
+	}
+}
+
+func (p *parser) parseDue(t token, op Op, value string) (Expr, error) {
+	switch strings.ToLower(value) {
+	case "none":
+		if op != OpEqual {
+			return nil, p.errorAt(t, fmt.Sprintf(`"none" can only be compared with ":", not %q`, op))
+		}
+
+		return Due{Op: op, None: true}, nil
+	case "today":
+		return Due{Op: op, Days: 0}, nil
+	case "tomorrow":
+		return Due{Op: op, Days: 1}, nil
+	case "yesterday":
+		return Due{Op: op, Days: -1}, nil
+	}
+
+	if match := relativeDatePattern.FindStringSubmatch(value); match != nil {
+		amount, err := strconv.Atoi(match[1])
+
+		if err != nil {
+			return nil, p.errorAt(t, fmt.Sprintf("the number in %q is too large", value))
+		}
+
+		if match[2] == "w" {
+			amount *= 7
+		}
+
+		return Due{Op: op, Days: amount}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+
+	if err != nil {
+		return nil, p.errorAt(t, fmt.Sprintf(`expected a date like "today", "7d", "2w" or "2006-01-02" but got %q`, value))
+	}
+
+	return Due{Op: op, Date: date}, nil
+}
+
+func describe(t token) string {
+	if t.kind == tokenWord {
+		return fmt.Sprintf("%q", t.text)
+	}
+
+	return t.kind.String()
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/query"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"", "all"},
+		{"   ", "all"},
+		{"tag:work", "tag:work"},
+		{"tag=work", "tag:work"},
+		{"priority>=2", "priority>=2"},
+		{"PRIORITY<3", "priority<3"},
+		{"due<7d", "due<7d"},
+		{"due<=2w", "due<=14d"},
+		{"due:today", "due:0d"},
+		{"due>tomorrow", "due>1d"},
+		{"due<yesterday", "due<-1d"},
+		{"due:-3d", "due:-3d"},
+		{"due>=2025-03-01", "due>=2025-03-01"},
+		{"due:none", "due:none"},
+		{"done", "done"},
+		{"-done", "(not done)"},
+		{"is:blocked", "blocked"},
+		{"is:snoozed", "snoozed"},
+		{"NOT open", "(not open)"},
+		{"deploy", "deploy"},
+		{`"deploy the website"`, `"deploy the website"`},
+		{"tag:work due<7d -done priority>=2", "(and tag:work due<7d (not done) priority>=2)"},
+		{"tag:work AND tag:urgent", "(and tag:work tag:urgent)"},
+		{"tag:work OR tag:home", "(or tag:work tag:home)"},
+		{"tag:a tag:b OR tag:c", "(or (and tag:a tag:b) tag:c)"},
+		{"tag:a (tag:b OR tag:c)", "(and tag:a (or tag:b tag:c))"},
+		{"-(tag:b OR tag:c)", "(not (or tag:b tag:c))"},
+		{"--done", "(not (not done))"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			expr, err := query.Parse(c.input)
+
+			if err != nil {
+				t.Fatalf("got error %v, want no error", err)
+			}
+
+			if got := expr.String(); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"tag:", `syntax error at position 1: expected a value after "tag:"`},
+		{"tag<work", `syntax error at position 1: tags can only be compared with ":", not "<"`},
+		{"priority>=high", `syntax error at position 1: expected a whole number for the priority but got "high"`},
+		{"due<soon", `syntax error at position 1: expected a date like "today", "7d", "2w" or "2006-01-02" but got "soon"`},
+		{"due<none", `syntax error at position 1: "none" can only be compared with ":", not "<"`},
+		{"is:finished", `syntax error at position 1: unknown status "finished", expected "done", "open", "blocked" or "snoozed"`},
+		{"colour:red", `syntax error at position 1: unknown field "colour", expected "tag", "priority", "due" or "is" (use quotes to search for text)`},
+		{"tag:work -", `syntax error at position 10: expected a term after "-"`},
+		{"tag:work - done", `syntax error at position 10: expected a term after "-"`},
+		{`"deploy`, "syntax error at position 1: missing closing quote"},
+		{"(tag:work OR tag:home", `syntax error at position 1: missing closing ")"`},
+		{"tag:work)", `syntax error at position 9: unexpected ")"`},
+		{"()", `syntax error at position 2: expected a term inside "()"`},
+		{"OR tag:work", "syntax error at position 1: expected a term before OR"},
+		{"tag:work OR", "syntax error at position 12: expected a term but got end of query"},
+		{"NOT", "syntax error at position 4: expected a term but got end of query"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			_, err := query.Parse(c.input)
+
+			var syntaxError *query.SyntaxError
+
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("got error %v, want a syntax error", err)
+			}
+
+			if got := err.Error(); got != c.want {
+				t.Errorf("got error %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// Run gets the tasks for `user` from `store` that match `expr`, where relative dates are relative to `now`.
+//
+// Returns nil if `user` has no tasks in the store.
+func Run(store stores.TaskStore, user string, expr Expr, now time.Time) ([]models.Task, error) {
+	tasks, err := store.GetTasks(user)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get tasks for %q: %v", user, err)
+	}
+
+	if tasks == nil {
+		return nil, nil
+	}
+
+	matches := []models.Task{}
+
+	for _, task := range tasks {
+		if expr.Match(task, now) {
+			matches = append(matches, task)
+		}
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,52 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/query"
+	"github.com/AnthonyDickson/yatta/stores"
+	"github.com/AnthonyDickson/yatta/yattatest"
+)
+
+func TestRun(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "deploy", "Tags": ["work"]},
+            {"ID": 2, "Description": "water plants", "Tags": ["home"]},
+            {"ID": 3, "Description": "get approval", "Tags": ["work"], "Done": true}
+          ]
+        }
+      ]`)
+	defer cleanup()
+
+	store, err := stores.NewFileTaskStore(database)
+	yattatest.AssertNoError(t, err)
+
+	expr, err := query.Parse("tag:work -done")
+	yattatest.AssertNoError(t, err)
+
+	t.Run("runs query against the user's tasks", func(t *testing.T) {
+		tasks, err := query.Run(store, "Alice", expr, time.Now())
+		yattatest.AssertNoError(t, err)
+
+		var got []uint64
+
+		for _, task := range tasks {
+			got = append(got, task.ID)
+		}
+
+		assertIDs(t, got, []uint64{1})
+	})
+
+	t.Run("returns nil for unknown user", func(t *testing.T) {
+		tasks, err := query.Run(store, "Bob", expr, time.Now())
+		yattatest.AssertNoError(t, err)
+
+		if tasks != nil {
+			t.Errorf("got tasks %v, want nil", tasks)
+		}
+	})
+}
@@ -0,0 +1,516 @@
+// Package quickadd parses the details of a task out of a single line of text, e.g.,
+// "Pay rent tomorrow 9am #home !high every month".
+//
+// The words that are recognised are:
+//
+//   - #NAME adds the tag NAME.
+//   - !low, !medium, !high or !1 to !3 sets the priority to 1, 2 or 3. Other numbers, e.g., !5, are kept in the
+//     description.
+//   - A date sets the due date: today, tomorrow, a weekday (monday, or mon to fri), next week, next month, in N
+//     days, weeks or months, a date (2006-01-02) or a day and month (14 mar, March 14th) optionally followed by a
+//     year.
+//     Dates may start with "on", "by" or "due". Weekdays are always in the future, so "monday" on a Monday is a week
+//     away, and days without a year that have already passed this year are next year.
+//   - A time sets the time of day that the task is due: 9am, 9:30 pm, 17:00 or noon, optionally starting with "at".
+//     A time without a date is due today, or tomorrow if the time has already passed.
+//   - daily, weekly, monthly, yearly, every day, every 2 weeks or every monday sets how often the task repeats.
+//     Repeating tasks without a date start today, or on the given weekday.
+//
+// Only the first date, time and recurrence are used. Any other words, and words starting with a backslash, e.g.,
+// \monday, are kept in the description.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A Kind is the detail of a task that part of the input was recognised as.
+type Kind string
+
+const (
+	KindTag        Kind = "tag"
+	KindPriority   Kind = "priority"
+	KindDate       Kind = "date"
+	KindTime       Kind = "time"
+	KindRecurrence Kind = "recurrence"
+)
+
+// A Match is part of the input that was recognised as a detail of the task.
+type Match struct {
+	Kind Kind
+	// The words that were recognised, e.g., "next week".
+	Text string
+}
+
+// A Result is the task described by the input.
+type Result struct {
+	Description string
+	Tags        []string
+	Priority    int
+	// When the task is due, or nil if no date or time was given.
+	Due        *time.Time
+	Recurrence *models.Recurrence
+	// The parts of the input that were recognised, in the order they appear in the input.
+	Matches []Match
+}
+
+// Update gets the details of the task, other than the description, as a task update.
+func (r Result) Update() models.TaskUpdate {
+	update := models.TaskUpdate{Tags: r.Tags, Due: r.Due, Recurrence: r.Recurrence}
+
+	if r.Priority != 0 {
+		priority := r.Priority
+		update.Priority = &priority
+	}
+
+	return update
+}
+
+// The priorities that can be given by name.
+var priorities = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"med":    2,
+	"high":   3,
+}
+
+// The names of weekdays. "sat" and "sun" are left out since they are also common words.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january":   time.January,
+	"february":  time.February,
+	"march":     time.March,
+	"april":     time.April,
+	"may":       time.May,
+	"june":      time.June,
+	"july":      time.July,
+	"august":    time.August,
+	"september": time.September,
+	"october":   time.October,
+	"november":  time.November,
+	"december":  time.December,
+}
+
+var (
+	// Matches a time on a 12 hour clock, e.g., "9am" or "9:30pm", or without the "am" or "pm".
+	twelveHourPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	// Matches a time on a 24 hour clock, e.g., "17:00".
+	twentyFourHourPattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	// Matches a day of the month, e.g., "14" or "14th".
+	dayPattern  = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	yearPattern = regexp.MustCompile(`^\d{4}$`)
+)
+
+// The state of parsing an input.
+type parser struct {
+	now time.Time
+	// Midnight at the start of the day of now.
+	today  time.Time
+	result Result
+	// The day that the task is due, or nil if no date was found.
+	date *time.Time
+	// The time of day that the task is due as the time since midnight, or nil if no time was found.
+	clock *time.Duration
+	// The first occurrence of a recurrence such as "every monday", used when no date is given.
+	firstOccurrence *time.Time
+}
+
+// Parse gets the task described by `input`, where dates are relative to `now` and in the location of `now`.
+//
+// If every word is recognised, e.g., "tomorrow", the whole input is used as the description and nothing else is set.
+func Parse(input string, now time.Time) Result {
+	year, month, day := now.Date()
+	p := parser{now: now, today: time.Date(year, month, day, 0, 0, 0, 0, now.Location())}
+	words := strings.Fields(input)
+	var description []string
+
+	for i := 0; i < len(words); {
+		if kind, n := p.parse(words[i:]); n > 0 {
+			p.result.Matches = append(p.result.Matches, Match{Kind: kind, Text: strings.Join(words[i:i+n], " ")})
+			i += n
+			continue
+		}
+
+		description = append(description, strings.TrimPrefix(words[i], `\`))
+		i++
+	}
+
+	if strings.Join(description, "") == "" {
+		return Result{Description: strings.TrimSpace(input)}
+	}
+
+	p.result.Description = strings.Join(description, " ")
+	p.result.Due = p.due()
+
+	return p.result
+}
+
+// Try to recognise a detail of the task at the start of `words`.
+//
+// Returns the kind of detail and the number of words that were recognised, or zero if the first word is part of the
+// description.
+func (p *parser) parse(words []string) (Kind, int) {
+	word := strings.ToLower(words[0])
+
+	if strings.HasPrefix(word, "#") && len(word) > 1 {
+		p.result.Tags = append(p.result.Tags, words[0][1:])
+		return KindTag, 1
+	}
+
+	if strings.HasPrefix(word, "!") {
+		if priority, ok := parsePriority(word[1:]); ok {
+			p.result.Priority = priority
+			return KindPriority, 1
+		}
+	}
+
+	if p.result.Recurrence == nil {
+		if n := p.parseRecurrence(words); n > 0 {
+			return KindRecurrence, n
+		}
+	}
+
+	if p.date == nil {
+		if n := p.parseDate(words); n > 0 {
+			return KindDate, n
+		}
+	}
+
+	if p.clock == nil {
+		if n := p.parseClock(words); n > 0 {
+			return KindTime, n
+		}
+	}
+
+	return "", 0
+}
+
+// Parse a priority such as "high" or "2". Numbers outside of the low to high range are not priorities, e.g., "!5".
+func parsePriority(s string) (int, bool) {
+	if priority, ok := priorities[s]; ok {
+		return priority, true
+	}
+
+	priority, err := strconv.Atoi(s)
+
+	return priority, err == nil && priority >= priorities["low"] && priority <= priorities["high"]
+}
+
+// Parse a recurrence such as "weekly", "every 2 weeks" or "every monday" at the start of `words`.
+func (p *parser) parseRecurrence(words []string) int {
+	lower := lowerAll(words, 3)
+
+	if recurrence, err := models.ParseRecurrence(lower[0]); err == nil {
+		p.result.Recurrence = &recurrence
+		return 1
+	}
+
+	if lower[0] != "every" || len(lower) < 2 {
+		return 0
+	}
+
+	if weekday, ok := parseWeekday(lower[1]); ok {
+		first := p.nextWeekday(weekday, true)
+		p.firstOccurrence = &first
+		p.result.Recurrence = &models.Recurrence{Frequency: models.Weekly, Interval: 1}
+		return 2
+	}
+
+	for n := min(len(lower), 3); n >= 2; n-- {
+		if recurrence, err := models.ParseRecurrence(strings.Join(lower[:n], " ")); err == nil {
+			p.result.Recurrence = &recurrence
+			return n
+		}
+	}
+
+	return 0
+}
+
+// Parse a date at the start of `words`, which may start with "on", "by" or "due".
+func (p *parser) parseDate(words []string) int {
+	lower := lowerAll(words, 4)
+
+	if len(lower) > 1 && (lower[0] == "on" || lower[0] == "by" || lower[0] == "due") {
+		if date, n := p.dateAt(lower[1:]); n > 0 {
+			p.date = &date
+			return n + 1
+		}
+	}
+
+	if date, n := p.dateAt(lower); n > 0 {
+		p.date = &date
+		return n
+	}
+
+	return 0
+}
+
+// Parse a date at the start of `words`, which must be in lower case.
+//
+// Returns the date and the number of words in it, or zero if there is no date.
+func (p *parser) dateAt(words []string) (time.Time, int) {
+	switch words[0] {
+	case "today":
+		return p.today, 1
+	case "tomorrow":
+		return p.today.AddDate(0, 0, 1), 1
+	}
+
+	if weekday, ok := parseWeekday(words[0]); ok {
+		return p.nextWeekday(weekday, false), 1
+	}
+
+	if date, err := time.ParseInLocation(time.DateOnly, words[0], p.today.Location()); err == nil {
+		return date, 1
+	}
+
+	if len(words) < 2 {
+		return time.Time{}, 0
+	}
+
+	if words[0] == "next" {
+		if weekday, ok := parseWeekday(words[1]); ok {
+			return p.nextWeekday(weekday, false), 2
+		}
+
+		switch words[1] {
+		case "week":
+			return p.nextWeekday(time.Monday, false), 2
+		case "month":
+			return time.Date(p.today.Year(), p.today.Month()+1, 1, 0, 0, 0, 0, p.today.Location()), 2
+		}
+	}
+
+	if words[0] == "in" && len(words) >= 3 {
+		if amount, err := strconv.Atoi(words[1]); err == nil && amount > 0 {
+			switch strings.TrimSuffix(words[2], "s") {
+			case "day":
+				return p.today.AddDate(0, 0, amount), 3
+			case "week":
+				return p.today.AddDate(0, 0, 7*amount), 3
+			case "month":
+				return p.today.AddDate(0, amount, 0), 3
+			}
+		}
+	}
+
+	return p.dayAndMonth(words)
+}
+
+// Parse a day and month such as "14 mar" or "march 14th", optionally followed by a year.
+func (p *parser) dayAndMonth(words []string) (time.Time, int) {
+	dayWord, monthWord := words[0], words[1]
+
+	if _, ok := parseMonth(dayWord); ok {
+		dayWord, monthWord = monthWord, dayWord
+	}
+
+	match := dayPattern.FindStringSubmatch(dayWord)
+	month, ok := parseMonth(monthWord)
+
+	if match == nil || !ok {
+		return time.Time{}, 0
+	}
+
+	day, _ := strconv.Atoi(match[1])
+	year := p.today.Year()
+	n := 2
+
+	if len(words) > 2 && yearPattern.MatchString(words[2]) {
+		year, _ = strconv.Atoi(words[2])
+		n = 3
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, p.today.Location())
+
+	// Reject days that do not exist, e.g., 31 April, rather than rolling over into the next month.
+	if date.Day() != day {
+		return time.Time{}, 0
+	}
+
+	if n == 2 && date.Before(p.today) {
+		date = date.AddDate(1, 0, 0)
+	}
+
+	return date, n
+}
+
+// Parse a time of day at the start of `words`, which may start with "at".
+func (p *parser) parseClock(words []string) int {
+	lower := lowerAll(words, 3)
+	prefix := 0
+
+	if len(lower) > 1 && lower[0] == "at" {
+		prefix = 1
+	}
+
+	clock, n := parseClock(lower[prefix:])
+
+	if n == 0 {
+		return 0
+	}
+
+	p.clock = &clock
+
+	return prefix + n
+}
+
+// Parse a time of day such as "9am", "9 am", "9:30pm", "17:00" or "noon" at the start of `words`.
+//
+// Returns the time since midnight and the number of words in the time, or zero if there is no time.
+func parseClock(words []string) (time.Duration, int) {
+	if words[0] == "noon" {
+		return 12 * time.Hour, 1
+	}
+
+	meridiem := len(words) > 1 && (words[1] == "am" || words[1] == "pm")
+
+	if match := twentyFourHourPattern.FindStringSubmatch(words[0]); match != nil && !meridiem {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+
+		if hour < 24 && minute < 60 {
+			return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, 1
+		}
+
+		return 0, 0
+	}
+
+	match := twelveHourPattern.FindStringSubmatch(words[0])
+
+	if match == nil {
+		return 0, 0
+	}
+
+	n := 1
+	suffix := match[3]
+
+	// Allow a space before "am" or "pm", e.g., "9 am".
+	if suffix == "" && meridiem {
+		suffix = words[1]
+		n = 2
+	}
+
+	if suffix == "" {
+		return 0, 0
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	if hour < 1 || hour > 12 || minute >= 60 {
+		return 0, 0
+	}
+
+	hour %= 12
+
+	if suffix == "pm" {
+		hour += 12
+	}
+
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, n
+}
+
+// Get when the task is due from the date, time and recurrence that were found.
+func (p *parser) due() *time.Time {
+	var date time.Time
+
+	switch {
+	case p.date != nil:
+		date = *p.date
+	case p.firstOccurrence != nil:
+		date = *p.firstOccurrence
+	case p.clock != nil:
+		date = p.today
+
+		if !p.at(date).After(p.now) {
+			date = date.AddDate(0, 0, 1)
+		}
+	case p.result.Recurrence != nil:
+		date = p.today
+	default:
+		return nil
+	}
+
+	due := p.at(date)
+
+	return &due
+}
+
+// Get the time on `date` at the time of day that was found, or midnight if there is no time.
+func (p *parser) at(date time.Time) time.Time {
+	if p.clock == nil {
+		return date
+	}
+
+	year, month, day := date.Date()
+	hour, minute := int(*p.clock/time.Hour), int(*p.clock%time.Hour/time.Minute)
+
+	return time.Date(year, month, day, hour, minute, 0, 0, date.Location())
+}
+
+// Get the next day that falls on `weekday`, which is after today unless `includeToday` is set.
+func (p *parser) nextWeekday(weekday time.Weekday, includeToday bool) time.Time {
+	days := (int(weekday) - int(p.today.Weekday()) + 7) % 7
+
+	if days == 0 && !includeToday {
+		days = 7
+	}
+
+	return p.today.AddDate(0, 0, days)
+}
+
+// Parse the name of a weekday, e.g., "monday" or "mon".
+func parseWeekday(s string) (time.Weekday, bool) {
+	weekday, ok := weekdays[s]
+
+	return weekday, ok
+}
+
+// Parse the full name of a month or its first three letters, e.g., "march" or "mar".
+func parseMonth(s string) (time.Month, bool) {
+	for name, month := range months {
+		if s == name || s == name[:3] {
+			return month, true
+		}
+	}
+
+	return 0, false
+}
+
+// Get up to the first `n` words in lower case, without any trailing commas.
+func lowerAll(words []string, n int) []string {
+	lower := make([]string, 0, n)
+
+	for _, word := range words[:min(n, len(words))] {
+		lower = append(lower, strings.TrimSuffix(strings.ToLower(word), ","))
+	}
+
+	return lower
+}
@@ -0,0 +1,164 @@
+package quickadd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/quickadd"
+)
+
+func TestParse(t *testing.T) {
+	location := time.FixedZone("NZDT", 13*60*60)
+	// Wednesday 12 March 2025 at 10:30am.
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, location)
+	at := func(year int, month time.Month, day int, hour int, minute int) *time.Time {
+		due := time.Date(year, month, day, hour, minute, 0, 0, location)
+		return &due
+	}
+	daily := &models.Recurrence{Frequency: models.Daily, Interval: 1}
+	weekly := &models.Recurrence{Frequency: models.Weekly, Interval: 1}
+	monthly := &models.Recurrence{Frequency: models.Monthly, Interval: 1}
+
+	cases := []struct {
+		input       string
+		description string
+		tags        []string
+		priority    int
+		due         *time.Time
+		recurrence  *models.Recurrence
+	}{
+		{input: "Pay rent tomorrow 9am #home !high every month", description: "Pay rent", tags: []string{"home"}, priority: 3, due: at(2025, time.March, 13, 9, 0), recurrence: monthly},
+		{input: "water plants", description: "water plants"},
+		{input: "  water   plants  ", description: "water plants"},
+
+		// Tags and priorities.
+		{input: "deploy #work #Urgent", description: "deploy", tags: []string{"work", "Urgent"}},
+		{input: "deploy # now", description: "deploy # now"},
+		{input: "deploy !low", description: "deploy", priority: 1},
+		{input: "deploy !MEDIUM", description: "deploy", priority: 2},
+		{input: "deploy !3", description: "deploy", priority: 3},
+		{input: "deploy !5", description: "deploy !5"},
+		{input: "deploy !0", description: "deploy !0"},
+		{input: "deploy !-1", description: "deploy !-1"},
+		{input: "deploy !important", description: "deploy !important"},
+		{input: "deploy !", description: "deploy !"},
+
+		// Dates.
+		{input: "deploy today", description: "deploy", due: at(2025, time.March, 12, 0, 0)},
+		{input: "deploy Tomorrow", description: "deploy", due: at(2025, time.March, 13, 0, 0)},
+		{input: "deploy friday", description: "deploy", due: at(2025, time.March, 14, 0, 0)},
+		{input: "deploy fri", description: "deploy", due: at(2025, time.March, 14, 0, 0)},
+		{input: "deploy on monday", description: "deploy", due: at(2025, time.March, 17, 0, 0)},
+		{input: "deploy wednesday", description: "deploy", due: at(2025, time.March, 19, 0, 0)},
+		{input: "deploy next tuesday", description: "deploy", due: at(2025, time.March, 18, 0, 0)},
+		{input: "deploy next week", description: "deploy", due: at(2025, time.March, 17, 0, 0)},
+		{input: "deploy next month", description: "deploy", due: at(2025, time.April, 1, 0, 0)},
+		{input: "deploy in 3 days", description: "deploy", due: at(2025, time.March, 15, 0, 0)},
+		{input: "deploy in 1 week", description: "deploy", due: at(2025, time.March, 19, 0, 0)},
+		{input: "deploy in 2 months", description: "deploy", due: at(2025, time.May, 12, 0, 0)},
+		{input: "deploy by 2025-04-01", description: "deploy", due: at(2025, time.April, 1, 0, 0)},
+		{input: "deploy 14 mar", description: "deploy", due: at(2025, time.March, 14, 0, 0)},
+		{input: "deploy due March 20th", description: "deploy", due: at(2025, time.March, 20, 0, 0)},
+		{input: "deploy 1 Jan 2026", description: "deploy", due: at(2026, time.January, 1, 0, 0)},
+		// Days that have passed this year are next year.
+		{input: "deploy 1 Jan", description: "deploy", due: at(2026, time.January, 1, 0, 0)},
+		{input: "deploy 31 apr", description: "deploy 31 apr"},
+		{input: "deploy in 3 boxes", description: "deploy in 3 boxes"},
+		{input: "wear sun cream on sat", description: "wear sun cream on sat"},
+		{input: "read may issue", description: "read may issue"},
+		// Only the first date is used.
+		{input: "move friday meeting to monday", description: "move meeting to monday", due: at(2025, time.March, 14, 0, 0)},
+
+		// Times.
+		{input: "call mum at 5pm", description: "call mum", due: at(2025, time.March, 12, 17, 0)},
+		{input: "call mum 9:45 AM", description: "call mum", due: at(2025, time.March, 13, 9, 45)},
+		{input: "call mum 17:30", description: "call mum", due: at(2025, time.March, 12, 17, 30)},
+		{input: "lunch noon friday", description: "lunch", due: at(2025, time.March, 14, 12, 0)},
+		{input: "call mum 12am tomorrow", description: "call mum", due: at(2025, time.March, 13, 0, 0)},
+		{input: "call mum 12pm", description: "call mum", due: at(2025, time.March, 12, 12, 0)},
+		{input: "buy 2 apples", description: "buy 2 apples"},
+		{input: "meet at the cafe", description: "meet at the cafe"},
+		{input: "call mum 13pm", description: "call mum 13pm"},
+		{input: "call mum 25:00", description: "call mum 25:00"},
+
+		// Recurrence.
+		{input: "stand up daily", description: "stand up", due: at(2025, time.March, 12, 0, 0), recurrence: daily},
+		{input: "stand up daily 9am", description: "stand up", due: at(2025, time.March, 13, 9, 0), recurrence: daily},
+		{input: "review every 2 weeks from friday", description: "review from", due: at(2025, time.March, 14, 0, 0), recurrence: &models.Recurrence{Frequency: models.Weekly, Interval: 2}},
+		{input: "team lunch every friday", description: "team lunch", due: at(2025, time.March, 14, 0, 0), recurrence: weekly},
+		// A recurrence on a weekday starts today if today is that weekday.
+		{input: "bins every wednesday", description: "bins", due: at(2025, time.March, 12, 0, 0), recurrence: weekly},
+		{input: "every now and then", description: "every now and then"},
+
+		// Escaping and inputs without a description.
+		{input: `plan \monday meeting`, description: "plan monday meeting"},
+		{input: "tomorrow", description: "tomorrow"},
+		{input: "#work !high", description: "#work !high"},
+		{input: "", description: ""},
+	}
+
+	for _, test := range cases {
+		t.Run(test.input, func(t *testing.T) {
+			got := quickadd.Parse(test.input, now)
+
+			if got.Description != test.description {
+				t.Errorf("got description %q, want %q", got.Description, test.description)
+			}
+
+			if !reflect.DeepEqual(got.Tags, test.tags) {
+				t.Errorf("got tags %q, want %q", got.Tags, test.tags)
+			}
+
+			if got.Priority != test.priority {
+				t.Errorf("got priority %d, want %d", got.Priority, test.priority)
+			}
+
+			if (got.Due == nil) != (test.due == nil) || (got.Due != nil && !got.Due.Equal(*test.due)) {
+				t.Errorf("got due date %v, want %v", got.Due, test.due)
+			}
+
+			if !reflect.DeepEqual(got.Recurrence, test.recurrence) {
+				t.Errorf("got recurrence %v, want %v", got.Recurrence, test.recurrence)
+			}
+		})
+	}
+}
+
+func TestParse_Matches(t *testing.T) {
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+	got := quickadd.Parse("Pay rent on 14 mar at 9 am #home !high every month", now).Matches
+	want := []quickadd.Match{
+		{Kind: quickadd.KindDate, Text: "on 14 mar"},
+		{Kind: quickadd.KindTime, Text: "at 9 am"},
+		{Kind: quickadd.KindTag, Text: "#home"},
+		{Kind: quickadd.KindPriority, Text: "!high"},
+		{Kind: quickadd.KindRecurrence, Text: "every month"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got matches %v, want %v", got, want)
+	}
+}
+
+func TestResult_Update(t *testing.T) {
+	due := time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC)
+	result := quickadd.Result{
+		Description: "Pay rent",
+		Tags:        []string{"home"},
+		Priority:    3,
+		Due:         &due,
+		Recurrence:  &models.Recurrence{Frequency: models.Monthly, Interval: 1},
+	}
+	priority := 3
+	want := models.TaskUpdate{Tags: result.Tags, Priority: &priority, Due: &due, Recurrence: result.Recurrence}
+
+	if got := result.Update(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got update %v, want %v", got, want)
+	}
+
+	if got := (quickadd.Result{Description: "water plants"}).Update(); !reflect.DeepEqual(got, models.TaskUpdate{}) {
+		t.Errorf("got update %v, want an empty update", got)
+	}
+}
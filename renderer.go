@@ -5,8 +5,19 @@ import (
 	"embed"
 	"fmt"
 	"html/template"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"time"
 
+	"github.com/AnthonyDickson/yatta/calendar"
+	"github.com/AnthonyDickson/yatta/importer"
+	"github.com/AnthonyDickson/yatta/markdown"
 	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/quickadd"
+	"github.com/AnthonyDickson/yatta/report"
+	"github.com/AnthonyDickson/yatta/search"
 )
 
 var (
@@ -16,12 +27,105 @@ var (
 
 // The paths to HTML templates relative to the project root dir.
 const (
-	baseTemplatePath     = "templates/base.html"
-	indexTemplatePath    = "templates/index.html"
-	taskTemplatePath     = "templates/task.html"
-	taskListTemplatePath = "templates/task_list.html"
+	baseTemplatePath          = "templates/base.html"
+	indexTemplatePath         = "templates/index.html"
+	taskTemplatePath          = "templates/task.html"
+	taskListTemplatePath      = "templates/task_list.html"
+	taskTreeTemplatePath      = "templates/task_tree.html"
+	nextActionsTemplatePath   = "templates/next_actions.html"
+	searchTemplatePath        = "templates/search.html"
+	trashTemplatePath         = "templates/trash.html"
+	timesheetTemplatePath     = "templates/timesheet.html"
+	reportTemplatePath        = "templates/report.html"
+	boardTemplatePath         = "templates/board.html"
+	calendarTemplatePath      = "templates/calendar.html"
+	templatesTemplatePath     = "templates/templates.html"
+	membersTemplatePath       = "templates/members.html"
+	workspaceTemplatePath     = "templates/workspace.html"
+	assignedTemplatePath      = "templates/assigned.html"
+	notificationsTemplatePath = "templates/notifications.html"
+	webhooksTemplatePath      = "templates/webhooks.html"
+	inboundTemplatePath       = "templates/inbound.html"
+	feedTemplatePath          = "templates/feed.html"
+	appPasswordsTemplatePath  = "templates/app_passwords.html"
+	importTemplatePath        = "templates/import.html"
 )
 
+// The name of the template in [notificationsTemplatePath] that renders the link to the notifications page.
+const notificationBellTemplateName = "notification_bell"
+
+// The name of the template in [searchTemplatePath] that renders just the search results.
+const searchResultsTemplateName = "search_results"
+
+// The name of the template in [taskListTemplatePath] that renders the preview of a quick-added task.
+const quickAddPreviewTemplateName = "quick_add_preview"
+
+// The name of the template in [taskTemplatePath] that renders a single comment.
+const commentTemplateName = "comment"
+
+// The paths to templates that define snippets shared between pages.
+var partialTemplatePaths = []string{taskTreeTemplatePath}
+
+// Functions that can be called from within templates.
+var templateFuncs = template.FuncMap{
+	"highlight": search.Highlight,
+	"markdown":  markdown.Render,
+	"fileSize":  formatFileSize,
+	"duration":  formatDuration,
+	"dueOffset": formatDueOffset,
+}
+
+// Format when a task in a template is due relative to the date the template is used for, e.g., "3 days after".
+//
+// Returns an empty string if the task has no due date.
+func formatDueOffset(offset *int) string {
+	switch {
+	case offset == nil:
+		return ""
+	case *offset == 0:
+		return "on the day"
+	case *offset == 1:
+		return "1 day after"
+	case *offset == -1:
+		return "1 day before"
+	case *offset < 0:
+		return fmt.Sprintf("%d days before", -*offset)
+	default:
+		return fmt.Sprintf("%d days after", *offset)
+	}
+}
+
+// Format a duration in hours and minutes for people to read, e.g., "1h 30m".
+func formatDuration(duration time.Duration) string {
+	minutes := int64(duration / time.Minute)
+
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
+
+// Format a number of bytes for people to read, e.g., "1.5 MB".
+func formatFileSize(bytes int64) string {
+	const unit = 1000
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	size := float64(bytes)
+	prefixes := "kMGTPE"
+	i := -1
+
+	for size >= unit && i < len(prefixes)-1 {
+		size /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %cB", size, prefixes[i])
+}
+
 type (
 	IndexRenderer interface {
 		// RenderIndex renders the index page.
@@ -29,23 +133,315 @@ type (
 	}
 
 	TaskRenderer interface {
-		// RenderTask renders a single task.
-		RenderTask(task models.Task) ([]byte, error)
+		// RenderTask renders a single task along with its subtasks, comments and activity.
+		RenderTask(page TaskPage) ([]byte, error)
+
+		// RenderComment renders a single comment as it appears on the task page, for adding it to the page.
+		// `user` is the email of the signed in user.
+		RenderComment(comment models.Comment, user string) ([]byte, error)
 	}
 
 	TaskListRenderer interface {
 		// RenderTaskList renders a list of tasks.
-		RenderTaskList(tasks []models.Task) ([]byte, error)
+		RenderTaskList(page TaskListPage) ([]byte, error)
+
+		// RenderQuickAddPreview renders the task that would be added from quick-add text, for updating the task list.
+		RenderQuickAddPreview(result quickadd.Result) ([]byte, error)
+	}
+
+	NextActionsRenderer interface {
+		// RenderNextActions renders tasks in the order they should be worked on.
+		RenderNextActions(tasks []models.Task) ([]byte, error)
+	}
+
+	SearchRenderer interface {
+		// RenderSearch renders the search page with the tasks that matched `query`.
+		RenderSearch(query string, results []models.Task) ([]byte, error)
+
+		// RenderSearchResults renders just the list of tasks that matched `query`, for updating the search page.
+		RenderSearchResults(query string, results []models.Task) ([]byte, error)
+	}
+
+	TrashRenderer interface {
+		// RenderTrash renders the tasks in the trash for `user`.
+		RenderTrash(user string, tasks []models.Task) ([]byte, error)
+	}
+
+	TimesheetRenderer interface {
+		// RenderTimesheet renders the time a user spent on tasks, grouped by day.
+		RenderTimesheet(page TimesheetPage) ([]byte, error)
+	}
+
+	ReportRenderer interface {
+		// RenderReport renders charts of the progress on a user's tasks.
+		RenderReport(page ReportPage) ([]byte, error)
+	}
+
+	BoardRenderer interface {
+		// RenderBoard renders a user's tasks as cards in the columns of their board.
+		RenderBoard(page BoardPage) ([]byte, error)
+	}
+
+	CalendarRenderer interface {
+		// RenderCalendar renders a user's tasks on the days that they are due.
+		RenderCalendar(page CalendarPage) ([]byte, error)
+	}
+
+	TemplateRenderer interface {
+		// RenderTemplates renders a user's templates along with forms for using them.
+		RenderTemplates(page TemplatesPage) ([]byte, error)
+	}
+
+	MembersRenderer interface {
+		// RenderMembers renders who a user's task list is shared with along with forms for changing it.
+		RenderMembers(page MembersPage) ([]byte, error)
+	}
+
+	WorkspaceRenderer interface {
+		// RenderWorkspace renders the workspace that a user belongs to, or the workspaces they have been invited to.
+		RenderWorkspace(page WorkspacePage) ([]byte, error)
+	}
+
+	AssignedRenderer interface {
+		// RenderAssigned renders the open tasks that are assigned to a user across all task lists.
+		RenderAssigned(page AssignedPage) ([]byte, error)
+	}
+
+	NotificationsRenderer interface {
+		// RenderNotifications renders a user's notifications.
+		RenderNotifications(page NotificationsPage) ([]byte, error)
+		// RenderNotificationBell renders the link to the notifications page that is shown on every page, along with
+		// how many notifications are `unread`.
+		RenderNotificationBell(unread int) ([]byte, error)
+	}
+
+	WebhooksRenderer interface {
+		// RenderWebhooks renders the webhooks of a user's task list along with the log of their deliveries.
+		RenderWebhooks(page WebhooksPage) ([]byte, error)
+	}
+
+	InboundRenderer interface {
+		// RenderInbound renders the URLs that other services can use to add tasks to a user's task list.
+		RenderInbound(page InboundPage) ([]byte, error)
+	}
+
+	FeedRenderer interface {
+		// RenderFeed renders the URL that calendar apps can subscribe to for the tasks in a user's task list.
+		RenderFeed(page FeedPage) ([]byte, error)
+	}
+
+	AppPasswordsRenderer interface {
+		// RenderAppPasswords renders the app passwords that a user's CalDAV clients sign in with.
+		RenderAppPasswords(page AppPasswordsPage) ([]byte, error)
+	}
+
+	ImportRenderer interface {
+		// RenderImport renders the form for importing tasks into a user's task list, along with the tasks that would be
+		// imported or how many were.
+		RenderImport(page ImportPage) ([]byte, error)
 	}
 
 	// Renderer renders page templates as a string.
 	Renderer interface {
 		TaskRenderer
 		TaskListRenderer
+		NextActionsRenderer
+		SearchRenderer
+		TrashRenderer
+		TimesheetRenderer
+		ReportRenderer
+		BoardRenderer
+		CalendarRenderer
+		TemplateRenderer
+		MembersRenderer
+		WorkspaceRenderer
+		AssignedRenderer
+		NotificationsRenderer
+		WebhooksRenderer
+		InboundRenderer
+		FeedRenderer
+		AppPasswordsRenderer
+		ImportRenderer
 		IndexRenderer
 	}
 )
 
+// TaskPage is the data for the page that shows a single task.
+type TaskPage struct {
+	Task     models.Task
+	Subtasks []models.Task
+	Comments []models.Comment
+	Activity []models.Activity
+	// The email of the signed in user, or empty if nobody is signed in.
+	User        string
+	Attachments []models.Attachment
+	// Whether files can be attached to the task.
+	CanAttach   bool
+	TimeEntries []models.TimeEntry
+	// The running timer of the signed in user, which may be for another task, or nil if there is none.
+	Timer *models.TimeEntry
+	// The users that the task can be assigned to, i.e., the owner and members of its list.
+	Assignable []string
+}
+
+// TimesheetPage is the data for the page that shows the time a user spent on tasks between two dates.
+type TimesheetPage struct {
+	// The email of the user that spent the time.
+	User string
+	// The first and last days of the timesheet.
+	From    time.Time
+	To      time.Time
+	Entries []models.TimeEntry
+	// The tasks that the time was spent on by ID. Tasks that are in the trash are missing.
+	Tasks map[uint64]models.Task
+	// The time that running timers are counted up to.
+	Now time.Time
+}
+
+// ReportPage is the data for the page that shows the progress on a user's tasks between two dates.
+type ReportPage struct {
+	User string
+	// The first and last days of the report.
+	From time.Time
+	To   time.Time
+	// The work left at the end of each day.
+	Burndown []report.Day
+	// The work completed in each week.
+	Throughput []report.Week
+}
+
+// BoardPage is the data for the page that shows a user's tasks on a kanban board.
+type BoardPage struct {
+	User    string
+	Columns []models.Column
+	Tasks   []models.Task
+}
+
+// CalendarPage is the data for the page that shows a user's tasks on a calendar.
+type CalendarPage struct {
+	User string
+	// Either "month" or "week".
+	View string
+	// A day in the month or week shown on the calendar.
+	Date time.Time
+	// The current time in the time zone of the calendar.
+	Today time.Time
+	// The name of the time zone that the calendar was requested in, or empty for the local time zone.
+	TimeZone string
+	// The days shown on the calendar, starting on a Monday and ending on a Sunday.
+	Days []calendar.Day
+}
+
+// TemplatesPage is the data for the page that shows a user's templates.
+type TemplatesPage struct {
+	User      string
+	Templates []models.Template
+	// The date that templates are used for unless another date is chosen.
+	Today time.Time
+}
+
+// MembersPage is the data for the page that shows who a user's task list is shared with.
+type MembersPage struct {
+	// The user that the task list belongs to.
+	User    string
+	Members []models.Member
+}
+
+// WebhooksPage is the data for the page that shows the webhooks of a user's task list.
+type WebhooksPage struct {
+	// The user that the task list belongs to.
+	User     string
+	Webhooks []models.Webhook
+	// The deliveries of the webhooks, newest first.
+	Deliveries []models.Delivery
+}
+
+// InboundPage is the data for the page that shows the inbound URLs of a user's task list.
+type InboundPage struct {
+	// The user that the task list belongs to.
+	User string
+	// The URL that tasks can be sent to, or empty if the task list does not have an inbound token yet. Emails are sent
+	// to this URL followed by "/email".
+	URL string
+}
+
+// FeedPage is the data for the page that shows the calendar feed of a user's task list.
+type FeedPage struct {
+	// The user that the task list belongs to.
+	User string
+	// The URL of the feed, or empty if the task list does not have a feed token yet.
+	URL string
+}
+
+// AppPasswordsPage is the data for the page that shows the app passwords of the signed in user.
+type AppPasswordsPage struct {
+	// The signed in user.
+	User         string
+	AppPasswords []models.AppPassword
+	// The URL that CalDAV clients sync with.
+	CalDAVURL string
+	// The app password that was just made, or empty if none was. It is only shown once, since only its hash is kept.
+	NewPassword string
+	// The name of the app password that was just made.
+	NewPasswordName string
+}
+
+// ImportPage is the data for the page that imports tasks into a user's task list.
+type ImportPage struct {
+	// The user that the task list belongs to.
+	User string
+	// The name of the time zone that dates without one are in, or empty for the server's time zone.
+	TimeZone string
+	// The tasks that would be imported from the uploaded file, or nil if nothing is being previewed.
+	Preview []importer.Task
+	// How many tasks were just imported, or zero if none were.
+	Imported int
+}
+
+// WorkspacePage is the data for the page that shows the workspace a user belongs to.
+type WorkspacePage struct {
+	// The signed in user.
+	User string
+	// The workspace that the user belongs to, or nil if they do not belong to one.
+	Workspace *models.Workspace
+	// The workspaces that the user has been asked to join.
+	Invitations []models.Workspace
+}
+
+// TaskListPage is the data for a page that shows a list of a user's tasks.
+type TaskListPage struct {
+	// The user that the tasks belong to.
+	User string
+	// The name of the list, e.g., the name of a saved search. Leave empty for the user's main task list.
+	Title string
+	Tasks []models.Task
+	// The user's saved searches, which are shown as smart lists.
+	SavedSearches []models.SavedSearch
+	// The lists that other users have shared with the signed in user.
+	SharedLists []models.SharedList
+	// The email of the signed in user, or empty if nobody is signed in.
+	SignedIn string
+	// Whether the tasks are shown again as soon as the user's task list changes.
+	Live bool
+	// The path and query of the page, which is where the tasks are fetched from when the list changes.
+	Path string
+}
+
+// AssignedPage is the data for the page that shows the open tasks assigned to a user.
+type AssignedPage struct {
+	// The email of the signed in user.
+	User  string
+	Tasks []models.Task
+}
+
+// NotificationsPage is the data for the page that shows a user's notifications.
+type NotificationsPage struct {
+	// The email of the signed in user.
+	User string
+	// The user's notifications, newest first.
+	Notifications []models.Notification
+}
+
 // Renders responses as HTML pages.
 type HTMLRenderer struct {
 	// A mapping between a template path and the parsed template.
@@ -60,13 +456,35 @@ func NewHTMLRenderer() (*HTMLRenderer, error) {
 	renderer.templates = make(map[string]*template.Template)
 
 	// Add new templates here!
-	templates := []string{indexTemplatePath, taskTemplatePath, taskListTemplatePath}
+	templates := []string{
+		indexTemplatePath,
+		taskTemplatePath,
+		taskListTemplatePath,
+		nextActionsTemplatePath,
+		searchTemplatePath,
+		trashTemplatePath,
+		timesheetTemplatePath,
+		reportTemplatePath,
+		boardTemplatePath,
+		calendarTemplatePath,
+		templatesTemplatePath,
+		membersTemplatePath,
+		workspaceTemplatePath,
+		assignedTemplatePath,
+		notificationsTemplatePath,
+		webhooksTemplatePath,
+		inboundTemplatePath,
+		feedTemplatePath,
+		appPasswordsTemplatePath,
+		importTemplatePath,
+	}
 
 	for _, templatePath := range templates {
-		tmpl, err := template.ParseFS(templatesFS, templatePath, baseTemplatePath)
+		patterns := append([]string{templatePath, baseTemplatePath}, partialTemplatePaths...)
+		tmpl, err := template.New(path.Base(templatePath)).Funcs(templateFuncs).ParseFS(templatesFS, patterns...)
 
 		if err != nil {
-			return nil, fmt.Errorf("could not parse the templates at %q and %q: %v", templatePath, baseTemplatePath, err)
+			return nil, fmt.Errorf("could not parse the templates at %q: %v", patterns, err)
 		}
 
 		renderer.templates[templatePath] = tmpl
@@ -82,18 +500,497 @@ func (r *HTMLRenderer) RenderIndex(users []models.User) ([]byte, error) {
 	return r.renderHTMLTemplate(indexTemplatePath, users)
 }
 
-// Render the HTML page for a single task.
+// The data for the task template.
+type taskTemplateData struct {
+	models.TaskNode
+	User string
+	// The comments and activity for the task, oldest first.
+	Timeline    []timelineEntry
+	Attachments []models.Attachment
+	CanAttach   bool
+	TimeEntries []models.TimeEntry
+	// The total time spent on the task by every user.
+	TimeSpent time.Duration
+	Timer     *models.TimeEntry
+	// The time that running timers are counted up to.
+	Now time.Time
+	// The users that the task can be assigned to.
+	Assignable []assignableUser
+}
+
+// An assignableUser is a user that a task can be assigned to on the task page.
+type assignableUser struct {
+	Email string
+	// Whether the task is assigned to the user.
+	Assigned bool
+}
+
+// A timelineEntry is either a comment or an activity on the task page.
+type timelineEntry struct {
+	At       time.Time
+	Comment  *models.Comment
+	Activity *models.Activity
+	// Whether the signed in user can edit and delete the comment.
+	Editable bool
+}
+
+// Render the HTML page for a single task, the tree of its subtasks and a timeline of its comments and activity.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderTask(page TaskPage) ([]byte, error) {
+	now := time.Now()
+	tree := models.NewTaskTree(append([]models.Task{page.Task}, page.Subtasks...))
+	var timeline []timelineEntry
+
+	for i := range page.Activity {
+		timeline = append(timeline, timelineEntry{At: page.Activity[i].At, Activity: &page.Activity[i]})
+	}
+
+	for i := range page.Comments {
+		timeline = append(timeline, newCommentEntry(page.Comments[i], page.User))
+	}
+
+	// Activity comes first so that it is shown before comments made at the same time.
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.Before(timeline[j].At)
+	})
+
+	var assignable []assignableUser
+
+	for _, email := range page.Assignable {
+		assignable = append(assignable, assignableUser{email, slices.Contains(page.Task.Assignees, email)})
+	}
+
+	return r.renderHTMLTemplate(taskTemplatePath, taskTemplateData{
+		TaskNode:    tree[0],
+		User:        page.User,
+		Timeline:    timeline,
+		Attachments: page.Attachments,
+		CanAttach:   page.CanAttach,
+		TimeEntries: page.TimeEntries,
+		TimeSpent:   models.TotalDuration(page.TimeEntries, now),
+		Timer:       page.Timer,
+		Now:         now,
+		Assignable:  assignable,
+	})
+}
+
+// Render the HTML fragment for a comment on the task page.
 //
 // Returns an error if the template could not be found or rendered.
-func (r *HTMLRenderer) RenderTask(task models.Task) ([]byte, error) {
-	return r.renderHTMLTemplate(taskTemplatePath, task)
+func (r *HTMLRenderer) RenderComment(comment models.Comment, user string) ([]byte, error) {
+	return r.renderHTMLFragment(taskTemplatePath, commentTemplateName, newCommentEntry(comment, user))
+}
+
+func newCommentEntry(comment models.Comment, user string) timelineEntry {
+	return timelineEntry{At: comment.CreatedAt, Comment: &comment, Editable: user != "" && comment.Author == user}
+}
+
+// The data for the task list template.
+type taskListTemplateData struct {
+	TaskListPage
+	Tree []models.TaskNode
 }
 
-// Render the HTML page for a list of tasks.
+// Render the HTML page for a list of tasks, nesting subtasks under their parents.
 //
 // Returns an error if the template could not be found or rendered.
-func (r *HTMLRenderer) RenderTaskList(tasks []models.Task) ([]byte, error) {
-	return r.renderHTMLTemplate(taskListTemplatePath, tasks)
+func (r *HTMLRenderer) RenderTaskList(page TaskListPage) ([]byte, error) {
+	return r.renderHTMLTemplate(taskListTemplatePath, taskListTemplateData{page, models.NewTaskTree(page.Tasks)})
+}
+
+// The data for the preview of a quick-added task.
+type quickAddPreview struct {
+	Task    models.Task
+	Matches []quickadd.Match
+}
+
+// Render the HTML fragment showing the task that would be added from quick-add text and the words that were recognised.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderQuickAddPreview(result quickadd.Result) ([]byte, error) {
+	task := models.Task{
+		Description: result.Description,
+		Tags:        result.Tags,
+		Priority:    result.Priority,
+		Due:         result.Due,
+		Recurrence:  result.Recurrence,
+	}
+
+	return r.renderHTMLFragment(taskListTemplatePath, quickAddPreviewTemplateName, quickAddPreview{task, result.Matches})
+}
+
+// Render the HTML page for a list of tasks in the order they should be worked on.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderNextActions(tasks []models.Task) ([]byte, error) {
+	return r.renderHTMLTemplate(nextActionsTemplatePath, tasks)
+}
+
+// The data for the search page.
+type searchPage struct {
+	Query   string
+	Results []models.Task
+}
+
+// Render the HTML page for searching tasks, with the words in each result that match `query` highlighted.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderSearch(query string, results []models.Task) ([]byte, error) {
+	return r.renderHTMLTemplate(searchTemplatePath, searchPage{query, results})
+}
+
+// Render the HTML fragment containing the results for the search page.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderSearchResults(query string, results []models.Task) ([]byte, error) {
+	return r.renderHTMLFragment(searchTemplatePath, searchResultsTemplateName, searchPage{query, results})
+}
+
+// The data for the trash page.
+type trashPage struct {
+	User  string
+	Tasks []models.Task
+}
+
+// Render the HTML page for the trash, with controls for restoring or permanently deleting each task.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderTrash(user string, tasks []models.Task) ([]byte, error) {
+	return r.renderHTMLTemplate(trashTemplatePath, trashPage{user, tasks})
+}
+
+// The data for the timesheet template.
+type timesheetTemplateData struct {
+	TimesheetPage
+	Days []models.TimesheetDay
+	// The time spent on each task, in the order the tasks were first worked on.
+	TaskTotals []taskTotal
+	// The total time spent over every day.
+	Total time.Duration
+}
+
+// The time spent on a task in a timesheet.
+type taskTotal struct {
+	TaskID uint64
+	Total  time.Duration
+}
+
+// Render the HTML page for a timesheet, with the time entries grouped by the day they started.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderTimesheet(page TimesheetPage) ([]byte, error) {
+	var taskTotals []taskTotal
+
+	for _, entry := range page.Entries {
+		i := slices.IndexFunc(taskTotals, func(total taskTotal) bool { return total.TaskID == entry.TaskID })
+
+		if i == -1 {
+			i = len(taskTotals)
+			taskTotals = append(taskTotals, taskTotal{TaskID: entry.TaskID})
+		}
+
+		taskTotals[i].Total += entry.Duration(page.Now)
+	}
+
+	return r.renderHTMLTemplate(timesheetTemplatePath, timesheetTemplateData{
+		TimesheetPage: page,
+		Days:          models.GroupByDay(page.Entries),
+		TaskTotals:    taskTotals,
+		Total:         models.TotalDuration(page.Entries, page.Now),
+	})
+}
+
+// The data for the report template.
+type reportTemplateData struct {
+	ReportPage
+	// What the charts measure, either "estimate" or "tasks" when none of the tasks have been estimated.
+	Unit       string
+	Burndown   lineChart
+	Throughput barChart
+	// The average work completed per week.
+	Velocity float64
+}
+
+// Render the HTML page for a report, drawing the burndown and throughput charts as SVG images.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderReport(page ReportPage) ([]byte, error) {
+	remaining := make([]float64, len(page.Burndown))
+	labels := make([]string, len(page.Burndown))
+	completed := make([]float64, len(page.Throughput))
+	weeks := make([]string, len(page.Throughput))
+	unit := "tasks"
+
+	// Fall back to counting tasks so that the charts are still useful for people that do not estimate their tasks.
+	estimated := slices.ContainsFunc(page.Burndown, func(day report.Day) bool { return day.Remaining > 0 }) ||
+		slices.ContainsFunc(page.Throughput, func(week report.Week) bool { return week.Estimate > 0 })
+
+	if estimated {
+		unit = "estimate"
+	}
+
+	for i, day := range page.Burndown {
+		remaining[i] = float64(day.Open)
+
+		if estimated {
+			remaining[i] = day.Remaining
+		}
+
+		labels[i] = day.Date.Format("2 Jan")
+	}
+
+	for i, week := range page.Throughput {
+		completed[i] = float64(week.Completed)
+
+		if estimated {
+			completed[i] = week.Estimate
+		}
+
+		weeks[i] = week.Start.Format("2 Jan")
+	}
+
+	velocity := 0.0
+
+	if len(completed) > 0 {
+		for _, value := range completed {
+			velocity += value
+		}
+
+		velocity /= float64(len(completed))
+	}
+
+	return r.renderHTMLTemplate(reportTemplatePath, reportTemplateData{
+		ReportPage: page,
+		Unit:       unit,
+		Burndown:   newBurndownChart(remaining, labels),
+		Throughput: newBarChart(completed, weeks),
+		Velocity:   math.Round(velocity*10) / 10,
+	})
+}
+
+// The data for the board template.
+type boardTemplateData struct {
+	User    string
+	Columns []boardColumn
+}
+
+// A column on the board along with the tasks in it.
+type boardColumn struct {
+	models.Column
+	Cards []boardCard
+	// Whether the column holds as many tasks as its WIP limit allows.
+	Full bool
+}
+
+// A task on the board, along with the IDs of the columns either side of the task's column for moving the task
+// without dragging it.
+type boardCard struct {
+	models.Task
+	// The IDs of the columns to the left and right, or zero at the edges of the board.
+	Previous uint64
+	Next     uint64
+}
+
+// Render the HTML page for a board, placing each task in its column.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderBoard(page BoardPage) ([]byte, error) {
+	data := boardTemplateData{User: page.User, Columns: make([]boardColumn, len(page.Columns))}
+
+	for i, column := range page.Columns {
+		data.Columns[i].Column = column
+	}
+
+	for _, task := range page.Tasks {
+		i := slices.IndexFunc(page.Columns, func(column models.Column) bool {
+			return column.ID == models.ColumnOf(task, page.Columns)
+		})
+
+		if i == -1 {
+			continue
+		}
+
+		card := boardCard{Task: task}
+
+		if i > 0 {
+			card.Previous = page.Columns[i-1].ID
+		}
+
+		if i < len(page.Columns)-1 {
+			card.Next = page.Columns[i+1].ID
+		}
+
+		data.Columns[i].Cards = append(data.Columns[i].Cards, card)
+	}
+
+	for i, column := range data.Columns {
+		data.Columns[i].Full = column.WIPLimit > 0 && len(column.Cards) >= column.WIPLimit
+	}
+
+	return r.renderHTMLTemplate(boardTemplatePath, data)
+}
+
+// The data for the calendar template.
+type calendarTemplateData struct {
+	CalendarPage
+	// The name of the month or the range of days on the calendar.
+	Title string
+	// The dates in the months or weeks either side of the calendar.
+	Previous time.Time
+	Next     time.Time
+	Weeks    [][]calendarDay
+}
+
+// A day on the calendar, along with how it should be shown.
+type calendarDay struct {
+	calendar.Day
+	// Whether the day falls in the months before or after the month on the calendar.
+	Outside bool
+	Today   bool
+}
+
+// Render the HTML page for a calendar, with a row for each week.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderCalendar(page CalendarPage) ([]byte, error) {
+	data := calendarTemplateData{CalendarPage: page}
+
+	if page.View == "week" {
+		data.Previous = page.Date.AddDate(0, 0, -7)
+		data.Next = page.Date.AddDate(0, 0, 7)
+
+		if len(page.Days) > 0 {
+			data.Title = fmt.Sprintf("%s to %s", page.Days[0].Date.Format("2 Jan"), page.Days[len(page.Days)-1].Date.Format("2 Jan 2006"))
+		}
+	} else {
+		// Move from the first of the month so that, e.g., 31 January does not skip February.
+		first := time.Date(page.Date.Year(), page.Date.Month(), 1, 0, 0, 0, 0, page.Date.Location())
+		data.Previous = first.AddDate(0, -1, 0)
+		data.Next = first.AddDate(0, 1, 0)
+		data.Title = page.Date.Format("January 2006")
+	}
+
+	todayYear, todayMonth, todayDay := page.Today.Date()
+
+	for i, day := range page.Days {
+		if i%7 == 0 {
+			data.Weeks = append(data.Weeks, nil)
+		}
+
+		year, month, date := day.Date.Date()
+		data.Weeks[len(data.Weeks)-1] = append(data.Weeks[len(data.Weeks)-1], calendarDay{
+			Day:     day,
+			Outside: page.View != "week" && (year != page.Date.Year() || month != page.Date.Month()),
+			Today:   year == todayYear && month == todayMonth && date == todayDay,
+		})
+	}
+
+	return r.renderHTMLTemplate(calendarTemplatePath, data)
+}
+
+// Render the HTML page listing a user's templates.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderTemplates(page TemplatesPage) ([]byte, error) {
+	return r.renderHTMLTemplate(templatesTemplatePath, page)
+}
+
+// The data for the page that shows who a task list is shared with.
+type membersTemplateData struct {
+	MembersPage
+	Roles []models.Role
+}
+
+// Render the HTML page listing who a user's task list is shared with.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderMembers(page MembersPage) ([]byte, error) {
+	return r.renderHTMLTemplate(membersTemplatePath, membersTemplateData{page, models.Roles})
+}
+
+// Render the HTML page listing the open tasks assigned to a user.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderAssigned(page AssignedPage) ([]byte, error) {
+	return r.renderHTMLTemplate(assignedTemplatePath, page)
+}
+
+// Render the HTML page listing a user's notifications.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderNotifications(page NotificationsPage) ([]byte, error) {
+	return r.renderHTMLTemplate(notificationsTemplatePath, page)
+}
+
+// Render the HTML fragment for the link to the notifications page, showing the number of `unread` notifications.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderNotificationBell(unread int) ([]byte, error) {
+	return r.renderHTMLFragment(notificationsTemplatePath, notificationBellTemplateName, unread)
+}
+
+// The data for the page that shows the webhooks of a task list.
+type webhooksTemplateData struct {
+	WebhooksPage
+	Events []models.WebhookEvent
+}
+
+// Render the HTML page listing the webhooks of a user's task list and their deliveries.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderWebhooks(page WebhooksPage) ([]byte, error) {
+	return r.renderHTMLTemplate(webhooksTemplatePath, webhooksTemplateData{page, models.WebhookEvents})
+}
+
+// Render the HTML page showing the inbound URLs of a user's task list.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderInbound(page InboundPage) ([]byte, error) {
+	return r.renderHTMLTemplate(inboundTemplatePath, page)
+}
+
+// Render the HTML page showing the calendar feed of a user's task list.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderFeed(page FeedPage) ([]byte, error) {
+	return r.renderHTMLTemplate(feedTemplatePath, page)
+}
+
+// Render the HTML page showing the app passwords of the signed in user.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderAppPasswords(page AppPasswordsPage) ([]byte, error) {
+	return r.renderHTMLTemplate(appPasswordsTemplatePath, page)
+}
+
+// The data for the page that imports tasks into a user's task list.
+type importTemplateData struct {
+	ImportPage
+	Formats []importer.Format
+	Fields  []importer.Field
+	// How many tasks, including subtasks, are being previewed.
+	PreviewCount int
+}
+
+// Render the HTML page for importing tasks into a user's task list.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderImport(page ImportPage) ([]byte, error) {
+	return r.renderHTMLTemplate(importTemplatePath, importTemplateData{page, importer.Formats, importer.Fields, importer.Count(page.Preview)})
+}
+
+// The data for the page that shows the workspace a user belongs to.
+type workspaceTemplateData struct {
+	WorkspacePage
+	Roles []models.Role
+}
+
+// Render the HTML page showing the workspace a user belongs to along with forms for inviting and removing members.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) RenderWorkspace(page WorkspacePage) ([]byte, error) {
+	return r.renderHTMLTemplate(workspaceTemplatePath, workspaceTemplateData{page, models.Roles})
 }
 
 // Render data with the template at templatePath.
@@ -116,3 +1013,24 @@ func (r *HTMLRenderer) renderHTMLTemplate(templatePath string, data any) ([]byte
 
 	return body.Bytes(), nil
 }
+
+// Render data with the template called `name` that is defined in the template file at templatePath.
+//
+// Use this function to render part of a page, e.g., in response to a request from HTMX.
+//
+// Returns an error if the template could not be found or rendered.
+func (r *HTMLRenderer) renderHTMLFragment(templatePath string, name string, data any) ([]byte, error) {
+	tmpl := r.templates[templatePath]
+
+	if tmpl == nil {
+		return nil, fmt.Errorf("could not find template for %q, did you parse it in the constructor?", templatePath)
+	}
+
+	body := new(bytes.Buffer)
+
+	if err := tmpl.ExecuteTemplate(body, name, data); err != nil {
+		return nil, fmt.Errorf("could not render the template %q in %q with data %q: %v", name, templatePath, data, err)
+	}
+
+	return body.Bytes(), nil
+}
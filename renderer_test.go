@@ -7,9 +7,14 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	yatta "github.com/AnthonyDickson/yatta"
+	"github.com/AnthonyDickson/yatta/calendar"
+	"github.com/AnthonyDickson/yatta/importer"
 	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/quickadd"
+	"github.com/AnthonyDickson/yatta/report"
 	"github.com/AnthonyDickson/yatta/yattatest"
 	"golang.org/x/net/html"
 )
@@ -38,11 +43,81 @@ func TestRenderer_TasksList(t *testing.T) {
 			{ID: 2, Description: "debug tests 🙃"},
 		}
 
-		htmlString, err := renderer.RenderTaskList(want)
+		htmlString, err := renderer.RenderTaskList(yatta.TaskListPage{User: "Alice", Tasks: want})
 
 		yattatest.AssertNoError(t, err)
 		assertHTMLContainsTasks(t, string(htmlString), want, "li")
 	})
+
+	t.Run("live task lists listen for changes", func(t *testing.T) {
+		tasks := []models.Task{{ID: 1, Description: "deploy"}}
+		page := yatta.TaskListPage{User: "alice@example.com", Tasks: tasks, Live: true, Path: "/users/alice@example.com/tasks?q=deploy"}
+
+		htmlString, err := renderer.RenderTaskList(page)
+		yattatest.AssertNoError(t, err)
+		assertHTMLContainsTasks(t, string(htmlString), tasks, "li")
+
+		for _, want := range []string{
+			`htmx-ext-sse`,
+			`sse-connect="/users/alice@example.com/events"`,
+			`hx-get="/users/alice@example.com/tasks?q=deploy"`,
+			`hx-trigger="sse:task-added, sse:task-changed, sse:task-removed"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		page.Live = false
+		htmlString, err = renderer.RenderTaskList(page)
+		yattatest.AssertNoError(t, err)
+
+		if strings.Contains(string(htmlString), "sse") {
+			t.Errorf("got SSE wiring in %s, want none", htmlString)
+		}
+	})
+}
+
+func TestRenderer_SmartLists(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders smart lists alongside tasks", func(t *testing.T) {
+		page := yatta.TaskListPage{
+			User:  "Alice",
+			Title: "Work",
+			Tasks: []models.Task{{ID: 1, Description: "deploy", Tags: []string{"work"}}},
+			SavedSearches: []models.SavedSearch{
+				{ID: 1, Name: "Work", Query: "tag:work"},
+				{ID: 2, Name: "Overdue", Query: "due<today"},
+			},
+		}
+
+		htmlString, err := renderer.RenderTaskList(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		var links []string
+
+		for _, nav := range findElements(doc, "nav") {
+			for _, link := range findElements(nav, "a") {
+				for _, attribute := range link.Attr {
+					if attribute.Key == "href" {
+						links = append(links, attribute.Val)
+					}
+				}
+			}
+		}
+
+		for _, want := range []string{"/users/Alice/searches/1", "/users/Alice/searches/2"} {
+			if !slices.Contains(links, want) {
+				t.Errorf("could not find link to smart list %q in %q", want, links)
+			}
+		}
+
+		if got := extractTextNodesFromHTML(t, doc, "h2"); !slices.Contains(got, "Work") {
+			t.Errorf("got headings %q, want the title %q", got, "Work")
+		}
+	})
 }
 
 func TestRenderer_Task(t *testing.T) {
@@ -53,11 +128,281 @@ func TestRenderer_Task(t *testing.T) {
 			{ID: 0, Description: "eat"},
 		}
 
-		htmlString, err := renderer.RenderTask(want[0])
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: want[0]})
 
 		yattatest.AssertNoError(t, err)
 		assertHTMLContainsTasks(t, string(htmlString), want, "p")
 	})
+
+	t.Run("renders notes as sanitised Markdown", func(t *testing.T) {
+		task := models.Task{
+			ID:          1,
+			Description: "deploy",
+			Notes:       "- [x] run **tests**\n- [ ] tag release\n\n<script>alert(1)</script>\n\n[docs](javascript:alert(1)) https://example.com",
+		}
+
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task})
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "strong")); got != 1 {
+			t.Errorf("got %d strong elements, want 1 from the Markdown notes", got)
+		}
+
+		if got := len(findElements(doc, "input")); got != 2 {
+			t.Errorf("got %d checkboxes, want 2 from the checklist in the notes", got)
+		}
+
+		// The only script is the one that loads HTMX in the base template.
+		for _, script := range findElements(doc, "script") {
+			if script.FirstChild != nil {
+				t.Errorf("got inline script %q, want scripts in the notes to be removed", script.FirstChild.Data)
+			}
+		}
+
+		for _, link := range findElements(doc, "a") {
+			for _, attribute := range link.Attr {
+				if attribute.Key == "href" && strings.HasPrefix(attribute.Val, "javascript:") {
+					t.Errorf("got link to %q, want javascript links to be removed", attribute.Val)
+				}
+			}
+		}
+
+		if !strings.Contains(string(htmlString), `href="https://example.com"`) {
+			t.Errorf("could not find link to the URL in the notes in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Timeline(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := models.Task{ID: 1, Description: "deploy"}
+	page := yatta.TaskPage{
+		Task: task,
+		Comments: []models.Comment{
+			{ID: 1, TaskID: 1, Author: "alice@example.com", Body: "first <script>alert(1)</script>", CreatedAt: start.Add(time.Minute)},
+			{ID: 2, TaskID: 1, Author: "bob@example.com", Body: "second", CreatedAt: start.Add(3 * time.Minute)},
+		},
+		Activity: []models.Activity{
+			{TaskID: 1, Kind: models.ActivityCreated, At: start},
+			{TaskID: 1, Kind: models.ActivityCompleted, At: start.Add(2 * time.Minute)},
+		},
+		User: "alice@example.com",
+	}
+
+	t.Run("renders comments and activity in chronological order", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		var got []string
+
+		for _, item := range findElements(doc, "li") {
+			for _, attribute := range item.Attr {
+				if attribute.Key == "class" {
+					got = append(got, attribute.Val)
+				}
+			}
+		}
+
+		want := []string{"activity", "comment", "activity", "comment"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got timeline %q, want %q", got, want)
+		}
+
+		if strings.Contains(string(htmlString), "<script>alert(1)") {
+			t.Errorf("got unescaped script in %s", htmlString)
+		}
+	})
+
+	t.Run("only the author can edit and delete their comments", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		var deleteButtons []string
+
+		for _, button := range findElements(doc, "button") {
+			for _, attribute := range button.Attr {
+				if attribute.Key == "hx-delete" {
+					deleteButtons = append(deleteButtons, attribute.Val)
+				}
+			}
+		}
+
+		if want := []string{"/comments/1"}; !reflect.DeepEqual(deleteButtons, want) {
+			t.Errorf("got delete buttons for %q, want %q", deleteButtons, want)
+		}
+	})
+
+	t.Run("comment form is only shown to signed in users", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task})
+		yattatest.AssertNoError(t, err)
+
+		if got := len(findElements(mustParseHTML(t, string(htmlString)), "form")); got != 0 {
+			t.Errorf("got %d forms, want none when nobody is signed in", got)
+		}
+
+		htmlString, err = renderer.RenderTask(yatta.TaskPage{Task: task, User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `hx-post="/tasks/1/comments"`) {
+			t.Errorf("could not find the comment form in %s", htmlString)
+		}
+	})
+
+	t.Run("renders a single comment", func(t *testing.T) {
+		htmlString, err := renderer.RenderComment(page.Comments[1], "bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if !strings.HasPrefix(strings.TrimSpace(string(htmlString)), `<li class="comment" id="comment-2">`) {
+			t.Errorf("got %s, want a list item for comment 2", htmlString)
+		}
+
+		if !strings.Contains(string(htmlString), `hx-delete="/comments/2"`) {
+			t.Errorf("could not find the delete button for the author in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Subtasks(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	tasks := []models.Task{
+		{ID: 1, Description: "prepare release"},
+		{ID: 2, Description: "write changelog", ParentID: 1, Done: true},
+		{ID: 3, Description: "bump version", ParentID: 1},
+		{ID: 4, Description: "update go.mod", ParentID: 3},
+	}
+
+	t.Run("renders subtasks nested in the task list", func(t *testing.T) {
+		htmlString, err := renderer.RenderTaskList(yatta.TaskListPage{User: "Alice", Tasks: tasks})
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		assertNestedList(t, doc, "prepare release", []string{"write changelog", "bump version", "update go.mod"})
+		assertProgress(t, doc, []string{"1/3", "0/1"})
+	})
+
+	t.Run("renders subtasks on the task page", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: tasks[0], Subtasks: tasks[1:]})
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		assertHTMLContainsTasks(t, string(htmlString), tasks[:1], "p")
+		assertProgress(t, doc, []string{"1/3", "0/1"})
+	})
+}
+
+func TestRenderer_NextActions(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders next actions in order", func(t *testing.T) {
+		want := []models.Task{
+			{ID: 4, Description: "write proposal"},
+			{ID: 3, Description: "get approval", BlockedBy: []uint64{4}, Blocked: true},
+			{ID: 1, Description: "deploy", BlockedBy: []uint64{3}, Blocked: true},
+		}
+
+		htmlString, err := renderer.RenderNextActions(want)
+
+		yattatest.AssertNoError(t, err)
+		assertHTMLContainsTasks(t, string(htmlString), want, "li")
+	})
+}
+
+func TestRenderer_Trash(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders restore and delete buttons for each task", func(t *testing.T) {
+		deletedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		tasks := []models.Task{
+			{ID: 1, Description: "prepare release", DeletedAt: &deletedAt},
+			{ID: 2, Description: "bump version", ParentID: 1, DeletedAt: &deletedAt},
+		}
+
+		htmlString, err := renderer.RenderTrash("Alice", tasks)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		var actions []string
+
+		for _, button := range findElements(doc, "button") {
+			for _, attribute := range button.Attr {
+				if attribute.Key == "hx-post" || attribute.Key == "hx-delete" {
+					actions = append(actions, attribute.Key+" "+attribute.Val)
+				}
+			}
+		}
+
+		want := []string{
+			"hx-post /trash/1/restore", "hx-delete /trash/1",
+			"hx-post /trash/2/restore", "hx-delete /trash/2",
+		}
+
+		if !reflect.DeepEqual(actions, want) {
+			t.Errorf("got actions %q, want %q", actions, want)
+		}
+	})
+
+	t.Run("renders message for empty trash", func(t *testing.T) {
+		htmlString, err := renderer.RenderTrash("Alice", []models.Task{})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "The trash is empty.") {
+			t.Errorf("could not find empty trash message in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Search(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	results := []models.Task{
+		{ID: 1, Description: "Deploy the website"},
+		{ID: 4, Description: "Deployed <script>alert(1)</script>"},
+	}
+
+	t.Run("renders search page with highlighted results", func(t *testing.T) {
+		htmlString, err := renderer.RenderSearch("deploy", results)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		assertElementTexts(t, doc, "li", []string{results[0].Description, results[1].Description})
+
+		got := extractTextNodesFromHTML(t, doc, "mark")
+		want := []string{"Deploy", "Deployed"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got highlighted words %q, want %q", got, want)
+		}
+
+		if len(findElements(doc, "script")) != 1 {
+			t.Errorf("got %d script elements, want just the HTMX script", len(findElements(doc, "script")))
+		}
+	})
+
+	t.Run("renders just the results", func(t *testing.T) {
+		htmlString, err := renderer.RenderSearchResults("deploy", results)
+		yattatest.AssertNoError(t, err)
+
+		if strings.Contains(string(htmlString), "<html") {
+			t.Errorf("got a whole page, want just the search results: %s", htmlString)
+		}
+
+		doc := mustParseHTML(t, string(htmlString))
+		assertElementTexts(t, doc, "li", []string{results[0].Description, results[1].Description})
+	})
+
+	t.Run("reports when nothing matches", func(t *testing.T) {
+		htmlString, err := renderer.RenderSearchResults("groceries", nil)
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "No tasks match") {
+			t.Errorf("got %s, want message that no tasks match", htmlString)
+		}
+	})
 }
 
 func mustCreateRenderer(t *testing.T) *yatta.HTMLRenderer {
@@ -145,3 +490,971 @@ func extractTextNodesFromHTML(t *testing.T, htmlFragment *html.Node, containerTa
 
 	return tasks
 }
+
+func mustParseHTML(t *testing.T, htmlString string) *html.Node {
+	t.Helper()
+
+	doc, err := html.Parse(strings.NewReader(htmlString))
+
+	if err != nil {
+		t.Fatalf("an error occurred while parsing the HTML string: %v", err)
+	}
+
+	return doc
+}
+
+// Assert that the list item containing the text `parent` contains the texts `children` in a nested list.
+func assertNestedList(t *testing.T, doc *html.Node, parent string, children []string) {
+	t.Helper()
+
+	for _, item := range findElements(doc, "li") {
+		texts := extractTextNodesFromHTML(t, item, "a")
+
+		if len(texts) == 0 || texts[0] != parent {
+			continue
+		}
+
+		nested := findElements(item, "ul")
+
+		if len(nested) == 0 {
+			t.Fatalf("list item for %q has no nested list", parent)
+		}
+
+		got := extractTextNodesFromHTML(t, nested[0], "a")
+
+		if !reflect.DeepEqual(got, children) {
+			t.Errorf("got nested tasks %q under %q, want %q", got, parent, children)
+		}
+
+		return
+	}
+
+	t.Errorf("could not find list item for %q", parent)
+}
+
+// Assert that the document contains progress bars with the values and maximums in `want`, formatted as "value/max".
+func assertProgress(t *testing.T, doc *html.Node, want []string) {
+	t.Helper()
+
+	var got []string
+
+	for _, progress := range findElements(doc, "progress") {
+		attributes := make(map[string]string)
+
+		for _, attribute := range progress.Attr {
+			attributes[attribute.Key] = attribute.Val
+		}
+
+		got = append(got, fmt.Sprintf("%s/%s", attributes["value"], attributes["max"]))
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got progress %q, want %q", got, want)
+	}
+}
+
+func findElements(node *html.Node, tag string) []*html.Node {
+	var elements []*html.Node
+
+	if node.Type == html.ElementNode && node.Data == tag {
+		elements = append(elements, node)
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		elements = append(elements, findElements(child, tag)...)
+	}
+
+	return elements
+}
+
+// Assert that the text content of each `tag` element in the document matches `want`.
+func assertElementTexts(t *testing.T, doc *html.Node, tag string, want []string) {
+	t.Helper()
+
+	var got []string
+
+	for _, element := range findElements(doc, tag) {
+		got = append(got, strings.Join(extractTextNodesFromHTML(t, element, tag), ""))
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s texts %q, want %q", tag, got, want)
+	}
+}
+
+func TestRenderer_Attachments(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	task := models.Task{ID: 1, Description: "fix layout"}
+
+	t.Run("lists attachments with their sizes", func(t *testing.T) {
+		attachments := []models.Attachment{
+			{ID: 1, TaskID: 1, Name: "notes.txt", Size: 512},
+			{ID: 2, TaskID: 1, Name: "screenshot.png", Size: 1_500_000},
+		}
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task, Attachments: attachments, CanAttach: true})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			`<a href="/attachments/1">notes.txt</a> <span class="size">512 B</span>`,
+			`<a href="/attachments/2">screenshot.png</a> <span class="size">1.5 MB</span>`,
+			`hx-post="/tasks/1/attachments"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %s in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("hides the upload form when attachments are disabled", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task})
+		yattatest.AssertNoError(t, err)
+
+		if strings.Contains(string(htmlString), "Attachments") {
+			t.Errorf("got attachments section in %s, want none when attachments are disabled", htmlString)
+		}
+	})
+}
+
+func TestRenderer_TimeTracking(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	laterEnd := start.Add(26 * time.Hour)
+	entries := []models.TimeEntry{
+		{ID: 1, TaskID: 1, User: "alice@example.com", Start: start, End: &end, Note: "release notes"},
+		{ID: 2, TaskID: 2, User: "alice@example.com", Start: start.Add(2 * time.Hour), End: &laterEnd},
+		{ID: 3, TaskID: 1, User: "alice@example.com", Start: start.Add(25 * time.Hour), End: &laterEnd},
+	}
+
+	t.Run("shows the time spent on a task", func(t *testing.T) {
+		task := models.Task{ID: 1, Description: "deploy"}
+		page := yatta.TaskPage{Task: task, TimeEntries: []models.TimeEntry{entries[0], entries[2]}, User: "bob@example.com"}
+		htmlString, err := renderer.RenderTask(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{"2h 30m spent", `hx-post="/tasks/1/timer"`, `hx-post="/tasks/1/time"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %s in %s", want, htmlString)
+			}
+		}
+
+		// Only Alice can delete the time she spent.
+		if strings.Contains(string(htmlString), `hx-delete="/time/`) {
+			t.Errorf("got a button to delete Alice's time entries for Bob in %s", htmlString)
+		}
+	})
+
+	t.Run("shows the running timer", func(t *testing.T) {
+		task := models.Task{ID: 1, Description: "deploy"}
+		timer := models.TimeEntry{ID: 4, TaskID: 2, User: "alice@example.com", Start: time.Now()}
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task, User: "alice@example.com", Timer: &timer})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `<a href="/tasks/2">#2</a>`) || !strings.Contains(string(htmlString), `hx-post="/timer/stop"`) {
+			t.Errorf("could not find the running timer on task 2 in %s", htmlString)
+		}
+	})
+
+	t.Run("renders timesheets with daily and per-task totals", func(t *testing.T) {
+		page := yatta.TimesheetPage{
+			User:    "alice@example.com",
+			From:    start,
+			To:      start.AddDate(0, 0, 6),
+			Entries: entries,
+			Tasks:   map[uint64]models.Task{1: {ID: 1, Description: "deploy"}},
+			Now:     laterEnd,
+		}
+		htmlString, err := renderer.RenderTimesheet(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+		var totals []string
+
+		for _, row := range findElements(doc, "tr") {
+			var cells []string
+
+			for _, cell := range findElements(row, "th") {
+				cells = append(cells, strings.Join(extractTextNodesFromHTML(t, cell, "th"), ""))
+			}
+
+			// Rows of totals have a heading that spans the task and start columns.
+			if len(cells) == 3 {
+				totals = append(totals, cells[0]+": "+cells[1])
+			}
+		}
+
+		want := []string{"Monday 3 Mar 2025: 25h 30m", "Tuesday 4 Mar 2025: 1h 0m", "Total: 26h 30m"}
+
+		if !reflect.DeepEqual(totals, want) {
+			t.Errorf("got totals %q, want %q", totals, want)
+		}
+
+		for _, want := range []string{
+			`<a href="/tasks/1">deploy</a>: 2h 30m`,
+			`<span class="deleted">Deleted task</span>: 24h 0m`,
+			`href="/timesheet.csv?from=2025-03-03&to=2025-03-09"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %s in %s", want, htmlString)
+			}
+		}
+	})
+}
+
+func TestRenderer_Report(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	page := yatta.ReportPage{
+		User: "Alice",
+		From: monday,
+		To:   monday.AddDate(0, 0, 2),
+		Burndown: []report.Day{
+			{Date: monday, Remaining: 8, Open: 2},
+			{Date: monday.AddDate(0, 0, 1), Remaining: 4, Open: 1},
+			{Date: monday.AddDate(0, 0, 2), Remaining: 0, Open: 0},
+		},
+		Throughput: []report.Week{{Start: monday, Completed: 2, Estimate: 8}},
+	}
+
+	t.Run("draws charts as SVG", func(t *testing.T) {
+		htmlString, err := renderer.RenderReport(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "svg")); got != 2 {
+			t.Fatalf("got %d SVG images, want 2 in %s", got, htmlString)
+		}
+
+		var points []string
+
+		for _, line := range findElements(doc, "polyline") {
+			for _, attribute := range line.Attr {
+				if attribute.Key == "points" {
+					points = append(points, attribute.Val)
+				}
+			}
+		}
+
+		// The plot spans from (40, 20) at the top left to (580, 200) at the bottom right.
+		want := []string{"40,20 580,200", "40,20 310,110 580,200"}
+
+		if !reflect.DeepEqual(points, want) {
+			t.Errorf("got lines %q, want %q", points, want)
+		}
+
+		if got := len(findElements(doc, "rect")); got != 1 {
+			t.Errorf("got %d bars, want 1", got)
+		}
+
+		if !strings.Contains(string(htmlString), "8 per week on average") {
+			t.Errorf("could not find the velocity in %s", htmlString)
+		}
+	})
+
+	t.Run("counts tasks when nothing is estimated", func(t *testing.T) {
+		unestimated := page
+		unestimated.Burndown = []report.Day{{Date: monday, Open: 2}, {Date: monday.AddDate(0, 0, 1), Open: 1}}
+		unestimated.Throughput = []report.Week{{Start: monday, Completed: 1}}
+
+		htmlString, err := renderer.RenderReport(unestimated)
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "Open tasks left") || !strings.Contains(string(htmlString), "1 per week on average") {
+			t.Errorf("got report %s, want charts of task counts", htmlString)
+		}
+	})
+
+	t.Run("shows estimates on tasks", func(t *testing.T) {
+		htmlString, err := renderer.RenderTaskList(yatta.TaskListPage{User: "Alice", Tasks: []models.Task{{ID: 1, Description: "build", Estimate: 2.5}}})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `<span class="estimate" title="Estimate">~2.5</span>`) {
+			t.Errorf("could not find the estimate in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Board(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	page := yatta.BoardPage{
+		User: "Alice",
+		Columns: []models.Column{
+			{ID: 1, Name: "Backlog"},
+			{ID: 2, Name: "Doing", WIPLimit: 1},
+			{ID: 3, Name: "Done", Done: true},
+		},
+		Tasks: []models.Task{
+			{ID: 1, Description: "deploy"},
+			{ID: 2, Description: "build", Status: 2},
+			{ID: 3, Description: "design", Status: 2, Done: true},
+		},
+	}
+
+	htmlString, err := renderer.RenderBoard(page)
+	yattatest.AssertNoError(t, err)
+
+	doc := mustParseHTML(t, string(htmlString))
+	var cards [][]string
+
+	for _, column := range findElements(doc, "section") {
+		var descriptions []string
+
+		for _, link := range findElements(column, "a") {
+			descriptions = append(descriptions, extractTextNodesFromHTML(t, link, "a")...)
+		}
+
+		cards = append(cards, descriptions)
+	}
+
+	want := [][]string{{"deploy"}, {"build"}, {"design"}}
+
+	if !reflect.DeepEqual(cards, want) {
+		t.Errorf("got cards %q, want %q", cards, want)
+	}
+
+	if !strings.Contains(string(htmlString), `<section class="column full"`) {
+		t.Errorf("want the column at its WIP limit to be marked as full in %s", htmlString)
+	}
+
+	if !strings.Contains(string(htmlString), `hx-post="/users/Alice/board/2" hx-vals='{"task": "1"}'`) {
+		t.Errorf("could not find the button to move a task to the next column in %s", htmlString)
+	}
+}
+
+func TestRenderer_Calendar(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	due := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: 1, Description: "standup", Due: &due, Recurrence: &models.Recurrence{Frequency: models.Weekly}},
+		{ID: 2, Description: "release", Due: &due},
+	}
+	from, to := calendar.Month(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	page := yatta.CalendarPage{
+		User:  "Alice",
+		View:  "month",
+		Date:  time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Today: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+		Days:  calendar.Days(tasks, from, to),
+	}
+
+	t.Run("show tasks on their due dates", func(t *testing.T) {
+		htmlString, err := renderer.RenderCalendar(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "tr")); got != 7 {
+			t.Errorf("got %d rows, want a header and 6 weeks", got)
+		}
+
+		var outside, today, repeats, draggable int
+
+		for _, cell := range findElements(doc, "td") {
+			for _, attribute := range cell.Attr {
+				if attribute.Key == "class" {
+					outside += strings.Count(attribute.Val, "outside")
+					today += strings.Count(attribute.Val, "today")
+				}
+			}
+		}
+
+		for _, entry := range findElements(doc, "li") {
+			for _, attribute := range entry.Attr {
+				if attribute.Key == "class" && strings.Contains(attribute.Val, "repeat") {
+					repeats++
+				}
+
+				if attribute.Key == "draggable" {
+					draggable++
+				}
+			}
+		}
+
+		// The days from 24 February to 28 February and from 1 April to 6 April are outside March.
+		if outside != 11 || today != 1 {
+			t.Errorf("got %d days outside the month and %d days marked as today, want 11 and 1", outside, today)
+		}
+
+		// The standup repeats on the 10th, 17th, 24th and 31st of March.
+		if repeats != 4 || draggable != 2 {
+			t.Errorf("got %d repeats and %d draggable tasks, want 4 and 2", repeats, draggable)
+		}
+
+		for _, want := range []string{"March 2025", "date=2025-02-01", "date=2025-04-01", `hx-post="/users/Alice/calendar/2025-03-03"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("keep the time zone in links", func(t *testing.T) {
+		week := page
+		week.View = "week"
+		week.TimeZone = "Pacific/Auckland"
+		week.Days = page.Days[7:14]
+
+		htmlString, err := renderer.RenderCalendar(week)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{"3 Mar to 9 Mar 2025", "date=2025-02-22", "tz=Pacific%2fAuckland", `hx-post="/users/Alice/calendar/2025-03-03?tz=Pacific%2FAuckland"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+}
+
+func TestRenderer_Snooze(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	task := models.Task{ID: 1, Description: "deploy"}
+
+	t.Run("offer to snooze open tasks", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: task, User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{`hx-vals='{"until": "tomorrow"}'`, `hx-vals='{"until": "next-week"}'`, `<input type="date" name="until"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("show when snoozed tasks show up again", func(t *testing.T) {
+		snoozed := task
+		until := time.Now().AddDate(1, 0, 0)
+		snoozed.HiddenUntil = &until
+
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: snoozed, User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), until.Format("2 Jan 2006")) || !strings.Contains(string(htmlString), `hx-delete="/tasks/1/snooze"`) {
+			t.Errorf("got %s, want the date the task is hidden until and a button to show it now", htmlString)
+		}
+	})
+}
+
+func TestRenderer_QuickAdd(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("task list has a quick-add form", func(t *testing.T) {
+		htmlString, err := renderer.RenderTaskList(yatta.TaskListPage{User: "Alice"})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{`hx-post="/users/Alice/tasks"`, `name="task"`, `hx-get="/users/Alice/tasks/preview"`, `id="quick-add-preview"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("preview shows the parsed task", func(t *testing.T) {
+		now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+		result := quickadd.Parse("Pay rent tomorrow #home !high every month", now)
+
+		htmlString, err := renderer.RenderQuickAddPreview(result)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got, want := extractTextNodesFromHTML(t, doc, "mark"), []string{"tomorrow", "#home", "!high", "every month"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got recognised words %q, want %q", got, want)
+		}
+
+		for _, want := range []string{"Pay rent", "due 13 Mar 2025", "#home", "!3", "every month"} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("preview is empty without a description", func(t *testing.T) {
+		htmlString, err := renderer.RenderQuickAddPreview(quickadd.Result{})
+		yattatest.AssertNoError(t, err)
+
+		if got := len(findElements(mustParseHTML(t, string(htmlString)), "p")); got != 0 {
+			t.Errorf("got %d paragraphs in %s, want an empty preview", got, htmlString)
+		}
+	})
+}
+
+func TestRenderer_Templates(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders templates with a form for each placeholder", func(t *testing.T) {
+		before, after := -2, 1
+		page := yatta.TemplatesPage{
+			User:  "Alice",
+			Today: time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC),
+			Templates: []models.Template{{ID: 1, Name: "Release", Task: models.TemplateTask{
+				Description: "release {{version}}",
+				Subtasks: []models.TemplateTask{
+					{Description: "write changelog", DueOffset: &before, Tags: []string{"docs"}},
+					{Description: "tell {{team}}", DueOffset: &after},
+				},
+			}}},
+		}
+
+		htmlString, err := renderer.RenderTemplates(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "li")); got != 3 {
+			t.Errorf("got %d tasks, want 3", got)
+		}
+
+		for _, want := range []string{
+			"release {{version}}",
+			"due 2 days before",
+			"due 1 day after",
+			`hx-post="/users/Alice/templates/1"`,
+			`name="value.version"`,
+			`name="value.team"`,
+			`value="2025-03-12"`,
+			`hx-delete="/users/Alice/templates/1"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("signed in users can save tasks as templates", func(t *testing.T) {
+		htmlString, err := renderer.RenderTask(yatta.TaskPage{Task: models.Task{ID: 1, Description: "deploy"}, User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `hx-post="/tasks/1/template"`) {
+			t.Errorf("could not find the form for saving the task as a template in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Sharing(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders the members of a list", func(t *testing.T) {
+		page := yatta.MembersPage{
+			User:    "alice@example.com",
+			Members: []models.Member{{Email: "bob@example.com", Role: models.RoleEditor}},
+		}
+
+		htmlString, err := renderer.RenderMembers(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "tr")); got != 2 {
+			t.Errorf("got %d rows, want a header and 1 member", got)
+		}
+
+		for _, want := range []string{
+			"bob@example.com",
+			`<option value="editor" selected>`,
+			`hx-delete="/users/alice@example.com/members/bob@example.com"`,
+			`hx-post="/users/alice@example.com/members"`,
+			`name="email"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("lists that are not shared say so", func(t *testing.T) {
+		htmlString, err := renderer.RenderMembers(yatta.MembersPage{User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "not shared with anyone") {
+			t.Errorf("could not find the note that the list is not shared in %s", htmlString)
+		}
+	})
+
+	t.Run("links to the lists shared with the user", func(t *testing.T) {
+		page := yatta.TaskListPage{
+			User:        "bob@example.com",
+			SharedLists: []models.SharedList{{Owner: "alice@example.com", Role: models.RoleViewer}},
+		}
+
+		htmlString, err := renderer.RenderTaskList(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{`href="/users/alice@example.com/tasks"`, `href="/users/bob@example.com/members"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+}
+
+func TestRenderer_Workspace(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	workspace := &models.Workspace{
+		ID:          1,
+		Name:        "Acme",
+		Members:     []models.Member{{Email: "alice@example.com", Role: models.RoleAdmin}, {Email: "bob@example.com", Role: models.RoleEditor}},
+		Invitations: []models.Member{{Email: "carol@example.com", Role: models.RoleViewer}},
+	}
+
+	t.Run("admins can invite and remove members", func(t *testing.T) {
+		htmlString, err := renderer.RenderWorkspace(yatta.WorkspacePage{User: "alice@example.com", Workspace: workspace})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			"Acme",
+			`hx-delete="/workspaces/1/members/bob@example.com"`,
+			`hx-delete="/workspaces/1/invitations/carol@example.com"`,
+			`hx-post="/workspaces/1/invitations"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("other members can only leave", func(t *testing.T) {
+		htmlString, err := renderer.RenderWorkspace(yatta.WorkspacePage{User: "bob@example.com", Workspace: workspace})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `hx-delete="/workspaces/1/members/bob@example.com"`) {
+			t.Errorf("could not find the button for leaving the workspace in %s", htmlString)
+		}
+
+		for _, unwanted := range []string{"/workspaces/1/members/alice@example.com", "/workspaces/1/invitations", "carol@example.com"} {
+			if strings.Contains(string(htmlString), unwanted) {
+				t.Errorf("found %q in %s, want only admins to see it", unwanted, htmlString)
+			}
+		}
+	})
+
+	t.Run("users outside of a workspace can join or create one", func(t *testing.T) {
+		page := yatta.WorkspacePage{User: "carol@example.com", Invitations: []models.Workspace{*workspace}}
+		htmlString, err := renderer.RenderWorkspace(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			`hx-post="/workspaces/1/members"`,
+			`hx-delete="/workspaces/1/invitations/carol@example.com"`,
+			`hx-post="/workspaces"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+}
+
+func TestRenderer_Assignment(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("task page shows the assignees and a form for changing them", func(t *testing.T) {
+		task := models.Task{ID: 1, Description: "deploy", Assignees: []string{"bob@example.com"}}
+		page := yatta.TaskPage{Task: task, Assignable: []string{"alice@example.com", "bob@example.com"}}
+
+		htmlString, err := renderer.RenderTask(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			"@bob@example.com",
+			`hx-post="/tasks/1/assignees"`,
+			`<option value="alice@example.com">`,
+			`<option value="bob@example.com" selected>`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("assigned to me lists the tasks", func(t *testing.T) {
+		page := yatta.AssignedPage{User: "bob@example.com", Tasks: []models.Task{{ID: 3, Description: "review"}}}
+
+		htmlString, err := renderer.RenderAssigned(page)
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), `<a href="/tasks/3">review</a>`) {
+			t.Errorf("could not find the link to the task in %s", htmlString)
+		}
+	})
+
+	t.Run("notifications link to their task", func(t *testing.T) {
+		notifications := []models.Notification{
+			{ID: 2, Kind: models.NotificationAssigned, TaskID: 3, Task: "review", Actor: "carol@example.com"},
+			{ID: 1, Kind: models.NotificationAssigned, TaskID: 2, Task: "test", Read: true},
+		}
+
+		htmlString, err := renderer.RenderNotifications(yatta.NotificationsPage{User: "bob@example.com", Notifications: notifications})
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "li")); got != 2 {
+			t.Errorf("got %d notifications, want 2", got)
+		}
+
+		for _, want := range []string{
+			`<li class="unread">`,
+			`carol@example.com assigned you to &#34;review&#34;`,
+			`Someone assigned you to &#34;test&#34;`,
+			`hx-post="/notifications/read"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("signed in users can find their assigned tasks and notifications", func(t *testing.T) {
+		page := yatta.TaskListPage{User: "alice@example.com", SignedIn: "bob@example.com"}
+
+		htmlString, err := renderer.RenderTaskList(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{`href="/assigned"`, `hx-get="/notifications/bell"`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("the notification bell shows the number of unread notifications", func(t *testing.T) {
+		htmlString, err := renderer.RenderNotificationBell(3)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{`href="/notifications"`, `<span class="unread-count">3</span>`} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		htmlString, err = renderer.RenderNotificationBell(0)
+		yattatest.AssertNoError(t, err)
+
+		if strings.Contains(string(htmlString), "unread-count") {
+			t.Errorf("got an unread count in %s, want none", htmlString)
+		}
+	})
+
+	t.Run("mentions link to the comment they were made in", func(t *testing.T) {
+		notifications := []models.Notification{
+			{ID: 2, Kind: models.NotificationMentioned, TaskID: 3, Task: "review", Actor: "carol@example.com", CommentID: 7},
+			{ID: 1, Kind: models.NotificationMentioned, TaskID: 2, Task: "test", Actor: "alice@example.com"},
+		}
+
+		htmlString, err := renderer.RenderNotifications(yatta.NotificationsPage{User: "bob@example.com", Notifications: notifications})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			`href="/tasks/3#comment-7"`,
+			`carol@example.com mentioned you in a comment on &#34;review&#34;`,
+			`href="/tasks/2"`,
+			`alice@example.com mentioned you in &#34;test&#34;`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+}
+
+func TestRenderer_Webhooks(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("renders the webhooks of a list and their deliveries", func(t *testing.T) {
+		page := yatta.WebhooksPage{
+			User: "alice@example.com",
+			Webhooks: []models.Webhook{
+				{ID: 1, URL: "https://example.com/hooks", Secret: "s3cret", Events: []models.WebhookEvent{models.WebhookTaskCreated}},
+			},
+			Deliveries: []models.Delivery{
+				{ID: 3, Event: models.WebhookTaskCompleted, TaskID: 2, URL: "https://example.com/hooks", CreatedAt: now, NextAttemptAt: now.Add(time.Minute), Attempts: 1, LastError: "the response had the status code 503"},
+				{ID: 2, Event: models.WebhookTaskCreated, TaskID: 2, URL: "https://example.com/hooks", CreatedAt: now, Failed: true, Attempts: 8, LastError: "connection refused"},
+				{ID: 1, Event: models.WebhookTaskCreated, TaskID: 1, URL: "https://example.com/hooks", CreatedAt: now, DeliveredAt: &now, Attempts: 1, StatusCode: 204},
+			},
+		}
+
+		htmlString, err := renderer.RenderWebhooks(page)
+		yattatest.AssertNoError(t, err)
+
+		doc := mustParseHTML(t, string(htmlString))
+
+		if got := len(findElements(doc, "tr")); got != 4 {
+			t.Errorf("got %d rows, want a header and 3 deliveries", got)
+		}
+
+		for _, want := range []string{
+			`hx-delete="/users/alice@example.com/webhooks/1"`,
+			`<input type="checkbox" name="event" value="task.deleted" checked>`,
+			"Pending: the response had the status code 503",
+			"Failed: connection refused",
+			"Delivered (204)",
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		if strings.Contains(string(htmlString), "s3cret") {
+			t.Errorf("found the secret of the webhook in %s", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Inbound(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders the inbound URLs of a list", func(t *testing.T) {
+		htmlString, err := renderer.RenderInbound(yatta.InboundPage{User: "alice@example.com", URL: "https://yatta.example.com/inbound/s3cret"})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			"<code>https://yatta.example.com/inbound/s3cret</code>",
+			"<code>https://yatta.example.com/inbound/s3cret/email</code>",
+			`hx-post="/users/alice@example.com/inbound"`,
+			"Reset URLs",
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("offers to create the URLs of a list without them", func(t *testing.T) {
+		htmlString, err := renderer.RenderInbound(yatta.InboundPage{User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "Create URLs") || strings.Contains(string(htmlString), "/inbound/") {
+			t.Errorf("got %s, want a button to create the URLs and no URLs", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Feed(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+
+	t.Run("renders the feed URL of a list", func(t *testing.T) {
+		htmlString, err := renderer.RenderFeed(yatta.FeedPage{User: "alice@example.com", URL: "https://yatta.example.com/feeds/s3cret/tasks.ics"})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			"<code>https://yatta.example.com/feeds/s3cret/tasks.ics</code>",
+			"<code>https://yatta.example.com/feeds/s3cret/tasks.ics?type=todo</code>",
+			`hx-post="/users/alice@example.com/feed"`,
+			"Reset URL",
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+	})
+
+	t.Run("offers to create the URL of a list without one", func(t *testing.T) {
+		htmlString, err := renderer.RenderFeed(yatta.FeedPage{User: "alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "Create URL") || strings.Contains(string(htmlString), "/feeds/") {
+			t.Errorf("got %s, want a button to create the URL and no URL", htmlString)
+		}
+	})
+}
+
+func TestRenderer_AppPasswords(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	page := yatta.AppPasswordsPage{
+		User: "alice@example.com",
+		AppPasswords: []models.AppPassword{
+			{ID: 1, Name: "Phone", CreatedAt: time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)},
+		},
+		CalDAVURL: "https://yatta.example.com/caldav/",
+	}
+
+	t.Run("renders the CalDAV URL and app passwords", func(t *testing.T) {
+		htmlString, err := renderer.RenderAppPasswords(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			"<code>https://yatta.example.com/caldav/</code>",
+			"<code>alice@example.com</code>",
+			"Phone",
+			"14 Mar 2025",
+			`hx-delete="/app-passwords/1"`,
+			`hx-post="/app-passwords"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		if strings.Contains(string(htmlString), "new-app-password") {
+			t.Errorf("got %s, want no new password", htmlString)
+		}
+	})
+
+	t.Run("shows a new password", func(t *testing.T) {
+		page := page
+		page.NewPassword = "abcd-efgh-ijkm-npqr-stuv-wxyz"
+		page.NewPasswordName = "Laptop"
+		htmlString, err := renderer.RenderAppPasswords(page)
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "The password for Laptop is <code>abcd-efgh-ijkm-npqr-stuv-wxyz</code>") {
+			t.Errorf("got %s, want the new password", htmlString)
+		}
+	})
+}
+
+func TestRenderer_Import(t *testing.T) {
+	renderer := mustCreateRenderer(t)
+	due := time.Date(2025, 3, 14, 17, 0, 0, 0, time.UTC)
+
+	t.Run("renders the form", func(t *testing.T) {
+		htmlString, err := renderer.RenderImport(yatta.ImportPage{User: "alice@example.com", TimeZone: "Pacific/Auckland"})
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{
+			`hx-post="/users/alice@example.com/import?tz=Pacific/Auckland"`,
+			`<option value="todotxt">`,
+			`<option value="trello">`,
+			`name="column-description"`,
+			`name="dry-run"`,
+		} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		if strings.Contains(string(htmlString), "imported-tasks") || strings.Contains(string(htmlString), "import-result") {
+			t.Errorf("got %s, want no preview or result", htmlString)
+		}
+	})
+
+	t.Run("renders the preview with subtasks", func(t *testing.T) {
+		page := yatta.ImportPage{User: "alice@example.com", Preview: []importer.Task{
+			{Description: "Launch", Due: &due, Priority: 3, Tags: []string{"work"}, Subtasks: []importer.Task{
+				{Description: "Write docs", Done: true},
+			}},
+		}}
+		htmlString, err := renderer.RenderImport(page)
+		yattatest.AssertNoError(t, err)
+
+		for _, want := range []string{"2 tasks would be imported", "Launch", "14 Mar 2025 17:00", "Priority 3", "#work", "Write docs"} {
+			if !strings.Contains(string(htmlString), want) {
+				t.Errorf("could not find %q in %s", want, htmlString)
+			}
+		}
+
+		if got := strings.Count(string(htmlString), `class="imported-tasks"`); got != 2 {
+			t.Errorf("got %d lists of tasks, want one for the tasks and one for the subtasks", got)
+		}
+	})
+
+	t.Run("renders how many tasks were imported", func(t *testing.T) {
+		htmlString, err := renderer.RenderImport(yatta.ImportPage{User: "alice@example.com", Imported: 3})
+		yattatest.AssertNoError(t, err)
+
+		if !strings.Contains(string(htmlString), "Imported 3 tasks.") {
+			t.Errorf("got %s, want the number of imported tasks", htmlString)
+		}
+	})
+}
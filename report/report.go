@@ -0,0 +1,170 @@
+package report
+
+import (
+	"slices"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// A Day is the work left at the end of a day.
+type Day struct {
+	// Midnight at the start of the day.
+	Date time.Time
+	// The sum of the estimates of the tasks that were open at the end of the day.
+	Remaining float64
+	// The number of tasks that were open at the end of the day.
+	Open int
+}
+
+// A Week is the work completed in the week starting on Monday.
+type Week struct {
+	// Midnight at the start of the Monday.
+	Start time.Time
+	// The number of tasks completed in the week.
+	Completed int
+	// The sum of the estimates of the tasks completed in the week.
+	Estimate float64
+}
+
+// A change to whether a task is done.
+type statusChange struct {
+	at   time.Time
+	done bool
+}
+
+// The history of a task, used to tell whether the task was open at a point in time.
+type taskHistory struct {
+	task models.Task
+	// When the task was created, or the zero time if that was not recorded.
+	created time.Time
+	// The times the task was completed and reopened, oldest first.
+	changes []statusChange
+}
+
+// Get whether the task existed and was open at `t`.
+func (h taskHistory) openAt(t time.Time) bool {
+	if h.created.After(t) {
+		return false
+	}
+
+	// Without any recorded changes, a task has always been in its current state.
+	done := h.task.Done && len(h.changes) == 0
+
+	for _, change := range h.changes {
+		if change.at.After(t) {
+			break
+		}
+
+		done = change.done
+	}
+
+	return !done
+}
+
+// Piece together the history of each of `tasks` from `history`, which holds the times the tasks were created,
+// completed and reopened, oldest first.
+func newTaskHistories(tasks []models.Task, history []models.Activity) []taskHistory {
+	histories := make([]taskHistory, len(tasks))
+	indices := make(map[uint64]int, len(tasks))
+
+	for i, task := range tasks {
+		histories[i].task = task
+		indices[task.ID] = i
+	}
+
+	for _, activity := range history {
+		i, ok := indices[activity.TaskID]
+
+		if !ok {
+			continue
+		}
+
+		switch activity.Kind {
+		case models.ActivityCreated:
+			histories[i].created = activity.At
+		case models.ActivityCompleted, models.ActivityReopened:
+			histories[i].changes = append(histories[i].changes, statusChange{activity.At, activity.Kind == models.ActivityCompleted})
+		}
+	}
+
+	// Tasks completed before completions were recorded only know when they were last completed.
+	for i, h := range histories {
+		if len(h.changes) == 0 && h.task.Done && h.task.CompletedAt != nil {
+			histories[i].changes = []statusChange{{*h.task.CompletedAt, true}}
+		}
+	}
+
+	return histories
+}
+
+// Burndown gets the work left at the end of each day from `from` to `to`, inclusive, where `history` holds the times
+// that `tasks` were created, completed and reopened, oldest first.
+//
+// Days start at midnight in the location of `from`.
+func Burndown(tasks []models.Task, history []models.Activity, from time.Time, to time.Time) []Day {
+	histories := newTaskHistories(tasks, history)
+	days := []Day{}
+
+	for date := startOfDay(from); !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := Day{Date: date}
+		// The last moment of the day.
+		end := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+		for _, h := range histories {
+			if h.openAt(end) {
+				day.Remaining += h.task.Estimate
+				day.Open++
+			}
+		}
+
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// Throughput gets the work completed in each week that overlaps the days from `from` to `to`, inclusive.
+// Tasks count towards the week that they were last completed in.
+//
+// Weeks start at midnight on Monday in the location of `from`.
+func Throughput(tasks []models.Task, from time.Time, to time.Time) []Week {
+	weeks := []Week{}
+	monday := startOfDay(from)
+	monday = monday.AddDate(0, 0, -(int(monday.Weekday())+6)%7)
+
+	for ; !monday.After(to); monday = monday.AddDate(0, 0, 7) {
+		weeks = append(weeks, Week{Start: monday})
+	}
+
+	for _, task := range tasks {
+		if !task.Done || task.CompletedAt == nil {
+			continue
+		}
+
+		i, found := slices.BinarySearchFunc(weeks, *task.CompletedAt, func(week Week, t time.Time) int {
+			return week.Start.Compare(t)
+		})
+
+		// The week that the task was completed in starts before the completion time.
+		if !found {
+			i--
+		}
+
+		if i < 0 || !task.CompletedAt.Before(weeks[i].Start.AddDate(0, 0, 7)) {
+			continue
+		}
+
+		weeks[i].Completed++
+		weeks[i].Estimate += task.Estimate
+	}
+
+	return weeks
+}
+
+// Get midnight at the start of the day of `t`.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,77 @@
+package report_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/report"
+)
+
+// Get the time at `hour` on the `day`th of March 2025, where the 3rd is a Monday.
+func at(day int, hour int) time.Time {
+	return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestBurndown(t *testing.T) {
+	completedAt := at(1, 12)
+	tasks := []models.Task{
+		{ID: 1, Description: "design", Estimate: 3, Done: true, CompletedAt: &completedAt},
+		{ID: 2, Description: "build", Estimate: 5},
+		{ID: 3, Description: "test", Estimate: 2, Done: true},
+		{ID: 4, Description: "document"},
+		// Completed before completions were recorded.
+		{ID: 5, Description: "plan", Estimate: 1, Done: true, CompletedAt: &completedAt},
+	}
+	history := []models.Activity{
+		{TaskID: 1, Kind: models.ActivityCreated, At: at(1, 9)},
+		{TaskID: 2, Kind: models.ActivityCreated, At: at(1, 9)},
+		{TaskID: 3, Kind: models.ActivityCreated, At: at(2, 9)},
+		{TaskID: 1, Kind: models.ActivityCompleted, At: at(2, 10)},
+		{TaskID: 4, Kind: models.ActivityCreated, At: at(2, 11)},
+		{TaskID: 3, Kind: models.ActivityCompleted, At: at(2, 17)},
+		{TaskID: 3, Kind: models.ActivityReopened, At: at(3, 9)},
+		{TaskID: 3, Kind: models.ActivityCompleted, At: at(4, 9)},
+		// Activity for tasks that are no longer in the list is ignored.
+		{TaskID: 6, Kind: models.ActivityCreated, At: at(1, 9)},
+	}
+
+	got := report.Burndown(tasks, history, at(1, 15), at(4, 0))
+	want := []report.Day{
+		{Date: at(1, 0), Remaining: 8, Open: 2},
+		{Date: at(2, 0), Remaining: 5, Open: 2},
+		{Date: at(3, 0), Remaining: 7, Open: 3},
+		{Date: at(4, 0), Remaining: 5, Open: 2},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got burndown %v, want %v", got, want)
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	completed := func(day int) *time.Time {
+		completedAt := at(day, 12)
+		return &completedAt
+	}
+	tasks := []models.Task{
+		{ID: 1, Estimate: 3, Done: true, CompletedAt: completed(3)},
+		{ID: 2, Estimate: 5, Done: true, CompletedAt: completed(9)},
+		{ID: 3, Estimate: 2, Done: true, CompletedAt: completed(10)},
+		{ID: 4, Done: true, CompletedAt: completed(12)},
+		{ID: 5, Estimate: 8},
+		// Completed before the report starts.
+		{ID: 6, Estimate: 1, Done: true, CompletedAt: completed(2)},
+	}
+
+	got := report.Throughput(tasks, at(4, 15), at(12, 0))
+	want := []report.Week{
+		{Start: at(3, 0), Completed: 2, Estimate: 8},
+		{Start: at(10, 0), Completed: 2, Estimate: 2},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got throughput %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package search
+
+import "strings"
+
+// A Segment is a piece of text that either matches a search query or not.
+type Segment struct {
+	Text  string
+	Match bool
+}
+
+// Highlight splits `text` into segments so that the words matching `query` can be displayed differently.
+//
+// Words match using the same rules as [Index.Search]. Concatenating the text of the segments gives back `text`.
+func Highlight(text string, query string) []Segment {
+	terms := parseQuery(query)
+	var segments []Segment
+	end := 0
+
+	appendSegment := func(text string, match bool) {
+		if text == "" {
+			return
+		}
+
+		if len(segments) > 0 && segments[len(segments)-1].Match == match {
+			segments[len(segments)-1].Text += text
+		} else {
+			segments = append(segments, Segment{Text: text, Match: match})
+		}
+	}
+
+	for _, token := range Tokenize(text) {
+		if !matchesAny(token, terms) {
+			continue
+		}
+
+		appendSegment(text[end:token.Start], false)
+		appendSegment(text[token.Start:token.End], true)
+		end = token.End
+	}
+
+	appendSegment(text[end:], false)
+
+	return segments
+}
+
+func matchesAny(token Token, terms []queryTerm) bool {
+	for _, term := range terms {
+		if token.Stem == term.Stem || (term.prefix && strings.HasPrefix(token.Word, term.Word)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parameters for the Okapi BM25 ranking function.
+const (
+	// Controls how quickly repeated occurrences of a term stop increasing the score.
+	bm25K1 = 1.2
+	// Controls how much longer documents are penalised.
+	bm25B = 0.75
+)
+
+// The weight of a word that only matches the prefix of a query term, relative to a word matching the whole term.
+const prefixWeight = 0.5
+
+// An Index is an in-memory inverted index that finds documents by the words they contain.
+//
+// Words are matched by their stems, so a search for "deploying" finds documents containing "deploy" or "deployed".
+type Index struct {
+	// Maps a stem to the documents that contain it and the number of times they contain it.
+	stems map[string]map[uint64]int
+	// Maps a word to the documents that contain it and the number of times they contain it.
+	words map[string]map[uint64]int
+	// Maps a document ID to the tokens in the document.
+	documents map[uint64][]Token
+	// The total number of tokens across all documents.
+	totalLength int
+}
+
+// A Result is a document that matched a search query.
+type Result struct {
+	ID    uint64
+	Score float64
+}
+
+// A queryTerm is a word in a search query.
+type queryTerm struct {
+	Token
+	// Whether the term may also match words that start with the term, e.g., when the user is still typing it.
+	prefix bool
+}
+
+// NewIndex creates an empty index.
+func NewIndex() *Index {
+	return &Index{
+		stems:     make(map[string]map[uint64]int),
+		words:     make(map[string]map[uint64]int),
+		documents: make(map[uint64][]Token),
+	}
+}
+
+// Add indexes `text` as the document `id`, replacing the document if it is already in the index.
+func (i *Index) Add(id uint64, text string) {
+	i.Remove(id)
+
+	tokens := Tokenize(text)
+	i.documents[id] = tokens
+	i.totalLength += len(tokens)
+
+	for _, token := range tokens {
+		addPosting(i.stems, token.Stem, id)
+		addPosting(i.words, token.Word, id)
+	}
+}
+
+// Remove deletes the document `id` from the index, if it exists.
+func (i *Index) Remove(id uint64) {
+	tokens, ok := i.documents[id]
+
+	if !ok {
+		return
+	}
+
+	for _, token := range tokens {
+		removePosting(i.stems, token.Stem, id)
+		removePosting(i.words, token.Word, id)
+	}
+
+	i.totalLength -= len(tokens)
+	delete(i.documents, id)
+}
+
+// Search finds the documents that contain every word in `query`, with the most relevant documents first.
+//
+// The last word in the query also matches words that start with it, unless the query ends with a space. This
+// allows results to be shown while the user is typing. Returns nil if the query contains no words.
+func (i *Index) Search(query string) []Result {
+	terms := parseQuery(query)
+
+	if len(terms) == 0 || len(i.documents) == 0 {
+		return nil
+	}
+
+	scores := make(map[uint64]float64)
+	averageLength := float64(i.totalLength) / float64(len(i.documents))
+
+	for termIndex, term := range terms {
+		frequencies := i.termFrequencies(term)
+		idf := math.Log(1 + (float64(len(i.documents))-float64(len(frequencies))+0.5)/(float64(len(frequencies))+0.5))
+
+		for id, frequency := range frequencies {
+			// Documents must match every term, so skip documents that did not match an earlier term.
+			if _, ok := scores[id]; !ok && termIndex > 0 {
+				continue
+			}
+
+			length := float64(len(i.documents[id]))
+			scores[id] += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*length/averageLength))
+		}
+
+		for id := range scores {
+			if _, ok := frequencies[id]; !ok {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+
+	for id, score := range scores {
+		results = append(results, Result{ID: id, Score: score})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+
+		return results[a].ID < results[b].ID
+	})
+
+	return results
+}
+
+// Get the (weighted) number of times each document contains `term`.
+func (i *Index) termFrequencies(term queryTerm) map[uint64]float64 {
+	frequencies := make(map[uint64]float64)
+
+	for id, count := range i.stems[term.Stem] {
+		frequencies[id] += float64(count)
+	}
+
+	if !term.prefix {
+		return frequencies
+	}
+
+	for word, postings := range i.words {
+		if !strings.HasPrefix(word, term.Word) || Stem(word) == term.Stem {
+			continue
+		}
+
+		for id, count := range postings {
+			frequencies[id] += prefixWeight * float64(count)
+		}
+	}
+
+	return frequencies
+}
+
+func addPosting(postings map[string]map[uint64]int, key string, id uint64) {
+	if postings[key] == nil {
+		postings[key] = make(map[uint64]int)
+	}
+
+	postings[key][id]++
+}
+
+func removePosting(postings map[string]map[uint64]int, key string, id uint64) {
+	delete(postings[key], id)
+
+	if len(postings[key]) == 0 {
+		delete(postings, key)
+	}
+}
+
+func parseQuery(query string) []queryTerm {
+	tokens := Tokenize(query)
+	terms := make([]queryTerm, 0, len(tokens))
+
+	for _, token := range tokens {
+		terms = append(terms, queryTerm{Token: token})
+	}
+
+	if len(terms) > 0 && terms[len(terms)-1].End == len(query) {
+		terms[len(terms)-1].prefix = true
+	}
+
+	return terms
+}
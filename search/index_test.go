@@ -0,0 +1,133 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/search"
+)
+
+func TestIndex_Search(t *testing.T) {
+	newIndex := func() *search.Index {
+		index := search.NewIndex()
+		index.Add(1, "Deploy the website")
+		index.Add(2, "Get approval for the deployment")
+		index.Add(3, "Water the plants")
+		index.Add(4, "Deployed website; deploy again after deploying the fix")
+		return index
+	}
+
+	cases := []struct {
+		name  string
+		query string
+		want  []uint64
+	}{
+		{"matches words with the same stem", "deploying ", []uint64{4, 1}},
+		{"matches every word in the query", "deploy website ", []uint64{1, 4}},
+		{"is case insensitive", "WATER ", []uint64{3}},
+		{"matches prefix of the last word", "depl", []uint64{4, 1, 2}},
+		{"only the last word matches as a prefix", "depl website", []uint64{}},
+		{"no matches", "groceries", []uint64{}},
+		{"empty query", "   ", nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			results := newIndex().Search(c.query)
+
+			var got []uint64
+
+			if results != nil {
+				got = []uint64{}
+			}
+
+			for _, result := range results {
+				got = append(got, result.ID)
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got results %v for %q, want %v", got, c.query, c.want)
+			}
+		})
+	}
+
+	t.Run("replacing a document updates the index", func(t *testing.T) {
+		index := newIndex()
+		index.Add(3, "Buy groceries")
+
+		assertSearchResults(t, index, "water ", []uint64{})
+		assertSearchResults(t, index, "groceries ", []uint64{3})
+	})
+
+	t.Run("removed documents are not found", func(t *testing.T) {
+		index := newIndex()
+		index.Remove(1)
+		index.Remove(42)
+
+		assertSearchResults(t, index, "website ", []uint64{4})
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		name  string
+		text  string
+		query string
+		want  []search.Segment
+	}{
+		{
+			name:  "highlights words with the same stem",
+			text:  "Deployed the website",
+			query: "deploy ",
+			want:  []search.Segment{{Text: "Deployed", Match: true}, {Text: " the website"}},
+		},
+		{
+			name:  "highlights prefix matches",
+			text:  "Water the plants",
+			query: "pla",
+			want:  []search.Segment{{Text: "Water the "}, {Text: "plants", Match: true}},
+		},
+		{
+			name:  "highlights several words",
+			text:  "Deploy the website, then deploy the app",
+			query: "deploy app",
+			want: []search.Segment{
+				{Text: "Deploy", Match: true},
+				{Text: " the website, then "},
+				{Text: "deploy", Match: true},
+				{Text: " the "},
+				{Text: "app", Match: true},
+			},
+		},
+		{
+			name:  "no matches",
+			text:  "Water the plants",
+			query: "groceries",
+			want:  []search.Segment{{Text: "Water the plants"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := search.Highlight(c.text, c.query)
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got segments %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func assertSearchResults(t *testing.T, index *search.Index, query string, want []uint64) {
+	t.Helper()
+
+	got := []uint64{}
+
+	for _, result := range index.Search(query) {
+		got = append(got, result.ID)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %v for %q, want %v", got, query, want)
+	}
+}
@@ -0,0 +1,277 @@
+package search
+
+import "strings"
+
+// Stem reduces an English word to its stem using the Porter stemming algorithm, e.g., "connected", "connecting" and
+// "connection" all become "connect".
+//
+// `word` is expected to be in lower case. Words with two or fewer letters and words containing characters other than
+// the letters a-z are returned unchanged.
+//
+// See https://tartarus.org/martin/PorterStemmer/def.txt for the definition of the algorithm.
+func Stem(word string) string {
+	if len(word) <= 2 || strings.IndexFunc(word, func(r rune) bool { return r < 'a' || r > 'z' }) != -1 {
+		return word
+	}
+
+	s := stemmer{[]byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5a()
+	s.step5b()
+
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+// A rule replaces a suffix with another suffix when the rest of the word has a large enough measure.
+type rule struct {
+	suffix      string
+	replacement string
+}
+
+// Whether the letter at index `i` is a consonant.
+//
+// A consonant is a letter other than a, e, i, o and u, and other than a y preceded by a consonant.
+func (s *stemmer) isConsonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.isConsonant(i-1)
+	default:
+		return true
+	}
+}
+
+// The number of vowel-consonant sequences in the first `n` letters, i.e., m in [C](VC){m}[V].
+func (s *stemmer) measure(n int) int {
+	m := 0
+	i := 0
+
+	for i < n && s.isConsonant(i) {
+		i++
+	}
+
+	for i < n {
+		for i < n && !s.isConsonant(i) {
+			i++
+		}
+
+		if i == n {
+			break
+		}
+
+		for i < n && s.isConsonant(i) {
+			i++
+		}
+
+		m++
+	}
+
+	return m
+}
+
+// Whether the first `n` letters contain a vowel.
+func (s *stemmer) hasVowel(n int) bool {
+	for i := range n {
+		if !s.isConsonant(i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether the first `n` letters end with a double consonant, e.g., "-tt".
+func (s *stemmer) endsWithDoubleConsonant(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.isConsonant(n-1)
+}
+
+// Whether the first `n` letters end consonant-vowel-consonant, where the last consonant is not w, x or y.
+func (s *stemmer) endsCVC(n int) bool {
+	if n < 3 || !s.isConsonant(n-3) || s.isConsonant(n-2) || !s.isConsonant(n-1) {
+		return false
+	}
+
+	last := s.b[n-1]
+
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// The number of letters before `suffix`.
+func (s *stemmer) stemLength(suffix string) int {
+	return len(s.b) - len(suffix)
+}
+
+func (s *stemmer) replaceSuffix(suffix string, replacement string) {
+	s.b = append(s.b[:s.stemLength(suffix)], replacement...)
+}
+
+// Apply the first rule whose suffix matches if the measure of the remaining stem is greater than `minMeasure`.
+func (s *stemmer) applyRules(rules []rule, minMeasure int) {
+	for _, r := range rules {
+		if s.hasSuffix(r.suffix) {
+			if s.measure(s.stemLength(r.suffix)) > minMeasure {
+				s.replaceSuffix(r.suffix, r.replacement)
+			}
+
+			return
+		}
+	}
+}
+
+// Step 1a deals with plurals, e.g., "caresses" -> "caress", "ponies" -> "poni" and "cats" -> "cat".
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.replaceSuffix("sses", "ss")
+	case s.hasSuffix("ies"):
+		s.replaceSuffix("ies", "i")
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.replaceSuffix("s", "")
+	}
+}
+
+// Step 1b deals with past participles, e.g., "agreed" -> "agree", "plastered" -> "plaster" and "hopping" -> "hop".
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(s.stemLength("eed")) > 0 {
+			s.replaceSuffix("eed", "ee")
+		}
+
+		return
+	}
+
+	removed := false
+
+	for _, suffix := range []string{"ed", "ing"} {
+		if s.hasSuffix(suffix) && s.hasVowel(s.stemLength(suffix)) {
+			s.replaceSuffix(suffix, "")
+			removed = true
+			break
+		}
+	}
+
+	if !removed {
+		return
+	}
+
+	n := len(s.b)
+
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.endsWithDoubleConsonant(n) && s.b[n-1] != 'l' && s.b[n-1] != 's' && s.b[n-1] != 'z':
+		s.b = s.b[:n-1]
+	case s.measure(n) == 1 && s.endsCVC(n):
+		s.b = append(s.b, 'e')
+	}
+}
+
+// Step 1c turns a terminal y into an i when there is another vowel in the stem, e.g., "happy" -> "happi".
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(s.stemLength("y")) {
+		s.replaceSuffix("y", "i")
+	}
+}
+
+// Step 2 maps double suffixes to single ones, e.g., "relational" -> "relate".
+func (s *stemmer) step2() {
+	s.applyRules([]rule{
+		{"ational", "ate"},
+		{"tional", "tion"},
+		{"enci", "ence"},
+		{"anci", "ance"},
+		{"izer", "ize"},
+		{"abli", "able"},
+		{"alli", "al"},
+		{"entli", "ent"},
+		{"eli", "e"},
+		{"ousli", "ous"},
+		{"ization", "ize"},
+		{"ation", "ate"},
+		{"ator", "ate"},
+		{"alism", "al"},
+		{"iveness", "ive"},
+		{"fulness", "ful"},
+		{"ousness", "ous"},
+		{"aliti", "al"},
+		{"iviti", "ive"},
+		{"biliti", "ble"},
+	}, 0)
+}
+
+// Step 3 removes or simplifies suffixes such as "-ful" and "-ness", e.g., "hopeful" -> "hope".
+func (s *stemmer) step3() {
+	s.applyRules([]rule{
+		{"icate", "ic"},
+		{"ative", ""},
+		{"alize", "al"},
+		{"iciti", "ic"},
+		{"ical", "ic"},
+		{"ful", ""},
+		{"ness", ""},
+	}, 0)
+}
+
+// Step 4 removes suffixes from longer stems, e.g., "adjustment" -> "adjust".
+//
+// Longer suffixes come before suffixes they end with (e.g., "-ement" before "-ment") so only the longest is removed.
+func (s *stemmer) step4() {
+	for _, suffix := range []string{
+		"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent", "ion", "ou", "ism", "ate",
+		"iti", "ous", "ive", "ize",
+	} {
+		if !s.hasSuffix(suffix) {
+			continue
+		}
+
+		n := s.stemLength(suffix)
+
+		if suffix == "ion" && (n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't')) {
+			return
+		}
+
+		if s.measure(n) > 1 {
+			s.b = s.b[:n]
+		}
+
+		return
+	}
+}
+
+// Step 5a removes a final e, e.g., "probate" -> "probat".
+func (s *stemmer) step5a() {
+	if !s.hasSuffix("e") {
+		return
+	}
+
+	n := s.stemLength("e")
+	m := s.measure(n)
+
+	if m > 1 || (m == 1 && !s.endsCVC(n)) {
+		s.b = s.b[:n]
+	}
+}
+
+// Step 5b removes a double l from longer stems, e.g., "controll" -> "control".
+func (s *stemmer) step5b() {
+	n := len(s.b)
+
+	if s.measure(n) > 1 && s.endsWithDoubleConsonant(n) && s.b[n-1] == 'l' {
+		s.b = s.b[:n-1]
+	}
+}
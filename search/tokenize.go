@@ -0,0 +1,47 @@
+// Package search implements full-text search over short documents such as task descriptions.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A Token is a word found in a piece of text.
+type Token struct {
+	// The word in lower case.
+	Word string
+	// The stem of the word, see [Stem].
+	Stem string
+	// The byte offsets of the word in the original text.
+	Start int
+	End   int
+}
+
+// Tokenize splits `text` into words, where a word is a run of letters and digits.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		if isWordRune && start == -1 {
+			start = i
+		} else if !isWordRune && start != -1 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+
+	if start != -1 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+
+	return tokens
+}
+
+func newToken(text string, start int, end int) Token {
+	word := strings.ToLower(text[start:end])
+
+	return Token{Word: word, Stem: Stem(word), Start: start, End: end}
+}
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/AnthonyDickson/yatta/calendar"
+	"github.com/AnthonyDickson/yatta/events"
 	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/query"
+	"github.com/AnthonyDickson/yatta/quickadd"
+	"github.com/AnthonyDickson/yatta/report"
 	"github.com/AnthonyDickson/yatta/stores"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,26 +31,161 @@ import (
 const htmlContentType = "text/html"
 
 type Server struct {
+	// Holds the users of every workspace. Handlers get the users that whoever made the request may see with
+	// [Server.users].
 	userStore stores.UserStore
+	// Holds the task lists of every workspace. Handlers get the task lists that whoever made the request may see with
+	// [Server.tasks].
 	taskStore stores.TaskStore
-	renderer  Renderer
+	// Stores the contents of attachments, or nil if attachments are disabled.
+	blobStore stores.BlobStore
+	// Stores the workspaces that keep the task lists of teams apart, or nil if workspaces are disabled.
+	workspaceStore stores.WorkspaceStore
+	// Tells open pages about changes to the task lists they show, or nil if pages are not updated live.
+	events *events.Bus
+	// How often to send something to open pages so that the connection is not closed for being idle.
+	heartbeatInterval time.Duration
+	renderer          Renderer
 	http.Handler
 }
 
-func NewServer(taskStore stores.TaskStore, userStore stores.UserStore, renderer Renderer) (*Server, error) {
+// A ServerOption configures an optional feature of a [Server].
+type ServerOption func(*Server)
+
+// WithBlobStore enables attaching files to tasks, storing the contents of the files in `blobStore`.
+func WithBlobStore(blobStore stores.BlobStore) ServerOption {
+	return func(s *Server) {
+		s.blobStore = blobStore
+	}
+}
+
+// WithWorkspaceStore enables workspaces, storing them in `workspaceStore`. The task lists of the members of a workspace
+// can only be seen by the other members of the workspace.
+func WithWorkspaceStore(workspaceStore stores.WorkspaceStore) ServerOption {
+	return func(s *Server) {
+		s.workspaceStore = workspaceStore
+	}
+}
+
+// WithEventBus enables updating the task lists that are open in browsers as soon as their tasks change, listening for
+// the changes on `bus`. A comment is sent every `heartbeat` while nothing has changed so that the connection stays
+// open.
+func WithEventBus(bus *events.Bus, heartbeat time.Duration) ServerOption {
+	return func(s *Server) {
+		s.events = bus
+		s.heartbeatInterval = heartbeat
+	}
+}
+
+func NewServer(taskStore stores.TaskStore, userStore stores.UserStore, renderer Renderer, options ...ServerOption) (*Server, error) {
 	server := new(Server)
 	server.taskStore = taskStore
 	server.userStore = userStore
 
+	for _, option := range options {
+		option(server)
+	}
+
 	router := http.NewServeMux()
 	router.Handle("GET /coffee", http.HandlerFunc(server.getCoffee))
 	router.Handle("GET /", http.HandlerFunc(server.getRoot))
-	router.Handle("GET /tasks/{id}", http.HandlerFunc(server.getTask))
-	router.Handle("GET /users/{user}/tasks", http.HandlerFunc(server.getTasks))
-	router.Handle("POST /users/{user}/tasks", http.HandlerFunc(server.addTask))
+	router.Handle("GET /search", http.HandlerFunc(server.getSearch))
+	router.Handle("GET /tasks/{id}", server.forTask(models.RoleViewer, server.getTask))
+	router.Handle("GET /users/{user}/tasks", server.forList(models.RoleViewer, server.getTasks))
+	router.Handle("GET /users/{user}/tasks/ready", server.forList(models.RoleViewer, server.getReadyTasks))
+	router.Handle("GET /users/{user}/tasks/next", server.forList(models.RoleViewer, server.getNextActions))
+	router.Handle("GET /users/{user}/tasks/preview", http.HandlerFunc(server.previewTask))
+	router.Handle("GET /users/{user}/searches/{id}", server.forList(models.RoleViewer, server.getSavedSearch))
+	router.Handle("POST /users/{user}/searches", server.forList(models.RoleAdmin, server.addSavedSearch))
+	router.Handle("DELETE /users/{user}/searches/{id}", server.forList(models.RoleAdmin, server.deleteSavedSearch))
+	router.Handle("POST /users/{user}/tasks", server.forList(models.RoleEditor, server.addTask))
+	router.Handle("POST /tasks/{id}", server.forTask(models.RoleEditor, server.updateTask))
+	router.Handle("POST /tasks/{id}/subtasks", server.forTask(models.RoleEditor, server.addSubtask))
+	router.Handle("POST /tasks/{id}/parent", server.forTask(models.RoleEditor, server.setParent))
+	router.Handle("POST /tasks/{id}/complete", server.forTask(models.RoleEditor, server.completeTask))
+	router.Handle("POST /tasks/{id}/reopen", server.forTask(models.RoleEditor, server.reopenTask))
+	router.Handle("POST /tasks/{id}/snooze", server.forTask(models.RoleEditor, server.snoozeTask))
+	router.Handle("DELETE /tasks/{id}/snooze", server.forTask(models.RoleEditor, server.unsnoozeTask))
+	router.Handle("POST /tasks/{id}/dependencies", server.forTask(models.RoleEditor, server.addDependency))
+	router.Handle("DELETE /tasks/{id}/dependencies/{blocker}", server.forTask(models.RoleEditor, server.removeDependency))
+	router.Handle("DELETE /tasks/{id}", server.forTask(models.RoleEditor, server.deleteTask))
+	router.Handle("GET /users/{user}/trash", server.forList(models.RoleViewer, server.getTrash))
+	router.Handle("POST /trash/{id}/restore", server.forTask(models.RoleEditor, server.restoreTask))
+	router.Handle("DELETE /trash/{id}", server.forTask(models.RoleEditor, server.purgeTask))
+	router.Handle("GET /users/{user}/archive", server.forList(models.RoleViewer, server.getArchive))
+	router.Handle("GET /users/{user}/report", server.forList(models.RoleViewer, server.getReport))
+	router.Handle("GET /users/{user}/board", server.forList(models.RoleViewer, server.getBoard))
+	router.Handle("POST /users/{user}/board/columns", server.forList(models.RoleAdmin, server.setColumns))
+	router.Handle("POST /users/{user}/board/{column}", server.forList(models.RoleEditor, server.moveTask))
+	router.Handle("GET /users/{user}/calendar", server.forList(models.RoleViewer, server.getCalendar))
+	router.Handle("POST /users/{user}/calendar/{date}", server.forList(models.RoleEditor, server.rescheduleTask))
+	router.Handle("GET /users/{user}/templates", server.forList(models.RoleViewer, server.getTemplates))
+	router.Handle("POST /users/{user}/templates/{id}", server.forList(models.RoleEditor, server.useTemplate))
+	router.Handle("DELETE /users/{user}/templates/{id}", server.forList(models.RoleAdmin, server.deleteTemplate))
+	router.Handle("POST /tasks/{id}/template", server.forTask(models.RoleAdmin, server.addTemplate))
+	router.Handle("POST /tasks/{id}/assignees", server.forTask(models.RoleEditor, server.setAssignees))
+	router.Handle("GET /assigned", http.HandlerFunc(server.getAssigned))
+	router.Handle("GET /notifications", http.HandlerFunc(server.getNotifications))
+	router.Handle("POST /notifications/read", http.HandlerFunc(server.readNotifications))
+	router.Handle("GET /notifications/bell", http.HandlerFunc(server.getNotificationBell))
+	router.Handle("GET /users/{user}/inbound", http.HandlerFunc(server.getInbound))
+	router.Handle("POST /users/{user}/inbound", http.HandlerFunc(server.resetInboundToken))
+	router.Handle("POST /inbound/{token}", http.HandlerFunc(server.receiveTask))
+	router.Handle("POST /inbound/{token}/email", http.HandlerFunc(server.receiveEmail))
+	router.Handle("GET /users/{user}/feed", http.HandlerFunc(server.getFeed))
+	router.Handle("POST /users/{user}/feed", http.HandlerFunc(server.resetFeedToken))
+	router.Handle("GET /feeds/{token}/tasks.ics", http.HandlerFunc(server.serveFeed))
+	router.Handle("GET /users/{user}/import", server.forList(models.RoleEditor, server.getImport))
+	router.Handle("POST /users/{user}/import", server.forList(models.RoleEditor, server.importTasks))
+	router.Handle("GET /app-passwords", http.HandlerFunc(server.getAppPasswords))
+	router.Handle("POST /app-passwords", http.HandlerFunc(server.addAppPassword))
+	router.Handle("DELETE /app-passwords/{id}", http.HandlerFunc(server.deleteAppPassword))
+	router.Handle("GET /users/{user}/webhooks", http.HandlerFunc(server.getWebhooks))
+	router.Handle("POST /users/{user}/webhooks", http.HandlerFunc(server.addWebhook))
+	router.Handle("DELETE /users/{user}/webhooks/{id}", http.HandlerFunc(server.deleteWebhook))
+	router.Handle("GET /users/{user}/members", http.HandlerFunc(server.getMembers))
+	router.Handle("POST /users/{user}/members", http.HandlerFunc(server.setMember))
+	router.Handle("DELETE /users/{user}/members/{email}", http.HandlerFunc(server.removeMember))
+	router.Handle("POST /tasks/{id}/comments", server.forTask(models.RoleEditor, server.addComment))
+	router.Handle("POST /comments/{id}", http.HandlerFunc(server.updateComment))
+	router.Handle("DELETE /comments/{id}", http.HandlerFunc(server.deleteComment))
+	router.Handle("GET /login", http.HandlerFunc(server.login))
+	router.Handle("POST /tasks/{id}/timer", server.forTask(models.RoleEditor, server.startTimer))
+	router.Handle("POST /timer/stop", http.HandlerFunc(server.stopTimer))
+	router.Handle("POST /tasks/{id}/time", server.forTask(models.RoleEditor, server.addTimeEntry))
+	router.Handle("DELETE /time/{id}", http.HandlerFunc(server.deleteTimeEntry))
+	router.Handle("GET /timesheet", http.HandlerFunc(server.getTimesheet))
+	router.Handle("GET /timesheet.csv", http.HandlerFunc(server.exportTimesheet))
+
+	// Patterns without a method would conflict with "GET /", so the CalDAV tree is routed for each method it serves.
+	for _, method := range caldavMethods {
+		router.Handle(method+" /.well-known/caldav", http.HandlerFunc(server.redirectCalDAV))
+		router.Handle(method+" "+caldavPrefix, http.HandlerFunc(server.serveCalDAV))
+	}
+
+	if server.blobStore != nil {
+		router.Handle("POST /tasks/{id}/attachments", server.forTask(models.RoleEditor, server.addAttachments))
+		router.Handle("GET /attachments/{id}", server.forAttachment(models.RoleViewer, server.getAttachment))
+		router.Handle("DELETE /attachments/{id}", server.forAttachment(models.RoleEditor, server.deleteAttachment))
+	}
+
+	if server.events != nil {
+		router.Handle("GET /users/{user}/events", server.forList(models.RoleViewer, server.streamEvents))
+	}
+
+	if server.workspaceStore != nil {
+		router.Handle("GET /workspace", http.HandlerFunc(server.getWorkspace))
+		router.Handle("POST /workspaces", http.HandlerFunc(server.addWorkspace))
+		router.Handle("POST /workspaces/{id}/invitations", http.HandlerFunc(server.inviteToWorkspace))
+		router.Handle("DELETE /workspaces/{id}/invitations/{email}", http.HandlerFunc(server.deleteInvitation))
+		router.Handle("POST /workspaces/{id}/members", http.HandlerFunc(server.joinWorkspace))
+		router.Handle("DELETE /workspaces/{id}/members/{email}", http.HandlerFunc(server.removeWorkspaceMember))
+		router.Handle("POST /workspaces/{id}/settings", http.HandlerFunc(server.updateWorkspaceSettings))
+	}
+
 	router.Handle("POST /users", http.HandlerFunc(server.createUser))
 
-	server.Handler = router
+	server.Handler = withAuthentication(router)
 
 	server.renderer = renderer
 
@@ -47,7 +197,13 @@ func (s *Server) getCoffee(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getRoot(w http.ResponseWriter, r *http.Request) {
-	users, err := s.userStore.GetUsers()
+	userStore := s.users(w, r)
+
+	if userStore == nil {
+		return
+	}
+
+	users, err := userStore.GetUsers()
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -60,15 +216,20 @@ func (s *Server) getRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
-	id_string := r.PathValue("id")
-	id, err := strconv.ParseUint(id_string, 10, 64)
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
 
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
 
-	task, err := s.taskStore.GetTask(id)
+	task, err := taskStore.GetTask(id)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -81,13 +242,95 @@ func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, err := s.renderer.RenderTask(*task)
+	page := TaskPage{Task: *task}
+
+	if page.Subtasks, err = taskStore.GetSubtasks(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get subtasks of task %d with URL %q: %v", id, r.URL, err))
+		return
+	}
+
+	if page.Comments, err = taskStore.GetComments(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get comments on task %d with URL %q: %v", id, r.URL, err))
+		return
+	}
+
+	if page.Activity, err = taskStore.GetActivity(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get activity of task %d with URL %q: %v", id, r.URL, err))
+		return
+	}
+
+	if page.TimeEntries, err = taskStore.GetTimeEntries(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get time entries of task %d with URL %q: %v", id, r.URL, err))
+		return
+	}
+
+	owner, err := taskStore.GetOwner(id)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the owner of task %d with URL %q: %v", id, r.URL, err))
+		return
+	}
+
+	if page.Assignable, err = s.assignable(owner); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return
+	}
+
+	if s.blobStore != nil {
+		page.CanAttach = true
+
+		if page.Attachments, err = taskStore.GetAttachments(id); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get attachments of task %d with URL %q: %v", id, r.URL, err))
+			return
+		}
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	if user != nil {
+		page.User = user.Email
+
+		if page.Timer, err = taskStore.GetRunningTimer(user.Email); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the running timer of %q: %v", user.Email, err))
+			return
+		}
+	}
+
+	body, err := s.renderer.RenderTask(page)
 	writeResponse(w, body, err, r.URL)
 }
 
 func (s *Server) getTasks(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
 	user := r.PathValue("user")
-	tasks, err := s.taskStore.GetTasks(user)
+	expr, err := query.Parse(r.URL.Query().Get("q"))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	tasks, err := query.Run(taskStore, user, expr, now)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -100,10 +343,154 @@ func (s *Server) getTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, err := s.renderer.RenderTaskList(tasks)
+	// Snoozed tasks stay out of the way unless they are asked for, e.g., with "is:snoozed".
+	if !query.Mentions(expr, query.StatusSnoozed) {
+		tasks = models.HideSnoozed(tasks, now)
+	}
+
+	s.writeTaskList(w, r, taskStore, TaskListPage{User: user, Tasks: tasks})
+}
+
+func (s *Server) getReadyTasks(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	tasks, err := taskStore.GetReadyTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the ready tasks for %s: %v", r.URL, err))
+		return
+	}
+
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	s.writeTaskList(w, r, taskStore, TaskListPage{User: user, Title: "Ready", Tasks: tasks})
+}
+
+// Render a page of a user's tasks along with the user's smart lists and, if someone is signed in, the lists that are
+// shared with them.
+func (s *Server) writeTaskList(w http.ResponseWriter, r *http.Request, taskStore stores.TaskStore, page TaskListPage) {
+	savedSearches, err := taskStore.GetSavedSearches(page.User)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the saved searches for %s: %v", r.URL, err))
+		return
+	}
+
+	page.SavedSearches = savedSearches
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	page.Live = s.events != nil
+	page.Path = r.URL.RequestURI()
+
+	if user != nil {
+		page.SignedIn = user.Email
+
+		if page.SharedLists, err = taskStore.GetSharedLists(user.Email); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the lists shared with %q: %v", user.Email, err))
+			return
+		}
+	}
+
+	body, err := s.renderer.RenderTaskList(page)
+	writeResponse(w, body, err, r.URL)
+}
+
+func (s *Server) getNextActions(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	tasks, err := taskStore.GetNextActions(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the next actions for %s: %v", r.URL, err))
+		return
+	}
+
+	body, err := s.renderer.RenderNextActions(tasks)
+	writeResponse(w, body, err, r.URL)
+}
+
+func (s *Server) getSearch(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	results, err := taskStore.SearchTasks("", query)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while searching for tasks matching %q: %v", query, err))
+		return
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	// Leave out tasks in shared lists that whoever is searching is not a member of.
+	var visible []models.Task
+
+	for _, result := range results {
+		ok, err := s.canView(user, result.ID)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not check who can see task %d: %v", result.ID, err))
+			return
+		}
+
+		if ok {
+			visible = append(visible, result)
+		}
+	}
+
+	results = visible
+
+	var body []byte
+
+	// Live search only needs to replace the results, not the whole page.
+	if isHTMXRequest(r) {
+		body, err = s.renderer.RenderSearchResults(query, results)
+	} else {
+		body, err = s.renderer.RenderSearch(query, results)
+	}
+
 	writeResponse(w, body, err, r.URL)
 }
 
+// Whether the request was made by HTMX, rather than by the browser navigating to a page.
+func isHTMXRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
 func writeResponse(w http.ResponseWriter, body []byte, err error, requestURL *url.URL) {
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -118,32 +505,78 @@ func writeResponse(w http.ResponseWriter, body []byte, err error, requestURL *ur
 	}
 }
 
+// Add a task for the user from a single line of text, given either as the request body or as the `task` field of a
+// form. Due dates, tags, priorities and recurrences in the text are parsed with [quickadd.Parse] in the time zone given
+// by the `tz` query parameter.
 func (s *Server) addTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
 	user := r.PathValue("user")
-	bodyBytes, err := io.ReadAll(r.Body)
+	location := s.requestLocation(w, r)
 
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		slog.Warn(fmt.Sprintf("an error occurred while reading the request body %v: %v", r.Body, err))
+	if location == nil {
 		return
 	}
 
-	task := string(bodyBytes)
+	var text string
+
+	if r.Header.Get("Content-Type") == formContentType {
+		text = r.FormValue("task")
+	} else {
+		bodyBytes, err := io.ReadAll(r.Body)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Warn(fmt.Sprintf("an error occurred while reading the request body %v: %v", r.Body, err))
+			return
+		}
+
+		text = string(bodyBytes)
+	}
 
-	err = s.taskStore.AddTask(user, task)
+	result := quickadd.Parse(text, time.Now().In(location))
+	id, err := taskStore.AddTaskWithDetails(user, result.Description, result.Update())
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		slog.Error(fmt.Sprintf("could not add task %q for user %q: %v", user, task, err))
+		slog.Error(fmt.Sprintf("could not add task %q for user %q: %v", text, user, err))
 		return
 	}
 
+	if err := s.notifyMentions(r, id, 0, result.Description); err != nil {
+		slog.Error(fmt.Sprintf("could not notify the users mentioned in task %d: %v", id, err))
+	}
+
+	refreshHTMXPage(w, r)
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// Show the task that would be added from the text in the `task` query parameter, without adding it.
+func (s *Server) previewTask(w http.ResponseWriter, r *http.Request) {
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	result := quickadd.Parse(r.URL.Query().Get("task"), time.Now().In(location))
+	body, err := s.renderer.RenderQuickAddPreview(result)
+	writeResponse(w, body, err, r.URL)
+}
+
 const formContentType = "application/x-www-form-urlencoded"
 
 func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	userStore := s.users(w, r)
+
+	if userStore == nil {
+		return
+	}
+
 	if r.Header.Get("Content-Type") != formContentType {
 		w.WriteHeader(http.StatusUnsupportedMediaType)
 		return
@@ -172,7 +605,7 @@ func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.userStore.AddUser(email, hash)
+	err = userStore.AddUser(email, hash)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -182,3 +615,2015 @@ func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusAccepted)
 }
+
+func (s *Server) addSubtask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	parentID, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Warn(fmt.Sprintf("an error occurred while reading the request body %v: %v", r.Body, err))
+		return
+	}
+
+	description := string(bodyBytes)
+	id, err := taskStore.AddSubtask(parentID, description)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add subtask %q to task %d", description, parentID))
+		return
+	}
+
+	if err := s.notifyMentions(r, id, 0, description); err != nil {
+		slog.Error(fmt.Sprintf("could not notify the users mentioned in task %d: %v", id, err))
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) setParent(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	parentID, err := strconv.ParseUint(r.Form.Get("parent"), 10, 64)
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = taskStore.SetParent(id, parentID)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not move task %d under task %d", id, parentID))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) completeTask(w http.ResponseWriter, r *http.Request) {
+	s.setDone(w, r, true)
+}
+
+func (s *Server) reopenTask(w http.ResponseWriter, r *http.Request) {
+	s.setDone(w, r, false)
+}
+
+func (s *Server) setDone(w http.ResponseWriter, r *http.Request, done bool) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.SetDone(id, done)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not set done to %t for task %d", done, id))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) addDependency(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	blockerID, err := strconv.ParseUint(r.Form.Get("blocked_by"), 10, 64)
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = taskStore.AddDependency(id, blockerID)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not make task %d depend on task %d", id, blockerID))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) removeDependency(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	blockerID, err := strconv.ParseUint(r.PathValue("blocker"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.RemoveDependency(id, blockerID)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not remove the dependency of task %d on task %d", id, blockerID))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Parse the task ID from the `id` path parameter.
+func parseTaskID(r *http.Request) (uint64, error) {
+	return strconv.ParseUint(r.PathValue("id"), 10, 64)
+}
+
+// Respond with the HTTP status that corresponds to an error returned by a [stores.TaskStore].
+//
+// Unexpected errors are logged along with `message` and reported as an internal server error.
+func writeTaskStoreError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, stores.ErrTaskNotFound),
+		errors.Is(err, stores.ErrCommentNotFound),
+		errors.Is(err, stores.ErrAttachmentNotFound),
+		errors.Is(err, stores.ErrTimeEntryNotFound),
+		errors.Is(err, stores.ErrColumnNotFound),
+		errors.Is(err, stores.ErrTemplateNotFound),
+		errors.Is(err, stores.ErrMemberNotFound),
+		errors.Is(err, stores.ErrWorkspaceNotFound),
+		errors.Is(err, stores.ErrListNotFound),
+		errors.Is(err, stores.ErrInvitationNotFound),
+		errors.Is(err, stores.ErrWebhookNotFound),
+		errors.Is(err, stores.ErrInboundTokenNotFound),
+		errors.Is(err, stores.ErrFeedTokenNotFound),
+		errors.Is(err, stores.ErrUserNotFound),
+		errors.Is(err, stores.ErrAppPasswordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, stores.ErrNotCommentAuthor),
+		errors.Is(err, stores.ErrNotTimeEntryOwner):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, stores.ErrTimerRunning),
+		errors.Is(err, stores.ErrNoTimerRunning),
+		errors.Is(err, stores.ErrWIPLimitReached),
+		errors.Is(err, stores.ErrAlreadyInWorkspace),
+		errors.Is(err, stores.ErrLastAdmin):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, stores.ErrInvalidColumns),
+		errors.Is(err, stores.ErrMissingValue),
+		errors.Is(err, stores.ErrInvalidMember),
+		errors.Is(err, stores.ErrInvalidWorkspace),
+		errors.Is(err, stores.ErrInvalidSettings),
+		errors.Is(err, stores.ErrInvalidAssignee),
+		errors.Is(err, stores.ErrInvalidWebhook):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, stores.ErrTaskCycle),
+		errors.Is(err, stores.ErrMaxDepthExceeded),
+		errors.Is(err, stores.ErrDifferentList),
+		errors.Is(err, stores.ErrDependencyCycle),
+		errors.Is(err, stores.ErrInvalidTimeEntry):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("%s: %v", message, err))
+	}
+}
+
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	update, err := parseTaskUpdate(r.Form)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = taskStore.UpdateTask(id, update)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not update task %d", id))
+		return
+	}
+
+	var text []string
+
+	if update.Description != nil {
+		text = append(text, *update.Description)
+	}
+
+	if update.Notes != nil {
+		text = append(text, *update.Notes)
+	}
+
+	if err := s.notifyMentions(r, id, 0, strings.Join(text, "\n")); err != nil {
+		slog.Error(fmt.Sprintf("could not notify the users mentioned in task %d: %v", id, err))
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Create a task update from the fields that are present in `form`.
+//
+// Tags are separated by commas or spaces, an empty due date removes the due date, and an empty recurrence stops the
+// task from repeating.
+func parseTaskUpdate(form url.Values) (models.TaskUpdate, error) {
+	var update models.TaskUpdate
+
+	if form.Has("description") {
+		description := form.Get("description")
+		update.Description = &description
+	}
+
+	if form.Has("notes") {
+		notes := form.Get("notes")
+		update.Notes = &notes
+	}
+
+	if form.Has("tags") {
+		update.Tags = strings.FieldsFunc(form.Get("tags"), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+
+		if update.Tags == nil {
+			update.Tags = []string{}
+		}
+	}
+
+	if form.Has("priority") {
+		priority, err := strconv.Atoi(form.Get("priority"))
+
+		if err != nil {
+			return update, fmt.Errorf("the priority %q is not a whole number", form.Get("priority"))
+		}
+
+		update.Priority = &priority
+	}
+
+	if form.Has("estimate") {
+		estimate := 0.0
+
+		if form.Get("estimate") != "" {
+			var err error
+			estimate, err = strconv.ParseFloat(form.Get("estimate"), 64)
+
+			if err != nil || estimate < 0 || math.IsInf(estimate, 0) || math.IsNaN(estimate) {
+				return update, fmt.Errorf("the estimate %q is not a number greater than or equal to zero", form.Get("estimate"))
+			}
+		}
+
+		update.Estimate = &estimate
+	}
+
+	if form.Has("due") {
+		if form.Get("due") == "" {
+			update.ClearDue = true
+		} else {
+			due, err := time.ParseInLocation(time.DateOnly, form.Get("due"), time.Local)
+
+			if err != nil {
+				return update, fmt.Errorf("the due date %q is not a date like 2006-01-02", form.Get("due"))
+			}
+
+			update.Due = &due
+		}
+	}
+
+	if form.Has("repeat") {
+		if form.Get("repeat") == "" {
+			update.ClearRecurrence = true
+		} else {
+			recurrence, err := models.ParseRecurrence(form.Get("repeat"))
+
+			if err != nil {
+				return update, err
+			}
+
+			update.Recurrence = &recurrence
+		}
+	}
+
+	return update, nil
+}
+
+func (s *Server) getSavedSearch(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	savedSearch, err := s.findSavedSearch(taskStore, r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the saved search for %s: %v", r.URL, err))
+		return
+	}
+
+	if savedSearch == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	expr, err := query.Parse(savedSearch.Query)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("the saved search %q is invalid: %v", savedSearch.Name, err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	tasks, err := query.Run(taskStore, user, expr, time.Now())
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while running the saved search for %s: %v", r.URL, err))
+		return
+	}
+
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	s.writeTaskList(w, r, taskStore, TaskListPage{User: user, Title: savedSearch.Name, Tasks: tasks})
+}
+
+// Find the saved search identified by the `user` and `id` path parameters.
+//
+// Returns nil if the saved search does not exist.
+func (s *Server) findSavedSearch(taskStore stores.TaskStore, r *http.Request) (*models.SavedSearch, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	savedSearches, err := taskStore.GetSavedSearches(r.PathValue("user"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, savedSearch := range savedSearches {
+		if savedSearch.ID == id {
+			return &savedSearch, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Server) addSavedSearch(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.Form.Get("name"))
+	queryString := r.Form.Get("query")
+
+	if name == "" {
+		http.Error(w, "a saved search needs a name", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := query.Parse(queryString); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := taskStore.AddSavedSearch(user, name, queryString); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not save the search %q for user %q: %v", queryString, user, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) deleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.DeleteSavedSearch(user, id)
+
+	switch {
+	case errors.Is(err, stores.ErrSavedSearchNotFound):
+		http.NotFound(w, r)
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not delete saved search %d for user %q: %v", id, user, err))
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.DeleteTask(id)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not move task %d to the trash", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getTrash(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	tasks, err := taskStore.GetTrash(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the trash for %s: %v", r.URL, err))
+		return
+	}
+
+	body, err := s.renderer.RenderTrash(user, tasks)
+	writeResponse(w, body, err, r.URL)
+}
+
+func (s *Server) restoreTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.RestoreTask(id)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not restore task %d from the trash", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) purgeTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.PurgeTask(id)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not permanently delete task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Ask HTMX to reload the page once the request completes, e.g., after removing an item from a list.
+func refreshHTMXPage(w http.ResponseWriter, r *http.Request) {
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Refresh", "true")
+	}
+}
+
+func (s *Server) getArchive(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	tasks, err := taskStore.GetArchivedTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("an error occurred while getting the archived tasks for %s: %v", r.URL, err))
+		return
+	}
+
+	s.writeTaskList(w, r, taskStore, TaskListPage{User: user, Title: "Archive", Tasks: tasks})
+}
+
+// The key of the [authentication] of a request in its context.
+type authenticationKey struct{}
+
+// Who made a request, which is remembered for the rest of the request since checking a password is deliberately slow.
+type authentication struct {
+	done bool
+	user *models.User
+	err  error
+}
+
+// Let the handlers behind `handler` ask who made a request as often as they need to while only checking the credentials
+// of the request once, see [Server.authenticate].
+func withAuthentication(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authenticationKey{}, new(authentication))))
+	})
+}
+
+// Get the user identified by the HTTP basic auth credentials of the request. The credentials are only checked the first
+// time this is called for a request.
+//
+// Returns nil if the request does not have credentials or the credentials are wrong.
+func (s *Server) authenticate(r *http.Request) (*models.User, error) {
+	cached, ok := r.Context().Value(authenticationKey{}).(*authentication)
+
+	if !ok {
+		return s.checkCredentials(r)
+	}
+
+	if !cached.done {
+		cached.user, cached.err = s.checkCredentials(r)
+		cached.done = true
+	}
+
+	return cached.user, cached.err
+}
+
+// Get the user identified by the HTTP basic auth credentials of the request, or nil if the request does not have
+// credentials or the credentials are wrong.
+func (s *Server) checkCredentials(r *http.Request) (*models.User, error) {
+	email, password, ok := r.BasicAuth()
+
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := s.userStore.GetUserByEmail(email)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get user %q: %v", email, err)
+	}
+
+	if user == nil || user.Password == nil || user.Password.Compare(password) != nil {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// Get the user that made the request, asking the client to sign in if the request was not authenticated.
+//
+// Returns nil if a response has already been written.
+func (s *Server) requireUser(w http.ResponseWriter, r *http.Request) *models.User {
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return nil
+	}
+
+	if user == nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="yatta", charset="UTF-8"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil
+	}
+
+	return user
+}
+
+// Ask the browser to sign in, then send the user back to the page given by the `next` query parameter.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if user := s.requireUser(w, r); user == nil {
+		return
+	}
+
+	next := r.URL.Query().Get("next")
+
+	// Only redirect to pages on this site.
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// Parse the body of a comment from a form.
+//
+// Returns an empty string if a response has already been written.
+func parseCommentBody(w http.ResponseWriter, r *http.Request) string {
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return ""
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return ""
+	}
+
+	body := strings.TrimSpace(r.Form.Get("body"))
+
+	if body == "" {
+		http.Error(w, "a comment cannot be empty", http.StatusBadRequest)
+	}
+
+	return body
+}
+
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	body := parseCommentBody(w, r)
+
+	if body == "" {
+		return
+	}
+
+	comment, err := taskStore.AddComment(id, user.Email, body)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add a comment to task %d", id))
+		return
+	}
+
+	if err := s.notifyMentions(r, id, comment.ID, body); err != nil {
+		slog.Error(fmt.Sprintf("could not notify the users mentioned in comment %d: %v", comment.ID, err))
+	}
+
+	s.writeComment(w, r, *comment, user.Email)
+}
+
+func (s *Server) updateComment(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	body := parseCommentBody(w, r)
+
+	if body == "" {
+		return
+	}
+
+	comment, err := taskStore.UpdateComment(id, user.Email, body)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not update comment %d", id))
+		return
+	}
+
+	if err := s.notifyMentions(r, comment.TaskID, comment.ID, body); err != nil {
+		slog.Error(fmt.Sprintf("could not notify the users mentioned in comment %d: %v", comment.ID, err))
+	}
+
+	s.writeComment(w, r, *comment, user.Email)
+}
+
+// Respond with the HTML for `comment` so that HTMX can add it to the page, or just acknowledge the change otherwise.
+func (s *Server) writeComment(w http.ResponseWriter, r *http.Request, comment models.Comment, user string) {
+	if !isHTMXRequest(r) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	body, err := s.renderer.RenderComment(comment, user)
+	writeResponse(w, body, err, r.URL)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	err = taskStore.DeleteComment(id, user.Email)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete comment %d", id))
+		return
+	}
+
+	// HTMX replaces the comment with the empty response body, removing it from the page.
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// The media types of attachments that browsers can safely display instead of downloading.
+var inlineContentTypes = []string{
+	"application/pdf",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"text/plain; charset=utf-8",
+}
+
+func (s *Server) addAttachments(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+
+	if err != nil {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Check that the task exists before storing any files for it.
+	task, err := taskStore.GetTask(id)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get task %d: %v", id, err))
+		return
+	}
+
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	added := 0
+
+	for {
+		part, err := reader.NextPart()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("could not read the uploaded files: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue
+		}
+
+		_, err = s.storeAttachment(taskStore, id, part.FileName(), part)
+
+		switch {
+		case errors.Is(err, stores.ErrBlobTooLarge):
+			http.Error(w, fmt.Sprintf("the file %q is too large", part.FileName()), http.StatusRequestEntityTooLarge)
+			return
+		case err != nil:
+			writeTaskStoreError(w, err, fmt.Sprintf("could not attach %q to task %d", part.FileName(), id))
+			return
+		}
+
+		added++
+	}
+
+	if added == 0 {
+		http.Error(w, `no files were uploaded in the "file" field`, http.StatusBadRequest)
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Store the file called `name` with the contents in `file` and attach it to the task with `taskID`.
+func (s *Server) storeAttachment(taskStore stores.TaskStore, taskID uint64, name string, file io.Reader) (*models.Attachment, error) {
+	// Detect the type from the contents rather than trusting the type sent by the client.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("could not read the uploaded file: %v", err)
+	}
+
+	head = head[:n]
+	key, size, err := s.blobStore.Put(io.MultiReader(bytes.NewReader(head), file))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return taskStore.AddAttachment(models.Attachment{
+		TaskID:      taskID,
+		Name:        name,
+		ContentType: http.DetectContentType(head),
+		Size:        size,
+		Key:         key,
+		UploadedAt:  time.Now(),
+	})
+}
+
+func (s *Server) getAttachment(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	attachment, err := taskStore.GetAttachment(id)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get attachment %d: %v", id, err))
+		return
+	}
+
+	if attachment == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	blob, err := s.blobStore.Open(attachment.Key)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not open the contents of attachment %d: %v", id, err))
+		return
+	}
+
+	defer blob.Close()
+
+	disposition := "attachment"
+
+	if slices.Contains(inlineContentTypes, attachment.ContentType) {
+		disposition = "inline"
+	}
+
+	// Encodes names that are not plain ASCII as described in RFC 2231.
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}); header != "" {
+		disposition = header
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", disposition)
+	// Stop browsers from running scripts in uploaded files.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+
+	if seeker, ok := blob.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, attachment.Name, attachment.UploadedAt, seeker)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+
+	if _, err := io.Copy(w, blob); err != nil {
+		slog.Error(fmt.Sprintf("an error occurred while writing attachment %d: %v", id, err))
+	}
+}
+
+func (s *Server) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = taskStore.DeleteAttachment(id)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete attachment %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) startTimer(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if _, err := taskStore.StartTimer(id, user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not start a timer on task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) stopTimer(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if _, err := taskStore.StopTimer(user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not stop the timer of %q", user.Email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// The layout of the start time in forms for entering time by hand, which matches the value of a
+// datetime-local input.
+const timeEntryStartLayout = "2006-01-02T15:04"
+
+func (s *Server) addTimeEntry(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	start, err := time.ParseInLocation(timeEntryStartLayout, r.Form.Get("start"), time.Local)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid start time %q", r.Form.Get("start")), http.StatusBadRequest)
+		return
+	}
+
+	duration, err := time.ParseDuration(r.Form.Get("duration"))
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid duration %q, use a duration such as 1h30m", r.Form.Get("duration")), http.StatusBadRequest)
+		return
+	}
+
+	end := start.Add(duration)
+	entry := models.TimeEntry{TaskID: id, User: user.Email, Start: start, End: &end, Note: strings.TrimSpace(r.Form.Get("note"))}
+
+	if _, err := taskStore.AddTimeEntry(entry); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add a time entry to task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) deleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if err := taskStore.DeleteTimeEntry(id, user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete time entry %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getTimesheet(w http.ResponseWriter, r *http.Request) {
+	page := s.findTimesheet(w, r)
+
+	if page == nil {
+		return
+	}
+
+	body, err := s.renderer.RenderTimesheet(*page)
+	writeResponse(w, body, err, r.URL)
+}
+
+// Write the signed in user's time entries in a date range as a CSV file, e.g., for creating invoices.
+func (s *Server) exportTimesheet(w http.ResponseWriter, r *http.Request) {
+	page := s.findTimesheet(w, r)
+
+	if page == nil {
+		return
+	}
+
+	name := fmt.Sprintf("timesheet-%s-%s.csv", page.From.Format(time.DateOnly), page.To.Format(time.DateOnly))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+
+	writer := csv.NewWriter(w)
+	records := [][]string{{"Date", "Task ID", "Task", "Start", "End", "Hours", "Note"}}
+
+	for _, entry := range page.Entries {
+		end := ""
+
+		if entry.End != nil {
+			end = entry.End.Format(time.RFC3339)
+		}
+
+		records = append(records, []string{
+			entry.Start.Format(time.DateOnly),
+			strconv.FormatUint(entry.TaskID, 10),
+			csvText(page.Tasks[entry.TaskID].Description),
+			entry.Start.Format(time.RFC3339),
+			end,
+			fmt.Sprintf("%.2f", entry.Duration(page.Now).Hours()),
+			csvText(entry.Note),
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		slog.Error(fmt.Sprintf("an error occurred while writing the timesheet for %q: %v", page.User, err))
+	}
+}
+
+// Make `text` safe to put in a cell of a CSV file. Spreadsheets run cells that start with "=", "+", "-" or "@" as
+// formulas, as well as those that start with a tab or carriage return followed by one, so these cells are started
+// with a "'" to keep them as text.
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+
+	return text
+}
+
+// Get the signed in user's time entries between the dates given by the `from` and `to` query parameters, which
+// default to the current week, along with the tasks that the time was spent on.
+//
+// Returns nil if a response has already been written.
+func (s *Server) findTimesheet(w http.ResponseWriter, r *http.Request) *TimesheetPage {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return nil
+	}
+
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return nil
+	}
+
+	now := time.Now()
+	// Weeks start on Monday.
+	monday := today(now).AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	from, to, err := parseDateRange(r, monday, monday.AddDate(0, 0, 6))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	// Include the time spent on the last day.
+	entries, err := taskStore.GetTimesheet(user.Email, from, to.AddDate(0, 0, 1))
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the timesheet of %q: %v", user.Email, err))
+		return nil
+	}
+
+	tasks := make(map[uint64]models.Task)
+
+	for _, entry := range entries {
+		if _, ok := tasks[entry.TaskID]; ok {
+			continue
+		}
+
+		task, err := taskStore.GetTask(entry.TaskID)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get task %d: %v", entry.TaskID, err))
+			return nil
+		}
+
+		// Tasks in the trash are left out, but the time spent on them is still shown.
+		if task != nil {
+			tasks[entry.TaskID] = *task
+		}
+	}
+
+	return &TimesheetPage{User: user.Email, From: from, To: to, Entries: entries, Tasks: tasks, Now: now}
+}
+
+// Get midnight at the start of the day of `now` in the local time zone.
+func today(now time.Time) time.Time {
+	year, month, day := now.In(time.Local).Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// Parse the first and last days shown on a page from the `from` and `to` query parameters, using the dates `from`
+// and `to` for missing parameters.
+func parseDateRange(r *http.Request, from time.Time, to time.Time) (time.Time, time.Time, error) {
+	for name, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := r.URL.Query().Get(name)
+
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+
+		if err != nil {
+			return from, to, fmt.Errorf("invalid date %q for %q, use a date such as 2025-03-01", value, name)
+		}
+
+		*date = parsed
+	}
+
+	if to.Before(from) {
+		return from, to, errors.New("the last day cannot be before the first day")
+	}
+
+	return from, to, nil
+}
+
+func (s *Server) getReport(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	// Show the last four weeks by default.
+	to := today(time.Now())
+	from, to, err := parseDateRange(r, to.AddDate(0, 0, -27), to)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks for %q: %v", user, err))
+		return
+	}
+
+	if tasks == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Completed tasks are archived after a while, but still count towards the report.
+	archive, err := taskStore.GetArchivedTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the archived tasks for %q: %v", user, err))
+		return
+	}
+
+	tasks = append(tasks, archive...)
+	history, err := taskStore.GetCompletionHistory(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the completion history for %q: %v", user, err))
+		return
+	}
+
+	body, err := s.renderer.RenderReport(ReportPage{
+		User:       user,
+		From:       from,
+		To:         to,
+		Burndown:   report.Burndown(tasks, history, from, to),
+		Throughput: report.Throughput(tasks, from, to),
+	})
+	writeResponse(w, body, err, r.URL)
+}
+
+func (s *Server) getBoard(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	tasks, err := taskStore.GetTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks for %q: %v", user, err))
+		return
+	}
+
+	if tasks == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	columns, err := taskStore.GetColumns(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the board columns for %q: %v", user, err))
+		return
+	}
+
+	body, err := s.renderer.RenderBoard(BoardPage{User: user, Columns: columns, Tasks: models.HideSnoozed(tasks, time.Now())})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Replace the columns of a user's board with the columns in the form, where each column is given by the values at the
+// same position in the "id", "name", "limit" and "kind" fields. Columns without a name are removed.
+func (s *Server) setColumns(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ids, names, limits, kinds := r.Form["id"], r.Form["name"], r.Form["limit"], r.Form["kind"]
+
+	if len(names) != len(ids) || len(limits) != len(ids) || len(kinds) != len(ids) {
+		http.Error(w, `every column needs an "id", "name", "limit" and "kind"`, http.StatusBadRequest)
+		return
+	}
+
+	var columns []models.Column
+
+	for i := range ids {
+		if strings.TrimSpace(names[i]) == "" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(ids[i], 10, 64)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid column ID %q", ids[i]), http.StatusBadRequest)
+			return
+		}
+
+		limit := 0
+
+		if limits[i] != "" {
+			if limit, err = strconv.Atoi(limits[i]); err != nil {
+				http.Error(w, fmt.Sprintf("the WIP limit %q is not a whole number", limits[i]), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if kinds[i] != "open" && kinds[i] != "done" {
+			http.Error(w, fmt.Sprintf(`invalid column kind %q, expected "open" or "done"`, kinds[i]), http.StatusBadRequest)
+			return
+		}
+
+		columns = append(columns, models.Column{ID: id, Name: names[i], WIPLimit: limit, Done: kinds[i] == "done"})
+	}
+
+	if err := taskStore.SetColumns(user, columns); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not set the board columns for %q", user))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Move the task given by the "task" field of the form to a column on a user's board, e.g., when the task is dropped
+// on the column.
+func (s *Server) moveTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	column, err := strconv.ParseUint(r.PathValue("column"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.Form.Get("task"), 10, 64)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid task ID %q", r.Form.Get("task")), http.StatusBadRequest)
+		return
+	}
+
+	if err := taskStore.MoveTask(user, id, column); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not move task %d to column %d", id, column))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Get the scheme and host that `r` was sent to, e.g., "https://yatta.example.com", for making links that are used
+// outside of Yatta.
+func baseURL(r *http.Request) string {
+	if r.TLS != nil {
+		return "https://" + r.Host
+	}
+
+	return "http://" + r.Host
+}
+
+// Get the time zone that dates and times in the request are in, which is the time zone in the `tz` query parameter,
+// e.g., the time zone of the browser, or else the time zone of the workspace of whoever made the request, or else the
+// time zone of the server.
+//
+// Returns nil if a response has already been written.
+func (s *Server) requestLocation(w http.ResponseWriter, r *http.Request) *time.Location {
+	if name := r.URL.Query().Get("tz"); name != "" {
+		location, err := time.LoadLocation(name)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unknown time zone %q, use a time zone such as Pacific/Auckland", name), http.StatusBadRequest)
+			return nil
+		}
+
+		return location
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return nil
+	}
+
+	if user == nil {
+		return time.Local
+	}
+
+	settings, err := s.workspaceSettings(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace settings of %q: %v", user.Email, err))
+		return nil
+	}
+
+	return settings.Location(time.Local)
+}
+
+// Show the tasks that are due in the month or week, given by the `view` query parameter, that contains the `date`
+// query parameter, which defaults to today.
+func (s *Server) getCalendar(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	now := time.Now().In(location)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	if value := r.URL.Query().Get("date"); value != "" {
+		var err error
+
+		if date, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			http.Error(w, fmt.Sprintf("invalid date %q, use a date such as 2025-03-01", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	view := r.URL.Query().Get("view")
+	var from, to time.Time
+
+	switch view {
+	case "", "month":
+		view = "month"
+		from, to = calendar.Month(date)
+	case "week":
+		from, to = calendar.Week(date)
+	default:
+		http.Error(w, fmt.Sprintf(`invalid view %q, expected "month" or "week"`, view), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks for %q: %v", user, err))
+		return
+	}
+
+	if tasks == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := s.renderer.RenderCalendar(CalendarPage{
+		User:     user,
+		View:     view,
+		Date:     date,
+		Today:    now,
+		TimeZone: r.URL.Query().Get("tz"),
+		Days:     calendar.Days(tasks, from, to),
+	})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Move the due date of the task given by the "task" field of the form to the `date` path parameter, e.g., when the
+// task is dropped on a day of the calendar. The task stays due at the same time of day in the time zone given by the
+// `tz` query parameter.
+func (s *Server) rescheduleTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, r.PathValue("date"), location)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.Form.Get("task"), 10, 64)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid task ID %q", r.Form.Get("task")), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := taskStore.GetTasks(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the tasks for %q: %v", user, err))
+		return
+	}
+
+	i := slices.IndexFunc(tasks, func(task models.Task) bool { return task.ID == id })
+
+	if i == -1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	due := date
+
+	if previous := tasks[i].Due; previous != nil {
+		hour, minute, second := previous.In(location).Clock()
+		due = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, 0, location)
+	}
+
+	if err := taskStore.UpdateTask(id, models.TaskUpdate{Due: &due}); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not reschedule task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Parse when a snoozed task should show up again, which is either "tomorrow", "next-week" for the coming Monday, or
+// a date such as 2025-03-01. Tasks show up again at the start of the day in the time zone of `now`.
+func parseSnooze(value string, now time.Time) (time.Time, error) {
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	case "next-week":
+		return midnight.AddDate(0, 0, 7-(int(midnight.Weekday())+6)%7), nil
+	}
+
+	until, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+
+	if err != nil {
+		return until, fmt.Errorf(`invalid snooze %q, use "tomorrow", "next-week" or a date such as 2025-03-01`, value)
+	}
+
+	if !until.After(now) {
+		return until, fmt.Errorf("cannot snooze a task until %s, which has already started", value)
+	}
+
+	return until, nil
+}
+
+// Hide a task from the user's lists until the time given by the "until" field of the form, see [parseSnooze].
+func (s *Server) snoozeTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	until, err := parseSnooze(r.Form.Get("until"), time.Now().In(location))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := taskStore.UpdateTask(id, models.TaskUpdate{HiddenUntil: &until}); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not snooze task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Show a snoozed task again straight away.
+func (s *Server) unsnoozeTask(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := taskStore.UpdateTask(id, models.TaskUpdate{ClearHiddenUntil: true}); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not unsnooze task %d", id))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// The prefix of the form fields that hold the values of the placeholders in a template, e.g., "value.version".
+const placeholderFieldPrefix = "value."
+
+func (s *Server) getTemplates(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	templates, err := taskStore.GetTemplates(user)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the templates for %q: %v", user, err))
+		return
+	}
+
+	body, err := s.renderer.RenderTemplates(TemplatesPage{User: user, Templates: templates, Today: today(time.Now())})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Save a task and its subtasks as a template with the name in the "name" field of the form.
+func (s *Server) addTemplate(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	id, err := parseTaskID(r)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.Form.Get("name"))
+
+	if name == "" {
+		http.Error(w, "a template needs a name", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := taskStore.AddTemplate(id, name); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not save task %d as a template", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Add the tasks in a template to the user's task list, with due dates counted from the "date" field of the form,
+// which defaults to today, in the time zone given by the `tz` query parameter. The value of each placeholder is given
+// by the field with the name of the placeholder after [placeholderFieldPrefix].
+//
+// HTMX requests are redirected to the new top-level task.
+func (s *Server) useTemplate(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	location := s.requestLocation(w, r)
+
+	if location == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().In(location)
+	anchor := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	if value := r.Form.Get("date"); value != "" {
+		if anchor, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			http.Error(w, fmt.Sprintf("invalid date %q, use a date such as 2025-03-01", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	values := map[string]string{}
+
+	for field := range r.Form {
+		if name, ok := strings.CutPrefix(field, placeholderFieldPrefix); ok {
+			values[name] = strings.TrimSpace(r.Form.Get(field))
+		}
+	}
+
+	taskID, err := taskStore.UseTemplate(user, id, anchor, values)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not use template %d for user %q", id, user))
+		return
+	}
+
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Redirect", fmt.Sprintf("/tasks/%d", taskID))
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	user := r.PathValue("user")
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := taskStore.DeleteTemplate(user, id); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete template %d for user %q", id, user))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
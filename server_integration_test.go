@@ -1,10 +1,21 @@
 package main_test
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	yatta "github.com/AnthonyDickson/yatta"
 	"github.com/AnthonyDickson/yatta/models"
 	"github.com/AnthonyDickson/yatta/stores"
 	"github.com/AnthonyDickson/yatta/yattatest"
@@ -89,3 +100,273 @@ func assertStoreHasUser(t *testing.T, store *stores.FileUserStore, want_id uint6
 		t.Errorf("hash %s does not verify password %q", got_user.Password, want.Password)
 	}
 }
+
+func TestCalDAV(t *testing.T) {
+	taskStore, cleanupTaskDatabase := mustCreateFileTaskStore(t, "")
+	defer cleanupTaskDatabase()
+
+	userStore, cleanupUserDatabase := mustCreateFileUserStore(t, "")
+	defer cleanupUserDatabase()
+
+	server := mustCreateServer(t, taskStore, userStore, mustCreateRenderer(t))
+
+	for _, name := range []string{"alice", "bob"} {
+		yattatest.AssertNoError(t, userStore.AddUser(name+"@example.com", yattatest.MustCreatePasswordHash(t, name)))
+	}
+
+	// The recorded requests sign in with this app password.
+	_, err := userStore.AddAppPassword("alice@example.com", "Phone", yattatest.MustCreatePasswordHash(t, "abcd-efgh-ijkm-npqr-stuv-wxyz"))
+	yattatest.AssertNoError(t, err)
+
+	due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	rent, err := taskStore.AddTaskWithDetails("alice@example.com", "Pay rent", models.TaskUpdate{Due: &due})
+	yattatest.AssertNoError(t, err)
+	taxes, err := taskStore.AddTaskWithDetails("alice@example.com", "File taxes", models.TaskUpdate{})
+	yattatest.AssertNoError(t, err)
+	yattatest.AssertNoError(t, taskStore.SetDone(taxes, true))
+	_, err = taskStore.AddTaskWithDetails("bob@example.com", "Mow the lawn", models.TaskUpdate{})
+	yattatest.AssertNoError(t, err)
+	yattatest.AssertNoError(t, taskStore.SetMember("bob@example.com", models.Member{Email: "alice@example.com", Role: models.RoleViewer}))
+
+	if rent != 1 {
+		t.Fatalf("got ID %d for the first task, want 1 as in the recorded requests", rent)
+	}
+
+	// The ETag of the last calendar object that was fetched, which replaces "{{etag}}" in the recorded requests since
+	// ETags change with the time that tasks are made.
+	var etag string
+	objectPath := "/caldav/calendars/alice@example.com/alice@example.com/9b1e4c1e-5f0a-4d7b-8a53-2f6c1d0e7a10.ics"
+
+	// Requests recorded from a CalDAV client syncing Alice's lists, replayed in order.
+	steps := []struct {
+		file   string
+		status int
+		// Text that the body of the response must have.
+		want []string
+		// Text that the body of the response must not have.
+		notWant []string
+		// Checks the tasks after the request.
+		check func(t *testing.T, tasks []models.Task)
+	}{
+		{file: "01_propfind_well_known.http", status: http.StatusMovedPermanently},
+		{file: "02_propfind_root.http", status: http.StatusMultiStatus, want: []string{
+			`<current-user-principal xmlns="DAV:"><href xmlns="DAV:">/caldav/principals/alice@example.com/</href></current-user-principal>`,
+			`<resourcetype xmlns="DAV:"><collection xmlns="DAV:"/></resourcetype>`,
+		}},
+		{file: "03_propfind_principal.http", status: http.StatusMultiStatus, want: []string{
+			`<calendar-home-set xmlns="urn:ietf:params:xml:ns:caldav"><href xmlns="DAV:">/caldav/calendars/alice@example.com/</href></calendar-home-set>`,
+			`<href xmlns="DAV:">mailto:alice@example.com</href>`,
+		}},
+		{file: "04_propfind_home.http", status: http.StatusMultiStatus, want: []string{
+			"<href>/caldav/calendars/alice@example.com/</href>",
+			"<href>/caldav/calendars/alice@example.com/alice@example.com/</href>",
+			"<href>/caldav/calendars/alice@example.com/bob@example.com/</href>",
+			`<calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`,
+			`<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VTODO"/>`,
+			`<getctag xmlns="http://calendarserver.org/ns/">`,
+			`<write xmlns="DAV:"/>`,
+			// Calendar colours are not supported.
+			`<calendar-color xmlns="http://apple.com/ns/ical/"></calendar-color></prop><status>HTTP/1.1 404 Not Found</status>`,
+		}},
+		{
+			file:    "05_report_query.http",
+			status:  http.StatusMultiStatus,
+			want:    []string{"<href>/caldav/calendars/alice@example.com/alice@example.com/1.ics</href>", `<getetag xmlns="DAV:">&#34;`},
+			notWant: []string{"2.ics", "calendar-data"},
+		},
+		{file: "06_report_multiget.http", status: http.StatusMultiStatus, want: []string{
+			// Line breaks are escaped so that XML parsers keep the CRs.
+			"SUMMARY:Pay rent&#xD;&#xA;",
+			"DUE;VALUE=DATE:20250314&#xD;&#xA;",
+			"<href>/caldav/calendars/alice%40example.com/alice%40example.com/missing.ics</href><status>HTTP/1.1 404 Not Found</status>",
+		}},
+		{file: "07_put_create.http", status: http.StatusCreated, check: func(t *testing.T, tasks []models.Task) {
+			task := tasks[len(tasks)-1]
+			wantDue := time.Date(2025, 3, 15, 0, 0, 0, 0, time.Local)
+
+			if task.Description != "Buy milk" || task.Notes != "Oat, not soy" || !slices.Equal(task.Tags, []string{"errands"}) ||
+				task.Due == nil || !task.Due.Equal(wantDue) || task.Done {
+				t.Errorf("got %+v, want an open task to buy milk", task)
+			}
+		}},
+		// Clients use If-None-Match to avoid overwriting objects that another client made.
+		{file: "07_put_create.http", status: http.StatusPreconditionFailed},
+		{file: "08_get.http", status: http.StatusOK, want: []string{
+			"UID:9b1e4c1e-5f0a-4d7b-8a53-2f6c1d0e7a10\r\n",
+			"STATUS:NEEDS-ACTION\r\n",
+		}},
+		{file: "09_put_complete.http", status: http.StatusNoContent, check: func(t *testing.T, tasks []models.Task) {
+			if task := tasks[len(tasks)-1]; !task.Done {
+				t.Errorf("got %+v, want the task to be done", task)
+			}
+		}},
+		// The ETag from before the task was completed no longer matches.
+		{file: "10_put_stale.http", status: http.StatusPreconditionFailed},
+		{file: "08_get.http", status: http.StatusOK, want: []string{"STATUS:COMPLETED\r\n"}},
+		{file: "11_delete.http", status: http.StatusNoContent, check: func(t *testing.T, tasks []models.Task) {
+			if len(tasks) != 2 {
+				t.Errorf("got %+v, want the task to be deleted", tasks)
+			}
+		}},
+		{file: "08_get.http", status: http.StatusNotFound},
+		// Alice may only view Bob's list.
+		{file: "12_put_viewer.http", status: http.StatusForbidden},
+		{file: "13_propfind_password.http", status: http.StatusUnauthorized},
+		{file: "14_options.http", status: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		request := mustReadRecordedRequest(t, filepath.Join("testdata", "caldav", step.file), etag)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		if response.Code != step.status {
+			t.Fatalf("%s: got status %d, want %d: %s", step.file, response.Code, step.status, response.Body)
+		}
+
+		if request.URL.Path == objectPath && request.Method == http.MethodGet && response.Code == http.StatusOK {
+			etag = response.Header().Get("ETag")
+		}
+
+		body := response.Body.String()
+
+		for _, want := range step.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: could not find %q in\n%s", step.file, want, body)
+			}
+		}
+
+		for _, notWant := range step.notWant {
+			if strings.Contains(body, notWant) {
+				t.Errorf("%s: found %q in\n%s", step.file, notWant, body)
+			}
+		}
+
+		if step.check != nil {
+			tasks, err := taskStore.GetTasks("alice@example.com")
+			yattatest.AssertNoError(t, err)
+			step.check(t, tasks)
+		}
+	}
+}
+
+// Read the raw HTTP request at `path`, replacing "{{etag}}" with `etag`, which is quoted.
+func mustReadRecordedRequest(t *testing.T, path string, etag string) *http.Request {
+	t.Helper()
+
+	recorded, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("could not read the recorded request: %v", err)
+	}
+
+	recorded = bytes.ReplaceAll(recorded, []byte(`"{{etag}}"`), []byte(etag))
+	header, body, _ := bytes.Cut(recorded, []byte("\r\n\r\n"))
+	request, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(append(header, "\r\n\r\n"...))))
+
+	if err != nil {
+		t.Fatalf("could not parse the recorded request %q: %v", path, err)
+	}
+
+	// The recordings do not have a Content-Length header, so that they can be edited by hand.
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.ContentLength = int64(len(body))
+
+	return request
+}
+
+func TestRunImport(t *testing.T) {
+	taskStore, cleanupTaskDatabase := mustCreateFileTaskStore(t, "")
+	defer cleanupTaskDatabase()
+
+	userStore, cleanupUserDatabase := mustCreateFileUserStore(t, "")
+	defer cleanupUserDatabase()
+
+	email := "alice@example.com"
+	yattatest.AssertNoError(t, userStore.AddUser(email, yattatest.MustCreatePasswordHash(t, "alice")))
+
+	export := filepath.Join(t.TempDir(), "todoist.json")
+	yattatest.AssertNoError(t, os.WriteFile(export, []byte(`[
+		{"id": "1", "content": "Launch", "priority": 4, "labels": ["work"], "due": {"date": "2025-03-14"}},
+		{"id": "2", "parent_id": "1", "content": "Write docs", "is_completed": true}
+	]`), 0o600))
+
+	t.Run("preview the tasks", func(t *testing.T) {
+		stdout := new(bytes.Buffer)
+		err := yatta.RunImport([]string{"-user", email, "-format", "todoist", "-dry-run", export}, taskStore, userStore, stdout)
+		yattatest.AssertNoError(t, err)
+
+		want := "[ ] Launch (due 2025-03-14, priority 3, #work)\n  [x] Write docs\n2 tasks would be imported into the list of alice@example.com\n"
+
+		if stdout.String() != want {
+			t.Errorf("got %q, want %q", stdout.String(), want)
+		}
+
+		if tasks, err := taskStore.GetTasks(email); err != nil || len(tasks) != 0 {
+			t.Errorf("got tasks %v and error %v, want no tasks", tasks, err)
+		}
+	})
+
+	t.Run("import the tasks", func(t *testing.T) {
+		stdout := new(bytes.Buffer)
+		err := yatta.RunImport([]string{"-user", email, "-format", "todoist", "-tz", "Pacific/Auckland", export}, taskStore, userStore, stdout)
+		yattatest.AssertNoError(t, err)
+
+		if want := "imported 2 tasks into the list of alice@example.com\n"; stdout.String() != want {
+			t.Errorf("got %q, want %q", stdout.String(), want)
+		}
+
+		tasks, err := taskStore.GetTasks(email)
+		yattatest.AssertNoError(t, err)
+
+		if len(tasks) != 2 {
+			t.Fatalf("got %d tasks, want 2", len(tasks))
+		}
+
+		launch, docs := tasks[0], tasks[1]
+
+		if launch.Description != "Launch" || launch.Priority != 3 || !slices.Equal(launch.Tags, []string{"work"}) || launch.Done {
+			t.Errorf("got %+v, want Launch with its priority and tags", launch)
+		}
+
+		if launch.Due == nil || launch.Due.Location().String() != "Pacific/Auckland" {
+			t.Errorf("got due %v, want a date in Pacific/Auckland", launch.Due)
+		}
+
+		if docs.Description != "Write docs" || docs.ParentID != launch.ID || !docs.Done {
+			t.Errorf("got %+v, want a done subtask of Launch", docs)
+		}
+	})
+
+	t.Run("reject unknown users", func(t *testing.T) {
+		err := yatta.RunImport([]string{"-user", "bob@example.com", "-format", "todoist", export}, taskStore, userStore, io.Discard)
+
+		if err == nil {
+			t.Error("got no error, want an error for a user that does not exist")
+		}
+	})
+	t.Run("reject tasks that are nested too deeply, even in a preview", func(t *testing.T) {
+		items := []string{`{"id": "1", "content": "Level 1"}`}
+
+		for level := 2; level <= stores.MaxTaskDepth+1; level++ {
+			items = append(items, fmt.Sprintf(`{"id": "%d", "parent_id": "%d", "content": "Level %d"}`, level, level-1, level))
+		}
+
+		deep := filepath.Join(t.TempDir(), "deep.json")
+		yattatest.AssertNoError(t, os.WriteFile(deep, []byte("["+strings.Join(items, ",")+"]"), 0o600))
+		before, err := taskStore.GetTasks(email)
+		yattatest.AssertNoError(t, err)
+
+		for _, args := range [][]string{{"-dry-run", deep}, {deep}} {
+			err := yatta.RunImport(append([]string{"-user", email, "-format", "todoist"}, args...), taskStore, userStore, io.Discard)
+
+			if !errors.Is(err, stores.ErrMaxDepthExceeded) {
+				t.Errorf("got error %v for %v, want %v", err, args, stores.ErrMaxDepthExceeded)
+			}
+		}
+
+		if after, _ := taskStore.GetTasks(email); len(after) != len(before) {
+			t.Errorf("got %d tasks, want the %d tasks from before", len(after), len(before))
+		}
+	})
+}
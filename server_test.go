@@ -1,16 +1,31 @@
 package main_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	yatta "github.com/AnthonyDickson/yatta"
+	"github.com/AnthonyDickson/yatta/events"
 	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/quickadd"
 	"github.com/AnthonyDickson/yatta/stores"
 	"github.com/AnthonyDickson/yatta/yattatest"
 )
@@ -125,7 +140,7 @@ func TestGetTask(t *testing.T) {
 		assertStatus(t, response, http.StatusOK)
 		assertContentType(t, response, htmlContentType)
 		assertGetTaskCall(t, store, want[0])
-		assertRenderTaskCall(t, renderer, want[0])
+		assertRenderTaskCall(t, renderer, want[0], nil)
 	})
 
 	t.Run("get task by invalid ID returns 404 not found", func(t *testing.T) {
@@ -192,6 +207,3784 @@ func TestCreateTasks(t *testing.T) {
 	})
 }
 
+func TestQuickAdd(t *testing.T) {
+	t.Run("parses the details of the task", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := newCreateTasksRequest(t, "Alice", "Pay rent 2030-03-01 9am #home !high every month")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+		assertAddTaskCalls(t, store, []addTaskCall{{"Alice", "Pay rent"}})
+
+		got := store.addDetails[0]
+		due := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.Local)
+
+		if !slices.Equal(got.Tags, []string{"home"}) {
+			t.Errorf("got tags %q, want %q", got.Tags, []string{"home"})
+		}
+
+		if got.Priority == nil || *got.Priority != 3 {
+			t.Errorf("got priority %v, want 3", got.Priority)
+		}
+
+		if got.Due == nil || !got.Due.Equal(due) {
+			t.Errorf("got due date %v, want %v", got.Due, due)
+		}
+
+		if want := (models.Recurrence{Frequency: models.Monthly, Interval: 1}); got.Recurrence == nil || *got.Recurrence != want {
+			t.Errorf("got recurrence %v, want %v", got.Recurrence, want)
+		}
+	})
+
+	t.Run("parses dates in the given time zone", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+		location, _ := time.LoadLocation("Pacific/Auckland")
+
+		request := httptest.NewRequest(http.MethodPost, "/users/Alice/tasks?tz=Pacific%2FAuckland", strings.NewReader("deploy 2030-03-01"))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got, want := store.addDetails[0].Due, time.Date(2030, time.March, 1, 0, 0, 0, 0, location); got == nil || !got.Equal(want) {
+			t.Errorf("got due date %v, want %v", got, want)
+		}
+	})
+
+	t.Run("adds a task from a form", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := newFormRequest(t, http.MethodPost, "/users/Alice/tasks", url.Values{"task": {"deploy #work"}}.Encode())
+		request.Header.Set("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+		assertAddTaskCalls(t, store, []addTaskCall{{"Alice", "deploy"}})
+
+		if got := store.addDetails[0].Tags; !slices.Equal(got, []string{"work"}) {
+			t.Errorf("got tags %q, want %q", got, []string{"work"})
+		}
+
+		if got := response.Header().Get("HX-Refresh"); got != "true" {
+			t.Errorf("got HX-Refresh header %q, want %q", got, "true")
+		}
+	})
+
+	t.Run("rejects unknown time zones", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/users/Alice/tasks?tz=Mars", strings.NewReader("deploy"))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+		assertAddTaskCalls(t, store, nil)
+	})
+
+	t.Run("previews a task without adding it", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/users/Alice/tasks/preview?task=deploy+%23work+%21high", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+		assertAddTaskCalls(t, store, nil)
+
+		if len(renderer.renderQuickAddCalls) != 1 {
+			t.Fatalf("got %d calls to render the preview, want 1", len(renderer.renderQuickAddCalls))
+		}
+
+		got := renderer.renderQuickAddCalls[0]
+
+		if got.Description != "deploy" || !slices.Equal(got.Tags, []string{"work"}) || got.Priority != 3 {
+			t.Errorf("got preview of %+v, want the task %q tagged %q with priority 3", got, "deploy", "work")
+		}
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	templates := map[string][]models.Template{"Alice": {{ID: 1, Name: "Release", Task: models.TemplateTask{Description: "release {{version}}"}}}}
+
+	t.Run("get templates", func(t *testing.T) {
+		store := &StubTaskStore{templates: templates}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/templates", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderTemplatesCalls) != 1 {
+			t.Fatalf("got %d calls to RenderTemplates, want 1", len(renderer.renderTemplatesCalls))
+		}
+
+		if got := renderer.renderTemplatesCalls[0]; got.User != "Alice" || !reflect.DeepEqual(got.Templates, templates["Alice"]) {
+			t.Errorf("got page %+v, want Alice's templates %v", got, templates["Alice"])
+		}
+	})
+
+	t.Run("save a task as a template", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/template", "name=+Release+"))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if want := []addTemplateCall{{3, "Release"}}; !reflect.DeepEqual(store.addTemplateCalls, want) {
+			t.Errorf("got calls to AddTemplate %v, want %v", store.addTemplateCalls, want)
+		}
+	})
+
+	t.Run("templates need a name", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/template", "name=+"))
+
+		assertStatus(t, response, http.StatusBadRequest)
+
+		if len(store.addTemplateCalls) != 0 {
+			t.Errorf("got calls to AddTemplate %v, want none", store.addTemplateCalls)
+		}
+	})
+
+	t.Run("use a template", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+		location, _ := time.LoadLocation("Pacific/Auckland")
+		form := url.Values{"date": {"2025-06-02"}, "value.version": {" 1.2.0 "}, "other": {"ignored"}}
+
+		request := newFormRequest(t, http.MethodPost, "/users/Alice/templates/1?tz=Pacific%2FAuckland", form.Encode())
+		request.Header.Set("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []useTemplateCall{{"Alice", 1, time.Date(2025, time.June, 2, 0, 0, 0, 0, location), map[string]string{"version": "1.2.0"}}}
+
+		if !reflect.DeepEqual(store.useTemplateCalls, want) {
+			t.Errorf("got calls to UseTemplate %v, want %v", store.useTemplateCalls, want)
+		}
+
+		if got := response.Header().Get("HX-Redirect"); got != "/tasks/42" {
+			t.Errorf("got HX-Redirect header %q, want %q", got, "/tasks/42")
+		}
+	})
+
+	t.Run("use a template today by default", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/users/Alice/templates/1", ""))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		now := time.Now()
+
+		if got, want := store.useTemplateCalls[0].anchor, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local); !got.Equal(want) {
+			t.Errorf("got anchor %v, want %v", got, want)
+		}
+	})
+
+	t.Run("reject invalid requests to use a template", func(t *testing.T) {
+		cases := map[string]struct {
+			target string
+			form   string
+			err    error
+			want   int
+		}{
+			"invalid date":     {"/users/Alice/templates/1", "date=tomorrow", nil, http.StatusBadRequest},
+			"unknown timezone": {"/users/Alice/templates/1?tz=Mars", "", nil, http.StatusBadRequest},
+			"missing value":    {"/users/Alice/templates/1", "", stores.ErrMissingValue, http.StatusBadRequest},
+			"missing template": {"/users/Alice/templates/9", "", stores.ErrTemplateNotFound, http.StatusNotFound},
+		}
+
+		for name, test := range cases {
+			t.Run(name, func(t *testing.T) {
+				server := mustCreateServer(t, &StubTaskStore{err: test.err}, new(DummyUserStore), new(SpyRenderer))
+
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newFormRequest(t, http.MethodPost, test.target, test.form))
+
+				assertStatus(t, response, test.want)
+			})
+		}
+	})
+
+	t.Run("delete a template", func(t *testing.T) {
+		store := &StubTaskStore{templates: map[string][]models.Template{"Alice": slices.Clone(templates["Alice"])}}
+		server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodDelete, "/users/Alice/templates/1", nil)
+		request.SetBasicAuth("Alice", "Alice")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.templates["Alice"]) != 0 {
+			t.Errorf("got templates %v, want none", store.templates["Alice"])
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+}
+
+func TestSharing(t *testing.T) {
+	users := map[string]models.User{}
+
+	for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		email := name + "@example.com"
+		users[name] = models.User{ID: uint64(len(users)), Email: email, Password: yattatest.MustCreatePasswordHash(t, name)}
+	}
+
+	userStore := &StubUserStore{}
+
+	for _, user := range users {
+		userStore.users = append(userStore.users, user)
+	}
+
+	// Alice shares her list with Bob as a viewer, Carol as an editor and Dave as an admin. Erin is not a member, and
+	// Frank is a member without an account.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{
+				"alice@example.com": {{ID: 1, Description: "deploy"}, {ID: 2, Description: "test"}},
+				"carol@example.com": {},
+				"erin@example.com":  {{ID: 4, Description: "deploy"}},
+			},
+			trash:         map[string][]models.Task{"alice@example.com": {{ID: 3, Description: "build"}}},
+			savedSearches: map[string][]models.SavedSearch{"alice@example.com": {{ID: 1, Name: "Work", Query: "tag:work"}}},
+			templates:     map[string][]models.Template{"alice@example.com": {{ID: 1, Name: "Release"}}},
+			members: map[string][]models.Member{"alice@example.com": {
+				{Email: "bob@example.com", Role: models.RoleViewer},
+				{Email: "carol@example.com", Role: models.RoleEditor},
+				{Email: "dave@example.com", Role: models.RoleAdmin},
+				{Email: "frank@example.com", Role: models.RoleViewer},
+			}},
+		}
+	}
+	list := "/users/alice@example.com"
+	routes := listRoutes()
+
+	// The role of each user in Alice's list, where the owner can do anything and non-members can do nothing.
+	roles := map[string]models.Role{"alice": models.RoleAdmin, "bob": models.RoleViewer, "carol": models.RoleEditor, "dave": models.RoleAdmin}
+
+	for _, route := range routes {
+		for name := range users {
+			t.Run(fmt.Sprintf("%s %s %s", name, route.method, route.target), func(t *testing.T) {
+				server := mustCreateServer(t, newStore(), userStore, new(SpyRenderer))
+				request := newListRequest(t, route.method, route.target, route.form)
+				request.SetBasicAuth(users[name].Email, name)
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, request)
+
+				role, isMember := roles[name]
+				allowed := isMember && role.Allows(route.role)
+
+				if allowed && (response.Code == http.StatusUnauthorized || response.Code == http.StatusForbidden) {
+					t.Errorf("got status %d, want %s to be allowed to %s %s", response.Code, name, route.method, route.target)
+				}
+
+				if !allowed {
+					assertStatus(t, response, http.StatusForbidden)
+				}
+			})
+		}
+
+		t.Run(fmt.Sprintf("anonymous %s %s", route.method, route.target), func(t *testing.T) {
+			server := mustCreateServer(t, newStore(), userStore, new(SpyRenderer))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newListRequest(t, route.method, route.target, route.form))
+
+			assertStatus(t, response, http.StatusUnauthorized)
+		})
+	}
+
+	t.Run("credentials are only checked once per request", func(t *testing.T) {
+		userStore := &StubUserStore{users: []models.User{users["bob"]}}
+		server := mustCreateServer(t, newStore(), userStore, new(SpyRenderer))
+
+		for _, target := range []string{list + "/tasks", "/tasks/1"} {
+			userStore.getUserByEmailCalls = 0
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			request.SetBasicAuth("bob@example.com", "bob")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusOK)
+
+			if userStore.getUserByEmailCalls != 1 {
+				t.Errorf("got %d checks of the credentials for %s, want 1", userStore.getUserByEmailCalls, target)
+			}
+		}
+	})
+
+	t.Run("lists that are not shared are open to everyone", func(t *testing.T) {
+		server := mustCreateServer(t, newStore(), userStore, new(SpyRenderer))
+
+		for _, target := range []string{"/tasks/4", "/users/erin@example.com/tasks"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assertStatus(t, response, http.StatusOK)
+		}
+	})
+
+	t.Run("only the owner of a list that is not shared can do what admins do", func(t *testing.T) {
+		for _, route := range routes {
+			for _, name := range []string{"alice", "bob", ""} {
+				store := newStore()
+				store.members = map[string][]models.Member{}
+				server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+				request := newListRequest(t, route.method, route.target, route.form)
+
+				if name != "" {
+					request.SetBasicAuth(users[name].Email, name)
+				}
+
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, request)
+
+				allowed := name == "alice" || route.role != models.RoleAdmin
+
+				// Some routes need someone to be signed in whatever the list, e.g., to be the author of a comment.
+				switch {
+				case !allowed && name == "":
+					assertStatus(t, response, http.StatusUnauthorized)
+				case !allowed:
+					assertStatus(t, response, http.StatusForbidden)
+				case name != "" && (response.Code == http.StatusUnauthorized || response.Code == http.StatusForbidden):
+					t.Errorf("got status %d, want %s to be allowed to %s %s", response.Code, name, route.method, route.target)
+				}
+			}
+		}
+	})
+
+	t.Run("members of lists that are not shared can only be changed by the owner", func(t *testing.T) {
+		store := newStore()
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := newFormRequest(t, http.MethodPost, "/users/erin@example.com/members", "email=bob%40example.com&role=admin")
+		request.SetBasicAuth("bob@example.com", "bob")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusForbidden)
+
+		request = newFormRequest(t, http.MethodPost, "/users/erin@example.com/members", "email=bob%40example.com&role=admin")
+		request.SetBasicAuth("erin@example.com", "erin")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if want := []models.Member{{Email: "bob@example.com", Role: models.RoleAdmin}}; !reflect.DeepEqual(store.members["erin@example.com"], want) {
+			t.Errorf("got members %v, want %v", store.members["erin@example.com"], want)
+		}
+	})
+
+	t.Run("reject invalid members", func(t *testing.T) {
+		cases := map[string]string{
+			"unknown user": "email=frank%40example.com&role=viewer",
+			"invalid role": "email=erin%40example.com&role=owner",
+		}
+
+		for name, form := range cases {
+			t.Run(name, func(t *testing.T) {
+				server := mustCreateServer(t, newStore(), userStore, new(SpyRenderer))
+				request := newFormRequest(t, http.MethodPost, list+"/members", form)
+				request.SetBasicAuth("alice@example.com", "alice")
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, request)
+
+				assertStatus(t, response, http.StatusBadRequest)
+			})
+		}
+	})
+
+	t.Run("members can leave a list", func(t *testing.T) {
+		store := newStore()
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodDelete, list+"/members/bob@example.com", nil)
+		request.SetBasicAuth("bob@example.com", "bob")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := len(store.members["alice@example.com"]); got != 3 {
+			t.Errorf("got %d members, want 3 after Bob left", got)
+		}
+	})
+
+	t.Run("shared lists are shown in the navigation of members", func(t *testing.T) {
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, newStore(), userStore, renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/users/carol@example.com/tasks", nil)
+		request.SetBasicAuth("carol@example.com", "carol")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		want := []models.SharedList{{Owner: "alice@example.com", Role: models.RoleEditor}}
+
+		if got := renderer.renderTasksCalls[0].SharedLists; !reflect.DeepEqual(got, want) {
+			t.Errorf("got shared lists %v, want %v", got, want)
+		}
+	})
+
+	t.Run("search leaves out tasks in lists that are shared with others", func(t *testing.T) {
+		cases := map[string][]uint64{"": {4}, "erin": {4}, "bob": {1, 4}}
+
+		for name, want := range cases {
+			renderer := new(SpyRenderer)
+			server := mustCreateServer(t, newStore(), userStore, renderer)
+
+			request := httptest.NewRequest(http.MethodGet, "/search?q=deploy", nil)
+
+			if name != "" {
+				request.SetBasicAuth(name+"@example.com", name)
+			}
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			var got []uint64
+
+			for _, task := range renderer.renderSearchCalls[0].results {
+				got = append(got, task.ID)
+			}
+
+			slices.Sort(got)
+
+			if !slices.Equal(got, want) {
+				t.Errorf("got results %v when searching as %q, want %v", got, name, want)
+			}
+		}
+	})
+}
+
+// A request that acts on the task list of alice@example.com and the role in the list needed to make it.
+type listRoute struct {
+	method string
+	target string
+	form   string
+	role   models.Role
+}
+
+// Get a request for each route that acts on the task list of alice@example.com. The requests expect tasks 1 and 2 in
+// the list, task 3 in its trash, saved search 1, template 1, and frank@example.com to be a member of the list.
+func listRoutes() []listRoute {
+	list := "/users/alice@example.com"
+
+	return []listRoute{
+		{http.MethodGet, "/tasks/1", "", models.RoleViewer},
+		{http.MethodGet, list + "/tasks", "", models.RoleViewer},
+		{http.MethodGet, list + "/tasks/ready", "", models.RoleViewer},
+		{http.MethodGet, list + "/tasks/next", "", models.RoleViewer},
+		{http.MethodGet, list + "/searches/1", "", models.RoleViewer},
+		{http.MethodGet, list + "/trash", "", models.RoleViewer},
+		{http.MethodGet, list + "/archive", "", models.RoleViewer},
+		{http.MethodGet, list + "/report", "", models.RoleViewer},
+		{http.MethodGet, list + "/board", "", models.RoleViewer},
+		{http.MethodGet, list + "/calendar", "", models.RoleViewer},
+		{http.MethodGet, list + "/templates", "", models.RoleViewer},
+		{http.MethodPost, list + "/tasks", "task=lint", models.RoleEditor},
+		{http.MethodPost, "/tasks/1", "description=ship", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/subtasks", "description=lint", models.RoleEditor},
+		{http.MethodPost, "/tasks/2/parent", "parent=1", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/complete", "", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/reopen", "", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/snooze", "until=tomorrow", models.RoleEditor},
+		{http.MethodDelete, "/tasks/1/snooze", "", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/dependencies", "blocker=2", models.RoleEditor},
+		{http.MethodDelete, "/tasks/1/dependencies/2", "", models.RoleEditor},
+		{http.MethodDelete, "/tasks/1", "", models.RoleEditor},
+		{http.MethodPost, "/trash/3/restore", "", models.RoleEditor},
+		{http.MethodDelete, "/trash/3", "", models.RoleEditor},
+		{http.MethodPost, list + "/board/2", "task=1", models.RoleEditor},
+		{http.MethodPost, list + "/calendar/2030-03-01", "task=1", models.RoleEditor},
+		{http.MethodPost, list + "/templates/1", "", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/comments", "body=done", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/timer", "", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/time", "start=2025-03-01T09:00&minutes=30", models.RoleEditor},
+		{http.MethodPost, "/tasks/1/assignees", "assignee=alice%40example.com", models.RoleEditor},
+		{http.MethodPost, list + "/searches", "name=Work&query=tag%3Awork", models.RoleAdmin},
+		{http.MethodDelete, list + "/searches/1", "", models.RoleAdmin},
+		{http.MethodPost, list + "/board/columns", "id=1&name=Doing&limit=0&kind=open&id=2&name=Done&limit=0&kind=done", models.RoleAdmin},
+		{http.MethodDelete, list + "/templates/1", "", models.RoleAdmin},
+		{http.MethodPost, "/tasks/1/template", "name=Release", models.RoleAdmin},
+		{http.MethodGet, list + "/members", "", models.RoleAdmin},
+		{http.MethodPost, list + "/members", "email=erin%40example.com&role=viewer", models.RoleAdmin},
+		{http.MethodDelete, list + "/members/frank@example.com", "", models.RoleAdmin},
+	}
+}
+
+// Create a request for a route in [listRoutes], sending `form` as the body of requests that are not GET or DELETE.
+func newListRequest(t *testing.T, method string, target string, form string) *http.Request {
+	t.Helper()
+
+	if method == http.MethodGet || method == http.MethodDelete {
+		return httptest.NewRequest(method, target, nil)
+	}
+
+	return newFormRequest(t, method, target, form)
+}
+
+func TestWorkspaces(t *testing.T) {
+	users := []string{"alice", "bob", "erin", "zed"}
+
+	// Alice and Bob work at Acme and Erin works at Globex. Zed does not belong to a workspace. Alice shares her list
+	// with Bob so that he can do anything with it.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{
+				"alice@example.com": {{ID: 1, Description: "deploy"}, {ID: 2, Description: "test"}},
+				"erin@example.com":  {{ID: 4, Description: "deploy"}},
+				"zed@example.com":   {{ID: 5, Description: "deploy"}},
+			},
+			trash:         map[string][]models.Task{"alice@example.com": {{ID: 3, Description: "build"}}},
+			savedSearches: map[string][]models.SavedSearch{"alice@example.com": {{ID: 1, Name: "Work", Query: "tag:work"}}},
+			templates:     map[string][]models.Template{"alice@example.com": {{ID: 1, Name: "Release"}}},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleAdmin}, {Email: "frank@example.com", Role: models.RoleViewer}},
+			},
+		}
+	}
+	newWorkspaceStore := func() *stores.FileWorkspaceStore {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+			{"ID": 1, "Name": "Acme", "Members": [{"Email": "alice@example.com", "Role": "admin"}, {"Email": "bob@example.com", "Role": "editor"}]},
+			{"ID": 2, "Name": "Globex", "Members": [{"Email": "erin@example.com", "Role": "admin"}]}
+		]`)
+		t.Cleanup(cleanup)
+
+		workspaceStore, err := stores.NewFileWorkspaceStore(database)
+		yattatest.AssertNoError(t, err)
+
+		return workspaceStore
+	}
+
+	for _, route := range listRoutes() {
+		t.Run(route.method+" "+route.target, func(t *testing.T) {
+			for _, name := range []string{"erin", "zed"} {
+				server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+				response := server.serve(newListRequest(t, route.method, route.target, route.form), name)
+
+				if response.Code != http.StatusNotFound {
+					t.Errorf("got status %d for %s, want %d", response.Code, name, http.StatusNotFound)
+				}
+			}
+
+			server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+			response := server.serve(newListRequest(t, route.method, route.target, route.form), "")
+			assertStatus(t, response, http.StatusUnauthorized)
+
+			server = newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+			response = server.serve(newListRequest(t, route.method, route.target, route.form), "bob")
+
+			if code := response.Code; code == http.StatusUnauthorized || code == http.StatusForbidden || code == http.StatusNotFound {
+				t.Errorf("got status %d, want Bob to be allowed to %s %s", code, route.method, route.target)
+			}
+		})
+	}
+
+	t.Run("search only finds tasks in the same workspace", func(t *testing.T) {
+		cases := map[string][]uint64{"alice": {1}, "erin": {4}, "zed": {5}, "": {5}}
+
+		for name, want := range cases {
+			server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+			server.serve(httptest.NewRequest(http.MethodGet, "/search?q=deploy", nil), name)
+
+			var got []uint64
+
+			for _, task := range server.renderer.renderSearchCalls[0].results {
+				got = append(got, task.ID)
+			}
+
+			slices.Sort(got)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got tasks %v when %q searched, want %v", got, name, want)
+			}
+		}
+	})
+
+	t.Run("the index only lists users in the same workspace", func(t *testing.T) {
+		cases := map[string][]string{
+			"alice": {"alice@example.com", "bob@example.com"},
+			"erin":  {"erin@example.com"},
+			"":      {"zed@example.com"},
+		}
+
+		for name, want := range cases {
+			server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+			server.serve(httptest.NewRequest(http.MethodGet, "/", nil), name)
+
+			var got []string
+
+			for _, user := range server.renderer.renderIndexCalls[0] {
+				got = append(got, user.Email)
+			}
+
+			slices.Sort(got)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got users %v for %q, want %v", got, name, want)
+			}
+		}
+	})
+
+	t.Run("lists cannot be shared with users in other workspaces", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store, yatta.WithWorkspaceStore(newWorkspaceStore()))
+		response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/members", "email=erin%40example.com&role=viewer"), "alice")
+
+		assertStatus(t, response, http.StatusBadRequest)
+
+		if strings.Contains(response.Body.String(), "workspace") {
+			t.Errorf("got %q, want the response to not mention that Erin is in another workspace", response.Body.String())
+		}
+
+		if got := len(store.members["alice@example.com"]); got != 2 {
+			t.Errorf("got %d members, want 2", got)
+		}
+	})
+
+	t.Run("lists shared from other workspaces are hidden", func(t *testing.T) {
+		store := newStore()
+		store.members["zed@example.com"] = []models.Member{{Email: "erin@example.com", Role: models.RoleViewer}}
+		server := newTestServer(t, users, store, yatta.WithWorkspaceStore(newWorkspaceStore()))
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/users/erin@example.com/tasks", nil), "erin")
+
+		assertStatus(t, response, http.StatusOK)
+
+		if got := server.renderer.renderTasksCalls[0].SharedLists; len(got) != 0 {
+			t.Errorf("got shared lists %v, want none", got)
+		}
+	})
+
+	t.Run("invite a user to a workspace", func(t *testing.T) {
+		workspaceStore := newWorkspaceStore()
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(workspaceStore))
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/invitations", "email=zed%40example.com&role=editor"), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/invitations", "email=zed%40example.com&role=editor"), "erin")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/invitations", "email=erin%40example.com&role=editor"), "alice")
+		assertStatus(t, response, http.StatusConflict)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/invitations", "email=zed%40example.com&role=editor"), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/workspace", nil), "zed")
+		assertStatus(t, response, http.StatusOK)
+
+		if page := server.renderer.renderWorkspaceCalls[0]; page.Workspace != nil || len(page.Invitations) != 1 || page.Invitations[0].Name != "Acme" {
+			t.Errorf("got workspace page %+v, want an invitation to Acme", page)
+		}
+
+		// Zed's list stays outside of Acme until Zed joins.
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/tasks/5", nil), "alice")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/workspaces/1/members", nil), "erin")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/workspaces/1/members", nil), "zed")
+		assertStatus(t, response, http.StatusAccepted)
+
+		workspace, err := workspaceStore.GetWorkspaceOf("zed@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if workspace == nil || workspace.ID != 1 || workspace.MemberRole("zed@example.com") != models.RoleEditor {
+			t.Fatalf("got workspace %+v, want Zed to be an editor in Acme", workspace)
+		}
+
+		// Zed's list moves into Acme with Zed.
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/tasks/5", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		// Joining a workspace does not give access to the lists in it that are shared with other members only.
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/tasks/1", nil), "zed")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/tasks/5", nil), "erin")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/tasks/5", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+
+	t.Run("decline an invitation", func(t *testing.T) {
+		workspaceStore := newWorkspaceStore()
+		yattatest.AssertNoError(t, workspaceStore.Invite(2, models.Member{Email: "zed@example.com", Role: models.RoleViewer}))
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(workspaceStore))
+
+		response := server.serve(httptest.NewRequest(http.MethodDelete, "/workspaces/2/invitations/zed@example.com", nil), "alice")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(httptest.NewRequest(http.MethodDelete, "/workspaces/2/invitations/zed@example.com", nil), "zed")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/workspaces/2/members", nil), "zed")
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("remove members from a workspace", func(t *testing.T) {
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(newWorkspaceStore()))
+
+		cases := []struct {
+			name   string
+			target string
+			want   int
+		}{
+			{"erin", "/workspaces/1/members/bob@example.com", http.StatusNotFound},
+			{"bob", "/workspaces/1/members/alice@example.com", http.StatusForbidden},
+			{"alice", "/workspaces/1/members/alice@example.com", http.StatusConflict},
+			{"alice", "/workspaces/1/members/zed@example.com", http.StatusNotFound},
+			{"bob", "/workspaces/1/members/bob@example.com", http.StatusAccepted},
+			{"alice", "/workspaces/1/members/alice@example.com", http.StatusAccepted},
+		}
+
+		for _, test := range cases {
+			response := server.serve(httptest.NewRequest(http.MethodDelete, test.target, nil), test.name)
+
+			if response.Code != test.want {
+				t.Errorf("got status %d when %s made the request DELETE %s, want %d", response.Code, test.name, test.target, test.want)
+			}
+		}
+	})
+
+	t.Run("admins change the settings of a workspace", func(t *testing.T) {
+		workspaceStore := newWorkspaceStore()
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(workspaceStore))
+		form := "time-zone=Pacific%2FAuckland&disable-secret-links=true"
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/settings", form), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/settings", form), "erin")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/settings", "time-zone=Middle%2FEarth"), "alice")
+		assertStatus(t, response, http.StatusBadRequest)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces/1/settings", form), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		workspace, err := workspaceStore.GetWorkspace(1)
+		yattatest.AssertNoError(t, err)
+		want := models.WorkspaceSettings{TimeZone: "Pacific/Auckland", DisableSecretLinks: true}
+
+		if workspace.Settings != want {
+			t.Errorf("got settings %+v, want %+v", workspace.Settings, want)
+		}
+	})
+
+	t.Run("dates are in the time zone of the workspace unless the browser says otherwise", func(t *testing.T) {
+		workspaceStore := newWorkspaceStore()
+		yattatest.AssertNoError(t, workspaceStore.UpdateSettings(1, models.WorkspaceSettings{TimeZone: "Pacific/Auckland"}))
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(workspaceStore))
+
+		server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/calendar", nil), "alice")
+		server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/calendar?tz=Europe%2FBerlin", nil), "alice")
+		server.serve(httptest.NewRequest(http.MethodGet, "/users/erin@example.com/calendar", nil), "erin")
+
+		want := []string{"Pacific/Auckland", "Europe/Berlin", time.Local.String()}
+
+		if len(server.renderer.renderCalendarCalls) != len(want) {
+			t.Fatalf("got %d calls to RenderCalendar, want %d", len(server.renderer.renderCalendarCalls), len(want))
+		}
+
+		for i, page := range server.renderer.renderCalendarCalls {
+			if got := page.Today.Location().String(); got != want[i] {
+				t.Errorf("got time zone %q for calendar %d, want %q", got, i, want[i])
+			}
+		}
+	})
+
+	t.Run("admins can turn off secret links", func(t *testing.T) {
+		store := newStore()
+		store.feedTokens = map[string]string{"alice@example.com": "feed", "erin@example.com": "feed2"}
+		store.inboundTokens = map[string]string{"alice@example.com": "inbound", "erin@example.com": "inbound2"}
+		workspaceStore := newWorkspaceStore()
+		yattatest.AssertNoError(t, workspaceStore.UpdateSettings(1, models.WorkspaceSettings{DisableSecretLinks: true}))
+		server := newTestServer(t, users, store, yatta.WithWorkspaceStore(workspaceStore))
+
+		cases := []struct {
+			request *http.Request
+			want    int
+		}{
+			{httptest.NewRequest(http.MethodGet, "/feeds/feed/tasks.ics", nil), http.StatusForbidden},
+			{newFormRequest(t, http.MethodPost, "/inbound/inbound", "description=hello"), http.StatusForbidden},
+			{httptest.NewRequest(http.MethodGet, "/feeds/feed2/tasks.ics", nil), http.StatusOK},
+			{newFormRequest(t, http.MethodPost, "/inbound/inbound2", "description=hello"), http.StatusAccepted},
+		}
+
+		for _, test := range cases {
+			response := server.serve(test.request, "")
+
+			if response.Code != test.want {
+				t.Errorf("got status %d for %s %s, want %d", response.Code, test.request.Method, test.request.URL, test.want)
+			}
+		}
+
+		if tasks := store.store["alice@example.com"]; len(tasks) != 2 {
+			t.Errorf("got tasks %v, want no task to be added to Alice's list", tasks)
+		}
+	})
+
+	t.Run("create a workspace", func(t *testing.T) {
+		workspaceStore := newWorkspaceStore()
+		server := newTestServer(t, users, newStore(), yatta.WithWorkspaceStore(workspaceStore))
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/workspaces", "name=+"), "zed")
+		assertStatus(t, response, http.StatusBadRequest)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces", "name=Initech"), "alice")
+		assertStatus(t, response, http.StatusConflict)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces", "name=Initech"), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+
+		response = server.serve(newFormRequest(t, http.MethodPost, "/workspaces", "name=Initech"), "zed")
+		assertStatus(t, response, http.StatusAccepted)
+
+		workspace, err := workspaceStore.GetWorkspaceOf("zed@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if workspace == nil || workspace.Name != "Initech" || workspace.MemberRole("zed@example.com") != models.RoleAdmin {
+			t.Errorf("got workspace %+v, want Zed to be the admin of Initech", workspace)
+		}
+	})
+}
+
+func TestAssignment(t *testing.T) {
+	users := []string{"alice", "bob", "carol"}
+
+	// Alice shares her list with Bob and Carol, and Carol shares her list with Bob.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{
+				"alice@example.com": {{ID: 1, Description: "deploy"}, {ID: 2, Description: "test", Assignees: []string{"bob@example.com"}}},
+				"carol@example.com": {{ID: 3, Description: "review", Assignees: []string{"bob@example.com"}}, {ID: 4, Description: "merge", Done: true, Assignees: []string{"bob@example.com"}}},
+				"erin@example.com":  {{ID: 5, Description: "lint", Assignees: []string{"bob@example.com"}}},
+			},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleEditor}, {Email: "carol@example.com", Role: models.RoleEditor}},
+				"carol@example.com": {{Email: "bob@example.com", Role: models.RoleViewer}},
+				"erin@example.com":  {{Email: "carol@example.com", Role: models.RoleViewer}},
+			},
+		}
+	}
+
+	t.Run("assign a task and notify the new assignees", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		form := "assignee=alice%40example.com&assignee=bob%40example.com&assignee=carol%40example.com"
+		response := server.serve(newFormRequest(t, http.MethodPost, "/tasks/1/assignees", form), "carol")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got, want := store.store["alice@example.com"][0].Assignees, []string{"alice@example.com", "bob@example.com", "carol@example.com"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got assignees %v, want %v", got, want)
+		}
+
+		want := []models.Notification{{ID: 1, Kind: models.NotificationAssigned, TaskID: 1, Task: "deploy", Actor: "carol@example.com"}}
+
+		for _, email := range []string{"alice@example.com", "bob@example.com"} {
+			if got := store.notifications[email]; !reflect.DeepEqual(got, want) {
+				t.Errorf("got notifications %v for %s, want %v", got, email, want)
+			}
+		}
+
+		if got := store.notifications["carol@example.com"]; len(got) != 0 {
+			t.Errorf("got notifications %v, want Carol to not be notified about assigning herself", got)
+		}
+	})
+
+	t.Run("users that were already assigned are not notified again", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/tasks/2/assignees", "assignee=bob%40example.com"), "alice")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := store.notifications["bob@example.com"]; len(got) != 0 {
+			t.Errorf("got notifications %v, want none", got)
+		}
+	})
+
+	t.Run("tasks can only be assigned to the owner and members of their list", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/tasks/3/assignees", "assignee=alice%40example.com"), "carol")
+
+		assertStatus(t, response, http.StatusBadRequest)
+
+		if got := store.notifications["alice@example.com"]; len(got) != 0 {
+			t.Errorf("got notifications %v, want none", got)
+		}
+	})
+
+	t.Run("assigned to me shows the open tasks the user can see across lists", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/assigned", nil), "bob")
+
+		assertStatus(t, response, http.StatusOK)
+
+		var got []uint64
+
+		for _, task := range server.renderer.renderAssignedCalls[0].Tasks {
+			got = append(got, task.ID)
+		}
+
+		slices.Sort(got)
+
+		// Bob is not a member of Erin's list anymore, so task 5 is left out.
+		if want := []uint64{2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got tasks %v, want %v", got, want)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/assigned", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+
+	t.Run("read notifications", func(t *testing.T) {
+		store := newStore()
+		store.notifications = map[string][]models.Notification{"bob@example.com": {
+			{ID: 1, Kind: models.NotificationAssigned, TaskID: 2, Task: "test"},
+			{ID: 2, Kind: models.NotificationAssigned, TaskID: 3, Task: "review"},
+		}}
+		server := newTestServer(t, users, store)
+
+		server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/tasks", nil), "bob")
+
+		if got := server.renderer.renderTasksCalls[0].SignedIn; got != "bob@example.com" {
+			t.Errorf("got signed in user %q, want bob@example.com", got)
+		}
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/notifications/bell", nil), "bob")
+		assertStatus(t, response, http.StatusOK)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/notifications", nil), "bob")
+		assertStatus(t, response, http.StatusOK)
+
+		if got := server.renderer.renderNotificationsCalls[0].Notifications; len(got) != 2 || got[0].ID != 2 {
+			t.Errorf("got notifications %v, want the 2 notifications newest first", got)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/notifications/read", nil), "bob")
+		assertStatus(t, response, http.StatusAccepted)
+
+		server.serve(httptest.NewRequest(http.MethodGet, "/notifications/bell", nil), "bob")
+
+		if got, want := server.renderer.renderNotificationBellCalls, []int{2, 0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got unread notifications %v, want %v", got, want)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/notifications", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+}
+
+func TestMentions(t *testing.T) {
+	users := []string{"alice", "bob", "carol", "erin"}
+
+	// Alice shares her list with Bob, and Carol does not share her list with anyone.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{
+				"alice@example.com": {{ID: 1, Description: "deploy"}},
+				"carol@example.com": {{ID: 2, Description: "review"}},
+			},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleEditor}},
+			},
+		}
+	}
+
+	t.Run("users mentioned in a comment are notified", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=%40bob+and+%40alice+can+you+check+this%3F"), "alice")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []models.Notification{{ID: 1, Kind: models.NotificationMentioned, TaskID: 1, Task: "deploy", Actor: "alice@example.com", CommentID: 1}}
+
+		if got := store.notifications["bob@example.com"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("got notifications %v, want %v", got, want)
+		}
+
+		if got := store.notifications["alice@example.com"]; len(got) != 0 {
+			t.Errorf("got notifications %v, want Alice to not be notified about mentioning herself", got)
+		}
+	})
+
+	t.Run("users that cannot see the task are not notified", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=%40carol+%40nobody"), "bob")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.notifications) != 0 {
+			t.Errorf("got notifications %v, want none", store.notifications)
+		}
+	})
+
+	t.Run("users in other workspaces are not notified", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+			{"ID": 1, "Name": "Acme", "Members": [{"Email": "bob@example.com", "Role": "editor"}, {"Email": "carol@example.com", "Role": "admin"}]},
+			{"ID": 2, "Name": "Globex", "Members": [{"Email": "erin@example.com", "Role": "admin"}]}
+		]`)
+		t.Cleanup(cleanup)
+
+		workspaceStore, err := stores.NewFileWorkspaceStore(database)
+		yattatest.AssertNoError(t, err)
+
+		store := newStore()
+		server := newTestServer(t, users, store, yatta.WithWorkspaceStore(workspaceStore))
+
+		response := server.serve(newCommentRequest(t, http.MethodPost, "/tasks/2/comments", "body=%40bob+%40erin"), "carol")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := store.notifications["bob@example.com"]; len(got) != 1 {
+			t.Errorf("got notifications %v, want Bob to be notified", got)
+		}
+
+		if got := store.notifications["erin@example.com"]; len(got) != 0 {
+			t.Errorf("got notifications %v, want Erin to not be notified", got)
+		}
+	})
+
+	t.Run("editing a comment only notifies newly mentioned users", func(t *testing.T) {
+		store := newStore()
+		store.members["alice@example.com"] = append(store.members["alice@example.com"], models.Member{Email: "carol@example.com", Role: models.RoleViewer})
+		server := newTestServer(t, users, store)
+
+		server.serve(newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=%40bob"), "alice")
+		response := server.serve(newCommentRequest(t, http.MethodPost, "/comments/1", "body=%40bob+%40carol"), "alice")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		for _, email := range []string{"bob@example.com", "carol@example.com"} {
+			if got := store.notifications[email]; len(got) != 1 || got[0].CommentID != 1 {
+				t.Errorf("got notifications %v for %s, want one notification about comment 1", got, email)
+			}
+		}
+	})
+
+	t.Run("users mentioned in a task are notified", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/tasks/1", "notes=Ask+%40bob%40example.com+first"), "alice")
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []models.Notification{{ID: 1, Kind: models.NotificationMentioned, TaskID: 1, Task: "deploy", Actor: "alice@example.com"}}
+
+		if got := store.notifications["bob@example.com"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("got notifications %v, want %v", got, want)
+		}
+	})
+
+	t.Run("tasks and comments are kept when the users mentioned cannot be notified", func(t *testing.T) {
+		store := newStore()
+		store.addNotificationErr = errors.New("disk full")
+		server := newTestServer(t, users, store)
+
+		// Failing the requests would only get the task and comment added again when the requests are retried.
+		response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/tasks", "task=Ask+%40bob"), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=%40bob"), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.addCalls) != 1 || len(store.comments) != 1 {
+			t.Errorf("got %d tasks and %d comments added, want 1 of each", len(store.addCalls), len(store.comments))
+		}
+	})
+
+	t.Run("the notification bell is empty when nobody is signed in", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/notifications/bell", nil), "")
+
+		assertStatus(t, response, http.StatusOK)
+
+		if response.Body.Len() != 0 || len(server.renderer.renderNotificationBellCalls) != 0 {
+			t.Errorf("got body %q, want nothing to be rendered", response.Body)
+		}
+	})
+}
+
+func TestLiveUpdates(t *testing.T) {
+	users := []string{"alice", "zed"}
+
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{"alice@example.com": {{ID: 1, Description: "deploy"}}},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleViewer}},
+			},
+		}
+	}
+
+	t.Run("stream the changes to a task list", func(t *testing.T) {
+		bus := events.NewBus(10)
+		server := newTestServer(t, users, newStore(), yatta.WithEventBus(bus, time.Millisecond))
+
+		bus.Publish("alice@example.com", events.TaskAdded, 1)
+		bus.Publish("bob@example.com", events.TaskAdded, 2)
+		bus.Publish("alice@example.com", events.TaskChanged, 1)
+
+		listener := httptest.NewServer(server)
+		defer listener.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, listener.URL+"/users/alice@example.com/events", nil)
+		yattatest.AssertNoError(t, err)
+		request.SetBasicAuth("alice@example.com", "alice")
+		request.Header.Set("Last-Event-ID", "1")
+		response, err := listener.Client().Do(request)
+		yattatest.AssertNoError(t, err)
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", response.StatusCode, http.StatusOK)
+		}
+
+		if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("got content type %q, want text/event-stream", got)
+		}
+
+		// The missed events are sent before the first heartbeat, so every event has been read once a heartbeat arrives.
+		frames := readEventFrames(t, response.Body, ": heartbeat\n\n")
+
+		for _, want := range []string{"retry: 3000\n\n", "id: 3\nevent: task-changed\ndata: 1\n\n"} {
+			if !slices.Contains(frames, want) {
+				t.Errorf("could not find %q in %q", want, frames)
+			}
+		}
+
+		for _, frame := range frames {
+			if strings.HasPrefix(frame, "id: 1\n") || strings.HasPrefix(frame, "id: 2\n") {
+				t.Errorf("found %q in %q, want only the events after the last event ID for Alice's list", frame, frames)
+			}
+		}
+	})
+
+	t.Run("invalid last event IDs are rejected", func(t *testing.T) {
+		server := newTestServer(t, users, newStore(), yatta.WithEventBus(events.NewBus(10), time.Second))
+
+		request := httptest.NewRequest(http.MethodGet, "/users/alice@example.com/events", nil)
+		request.SetBasicAuth("alice@example.com", "alice")
+		request.Header.Set("Last-Event-ID", "latest")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("only users that can see the list can listen for changes", func(t *testing.T) {
+		server := newTestServer(t, users, newStore(), yatta.WithEventBus(events.NewBus(10), time.Second))
+
+		request := httptest.NewRequest(http.MethodGet, "/users/alice@example.com/events", nil)
+		request.SetBasicAuth("zed@example.com", "zed")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusForbidden)
+	})
+
+	t.Run("task lists are only updated live when there is an event bus", func(t *testing.T) {
+		for _, live := range []bool{false, true} {
+			var options []yatta.ServerOption
+
+			if live {
+				options = append(options, yatta.WithEventBus(events.NewBus(10), time.Second))
+			}
+
+			server := newTestServer(t, users, newStore(), options...)
+
+			request := httptest.NewRequest(http.MethodGet, "/users/alice@example.com/tasks?q=deploy", nil)
+			request.SetBasicAuth("alice@example.com", "alice")
+			server.ServeHTTP(httptest.NewRecorder(), request)
+
+			if page := server.renderer.renderTasksCalls[0]; page.Live != live || page.Path != "/users/alice@example.com/tasks?q=deploy" {
+				t.Errorf("got live %t and path %q, want live %t and the path of the request", page.Live, page.Path, live)
+			}
+		}
+	})
+}
+
+// Read the frames of a stream of server-sent events from `body`, each ending with a blank line, until the frame `until`
+// arrives.
+//
+// Fails the test if the stream ends first, e.g., because the request's deadline passed.
+func readEventFrames(t *testing.T, body io.Reader, until string) []string {
+	t.Helper()
+
+	reader := bufio.NewReader(body)
+	var frames []string
+	var frame strings.Builder
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			t.Fatalf("the stream ended before %q arrived, got %q: %v", until, frames, err)
+		}
+
+		frame.WriteString(line)
+
+		if line != "\n" {
+			continue
+		}
+
+		frames = append(frames, frame.String())
+		frame.Reset()
+
+		if frames[len(frames)-1] == until {
+			return frames
+		}
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	users := []string{"alice", "bob"}
+
+	// Alice shares her list with Bob as an editor, and Carol's list is not shared.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{"alice@example.com": {{ID: 1, Description: "deploy"}}},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleEditor}},
+			},
+		}
+	}
+	const form = "url=https%3A%2F%2Fexample.com%2Fhooks&secret=s3cret&event=task.created&event=task.completed"
+
+	t.Run("add, show and delete a webhook", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/webhooks", form), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []models.Webhook{{
+			ID:     1,
+			URL:    "https://example.com/hooks",
+			Secret: "s3cret",
+			Events: []models.WebhookEvent{models.WebhookTaskCreated, models.WebhookTaskCompleted},
+		}}
+
+		if got := store.webhooks["alice@example.com"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("got webhooks %+v, want %+v", got, want)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/webhooks", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if got := server.renderer.renderWebhooksCalls[0]; got.User != "alice@example.com" || !reflect.DeepEqual(got.Webhooks, want) {
+			t.Errorf("got page %+v, want Alice's webhooks", got)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodDelete, "/users/alice@example.com/webhooks/1", nil), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(httptest.NewRequest(http.MethodDelete, "/users/alice@example.com/webhooks/1", nil), "alice")
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("invalid webhooks are rejected", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		for _, form := range []string{
+			"url=https%3A%2F%2Fexample.com%2Fhooks&secret=s3cret&event=task.renamed",
+			"url=example.com&secret=s3cret&event=task.created",
+			"url=https%3A%2F%2Fexample.com%2Fhooks&secret=s3cret",
+		} {
+			response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/webhooks", form), "alice")
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+
+		if got := store.webhooks["alice@example.com"]; len(got) != 0 {
+			t.Errorf("got webhooks %+v, want none", got)
+		}
+	})
+
+	t.Run("only the owner and admins of a list can manage its webhooks", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/webhooks", form), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/webhooks", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		// Lists that are not shared are open to everyone, but their webhooks hold secrets.
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/carol@example.com/webhooks", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/carol@example.com/webhooks", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+	})
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	type received struct {
+		event     string
+		delivery  string
+		signature string
+		body      []byte
+	}
+
+	newReceiver := func(t *testing.T, statusCode int) (*httptest.Server, *[]received) {
+		var requests []received
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			yattatest.AssertNoError(t, err)
+
+			requests = append(requests, received{r.Header.Get("X-Yatta-Event"), r.Header.Get("X-Yatta-Delivery"), r.Header.Get("X-Yatta-Signature"), body})
+			w.WriteHeader(statusCode)
+		}))
+		t.Cleanup(receiver.Close)
+
+		return receiver, &requests
+	}
+	newStore := func(t *testing.T, url string) *stores.FileTaskStore {
+		store, cleanup := mustCreateFileTaskStore(t, `[{"User": "alice@example.com", "Tasks": []}]`)
+		t.Cleanup(cleanup)
+
+		_, err := store.AddWebhook("alice@example.com", models.Webhook{URL: url, Secret: "s3cret", Events: []models.WebhookEvent{models.WebhookTaskCreated}})
+		yattatest.AssertNoError(t, err)
+
+		return store
+	}
+
+	t.Run("deliveries are signed and sent once", func(t *testing.T) {
+		receiver, requests := newReceiver(t, http.StatusNoContent)
+		store := newStore(t, receiver.URL)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "deploy"))
+
+		dispatcher := yatta.WebhookDispatcher{Store: store, Client: receiver.Client()}
+		now := time.Now()
+		yattatest.AssertNoError(t, dispatcher.Run(now))
+		yattatest.AssertNoError(t, dispatcher.Run(now.Add(time.Hour)))
+
+		if len(*requests) != 1 {
+			t.Fatalf("got %d requests, want 1", len(*requests))
+		}
+
+		request := (*requests)[0]
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(request.body)
+
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); request.signature != want {
+			t.Errorf("got signature %q, want %q", request.signature, want)
+		}
+
+		var payload models.WebhookPayload
+		yattatest.AssertNoError(t, json.Unmarshal(request.body, &payload))
+
+		if request.event != "task.created" || request.delivery != "1" || payload.List != "alice@example.com" || payload.Task.Description != "deploy" {
+			t.Errorf("got event %q, delivery %q and payload %+v, want the creation of the task", request.event, request.delivery, payload)
+		}
+
+		deliveries, err := store.GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(deliveries) != 1 || deliveries[0].DeliveredAt == nil || deliveries[0].StatusCode != http.StatusNoContent {
+			t.Errorf("got deliveries %+v, want the delivery to be received", deliveries)
+		}
+	})
+
+	t.Run("failed deliveries are retried with backoff", func(t *testing.T) {
+		receiver, requests := newReceiver(t, http.StatusInternalServerError)
+		store := newStore(t, receiver.URL)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "deploy"))
+
+		dispatcher := yatta.WebhookDispatcher{Store: store, Client: receiver.Client()}
+		now := time.Now()
+
+		for _, at := range []time.Time{now, now.Add(time.Second), now.Add(models.FirstRetryDelay), now.Add(2 * models.FirstRetryDelay)} {
+			yattatest.AssertNoError(t, dispatcher.Run(at))
+		}
+
+		// The second run is too soon and the last run is before the second retry is due.
+		if len(*requests) != 2 {
+			t.Errorf("got %d requests, want 2", len(*requests))
+		}
+
+		deliveries, err := store.GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(deliveries) != 1 || !deliveries[0].Pending() || deliveries[0].Attempts != 2 || deliveries[0].StatusCode != http.StatusInternalServerError {
+			t.Errorf("got deliveries %+v, want a pending delivery after two failed attempts", deliveries)
+		}
+	})
+
+	t.Run("deliveries are still sent when an attempt cannot be recorded", func(t *testing.T) {
+		receiver, requests := newReceiver(t, http.StatusNoContent)
+		store := newStore(t, receiver.URL)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "deploy"))
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "test"))
+
+		dispatcher := yatta.WebhookDispatcher{Store: &unrecordedDeliveryStore{TaskStore: store, id: 1}, Client: receiver.Client()}
+		yattatest.AssertNoError(t, dispatcher.Run(time.Now()))
+
+		if len(*requests) != 2 {
+			t.Fatalf("got %d requests, want 2", len(*requests))
+		}
+
+		deliveries, err := store.GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		// Deliveries are listed newest first.
+		if len(deliveries) != 2 || deliveries[0].Pending() || !deliveries[1].Pending() {
+			t.Errorf("got deliveries %+v, want only the second delivery to be recorded", deliveries)
+		}
+	})
+
+	t.Run("deliveries of webhooks deleted since the deliveries were got are not sent", func(t *testing.T) {
+		receiver, requests := newReceiver(t, http.StatusNoContent)
+		store := newStore(t, receiver.URL)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "deploy"))
+
+		dispatcher := yatta.WebhookDispatcher{Store: &deletedWebhookStore{TaskStore: store}, Client: receiver.Client()}
+		yattatest.AssertNoError(t, dispatcher.Run(time.Now()))
+
+		if len(*requests) != 0 {
+			t.Errorf("got %d requests, want none to be sent without the secret of the webhook", len(*requests))
+		}
+	})
+}
+
+// A task store whose webhooks are all deleted right after the due deliveries are got.
+type deletedWebhookStore struct {
+	stores.TaskStore
+}
+
+func (s *deletedWebhookStore) GetWebhooks(user string) ([]models.Webhook, error) {
+	return []models.Webhook{}, nil
+}
+
+// A task store that cannot record the attempts at the delivery with the ID `id`.
+type unrecordedDeliveryStore struct {
+	stores.TaskStore
+	id uint64
+}
+
+func (s *unrecordedDeliveryStore) RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error {
+	if id == s.id {
+		return errors.New("disk full")
+	}
+
+	return s.TaskStore.RecordDeliveryAttempt(user, id, statusCode, err, at)
+}
+
+func TestInbound(t *testing.T) {
+	users := []string{"alice", "bob"}
+
+	// Alice shares her list with Bob as an editor, and has given her list an inbound token.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{"alice@example.com": {}},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleEditor}},
+			},
+			inboundTokens: map[string]string{"alice@example.com": "s3cret"},
+		}
+	}
+	newRequest := func(target string, contentType string, body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+
+		return request
+	}
+
+	t.Run("add tasks sent as JSON or a form", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newRequest("/inbound/s3cret", "application/json; charset=utf-8", `{"description": "Disk almost full", "notes": "Usage is at 95%"}`), "")
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := response.Header().Get("Location"); got != "/tasks/1" {
+			t.Errorf("got Location header %q, want %q", got, "/tasks/1")
+		}
+
+		response = server.serve(newRequest("/inbound/s3cret", formContentType, "description=Renew+the+certificate"), "")
+		assertStatus(t, response, http.StatusAccepted)
+
+		wantCalls := []addTaskCall{{"alice@example.com", "Disk almost full"}, {"alice@example.com", "Renew the certificate"}}
+
+		if !reflect.DeepEqual(store.addCalls, wantCalls) {
+			t.Errorf("got add calls %v, want %v", store.addCalls, wantCalls)
+		}
+
+		if got := store.addDetails[0].Notes; got == nil || *got != "Usage is at 95%" {
+			t.Errorf("got notes %v, want %q", got, "Usage is at 95%")
+		}
+
+		if got := store.addDetails[1].Notes; got != nil {
+			t.Errorf("got notes %q, want none", *got)
+		}
+	})
+
+	t.Run("reject unknown tokens and invalid tasks", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			request *http.Request
+			want    int
+		}{
+			{"unknown token", newRequest("/inbound/guess", "application/json", `{"description": "hello"}`), http.StatusNotFound},
+			{"unknown token for emails", newRequest("/inbound/guess/email", "message/rfc822", "Subject: hello\r\n\r\n"), http.StatusNotFound},
+			{"no description", newRequest("/inbound/s3cret", "application/json", `{"notes": "hello"}`), http.StatusBadRequest},
+			{"blank description", newRequest("/inbound/s3cret", formContentType, "description=+"), http.StatusBadRequest},
+			{"malformed JSON", newRequest("/inbound/s3cret", "application/json", `{"description":`), http.StatusBadRequest},
+			{"unsupported content type", newRequest("/inbound/s3cret", "text/plain", "hello"), http.StatusUnsupportedMediaType},
+			{"email that is not a message", newRequest("/inbound/s3cret/email", "text/plain", "Subject: hello\r\n\r\n"), http.StatusUnsupportedMediaType},
+			{"malformed email", newRequest("/inbound/s3cret/email", "message/rfc822", "not an email"), http.StatusBadRequest},
+		}
+
+		for _, test := range cases {
+			t.Run(test.name, func(t *testing.T) {
+				store := newStore()
+				server := newTestServer(t, users, store)
+
+				response := server.serve(test.request, "")
+				assertStatus(t, response, test.want)
+
+				if len(store.addCalls) != 0 {
+					t.Errorf("got add calls %v, want none", store.addCalls)
+				}
+			})
+		}
+	})
+
+	t.Run("add a task from an email with its attachments", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store, yatta.WithBlobStore(mustCreateBlobStore(t, 1024)))
+		message := "From: alerts@example.com\r\n" +
+			"Subject: Disk almost full\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: multipart/mixed; boundary=b\r\n" +
+			"\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"\r\n" +
+			"Usage is at 95%.\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"Content-Disposition: attachment; filename=df.txt\r\n" +
+			"\r\n" +
+			"/dev/sda1 95%\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"Content-Disposition: attachment; filename=big.txt\r\n" +
+			"\r\n" +
+			strings.Repeat("a", 2048) + "\r\n" +
+			"--b--\r\n"
+
+		response := server.serve(newRequest("/inbound/s3cret/email", "message/rfc822", message), "")
+		assertStatus(t, response, http.StatusAccepted)
+
+		wantCalls := []addTaskCall{{"alice@example.com", "Disk almost full"}}
+
+		if !reflect.DeepEqual(store.addCalls, wantCalls) {
+			t.Errorf("got add calls %v, want %v", store.addCalls, wantCalls)
+		}
+
+		if got := store.addDetails[0].Notes; got == nil || *got != "Usage is at 95%." {
+			t.Errorf("got notes %v, want %q", got, "Usage is at 95%.")
+		}
+
+		// The attachment that is too large is skipped rather than failing the request, which would only make the
+		// mail server send the email again.
+		if len(store.attachments) != 1 || store.attachments[0].Name != "df.txt" || store.attachments[0].TaskID != 1 {
+			t.Errorf("got attachments %+v, want df.txt on task 1", store.attachments)
+		}
+	})
+
+	t.Run("emails without a subject get a placeholder description", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(newRequest("/inbound/s3cret/email", "message/rfc822", "From: alice@example.com\r\n\r\ncall Bob\r\n"), "")
+		assertStatus(t, response, http.StatusAccepted)
+
+		wantCalls := []addTaskCall{{"alice@example.com", "(no subject)"}}
+
+		if !reflect.DeepEqual(store.addCalls, wantCalls) {
+			t.Errorf("got add calls %v, want %v", store.addCalls, wantCalls)
+		}
+	})
+
+	t.Run("show and reset the inbound URL", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "http://yatta.example.com/users/alice@example.com/inbound", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		want := yatta.InboundPage{User: "alice@example.com", URL: "http://yatta.example.com/inbound/s3cret"}
+
+		if got := server.renderer.renderInboundCalls[0]; got != want {
+			t.Errorf("got page %+v, want %+v", got, want)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/users/alice@example.com/inbound", nil), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(newRequest("/inbound/s3cret", formContentType, "description=hello"), "")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(newRequest("/inbound/"+store.inboundTokens["alice@example.com"], formContentType, "description=hello"), "")
+		assertStatus(t, response, http.StatusAccepted)
+	})
+
+	t.Run("only the owner and admins of a list can see its inbound URL", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/inbound", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/users/alice@example.com/inbound", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/carol@example.com/inbound", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+}
+
+func TestFeed(t *testing.T) {
+	users := []string{"alice", "bob"}
+
+	due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+
+	// Alice shares her list with Bob as an editor, and has given her list a feed token.
+	newStore := func() *StubTaskStore {
+		return &StubTaskStore{
+			store: map[string][]models.Task{"alice@example.com": {
+				{ID: 1, Description: "Pay rent", Due: &due, Recurrence: &models.Recurrence{Frequency: models.Monthly}},
+				{ID: 2, Description: "Someday"},
+				{ID: 3, Description: "File taxes", Due: &due, Done: true},
+			}},
+			members: map[string][]models.Member{
+				"alice@example.com": {{Email: "bob@example.com", Role: models.RoleEditor}},
+			},
+			feedTokens: map[string]string{"alice@example.com": "s3cret"},
+		}
+	}
+
+	t.Run("serve the tasks with due dates as events", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "http://yatta.example.com/feeds/s3cret/tasks.ics", nil), "")
+		assertStatus(t, response, http.StatusOK)
+
+		if got := response.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+			t.Errorf("got content type %q, want text/calendar", got)
+		}
+
+		body := response.Body.String()
+
+		for _, want := range []string{
+			"BEGIN:VCALENDAR\r\n",
+			"X-WR-CALNAME:alice@example.com's tasks\r\n",
+			"BEGIN:VEVENT\r\nUID:task-1@yatta\r\n",
+			"DTSTART;VALUE=DATE:20250314\r\nRRULE:FREQ=MONTHLY\r\nSUMMARY:Pay rent\r\n",
+			"URL:http://yatta.example.com/tasks/1\r\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("could not find %q in\n%s", want, body)
+			}
+		}
+
+		if strings.Count(body, "BEGIN:VEVENT") != 1 {
+			t.Errorf("got\n%s\nwant one event for the open task with a due date", body)
+		}
+	})
+
+	t.Run("serve the tasks with due dates as to-dos", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/feeds/s3cret/tasks.ics?type=todo", nil), "")
+		assertStatus(t, response, http.StatusOK)
+
+		if got := strings.Count(response.Body.String(), "BEGIN:VTODO"); got != 2 {
+			t.Errorf("got %d to-dos, want one for each task with a due date", got)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/feeds/s3cret/tasks.ics?type=journal", nil), "")
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("reject unknown tokens", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/feeds/guess/tasks.ics", nil), "alice")
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("show and reset the feed URL", func(t *testing.T) {
+		store := newStore()
+		server := newTestServer(t, users, store)
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "http://yatta.example.com/users/alice@example.com/feed", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		want := yatta.FeedPage{User: "alice@example.com", URL: "http://yatta.example.com/feeds/s3cret/tasks.ics"}
+
+		if got := server.renderer.renderFeedCalls[0]; got != want {
+			t.Errorf("got page %+v, want %+v", got, want)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/users/alice@example.com/feed", nil), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/feeds/s3cret/tasks.ics", nil), "")
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/feeds/"+store.feedTokens["alice@example.com"]+"/tasks.ics", nil), "")
+		assertStatus(t, response, http.StatusOK)
+	})
+
+	t.Run("only the owner and admins of a list can see its feed URL", func(t *testing.T) {
+		server := newTestServer(t, users, newStore())
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/feed", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodPost, "/users/alice@example.com/feed", nil), "bob")
+		assertStatus(t, response, http.StatusForbidden)
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/users/carol@example.com/feed", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+}
+
+func TestAppPasswords(t *testing.T) {
+	// Alice has signed in to CalDAV on her phone with an app password.
+	newServer := func() *testServer {
+		server := newTestServer(t, []string{"alice"}, new(StubTaskStore))
+		server.userStore.users[0].AppPasswords = []models.AppPassword{
+			{ID: 1, Name: "Phone", Hash: yattatest.MustCreatePasswordHash(t, "abcd-efgh-ijkm-npqr-stuv-wxyz")},
+		}
+
+		return server
+	}
+
+	t.Run("show the app passwords and the CalDAV URL", func(t *testing.T) {
+		server := newServer()
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "https://yatta.example.com/app-passwords", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if len(server.renderer.renderAppPasswordsCalls) != 1 {
+			t.Fatalf("got %d calls to RenderAppPasswords, want 1", len(server.renderer.renderAppPasswordsCalls))
+		}
+
+		got := server.renderer.renderAppPasswordsCalls[0]
+
+		if got.User != "alice@example.com" || got.CalDAVURL != "https://yatta.example.com/caldav/" || len(got.AppPasswords) != 1 || got.NewPassword != "" {
+			t.Errorf("got %+v, want Alice's app password and the CalDAV URL", got)
+		}
+
+		response = server.serve(httptest.NewRequest(http.MethodGet, "/app-passwords", nil), "")
+		assertStatus(t, response, http.StatusUnauthorized)
+	})
+
+	t.Run("make an app password that signs in to CalDAV", func(t *testing.T) {
+		server := newServer()
+
+		request := newFormRequest(t, http.MethodPost, "/app-passwords", "name=+Laptop+")
+		response := server.serve(request, "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if len(server.renderer.renderAppPasswordsCalls) != 1 {
+			t.Fatalf("got %d calls to RenderAppPasswords, want 1", len(server.renderer.renderAppPasswordsCalls))
+		}
+
+		got := server.renderer.renderAppPasswordsCalls[0]
+
+		if got.NewPasswordName != "Laptop" || got.NewPassword == "" || len(got.AppPasswords) != 2 {
+			t.Fatalf("got %+v, want the new password and both app passwords", got)
+		}
+
+		if appPassword := server.userStore.users[0].MatchAppPassword(got.NewPassword); appPassword == nil || appPassword.Name != "Laptop" {
+			t.Errorf("got %+v, want the new password to match the app password called Laptop", appPassword)
+		}
+
+		request = httptest.NewRequest(http.MethodGet, "/caldav/", nil)
+		request.SetBasicAuth("alice@example.com", got.NewPassword)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusMethodNotAllowed)
+	})
+
+	t.Run("reject app passwords without a name", func(t *testing.T) {
+		server := newServer()
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/app-passwords", "name=+"), "alice")
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("revoke an app password", func(t *testing.T) {
+		server := newServer()
+
+		response := server.serve(httptest.NewRequest(http.MethodDelete, "/app-passwords/1", nil), "alice")
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(server.userStore.users[0].AppPasswords) != 0 {
+			t.Errorf("got %+v, want no app passwords", server.userStore.users[0].AppPasswords)
+		}
+
+		request := httptest.NewRequest("PROPFIND", "/caldav/", nil)
+		request.SetBasicAuth("alice@example.com", "abcd-efgh-ijkm-npqr-stuv-wxyz")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusUnauthorized)
+
+		response = server.serve(httptest.NewRequest(http.MethodDelete, "/app-passwords/1", nil), "alice")
+		assertStatus(t, response, http.StatusNotFound)
+	})
+}
+
+func TestImport(t *testing.T) {
+	users := []string{"alice"}
+	todoTxt := "(A) Pay rent +home due:2025-03-14\nx Call Mum @phone\n"
+
+	t.Run("show the import form", func(t *testing.T) {
+		server := newTestServer(t, users, new(StubTaskStore))
+
+		response := server.serve(httptest.NewRequest(http.MethodGet, "/users/alice@example.com/import?tz=Pacific/Auckland", nil), "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		want := []yatta.ImportPage{{User: "alice@example.com", TimeZone: "Pacific/Auckland"}}
+
+		if !reflect.DeepEqual(server.renderer.renderImportCalls, want) {
+			t.Errorf("got %+v, want %+v", server.renderer.renderImportCalls, want)
+		}
+	})
+
+	t.Run("preview tasks without adding them", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := newTestServer(t, users, store)
+
+		request := newImportRequest(t, "/users/alice@example.com/import", todoTxt, map[string]string{"format": "todotxt", "dry-run": "true"})
+		response := server.serve(request, "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if len(store.addCalls) != 0 {
+			t.Errorf("got %v, want no tasks to be added", store.addCalls)
+		}
+
+		if len(server.renderer.renderImportCalls) != 1 {
+			t.Fatalf("got %d calls to RenderImport, want 1", len(server.renderer.renderImportCalls))
+		}
+
+		got := server.renderer.renderImportCalls[0]
+
+		if len(got.Preview) != 2 || got.Preview[0].Description != "Pay rent" || !got.Preview[1].Done || got.Imported != 0 {
+			t.Errorf("got %+v, want a preview of both tasks", got)
+		}
+	})
+
+	t.Run("import tasks with their subtasks", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := newTestServer(t, users, store)
+		board := `{
+			"lists": [{"id": "l1", "name": "Doing", "pos": 1}],
+			"cards": [{"id": "c1", "name": "Launch", "idList": "l1", "dueComplete": true}],
+			"checklists": [{"idCard": "c1", "checkItems": [{"name": "Write docs", "state": "complete"}]}]
+		}`
+
+		request := newImportRequest(t, "/users/alice@example.com/import", board, map[string]string{"format": "trello"})
+		response := server.serve(request, "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if want := []addTaskCall{{"alice@example.com", "Launch"}}; !reflect.DeepEqual(store.addCalls, want) {
+			t.Errorf("got added tasks %v, want %v", store.addCalls, want)
+		}
+
+		if want := []addSubtaskCall{{1, "Write docs"}}; !reflect.DeepEqual(store.addSubtaskCalls, want) {
+			t.Errorf("got added subtasks %v, want %v", store.addSubtaskCalls, want)
+		}
+
+		if want := []setDoneCall{{1, true}, {1, true}}; !reflect.DeepEqual(store.setDoneCalls, want) {
+			t.Errorf("got %v, want the subtask and then the task to be done", store.setDoneCalls)
+		}
+
+		if len(server.renderer.renderImportCalls) != 1 || server.renderer.renderImportCalls[0].Imported != 2 {
+			t.Errorf("got %+v, want two tasks to be imported", server.renderer.renderImportCalls)
+		}
+	})
+
+	t.Run("map CSV columns", func(t *testing.T) {
+		store := new(StubTaskStore)
+		server := newTestServer(t, users, store)
+		csv := "Item,Due Date\nPay rent,2025-03-14\n"
+
+		request := newImportRequest(t, "/users/alice@example.com/import", csv, map[string]string{"format": "csv", "column-description": "Item"})
+		response := server.serve(request, "alice")
+		assertStatus(t, response, http.StatusOK)
+
+		if want := []addTaskCall{{"alice@example.com", "Pay rent"}}; !reflect.DeepEqual(store.addCalls, want) {
+			t.Fatalf("got added tasks %v, want %v", store.addCalls, want)
+		}
+
+		if due := store.addDetails[0].Due; due == nil || due.Format(time.DateOnly) != "2025-03-14" {
+			t.Errorf("got due %v, want 2025-03-14", due)
+		}
+	})
+
+	t.Run("reject files that cannot be read", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			format string
+			file   string
+		}{
+			{"unknown format", "things", todoTxt},
+			{"invalid due date", "todotxt", "Pay rent due:tomorrow"},
+			{"invalid JSON", "todoist", "{"},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				store := new(StubTaskStore)
+				server := newTestServer(t, users, store)
+
+				request := newImportRequest(t, "/users/alice@example.com/import", test.file, map[string]string{"format": test.format})
+				response := server.serve(request, "alice")
+				assertStatus(t, response, http.StatusBadRequest)
+
+				if len(store.addCalls) != 0 {
+					t.Errorf("got %v, want no tasks to be added", store.addCalls)
+				}
+			})
+		}
+	})
+
+	t.Run("reject tasks that are nested too deeply before adding any", func(t *testing.T) {
+		items := []string{`{"id": "1", "content": "Level 1"}`}
+
+		for level := 2; level <= stores.MaxTaskDepth+1; level++ {
+			items = append(items, fmt.Sprintf(`{"id": "%d", "parent_id": "%d", "content": "Level %d"}`, level, level-1, level))
+		}
+
+		export := "[" + strings.Join(items, ",") + "]"
+
+		for _, fields := range []map[string]string{{"format": "todoist", "dry-run": "true"}, {"format": "todoist"}} {
+			store := new(StubTaskStore)
+			server := newTestServer(t, users, store)
+
+			response := server.serve(newImportRequest(t, "/users/alice@example.com/import", export, fields), "alice")
+			assertStatus(t, response, http.StatusBadRequest)
+
+			if len(store.addCalls) != 0 || len(store.addSubtaskCalls) != 0 {
+				t.Errorf("got added tasks %v and subtasks %v, want none", store.addCalls, store.addSubtaskCalls)
+			}
+		}
+	})
+
+	t.Run("say how many tasks were imported when the import fails part way through", func(t *testing.T) {
+		store := &StubTaskStore{err: errors.New("disk full")}
+		server := newTestServer(t, users, store)
+		board := `{
+			"lists": [{"id": "l1", "name": "Doing", "pos": 1}],
+			"cards": [{"id": "c1", "name": "Launch", "idList": "l1"}],
+			"checklists": [{"idCard": "c1", "checkItems": [{"name": "Write docs", "state": "incomplete"}]}]
+		}`
+
+		response := server.serve(newImportRequest(t, "/users/alice@example.com/import", board, map[string]string{"format": "trello"}), "alice")
+		assertStatus(t, response, http.StatusInternalServerError)
+
+		if body := response.Body.String(); !strings.Contains(body, "1 of 2 tasks") {
+			t.Errorf("got %q, want the response to say that 1 of 2 tasks were imported", body)
+		}
+	})
+
+	t.Run("reject requests that are not uploads", func(t *testing.T) {
+		server := newTestServer(t, users, new(StubTaskStore))
+
+		response := server.serve(newFormRequest(t, http.MethodPost, "/users/alice@example.com/import", "format=todotxt"), "alice")
+		assertStatus(t, response, http.StatusUnsupportedMediaType)
+	})
+}
+
+// Create a request that uploads `file` in the "file" field of a multipart form along with the other `fields`.
+func newImportRequest(t *testing.T, target string, file string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		yattatest.AssertNoError(t, writer.WriteField(name, value))
+	}
+
+	part, err := writer.CreateFormFile("file", "export")
+	yattatest.AssertNoError(t, err)
+	_, err = io.WriteString(part, file)
+	yattatest.AssertNoError(t, err)
+	yattatest.AssertNoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, target, body)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func TestSubtasks(t *testing.T) {
+	t.Run("get task renders its subtasks", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 1, Description: "prepare release"},
+			{ID: 2, Description: "write changelog", ParentID: 1},
+		}
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTaskCall(t, renderer, tasks[0], tasks[1:])
+	})
+
+	t.Run("add subtask", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/1/subtasks", strings.NewReader("write changelog"))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []addSubtaskCall{{parentID: 1, description: "write changelog"}}
+
+		if !reflect.DeepEqual(store.addSubtaskCalls, want) {
+			t.Errorf("got calls to AddSubtask %v, want %v", store.addSubtaskCalls, want)
+		}
+	})
+
+	t.Run("set parent", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/2/parent", strings.NewReader("parent=1"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []setParentCall{{id: 2, parentID: 1}}
+
+		if !reflect.DeepEqual(store.setParentCalls, want) {
+			t.Errorf("got calls to SetParent %v, want %v", store.setParentCalls, want)
+		}
+	})
+
+	t.Run("set parent with invalid parent returns bad request", func(t *testing.T) {
+		server := mustCreateServer(t, new(StubTaskStore), new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/2/parent", strings.NewReader("parent=one"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("complete and reopen task", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		for _, path := range []string{"/tasks/3/complete", "/tasks/3/reopen"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, path, nil))
+
+			assertStatus(t, response, http.StatusAccepted)
+		}
+
+		want := []setDoneCall{{id: 3, done: true}, {id: 3, done: false}}
+
+		if !reflect.DeepEqual(store.setDoneCalls, want) {
+			t.Errorf("got calls to SetDone %v, want %v", store.setDoneCalls, want)
+		}
+	})
+
+	t.Run("store errors map to HTTP statuses", func(t *testing.T) {
+		cases := []struct {
+			err  error
+			want int
+		}{
+			{stores.ErrTaskNotFound, http.StatusNotFound},
+			{stores.ErrTaskCycle, http.StatusBadRequest},
+			{stores.ErrMaxDepthExceeded, http.StatusBadRequest},
+			{stores.ErrDifferentList, http.StatusBadRequest},
+		}
+
+		for _, c := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{}, err: c.err}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			request := httptest.NewRequest(http.MethodPost, "/tasks/1/subtasks", strings.NewReader("subtask"))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.want)
+		}
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	t.Run("add dependency", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/2/dependencies", strings.NewReader("blocked_by=1"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []dependencyCall{{id: 2, blockerID: 1}}
+
+		if !reflect.DeepEqual(store.addDependencyCalls, want) {
+			t.Errorf("got calls to AddDependency %v, want %v", store.addDependencyCalls, want)
+		}
+	})
+
+	t.Run("dependency cycle returns bad request", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}, err: stores.ErrDependencyCycle}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/2/dependencies", strings.NewReader("blocked_by=1"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("remove dependency", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodDelete, "/tasks/2/dependencies/1", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []dependencyCall{{id: 2, blockerID: 1}}
+
+		if !reflect.DeepEqual(store.removeDependencyCalls, want) {
+			t.Errorf("got calls to RemoveDependency %v, want %v", store.removeDependencyCalls, want)
+		}
+	})
+
+	t.Run("ready view hides blocked tasks", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 1, Description: "get approval"},
+			{ID: 2, Description: "deploy", BlockedBy: []uint64{1}, Blocked: true},
+		}
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/tasks/ready", nil))
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, tasks[:1])
+	})
+
+	t.Run("next actions", func(t *testing.T) {
+		tasks := []models.Task{
+			{ID: 1, Description: "get approval"},
+			{ID: 2, Description: "deploy", BlockedBy: []uint64{1}, Blocked: true},
+		}
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/tasks/next", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderNextActionsCalls) != 1 || !reflect.DeepEqual(renderer.renderNextActionsCalls[0], tasks) {
+			t.Errorf("got calls to RenderNextActions %v, want a single call with %v", renderer.renderNextActionsCalls, tasks)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Description: "deploy website"},
+		{ID: 2, Description: "water plants"},
+	}
+
+	t.Run("renders search page with results", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/search?q=deploy", nil))
+
+		assertStatus(t, response, http.StatusOK)
+		assertContentType(t, response, htmlContentType)
+		assertRenderSearchCall(t, renderer, renderSearchCall{"deploy", tasks[:1], false})
+	})
+
+	t.Run("renders just the results for HTMX requests", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/search?q=water", nil)
+		request.Header.Add("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderSearchCall(t, renderer, renderSearchCall{"water", tasks[1:], true})
+	})
+}
+
+func TestTaskQueries(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Description: "deploy", Tags: []string{"work"}, Priority: 2},
+		{ID: 2, Description: "water plants", Tags: []string{"home"}},
+		{ID: 3, Description: "get approval", Tags: []string{"work"}, Done: true},
+	}
+
+	t.Run("filter tasks with a query", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		request := newGetTasksRequest(t, "Alice")
+		request.URL.RawQuery = url.Values{"q": {"tag:work -done"}}.Encode()
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, tasks[:1])
+	})
+
+	t.Run("invalid query returns bad request with the syntax error", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := newGetTasksRequest(t, "Alice")
+		request.URL.RawQuery = url.Values{"q": {"priority>=high"}}.Encode()
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+
+		if !strings.Contains(response.Body.String(), "syntax error at position 1") {
+			t.Errorf("got body %q, want syntax error message", response.Body.String())
+		}
+	})
+
+	t.Run("update task details", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/1", strings.NewReader("tags=work,+urgent&priority=3&due=2025-03-12&notes=-+%5B+%5D+check+logs"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.updateTaskCalls) != 1 {
+			t.Fatalf("got %d calls to UpdateTask, want 1", len(store.updateTaskCalls))
+		}
+
+		got := store.updateTaskCalls[0]
+
+		if got.id != 1 || got.update.Description != nil || !reflect.DeepEqual(got.update.Tags, []string{"work", "urgent"}) ||
+			got.update.Notes == nil || *got.update.Notes != "- [ ] check logs" ||
+			got.update.Priority == nil || *got.update.Priority != 3 ||
+			got.update.Due == nil || got.update.Due.Format(time.DateOnly) != "2025-03-12" {
+			t.Errorf("got call to UpdateTask %v, want tags, priority, due date and notes for task 1", got)
+		}
+	})
+
+	t.Run("update task with invalid priority returns bad request", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/1", strings.NewReader("priority=high"))
+		request.Header.Add("Content-Type", formContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("estimate tasks", func(t *testing.T) {
+		cases := map[string]float64{"estimate=2.5": 2.5, "estimate=": 0}
+
+		for body, want := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			request := httptest.NewRequest(http.MethodPost, "/tasks/1", strings.NewReader(body))
+			request.Header.Add("Content-Type", formContentType)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusAccepted)
+
+			if got := store.updateTaskCalls[0].update.Estimate; got == nil || *got != want {
+				t.Errorf("got estimate %v for %q, want %v", got, body, want)
+			}
+		}
+
+		for _, body := range []string{"estimate=lots", "estimate=-1", "estimate=NaN", "estimate=Inf"} {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			request := httptest.NewRequest(http.MethodPost, "/tasks/1", strings.NewReader(body))
+			request.Header.Add("Content-Type", formContentType)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestCalendar(t *testing.T) {
+	due := time.Date(2025, 3, 12, 9, 30, 0, 0, time.Local)
+	tasks := []models.Task{{ID: 1, Description: "build", Due: &due}, {ID: 2, Description: "design"}}
+
+	t.Run("show a month", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/calendar?date=2025-03-15", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderCalendarCalls) != 1 {
+			t.Fatalf("got %d calls to RenderCalendar, want 1", len(renderer.renderCalendarCalls))
+		}
+
+		page := renderer.renderCalendarCalls[0]
+
+		// March 2025 is shown from Monday 24 February to Sunday 6 April.
+		if page.View != "month" || len(page.Days) != 42 || page.Days[0].Date.Format(time.DateOnly) != "2025-02-24" {
+			t.Errorf("got %s view of %d days from %v, want month view of 42 days from 24 February", page.View, len(page.Days), page.Days[0].Date)
+		}
+
+		if entries := page.Days[16].Entries; len(entries) != 1 || entries[0].Task.ID != 1 {
+			t.Errorf("got entries %v on %v, want task 1", entries, page.Days[16].Date)
+		}
+	})
+
+	t.Run("show a week in another time zone", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/calendar?view=week&date=2025-03-12&tz=Pacific/Auckland", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		page := renderer.renderCalendarCalls[0]
+
+		if len(page.Days) != 7 || page.Days[0].Date.Location().String() != "Pacific/Auckland" || page.TimeZone != "Pacific/Auckland" {
+			t.Errorf("got %d days in %v, want 7 days in Pacific/Auckland", len(page.Days), page.Days[0].Date.Location())
+		}
+	})
+
+	t.Run("reject invalid calendars", func(t *testing.T) {
+		cases := map[string]int{
+			"/users/Alice/calendar?date=tomorrow":   http.StatusBadRequest,
+			"/users/Alice/calendar?view=year":       http.StatusBadRequest,
+			"/users/Alice/calendar?tz=Nowhere/Land": http.StatusBadRequest,
+			"/users/Bob/calendar":                   http.StatusNotFound,
+		}
+
+		for target, want := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assertStatus(t, response, want)
+		}
+	})
+
+	t.Run("reschedule a task keeping the time of day", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/users/Alice/calendar/2025-03-14", "task=1"))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+
+		if len(store.updateTaskCalls) != 1 || !store.updateTaskCalls[0].update.Due.Equal(want) {
+			t.Errorf("got calls to UpdateTask %v, want task 1 due %v", store.updateTaskCalls, want)
+		}
+	})
+
+	t.Run("reschedule a task without a due date in another time zone", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/users/Alice/calendar/2025-03-14?tz=UTC", "task=2"))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+		if len(store.updateTaskCalls) != 1 || !store.updateTaskCalls[0].update.Due.Equal(want) {
+			t.Errorf("got calls to UpdateTask %v, want task 2 due %v", store.updateTaskCalls, want)
+		}
+	})
+
+	t.Run("reject invalid reschedules", func(t *testing.T) {
+		cases := []struct {
+			target string
+			body   string
+			want   int
+		}{
+			{"/users/Alice/calendar/tomorrow", "task=1", http.StatusNotFound},
+			{"/users/Alice/calendar/2025-03-14", "task=first", http.StatusBadRequest},
+			{"/users/Alice/calendar/2025-03-14?tz=Nowhere/Land", "task=1", http.StatusBadRequest},
+			// Tasks can only be rescheduled on their owner's calendar.
+			{"/users/Bob/calendar/2025-03-14", "task=1", http.StatusNotFound},
+		}
+
+		for _, test := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, test.target, test.body))
+
+			assertStatus(t, response, test.want)
+
+			if len(store.updateTaskCalls) != 0 {
+				t.Errorf("got calls to UpdateTask %v for %s, want none", store.updateTaskCalls, test.target)
+			}
+		}
+	})
+
+	t.Run("set how often tasks repeat", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		for _, body := range []string{"repeat=every+2+weeks", "repeat=", "repeat=sometimes"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/1", body))
+
+			if body == "repeat=sometimes" {
+				assertStatus(t, response, http.StatusBadRequest)
+			} else {
+				assertStatus(t, response, http.StatusAccepted)
+			}
+		}
+
+		want := []updateTaskCall{
+			{1, models.TaskUpdate{Recurrence: &models.Recurrence{Frequency: models.Weekly, Interval: 2}}},
+			{1, models.TaskUpdate{ClearRecurrence: true}},
+		}
+
+		if !reflect.DeepEqual(store.updateTaskCalls, want) {
+			t.Errorf("got calls to UpdateTask %v, want %v", store.updateTaskCalls, want)
+		}
+	})
+}
+
+func TestSnooze(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	tasks := []models.Task{
+		{ID: 1, Description: "deploy", HiddenUntil: &tomorrow},
+		{ID: 2, Description: "test", ParentID: 1},
+		{ID: 3, Description: "water plants", HiddenUntil: &yesterday},
+	}
+
+	t.Run("snoozed tasks are hidden from the task list", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTasksRequest(t, "Alice"))
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, tasks[2:])
+	})
+
+	t.Run("show snoozed tasks when asked for", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/tasks?q=is%3Asnoozed", nil))
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, tasks[:1])
+	})
+
+	t.Run("snooze a task", func(t *testing.T) {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		cases := map[string]func(until time.Time) bool{
+			"until=tomorrow": func(until time.Time) bool {
+				return until.Equal(today.AddDate(0, 0, 1))
+			},
+			"until=next-week": func(until time.Time) bool {
+				return until.Weekday() == time.Monday && until.After(now) && until.Before(today.AddDate(0, 0, 8))
+			},
+			"until=2999-01-01": func(until time.Time) bool {
+				return until.Equal(time.Date(2999, 1, 1, 0, 0, 0, 0, time.Local))
+			},
+		}
+
+		for body, check := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/snooze", body))
+
+			assertStatus(t, response, http.StatusAccepted)
+
+			if len(store.updateTaskCalls) != 1 || store.updateTaskCalls[0].update.HiddenUntil == nil || !check(*store.updateTaskCalls[0].update.HiddenUntil) {
+				t.Errorf("got calls to UpdateTask %v for %q", store.updateTaskCalls, body)
+			}
+		}
+	})
+
+	t.Run("snooze a task until the start of the day in the time zone of the request", func(t *testing.T) {
+		location, _ := time.LoadLocation("Pacific/Auckland")
+		now := time.Now().In(location)
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, location)
+		cases := map[string]time.Time{
+			"until=tomorrow":   tomorrow,
+			"until=2999-01-01": time.Date(2999, 1, 1, 0, 0, 0, 0, location),
+		}
+
+		for body, want := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/snooze?tz=Pacific%2FAuckland", body))
+
+			assertStatus(t, response, http.StatusAccepted)
+
+			if len(store.updateTaskCalls) != 1 || store.updateTaskCalls[0].update.HiddenUntil == nil || !store.updateTaskCalls[0].update.HiddenUntil.Equal(want) {
+				t.Errorf("got calls to UpdateTask %v for %q, want hidden until %v", store.updateTaskCalls, body, want)
+			}
+		}
+
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/snooze?tz=Mars", "until=tomorrow"))
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+
+	t.Run("reject invalid snoozes", func(t *testing.T) {
+		for _, body := range []string{"until=", "until=later", "until=2000-01-01"} {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/3/snooze", body))
+
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}, err: stores.ErrTaskNotFound}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/9/snooze", "until=tomorrow"))
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("show a snoozed task again", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/tasks/1/snooze", nil))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []updateTaskCall{{1, models.TaskUpdate{ClearHiddenUntil: true}}}
+
+		if !reflect.DeepEqual(store.updateTaskCalls, want) {
+			t.Errorf("got calls to UpdateTask %v, want %v", store.updateTaskCalls, want)
+		}
+	})
+}
+
+func TestReport(t *testing.T) {
+	completedAt := time.Date(2025, 3, 4, 12, 0, 0, 0, time.Local)
+	tasks := []models.Task{{ID: 1, Description: "build", Estimate: 5}}
+	archive := []models.Task{{ID: 2, Description: "design", Estimate: 3, Done: true, CompletedAt: &completedAt}}
+
+	t.Run("report on active and archived tasks", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}, archive: map[string][]models.Task{"Alice": archive}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/report?from=2025-03-03&to=2025-03-05", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderReportCalls) != 1 {
+			t.Fatalf("got %d calls to RenderReport, want 1", len(renderer.renderReportCalls))
+		}
+
+		page := renderer.renderReportCalls[0]
+		var remaining []float64
+
+		for _, day := range page.Burndown {
+			remaining = append(remaining, day.Remaining)
+		}
+
+		if want := []float64{8, 5, 5}; !reflect.DeepEqual(remaining, want) {
+			t.Errorf("got remaining work %v, want %v", remaining, want)
+		}
+
+		if len(page.Throughput) != 1 || page.Throughput[0].Estimate != 3 {
+			t.Errorf("got throughput %v, want 3 completed in one week", page.Throughput)
+		}
+	})
+
+	t.Run("reports default to the last four weeks", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/report", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if got := len(renderer.renderReportCalls[0].Burndown); got != 28 {
+			t.Errorf("got %d days in the burndown, want 28", got)
+		}
+	})
+
+	t.Run("reject invalid dates and unknown users", func(t *testing.T) {
+		cases := map[string]int{
+			"/users/Alice/report?from=2025-03-05&to=2025-03-03": http.StatusBadRequest,
+			"/users/Alice/report?to=tomorrow":                   http.StatusBadRequest,
+			"/users/Bob/report":                                 http.StatusNotFound,
+		}
+
+		for target, want := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assertStatus(t, response, want)
+		}
+	})
+}
+
+func TestBoard(t *testing.T) {
+	tasks := []models.Task{{ID: 1, Description: "build"}, {ID: 2, Description: "design", Status: 2}}
+
+	t.Run("show the board", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/board", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		want := []yatta.BoardPage{{User: "Alice", Columns: models.DefaultColumns, Tasks: tasks}}
+
+		if !reflect.DeepEqual(renderer.renderBoardCalls, want) {
+			t.Errorf("got calls to RenderBoard %v, want %v", renderer.renderBoardCalls, want)
+		}
+	})
+
+	t.Run("unknown users have no board", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Bob/board", nil))
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("move a task to a column", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/users/Alice/board/3", "task=1"))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []moveTaskCall{{"Alice", 1, 3}}
+
+		if !reflect.DeepEqual(store.moveTaskCalls, want) {
+			t.Errorf("got calls to MoveTask %v, want %v", store.moveTaskCalls, want)
+		}
+	})
+
+	t.Run("report errors moving tasks", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			target string
+			body   string
+			err    error
+			want   int
+		}{
+			{"invalid task", "/users/Alice/board/3", "task=first", nil, http.StatusBadRequest},
+			{"invalid column", "/users/Alice/board/doing", "task=1", nil, http.StatusNotFound},
+			{"missing task", "/users/Alice/board/3", "task=9", stores.ErrTaskNotFound, http.StatusNotFound},
+			{"missing column", "/users/Alice/board/9", "task=1", stores.ErrColumnNotFound, http.StatusNotFound},
+			{"column full", "/users/Alice/board/2", "task=1", stores.ErrWIPLimitReached, http.StatusConflict},
+		}
+
+		for _, test := range cases {
+			t.Run(test.name, func(t *testing.T) {
+				store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}, err: test.err}
+				server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newFormRequest(t, http.MethodPost, test.target, test.body))
+
+				assertStatus(t, response, test.want)
+			})
+		}
+	})
+
+	t.Run("set the columns", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+
+		form := url.Values{
+			"id":    {"1", "2", "4", "0"},
+			"name":  {"To do", "", "Done", "Doing"},
+			"limit": {"0", "3", "", "2"},
+			"kind":  {"open", "open", "done", "open"},
+		}
+		request := newFormRequest(t, http.MethodPost, "/users/Alice/board/columns", form.Encode())
+		request.SetBasicAuth("Alice", "Alice")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []models.Column{
+			{ID: 1, Name: "To do"},
+			{ID: 4, Name: "Done", Done: true},
+			{ID: 0, Name: "Doing", WIPLimit: 2},
+		}
+
+		if got := store.columns["Alice"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("got columns %v, want %v", got, want)
+		}
+	})
+
+	t.Run("reject invalid columns", func(t *testing.T) {
+		cases := map[string]url.Values{
+			"missing fields": {"id": {"1"}, "name": {"To do"}},
+			"invalid ID":     {"id": {"one"}, "name": {"To do"}, "limit": {"0"}, "kind": {"open"}},
+			"invalid limit":  {"id": {"1"}, "name": {"To do"}, "limit": {"many"}, "kind": {"open"}},
+			"invalid kind":   {"id": {"1"}, "name": {"To do"}, "limit": {"0"}, "kind": {"blocked"}},
+		}
+
+		for name, form := range cases {
+			t.Run(name, func(t *testing.T) {
+				store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+				server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+
+				request := newFormRequest(t, http.MethodPost, "/users/Alice/board/columns", form.Encode())
+				request.SetBasicAuth("Alice", "Alice")
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, request)
+
+				assertStatus(t, response, http.StatusBadRequest)
+
+				if store.columns != nil {
+					t.Errorf("got columns %v, want the columns to be unchanged", store.columns)
+				}
+			})
+		}
+
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}, err: stores.ErrInvalidColumns}
+		server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+		form := url.Values{"id": {"1"}, "name": {"To do"}, "limit": {"0"}, "kind": {"open"}}
+
+		request := newFormRequest(t, http.MethodPost, "/users/Alice/board/columns", form.Encode())
+		request.SetBasicAuth("Alice", "Alice")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+	})
+}
+
+func TestSavedSearches(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Description: "deploy", Tags: []string{"work"}},
+		{ID: 2, Description: "water plants", Tags: []string{"home"}},
+	}
+
+	t.Run("create a saved search and view it as a smart list", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), renderer)
+
+		request := httptest.NewRequest(http.MethodPost, "/users/Alice/searches", strings.NewReader("name=Work&query=tag%3Awork"))
+		request.Header.Add("Content-Type", formContentType)
+		request.SetBasicAuth("Alice", "Alice")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/searches/1", nil))
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, tasks[:1])
+
+		page := renderer.renderTasksCalls[0]
+		wantSavedSearches := []models.SavedSearch{{ID: 1, Name: "Work", Query: "tag:work"}}
+
+		if page.Title != "Work" || !reflect.DeepEqual(page.SavedSearches, wantSavedSearches) {
+			t.Errorf("got page titled %q with smart lists %v, want %q with %v", page.Title, page.SavedSearches, "Work", wantSavedSearches)
+		}
+	})
+
+	t.Run("smart lists are shown alongside the task list", func(t *testing.T) {
+		store := &StubTaskStore{
+			store:         map[string][]models.Task{"Alice": tasks},
+			savedSearches: map[string][]models.SavedSearch{"Alice": {{ID: 1, Name: "Work", Query: "tag:work"}}},
+		}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTasksRequest(t, "Alice"))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if got := renderer.renderTasksCalls[0].SavedSearches; !reflect.DeepEqual(got, store.savedSearches["Alice"]) {
+			t.Errorf("got smart lists %v, want %v", got, store.savedSearches["Alice"])
+		}
+	})
+
+	t.Run("reject invalid saved searches", func(t *testing.T) {
+		cases := []string{"name=&query=tag%3Awork", "name=Work&query=tag%3A"}
+
+		for _, body := range cases {
+			store := &StubTaskStore{store: map[string][]models.Task{"Alice": tasks}}
+			server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+
+			request := httptest.NewRequest(http.MethodPost, "/users/Alice/searches", strings.NewReader(body))
+			request.Header.Add("Content-Type", formContentType)
+			request.SetBasicAuth("Alice", "Alice")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusBadRequest)
+
+			if len(store.savedSearches["Alice"]) != 0 {
+				t.Errorf("got saved searches %v for %q, want none", store.savedSearches["Alice"], body)
+			}
+		}
+	})
+
+	t.Run("delete saved search", func(t *testing.T) {
+		store := &StubTaskStore{
+			store:         map[string][]models.Task{"Alice": tasks},
+			savedSearches: map[string][]models.SavedSearch{"Alice": {{ID: 1, Name: "Work", Query: "tag:work"}}},
+		}
+		server := mustCreateServer(t, store, newOwnerStore(t, "Alice"), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodDelete, "/users/Alice/searches/1", nil)
+		request.SetBasicAuth("Alice", "Alice")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/searches/1", nil))
+		assertStatus(t, response, http.StatusNotFound)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusNotFound)
+	})
+}
+
+func TestTrash(t *testing.T) {
+	t.Run("delete task moves it to the trash", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/tasks/2", nil))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if !reflect.DeepEqual(store.deleteTaskCalls, []uint64{2}) {
+			t.Errorf("got calls to DeleteTask %v, want %v", store.deleteTaskCalls, []uint64{2})
+		}
+	})
+
+	t.Run("delete missing task returns not found", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}, err: stores.ErrTaskNotFound}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/tasks/2", nil))
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("get trash", func(t *testing.T) {
+		trash := []models.Task{{ID: 2, Description: "bump version"}}
+		store := &StubTaskStore{store: map[string][]models.Task{}, trash: map[string][]models.Task{"Alice": trash}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/trash", nil))
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderTrashCalls) != 1 || !reflect.DeepEqual(renderer.renderTrashCalls[0], trash) {
+			t.Errorf("got calls to RenderTrash %v, want one call with %v", renderer.renderTrashCalls, trash)
+		}
+	})
+
+	t.Run("restore and permanently delete tasks", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/trash/2/restore", nil)
+		request.Header.Add("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := response.Header().Get("HX-Refresh"); got != "true" {
+			t.Errorf("got HX-Refresh header %q, want %q", got, "true")
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/trash/3", nil))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if !reflect.DeepEqual(store.restoreTaskCalls, []uint64{2}) || !reflect.DeepEqual(store.purgeTaskCalls, []uint64{3}) {
+			t.Errorf("got calls to RestoreTask %v and PurgeTask %v, want [2] and [3]", store.restoreTaskCalls, store.purgeTaskCalls)
+		}
+	})
+
+	t.Run("restore task that is not in the trash returns not found", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}, err: stores.ErrTaskNotFound}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/trash/2/restore", nil))
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("get archive", func(t *testing.T) {
+		archive := []models.Task{{ID: 1, Description: "prepare release", Done: true}}
+		store := &StubTaskStore{store: map[string][]models.Task{}, archive: map[string][]models.Task{"Alice": archive}}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, new(DummyUserStore), renderer)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/Alice/archive", nil))
+
+		assertStatus(t, response, http.StatusOK)
+		assertRenderTasksCall(t, renderer, archive)
+
+		if got := renderer.renderTasksCalls[0].Title; got != "Archive" {
+			t.Errorf("got title %q, want %q", got, "Archive")
+		}
+	})
+}
+
+func TestHousekeeping(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("purges trash and archives completed tasks", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		housekeeping := yatta.Housekeeping{Store: store, TrashRetention: 30 * 24 * time.Hour, ArchiveAfter: 7 * 24 * time.Hour}
+
+		yattatest.AssertNoError(t, housekeeping.Run(now))
+
+		wantPurge := []time.Time{time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)}
+		wantArchive := []time.Time{time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC)}
+
+		if !reflect.DeepEqual(store.purgeTrashCalls, wantPurge) || !reflect.DeepEqual(store.archiveTaskCalls, wantArchive) {
+			t.Errorf("got cutoffs %v for PurgeTrash and %v for ArchiveCompletedTasks, want %v and %v",
+				store.purgeTrashCalls, store.archiveTaskCalls, wantPurge, wantArchive)
+		}
+	})
+
+	t.Run("deletes blobs that are not attached to any task", func(t *testing.T) {
+		blobStore := mustCreateBlobStore(t, 1024)
+		attached, _, err := blobStore.Put(strings.NewReader("attached"))
+		yattatest.AssertNoError(t, err)
+		_, _, err = blobStore.Put(strings.NewReader("unattached"))
+		yattatest.AssertNoError(t, err)
+
+		store := &StubTaskStore{store: map[string][]models.Task{}, attachments: []models.Attachment{{ID: 1, TaskID: 1, Key: attached}}}
+		housekeeping := yatta.Housekeeping{Store: store, Blobs: blobStore, TrashRetention: time.Hour}
+
+		// Blobs that were just stored may be about to be attached to a task.
+		yattatest.AssertNoError(t, housekeeping.Run(time.Now()))
+		assertBlobCount(t, blobStore, 2)
+
+		yattatest.AssertNoError(t, housekeeping.Run(time.Now().Add(2*time.Hour)))
+		assertBlobCount(t, blobStore, 1)
+
+		_, err = blobStore.Open(attached)
+		yattatest.AssertNoError(t, err)
+	})
+
+	t.Run("archiving can be disabled", func(t *testing.T) {
+		store := &StubTaskStore{store: map[string][]models.Task{}}
+		housekeeping := yatta.Housekeeping{Store: store, TrashRetention: time.Hour}
+
+		yattatest.AssertNoError(t, housekeeping.Run(now))
+
+		if len(store.purgeTrashCalls) != 1 || len(store.archiveTaskCalls) != 0 {
+			t.Errorf("got %d calls to PurgeTrash and %d to ArchiveCompletedTasks, want 1 and 0",
+				len(store.purgeTrashCalls), len(store.archiveTaskCalls))
+		}
+	})
+}
+
+func assertBlobCount(t *testing.T, blobStore stores.BlobStore, want int) {
+	t.Helper()
+
+	keys, err := blobStore.GetKeys(time.Now().Add(24 * time.Hour))
+	yattatest.AssertNoError(t, err)
+
+	if len(keys) != want {
+		t.Errorf("got %d blobs, want %d", len(keys), want)
+	}
+}
+
+func TestComments(t *testing.T) {
+	alice := models.User{ID: 1, Email: "alice@example.com", Password: yattatest.MustCreatePasswordHash(t, "alicepassword")}
+	bob := models.User{ID: 2, Email: "bob@example.com", Password: yattatest.MustCreatePasswordHash(t, "bobpassword")}
+	userStore := &StubUserStore{users: []models.User{alice, bob}}
+	tasks := map[string][]models.Task{alice.Email: {{ID: 1, Description: "deploy"}}}
+
+	t.Run("adding a comment requires signing in", func(t *testing.T) {
+		cases := map[string]func(r *http.Request){
+			"no credentials":    func(r *http.Request) {},
+			"wrong password":    func(r *http.Request) { r.SetBasicAuth(alice.Email, "bobpassword") },
+			"unknown user":      func(r *http.Request) { r.SetBasicAuth("carol@example.com", "alicepassword") },
+			"missing user name": func(r *http.Request) { r.SetBasicAuth("", "") },
+		}
+
+		for name, authenticate := range cases {
+			store := &StubTaskStore{store: tasks}
+			server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+			request := newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=hello")
+			authenticate(request)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusUnauthorized)
+
+			if response.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: got no WWW-Authenticate header, want a request for credentials", name)
+			}
+
+			if len(store.comments) != 0 {
+				t.Errorf("%s: got comments %v, want none", name, store.comments)
+			}
+		}
+	})
+
+	t.Run("add comment as the signed in user", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, userStore, renderer)
+
+		request := newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=Needs+a+staging+run")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		want := []models.Comment{{ID: 1, TaskID: 1, Author: alice.Email, Body: "Needs a staging run"}}
+
+		if !reflect.DeepEqual(store.comments, want) {
+			t.Errorf("got comments %v, want %v", store.comments, want)
+		}
+
+		if len(renderer.renderCommentCalls) != 0 {
+			t.Errorf("got %d calls to RenderComment, want none for a request not made by HTMX", len(renderer.renderCommentCalls))
+		}
+	})
+
+	t.Run("comments added with HTMX are rendered", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, userStore, renderer)
+
+		request := newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=hello")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		request.Header.Add("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		if !reflect.DeepEqual(renderer.renderCommentCalls, store.comments) {
+			t.Errorf("got calls to RenderComment %v, want %v", renderer.renderCommentCalls, store.comments)
+		}
+	})
+
+	t.Run("reject empty comments and comments on missing tasks", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := newCommentRequest(t, http.MethodPost, "/tasks/1/comments", "body=+")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusBadRequest)
+
+		store.err = stores.ErrTaskNotFound
+		request = newCommentRequest(t, http.MethodPost, "/tasks/42/comments", "body=hello")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	t.Run("only the author can edit or delete a comment", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks, comments: []models.Comment{{ID: 1, TaskID: 1, Author: alice.Email, Body: "hello"}}}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := newCommentRequest(t, http.MethodPost, "/comments/1", "body=edited")
+		request.SetBasicAuth(bob.Email, "bobpassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusForbidden)
+
+		request = newCommentRequest(t, http.MethodDelete, "/comments/1", "")
+		request.SetBasicAuth(bob.Email, "bobpassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusForbidden)
+
+		request = newCommentRequest(t, http.MethodPost, "/comments/1", "body=edited")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusAccepted)
+
+		if store.comments[0].Body != "edited" {
+			t.Errorf("got comment %q, want %q", store.comments[0].Body, "edited")
+		}
+
+		request = newCommentRequest(t, http.MethodDelete, "/comments/1", "")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.comments) != 0 {
+			t.Errorf("got comments %v, want none", store.comments)
+		}
+	})
+
+	t.Run("task page shows comments, activity and the signed in user", func(t *testing.T) {
+		comments := []models.Comment{{ID: 1, TaskID: 1, Author: alice.Email, Body: "hello"}}
+		activity := []models.Activity{{TaskID: 1, Kind: models.ActivityCreated}}
+		store := &StubTaskStore{store: tasks, comments: comments, activity: activity}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, userStore, renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+		request.SetBasicAuth(bob.Email, "bobpassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		want := yatta.TaskPage{Task: tasks[alice.Email][0], Comments: comments, Activity: activity, User: bob.Email, Assignable: []string{alice.Email}}
+
+		if len(renderer.renderTaskCalls) != 1 || !reflect.DeepEqual(renderer.renderTaskCalls[0], want) {
+			t.Errorf("got calls to RenderTask %v, want one call with %v", renderer.renderTaskCalls, want)
+		}
+	})
+
+	t.Run("sign in redirects back to the page", func(t *testing.T) {
+		server := mustCreateServer(t, new(StubTaskStore), userStore, new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/login?next=/tasks/1", nil))
+		assertStatus(t, response, http.StatusUnauthorized)
+
+		cases := map[string]string{
+			"/tasks/1":                     "/tasks/1",
+			"https://example.com/phishing": "/",
+			"//example.com/phishing":       "/",
+		}
+
+		for next, want := range cases {
+			request := httptest.NewRequest(http.MethodGet, "/login?"+url.Values{"next": {next}}.Encode(), nil)
+			request.SetBasicAuth(alice.Email, "alicepassword")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusSeeOther)
+
+			if got := response.Header().Get("Location"); got != want {
+				t.Errorf("got redirect to %q for next page %q, want %q", got, next, want)
+			}
+		}
+	})
+}
+
+func newCommentRequest(t *testing.T, method string, target string, body string) *http.Request {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Add("Content-Type", formContentType)
+
+	return request
+}
+
+// Create a request with the form in `body`, e.g., "name=Work&query=tag%3Awork".
+func newFormRequest(t *testing.T, method string, target string, body string) *http.Request {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Add("Content-Type", formContentType)
+
+	return request
+}
+
+func TestAttachments(t *testing.T) {
+	tasks := map[string][]models.Task{"Alice": {{ID: 1, Description: "fix layout"}}}
+	// The first bytes of a PNG image.
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	t.Run("upload files", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer), yatta.WithBlobStore(mustCreateBlobStore(t, 1024)))
+
+		request := newAttachmentRequest(t, "/tasks/1/attachments", map[string]string{"screenshot.png": png, "notes.txt": "hello"})
+		request.Header.Add("HX-Request", "true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if got := response.Header().Get("HX-Refresh"); got != "true" {
+			t.Errorf("got HX-Refresh header %q, want %q", got, "true")
+		}
+
+		got := map[string]string{}
+
+		for _, attachment := range store.attachments {
+			got[attachment.Name] = attachment.ContentType
+
+			if attachment.TaskID != 1 || attachment.Key == "" || attachment.UploadedAt.IsZero() {
+				t.Errorf("got attachment %v, want an attachment on task 1 with a key and upload time", attachment)
+			}
+		}
+
+		want := map[string]string{"screenshot.png": "image/png", "notes.txt": "text/plain; charset=utf-8"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got attachments with content types %v, want %v", got, want)
+		}
+	})
+
+	t.Run("reject uploads that are too large, empty or for missing tasks", func(t *testing.T) {
+		cases := []struct {
+			target string
+			files  map[string]string
+			want   int
+		}{
+			{"/tasks/1/attachments", map[string]string{"big.txt": strings.Repeat("a", 17)}, http.StatusRequestEntityTooLarge},
+			{"/tasks/1/attachments", map[string]string{}, http.StatusBadRequest},
+			{"/tasks/42/attachments", map[string]string{"notes.txt": "hello"}, http.StatusNotFound},
+		}
+
+		for _, test := range cases {
+			store := &StubTaskStore{store: tasks}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer), yatta.WithBlobStore(mustCreateBlobStore(t, 16)))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newAttachmentRequest(t, test.target, test.files))
+
+			assertStatus(t, response, test.want)
+
+			if len(store.attachments) != 0 {
+				t.Errorf("got attachments %v, want none", store.attachments)
+			}
+		}
+	})
+
+	t.Run("uploads must be multipart forms", func(t *testing.T) {
+		server := mustCreateServer(t, &StubTaskStore{store: tasks}, new(DummyUserStore), new(SpyRenderer), yatta.WithBlobStore(mustCreateBlobStore(t, 1024)))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newFormRequest(t, http.MethodPost, "/tasks/1/attachments", "file=hello"))
+
+		assertStatus(t, response, http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("download files", func(t *testing.T) {
+		cases := []struct {
+			name            string
+			contents        string
+			wantType        string
+			wantDisposition string
+		}{
+			{"screenshot.png", png, "image/png", `inline; filename=screenshot.png`},
+			{"page.html", "<script>alert(1)</script>", "text/html; charset=utf-8", `attachment; filename=page.html`},
+			{"résumé.txt", "hello", "text/plain; charset=utf-8", `inline; filename*=utf-8''r%C3%A9sum%C3%A9.txt`},
+		}
+
+		for _, test := range cases {
+			store := &StubTaskStore{store: tasks}
+			server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer), yatta.WithBlobStore(mustCreateBlobStore(t, 1024)))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newAttachmentRequest(t, "/tasks/1/attachments", map[string]string{test.name: test.contents}))
+			assertStatus(t, response, http.StatusAccepted)
+
+			response = httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/attachments/1", nil))
+
+			assertStatus(t, response, http.StatusOK)
+
+			if got := response.Body.String(); got != test.contents {
+				t.Errorf("%s: got contents %q, want %q", test.name, got, test.contents)
+			}
+
+			headers := map[string]string{
+				"Content-Type":           test.wantType,
+				"Content-Disposition":    test.wantDisposition,
+				"X-Content-Type-Options": "nosniff",
+			}
+
+			for header, want := range headers {
+				if got := response.Header().Get(header); got != want {
+					t.Errorf("%s: got %s header %q, want %q", test.name, header, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("delete files", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks, attachments: []models.Attachment{{ID: 1, TaskID: 1, Name: "notes.txt"}}}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer), yatta.WithBlobStore(mustCreateBlobStore(t, 1024)))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/attachments/1", nil))
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.attachments) != 0 {
+			t.Errorf("got attachments %v, want none", store.attachments)
+		}
+
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			response = httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(method, "/attachments/1", nil))
+
+			assertStatus(t, response, http.StatusNotFound)
+		}
+	})
+
+	t.Run("attachments are disabled without a blob store", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, new(DummyUserStore), new(SpyRenderer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAttachmentRequest(t, "/tasks/1/attachments", map[string]string{"notes.txt": "hello"}))
+
+		if response.Code == http.StatusAccepted || len(store.attachments) != 0 {
+			t.Errorf("got status %d and attachments %v, want the upload to be rejected", response.Code, store.attachments)
+		}
+	})
+}
+
+func mustCreateBlobStore(t *testing.T, maxSize int64) *stores.FileBlobStore {
+	t.Helper()
+
+	blobStore, err := stores.NewFileBlobStore(t.TempDir(), maxSize)
+
+	if err != nil {
+		t.Fatalf("could not create blob store: %v", err)
+	}
+
+	return blobStore
+}
+
+// Create a request that uploads `files`, a map of file names to their contents, in the "file" field of a multipart form.
+func newAttachmentRequest(t *testing.T, target string, files map[string]string) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, contents := range files {
+		part, err := writer.CreateFormFile("file", name)
+		yattatest.AssertNoError(t, err)
+		_, err = io.WriteString(part, contents)
+		yattatest.AssertNoError(t, err)
+	}
+
+	yattatest.AssertNoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, target, body)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func TestTimeTracking(t *testing.T) {
+	alice := models.User{ID: 1, Email: "alice@example.com", Password: yattatest.MustCreatePasswordHash(t, "alicepassword")}
+	userStore := &StubUserStore{users: []models.User{alice}}
+	tasks := map[string][]models.Task{alice.Email: {{ID: 1, Description: "deploy"}}}
+
+	t.Run("tracking time requires signing in", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		for _, target := range []string{"/tasks/1/timer", "/timer/stop", "/tasks/1/time"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newFormRequest(t, http.MethodPost, target, "start=2025-03-03T09:00&duration=1h"))
+
+			assertStatus(t, response, http.StatusUnauthorized)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/timesheet", nil))
+
+		assertStatus(t, response, http.StatusUnauthorized)
+
+		if len(store.timeEntries) != 0 {
+			t.Errorf("got time entries %v, want none", store.timeEntries)
+		}
+	})
+
+	t.Run("start and stop a timer", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/1/timer", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if len(store.timeEntries) != 1 || !store.timeEntries[0].Running() || store.timeEntries[0].User != alice.Email {
+			t.Fatalf("got time entries %v, want a running timer for Alice", store.timeEntries)
+		}
+
+		request = httptest.NewRequest(http.MethodPost, "/timer/stop", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		if store.timeEntries[0].Running() {
+			t.Errorf("got running timer %v, want it stopped", store.timeEntries[0])
+		}
+
+		request = httptest.NewRequest(http.MethodPost, "/timer/stop", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusConflict)
+	})
+
+	t.Run("starting a second timer is a conflict", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks, err: stores.ErrTimerRunning}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodPost, "/tasks/1/timer", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusConflict)
+	})
+
+	t.Run("enter time by hand", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := newFormRequest(t, http.MethodPost, "/tasks/1/time", "start=2025-03-03T09:00&duration=1h30m&note=+release+notes+")
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+		end := start.Add(90 * time.Minute)
+		want := []models.TimeEntry{{ID: 1, TaskID: 1, User: alice.Email, Start: start, End: &end, Note: "release notes"}}
+
+		if !reflect.DeepEqual(store.timeEntries, want) {
+			t.Errorf("got time entries %v, want %v", store.timeEntries, want)
+		}
+	})
+
+	t.Run("reject invalid time entries", func(t *testing.T) {
+		for _, body := range []string{"start=yesterday&duration=1h", "start=2025-03-03T09:00&duration=an+hour", "start=2025-03-03T09:00"} {
+			store := &StubTaskStore{store: tasks}
+			server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+			request := newFormRequest(t, http.MethodPost, "/tasks/1/time", body)
+			request.SetBasicAuth(alice.Email, "alicepassword")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("delete time entries", func(t *testing.T) {
+		store := &StubTaskStore{store: tasks, err: stores.ErrNotTimeEntryOwner}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodDelete, "/time/1", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusForbidden)
+	})
+
+	t.Run("get timesheet for a date range", func(t *testing.T) {
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+		end := start.Add(90 * time.Minute)
+		entries := []models.TimeEntry{
+			{ID: 1, TaskID: 1, User: alice.Email, Start: start, End: &end},
+			{ID: 2, TaskID: 1, User: alice.Email, Start: start.AddDate(0, 0, 7), End: &end},
+		}
+		store := &StubTaskStore{store: tasks, timeEntries: entries}
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, store, userStore, renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/timesheet?from=2025-03-03&to=2025-03-09", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		if len(renderer.renderTimesheetCalls) != 1 {
+			t.Fatalf("got %d calls to RenderTimesheet, want 1", len(renderer.renderTimesheetCalls))
+		}
+
+		page := renderer.renderTimesheetCalls[0]
+
+		if !reflect.DeepEqual(page.Entries, entries[:1]) || page.Tasks[1].Description != "deploy" {
+			t.Errorf("got entries %v with tasks %v, want %v with task 1", page.Entries, page.Tasks, entries[:1])
+		}
+
+		if page.User != alice.Email || page.From.Format("2006-01-02") != "2025-03-03" || page.To.Format("2006-01-02") != "2025-03-09" {
+			t.Errorf("got timesheet for %q from %v to %v, want Alice's from 2025-03-03 to 2025-03-09", page.User, page.From, page.To)
+		}
+	})
+
+	t.Run("timesheets default to the current week", func(t *testing.T) {
+		renderer := new(SpyRenderer)
+		server := mustCreateServer(t, &StubTaskStore{store: tasks}, userStore, renderer)
+
+		request := httptest.NewRequest(http.MethodGet, "/timesheet", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		page := renderer.renderTimesheetCalls[0]
+
+		if page.From.Weekday() != time.Monday || page.To.Sub(page.From) != 6*24*time.Hour || page.From.After(time.Now()) {
+			t.Errorf("got timesheet from %v to %v, want the current week starting on Monday", page.From, page.To)
+		}
+	})
+
+	t.Run("reject invalid date ranges", func(t *testing.T) {
+		for _, query := range []string{"from=last+week", "from=2025-03-09&to=2025-03-03"} {
+			server := mustCreateServer(t, &StubTaskStore{store: tasks}, userStore, new(SpyRenderer))
+
+			request := httptest.NewRequest(http.MethodGet, "/timesheet?"+query, nil)
+			request.SetBasicAuth(alice.Email, "alicepassword")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("export timesheet as CSV", func(t *testing.T) {
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+		end := start.Add(90 * time.Minute)
+		entries := []models.TimeEntry{{ID: 1, TaskID: 1, User: alice.Email, Start: start, End: &end, Note: "release, notes"}}
+		server := mustCreateServer(t, &StubTaskStore{store: tasks, timeEntries: entries}, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodGet, "/timesheet.csv?from=2025-03-03&to=2025-03-09", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		if got := response.Header().Get("Content-Disposition"); got != "attachment; filename=timesheet-2025-03-03-2025-03-09.csv" {
+			t.Errorf("got Content-Disposition %q, want an attachment named after the dates", got)
+		}
+
+		want := "Date,Task ID,Task,Start,End,Hours,Note\n" +
+			fmt.Sprintf("2025-03-03,1,deploy,%s,%s,1.50,\"release, notes\"\n", start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+		if got := response.Body.String(); got != want {
+			t.Errorf("got CSV %q, want %q", got, want)
+		}
+	})
+
+	t.Run("keep spreadsheets from running text in the CSV as formulas", func(t *testing.T) {
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+		end := start.Add(time.Hour)
+		notes := []string{"=HYPERLINK(\"https://example.com\")", "+1", "-1", "@SUM(A1)", "\t=1", "\r=1", "a=b"}
+		var entries []models.TimeEntry
+
+		for i, note := range notes {
+			entries = append(entries, models.TimeEntry{ID: uint64(i + 1), TaskID: 2, User: alice.Email, Start: start, End: &end, Note: note})
+		}
+
+		store := &StubTaskStore{store: map[string][]models.Task{alice.Email: {{ID: 2, Description: "=cmd|' /C calc'!A0"}}}, timeEntries: entries}
+		server := mustCreateServer(t, store, userStore, new(SpyRenderer))
+
+		request := httptest.NewRequest(http.MethodGet, "/timesheet.csv?from=2025-03-03&to=2025-03-09", nil)
+		request.SetBasicAuth(alice.Email, "alicepassword")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+
+		records, err := csv.NewReader(response.Body).ReadAll()
+		yattatest.AssertNoError(t, err)
+		want := []string{"'=HYPERLINK(\"https://example.com\")", "'+1", "'-1", "'@SUM(A1)", "'\t=1", "'\r=1", "a=b"}
+
+		if len(records) != len(want)+1 {
+			t.Fatalf("got %d rows, want a header and %d entries", len(records), len(want))
+		}
+
+		for i, record := range records[1:] {
+			if record[2] != "'=cmd|' /C calc'!A0" {
+				t.Errorf("got task %q, want it to start with a quote", record[2])
+			}
+
+			if record[6] != want[i] {
+				t.Errorf("got note %q, want %q", record[6], want[i])
+			}
+		}
+	})
+}
+
 func TestCreateUser(t *testing.T) {
 	t.Run("can create a new user", func(t *testing.T) {
 		cases := []createUserRequestData{
@@ -199,168 +3992,1452 @@ func TestCreateUser(t *testing.T) {
 			{"foo@bar.com", "baz"},
 		}
 
-		for _, c := range cases {
-			store := new(SpyUserStore)
+		for _, c := range cases {
+			store := new(SpyUserStore)
+
+			server := mustCreateServer(t, new(DummyTaskStore), store, new(DummyRenderer))
+			request := newCreateUserRequest(t, c)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusAccepted)
+			assertAddUserCalls(t, store, c)
+		}
+	})
+
+	server := mustCreateServer(t, new(DummyTaskStore), new(DummyUserStore), new(DummyRenderer))
+
+	t.Run("wrong content type returns HTTP status unsupported media type", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/users", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("invalid form returns HTTP status bad request", func(t *testing.T) {
+		cases := []io.Reader{
+			nil,
+			strings.NewReader(""),
+			strings.NewReader("email="),
+			strings.NewReader("password="),
+		}
+
+		for _, requestBody := range cases {
+			request := httptest.NewRequest(http.MethodPost, "/users", requestBody)
+			request.Header.Add("Content-Type", formContentType)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response, http.StatusBadRequest)
+		}
+	})
+
+	// TODO: validate emails for formatting and uniqueness, and passwords for strength.
+}
+
+const htmlContentType = "text/html"
+const formContentType = "application/x-www-form-urlencoded"
+
+type addTaskCall struct {
+	user string
+	task string
+}
+
+type getTasksCall struct {
+	user  string
+	tasks []models.Task
+}
+
+type addSubtaskCall struct {
+	parentID    uint64
+	description string
+}
+
+type getTaskCall struct {
+	id   uint64
+	task *models.Task
+}
+
+type setParentCall struct {
+	id       uint64
+	parentID uint64
+}
+
+type setDoneCall struct {
+	id   uint64
+	done bool
+}
+
+type updateTaskCall struct {
+	id     uint64
+	update models.TaskUpdate
+}
+
+type dependencyCall struct {
+	id        uint64
+	blockerID uint64
+}
+
+type StubTaskStore struct {
+	DummyTaskStore
+	store    map[string][]models.Task
+	addCalls []addTaskCall
+	// The details passed to AddTaskWithDetails, in the same order as `addCalls`.
+	addDetails      []models.TaskUpdate
+	getTasksCalls   []getTasksCall
+	getTaskCalls    []getTaskCall
+	addSubtaskCalls []addSubtaskCall
+	setParentCalls  []setParentCall
+	setDoneCalls    []setDoneCall
+	// Calls to AddDependency and RemoveDependency.
+	addDependencyCalls    []dependencyCall
+	removeDependencyCalls []dependencyCall
+	updateTaskCalls       []updateTaskCall
+	savedSearches         map[string][]models.SavedSearch
+	// Calls to DeleteTask, RestoreTask and PurgeTask.
+	deleteTaskCalls  []uint64
+	restoreTaskCalls []uint64
+	purgeTaskCalls   []uint64
+	trash            map[string][]models.Task
+	archive          map[string][]models.Task
+	// The cutoff times passed to PurgeTrash and ArchiveCompletedTasks.
+	purgeTrashCalls  []time.Time
+	archiveTaskCalls []time.Time
+	comments         []models.Comment
+	activity         []models.Activity
+	attachments      []models.Attachment
+	timeEntries      []models.TimeEntry
+	columns          map[string][]models.Column
+	moveTaskCalls    []moveTaskCall
+	templates        map[string][]models.Template
+	addTemplateCalls []addTemplateCall
+	useTemplateCalls []useTemplateCall
+	// The members that each user's list is shared with.
+	members map[string][]models.Member
+	// The notifications of each user, oldest first.
+	notifications map[string][]models.Notification
+	// The webhooks of each user's list.
+	webhooks map[string][]models.Webhook
+	// The inbound token of each user's list.
+	inboundTokens map[string]string
+	// The calendar feed token of each user's list.
+	feedTokens map[string]string
+	// The error returned by methods that modify the store.
+	err error
+	// The error returned by AddNotification, if set, while the other methods keep working.
+	addNotificationErr error
+}
+
+func (s *StubTaskStore) GetTasks(user string) ([]models.Task, error) {
+	tasks := s.store[user]
+
+	s.getTasksCalls = append(s.getTasksCalls, getTasksCall{user, tasks})
+
+	return tasks, nil
+}
+
+func (s *StubTaskStore) GetTask(id uint64) (*models.Task, error) {
+	for _, tasks := range s.store {
+		for _, task := range tasks {
+			if task.ID == id {
+				s.getTaskCalls = append(s.getTaskCalls, getTaskCall{id: id, task: &task})
+				return &task, nil
+			}
+		}
+	}
+
+	s.getTaskCalls = append(s.getTaskCalls, getTaskCall{id: id, task: nil})
+	return nil, nil
+}
+
+type renderSearchCall struct {
+	query   string
+	results []models.Task
+	// Whether just the search results were rendered.
+	fragment bool
+}
+
+type SpyRenderer struct {
+	renderIndexCalls         [][]models.User
+	renderTasksCalls         []yatta.TaskListPage
+	renderTaskCalls          []yatta.TaskPage
+	renderCommentCalls       []models.Comment
+	renderNextActionsCalls   [][]models.Task
+	renderSearchCalls        []renderSearchCall
+	renderTrashCalls         [][]models.Task
+	renderTimesheetCalls     []yatta.TimesheetPage
+	renderReportCalls        []yatta.ReportPage
+	renderBoardCalls         []yatta.BoardPage
+	renderCalendarCalls      []yatta.CalendarPage
+	renderTemplatesCalls     []yatta.TemplatesPage
+	renderMembersCalls       []yatta.MembersPage
+	renderWorkspaceCalls     []yatta.WorkspacePage
+	renderAssignedCalls      []yatta.AssignedPage
+	renderNotificationsCalls []yatta.NotificationsPage
+	// The number of unread notifications passed to each call of RenderNotificationBell.
+	renderNotificationBellCalls []int
+	renderWebhooksCalls         []yatta.WebhooksPage
+	renderInboundCalls          []yatta.InboundPage
+	renderFeedCalls             []yatta.FeedPage
+	renderAppPasswordsCalls     []yatta.AppPasswordsPage
+	renderImportCalls           []yatta.ImportPage
+	renderQuickAddCalls         []quickadd.Result
+}
+
+func (s *SpyRenderer) RenderIndex(users []models.User) ([]byte, error) {
+	s.renderIndexCalls = append(s.renderIndexCalls, users)
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderTaskList(page yatta.TaskListPage) ([]byte, error) {
+	s.renderTasksCalls = append(s.renderTasksCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderQuickAddPreview(result quickadd.Result) ([]byte, error) {
+	s.renderQuickAddCalls = append(s.renderQuickAddCalls, result)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderNextActions(tasks []models.Task) ([]byte, error) {
+	s.renderNextActionsCalls = append(s.renderNextActionsCalls, tasks)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderSearch(query string, results []models.Task) ([]byte, error) {
+	s.renderSearchCalls = append(s.renderSearchCalls, renderSearchCall{query, results, false})
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderSearchResults(query string, results []models.Task) ([]byte, error) {
+	s.renderSearchCalls = append(s.renderSearchCalls, renderSearchCall{query, results, true})
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderTask(page yatta.TaskPage) ([]byte, error) {
+	s.renderTaskCalls = append(s.renderTaskCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderComment(comment models.Comment, user string) ([]byte, error) {
+	s.renderCommentCalls = append(s.renderCommentCalls, comment)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderTrash(user string, tasks []models.Task) ([]byte, error) {
+	s.renderTrashCalls = append(s.renderTrashCalls, tasks)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderTimesheet(page yatta.TimesheetPage) ([]byte, error) {
+	s.renderTimesheetCalls = append(s.renderTimesheetCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderReport(page yatta.ReportPage) ([]byte, error) {
+	s.renderReportCalls = append(s.renderReportCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderBoard(page yatta.BoardPage) ([]byte, error) {
+	s.renderBoardCalls = append(s.renderBoardCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderCalendar(page yatta.CalendarPage) ([]byte, error) {
+	s.renderCalendarCalls = append(s.renderCalendarCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderTemplates(page yatta.TemplatesPage) ([]byte, error) {
+	s.renderTemplatesCalls = append(s.renderTemplatesCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderMembers(page yatta.MembersPage) ([]byte, error) {
+	s.renderMembersCalls = append(s.renderMembersCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderAssigned(page yatta.AssignedPage) ([]byte, error) {
+	s.renderAssignedCalls = append(s.renderAssignedCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderNotifications(page yatta.NotificationsPage) ([]byte, error) {
+	s.renderNotificationsCalls = append(s.renderNotificationsCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderNotificationBell(unread int) ([]byte, error) {
+	s.renderNotificationBellCalls = append(s.renderNotificationBellCalls, unread)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderWebhooks(page yatta.WebhooksPage) ([]byte, error) {
+	s.renderWebhooksCalls = append(s.renderWebhooksCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderInbound(page yatta.InboundPage) ([]byte, error) {
+	s.renderInboundCalls = append(s.renderInboundCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderFeed(page yatta.FeedPage) ([]byte, error) {
+	s.renderFeedCalls = append(s.renderFeedCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderAppPasswords(page yatta.AppPasswordsPage) ([]byte, error) {
+	s.renderAppPasswordsCalls = append(s.renderAppPasswordsCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderImport(page yatta.ImportPage) ([]byte, error) {
+	s.renderImportCalls = append(s.renderImportCalls, page)
+
+	return nil, nil
+}
+
+func (s *SpyRenderer) RenderWorkspace(page yatta.WorkspacePage) ([]byte, error) {
+	s.renderWorkspaceCalls = append(s.renderWorkspaceCalls, page)
+
+	return nil, nil
+}
+
+func (s *StubTaskStore) AddTask(user string, task string) error {
+	_, err := s.AddTaskWithDetails(user, task, models.TaskUpdate{})
+	return err
+}
+
+func (s *StubTaskStore) AddTaskWithDetails(user string, task string, details models.TaskUpdate) (uint64, error) {
+	s.addCalls = append(s.addCalls, addTaskCall{user, task})
+	s.addDetails = append(s.addDetails, details)
+
+	return uint64(len(s.addCalls)), nil
+}
+
+func (s *StubTaskStore) GetSubtasks(id uint64) ([]models.Task, error) {
+	var subtasks []models.Task
+
+	for _, tasks := range s.store {
+		for _, task := range tasks {
+			if task.ParentID != 0 && task.ParentID == id {
+				subtasks = append(subtasks, task)
+			}
+		}
+	}
+
+	return subtasks, nil
+}
+
+func (s *StubTaskStore) AddSubtask(parentID uint64, description string) (uint64, error) {
+	s.addSubtaskCalls = append(s.addSubtaskCalls, addSubtaskCall{parentID, description})
+
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	return uint64(len(s.addSubtaskCalls)), nil
+}
+
+func (s *StubTaskStore) SetParent(id uint64, parentID uint64) error {
+	s.setParentCalls = append(s.setParentCalls, setParentCall{id, parentID})
+
+	return s.err
+}
+
+func (s *StubTaskStore) SetDone(id uint64, done bool) error {
+	s.setDoneCalls = append(s.setDoneCalls, setDoneCall{id, done})
+
+	return s.err
+}
+
+func (s *StubTaskStore) AddDependency(id uint64, blockerID uint64) error {
+	s.addDependencyCalls = append(s.addDependencyCalls, dependencyCall{id, blockerID})
+
+	return s.err
+}
+
+func (s *StubTaskStore) RemoveDependency(id uint64, blockerID uint64) error {
+	s.removeDependencyCalls = append(s.removeDependencyCalls, dependencyCall{id, blockerID})
+
+	return s.err
+}
+
+func (s *StubTaskStore) GetReadyTasks(user string) ([]models.Task, error) {
+	var ready []models.Task
+
+	for _, task := range s.store[user] {
+		if !task.Done && !task.Blocked {
+			ready = append(ready, task)
+		}
+	}
+
+	return ready, nil
+}
+
+func (s *StubTaskStore) GetNextActions(user string) ([]models.Task, error) {
+	return s.store[user], nil
+}
+
+func (s *StubTaskStore) UpdateTask(id uint64, update models.TaskUpdate) error {
+	s.updateTaskCalls = append(s.updateTaskCalls, updateTaskCall{id, update})
+
+	return s.err
+}
+
+func (s *StubTaskStore) AddSavedSearch(user string, name string, query string) error {
+	if s.savedSearches == nil {
+		s.savedSearches = make(map[string][]models.SavedSearch)
+	}
+
+	id := uint64(len(s.savedSearches[user]) + 1)
+	s.savedSearches[user] = append(s.savedSearches[user], models.SavedSearch{ID: id, Name: name, Query: query})
+
+	return s.err
+}
+
+func (s *StubTaskStore) GetSavedSearches(user string) ([]models.SavedSearch, error) {
+	return s.savedSearches[user], nil
+}
+
+func (s *StubTaskStore) DeleteSavedSearch(user string, id uint64) error {
+	for i, savedSearch := range s.savedSearches[user] {
+		if savedSearch.ID == id {
+			s.savedSearches[user] = append(s.savedSearches[user][:i], s.savedSearches[user][i+1:]...)
+			return nil
+		}
+	}
+
+	return stores.ErrSavedSearchNotFound
+}
+
+func (s *StubTaskStore) SearchTasks(user string, query string) ([]models.Task, error) {
+	var results []models.Task
+
+	for _, tasks := range s.store {
+		for _, task := range tasks {
+			if strings.Contains(task.Description, query) {
+				results = append(results, task)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (s *StubTaskStore) DeleteTask(id uint64) error {
+	s.deleteTaskCalls = append(s.deleteTaskCalls, id)
+
+	return s.err
+}
+
+func (s *StubTaskStore) GetTrash(user string) ([]models.Task, error) {
+	return s.trash[user], nil
+}
+
+func (s *StubTaskStore) RestoreTask(id uint64) error {
+	s.restoreTaskCalls = append(s.restoreTaskCalls, id)
+
+	return s.err
+}
+
+func (s *StubTaskStore) PurgeTask(id uint64) error {
+	s.purgeTaskCalls = append(s.purgeTaskCalls, id)
+
+	return s.err
+}
+
+func (s *StubTaskStore) PurgeTrash(before time.Time) error {
+	s.purgeTrashCalls = append(s.purgeTrashCalls, before)
+
+	return s.err
+}
+
+func (s *StubTaskStore) ArchiveCompletedTasks(before time.Time) error {
+	s.archiveTaskCalls = append(s.archiveTaskCalls, before)
+
+	return s.err
+}
+
+func (s *StubTaskStore) GetArchivedTasks(user string) ([]models.Task, error) {
+	return s.archive[user], nil
+}
+
+func (s *StubTaskStore) AddComment(taskID uint64, author string, body string) (*models.Comment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	comment := models.Comment{ID: uint64(len(s.comments) + 1), TaskID: taskID, Author: author, Body: body}
+	s.comments = append(s.comments, comment)
+
+	return &comment, nil
+}
+
+func (s *StubTaskStore) GetComments(taskID uint64) ([]models.Comment, error) {
+	var comments []models.Comment
+
+	for _, comment := range s.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+
+	return comments, nil
+}
+
+func (s *StubTaskStore) UpdateComment(id uint64, author string, body string) (*models.Comment, error) {
+	for i := range s.comments {
+		if s.comments[i].ID != id {
+			continue
+		}
+
+		if s.comments[i].Author != author {
+			return nil, stores.ErrNotCommentAuthor
+		}
+
+		s.comments[i].Body = body
+		return &s.comments[i], nil
+	}
+
+	return nil, stores.ErrCommentNotFound
+}
+
+func (s *StubTaskStore) DeleteComment(id uint64, author string) error {
+	for i, comment := range s.comments {
+		if comment.ID != id {
+			continue
+		}
+
+		if comment.Author != author {
+			return stores.ErrNotCommentAuthor
+		}
+
+		s.comments = append(s.comments[:i], s.comments[i+1:]...)
+		return nil
+	}
+
+	return stores.ErrCommentNotFound
+}
+
+func (s *StubTaskStore) GetActivity(taskID uint64) ([]models.Activity, error) {
+	var activity []models.Activity
+
+	for _, change := range s.activity {
+		if change.TaskID == taskID {
+			activity = append(activity, change)
+		}
+	}
+
+	return activity, nil
+}
+
+func (s *StubTaskStore) GetCompletionHistory(user string) ([]models.Activity, error) {
+	return s.activity, nil
+}
+
+func (s *StubTaskStore) AddAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	attachment.ID = uint64(len(s.attachments) + 1)
+	s.attachments = append(s.attachments, attachment)
+
+	return &attachment, nil
+}
+
+func (s *StubTaskStore) GetAttachments(taskID uint64) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+
+	for _, attachment := range s.attachments {
+		if attachment.TaskID == taskID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	return attachments, nil
+}
+
+func (s *StubTaskStore) GetAttachment(id uint64) (*models.Attachment, error) {
+	for _, attachment := range s.attachments {
+		if attachment.ID == id {
+			return &attachment, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *StubTaskStore) DeleteAttachment(id uint64) error {
+	for i, attachment := range s.attachments {
+		if attachment.ID == id {
+			s.attachments = append(s.attachments[:i], s.attachments[i+1:]...)
+			return nil
+		}
+	}
+
+	return stores.ErrAttachmentNotFound
+}
+
+func (s *StubTaskStore) GetAttachmentKeys() ([]string, error) {
+	var keys []string
+
+	for _, attachment := range s.attachments {
+		keys = append(keys, attachment.Key)
+	}
+
+	return keys, nil
+}
+
+func (s *StubTaskStore) StartTimer(taskID uint64, user string) (*models.TimeEntry, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	entry := models.TimeEntry{ID: uint64(len(s.timeEntries) + 1), TaskID: taskID, User: user, Start: time.Now()}
+	s.timeEntries = append(s.timeEntries, entry)
+
+	return &entry, nil
+}
+
+func (s *StubTaskStore) StopTimer(user string) (*models.TimeEntry, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for i := range s.timeEntries {
+		if s.timeEntries[i].User == user && s.timeEntries[i].Running() {
+			now := time.Now()
+			s.timeEntries[i].End = &now
+
+			return &s.timeEntries[i], nil
+		}
+	}
+
+	return nil, stores.ErrNoTimerRunning
+}
+
+func (s *StubTaskStore) GetRunningTimer(user string) (*models.TimeEntry, error) {
+	for _, entry := range s.timeEntries {
+		if entry.User == user && entry.Running() {
+			return &entry, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *StubTaskStore) AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	entry.ID = uint64(len(s.timeEntries) + 1)
+	s.timeEntries = append(s.timeEntries, entry)
+
+	return &entry, nil
+}
+
+func (s *StubTaskStore) GetTimeEntries(taskID uint64) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+
+	for _, entry := range s.timeEntries {
+		if entry.TaskID == taskID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *StubTaskStore) GetTimesheet(user string, from time.Time, to time.Time) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+
+	for _, entry := range s.timeEntries {
+		if entry.User == user && !entry.Start.Before(from) && entry.Start.Before(to) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *StubTaskStore) DeleteTimeEntry(id uint64, user string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	for i, entry := range s.timeEntries {
+		if entry.ID == id {
+			s.timeEntries = append(s.timeEntries[:i], s.timeEntries[i+1:]...)
+			return nil
+		}
+	}
+
+	return stores.ErrTimeEntryNotFound
+}
+
+type moveTaskCall struct {
+	user     string
+	id       uint64
+	columnID uint64
+}
+
+func (s *StubTaskStore) GetColumns(user string) ([]models.Column, error) {
+	if columns, ok := s.columns[user]; ok {
+		return columns, nil
+	}
+
+	return models.DefaultColumns, nil
+}
+
+func (s *StubTaskStore) SetColumns(user string, columns []models.Column) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.columns == nil {
+		s.columns = make(map[string][]models.Column)
+	}
+
+	s.columns[user] = columns
+
+	return nil
+}
+
+func (s *StubTaskStore) MoveTask(user string, id uint64, columnID uint64) error {
+	s.moveTaskCalls = append(s.moveTaskCalls, moveTaskCall{user, id, columnID})
+
+	return s.err
+}
+
+type addTemplateCall struct {
+	taskID uint64
+	name   string
+}
+
+type useTemplateCall struct {
+	user   string
+	id     uint64
+	anchor time.Time
+	values map[string]string
+}
+
+func (s *StubTaskStore) AddTemplate(taskID uint64, name string) (*models.Template, error) {
+	s.addTemplateCalls = append(s.addTemplateCalls, addTemplateCall{taskID, name})
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &models.Template{ID: 1, Name: name}, nil
+}
+
+func (s *StubTaskStore) GetTemplates(user string) ([]models.Template, error) {
+	return s.templates[user], nil
+}
+
+func (s *StubTaskStore) DeleteTemplate(user string, id uint64) error {
+	for i, template := range s.templates[user] {
+		if template.ID == id {
+			s.templates[user] = append(s.templates[user][:i], s.templates[user][i+1:]...)
+			return nil
+		}
+	}
+
+	return stores.ErrTemplateNotFound
+}
+
+func (s *StubTaskStore) GetOwner(id uint64) (string, error) {
+	for _, lists := range []map[string][]models.Task{s.store, s.archive, s.trash} {
+		for user, tasks := range lists {
+			for _, task := range tasks {
+				if task.ID == id {
+					return user, nil
+				}
+			}
+		}
+	}
+
+	return "", stores.ErrTaskNotFound
+}
+
+func (s *StubTaskStore) GetMembers(user string) ([]models.Member, error) {
+	return s.members[user], nil
+}
+
+func (s *StubTaskStore) SetMember(user string, member models.Member) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.members == nil {
+		s.members = make(map[string][]models.Member)
+	}
+
+	for i, existing := range s.members[user] {
+		if existing.Email == member.Email {
+			s.members[user][i] = member
+			return nil
+		}
+	}
+
+	s.members[user] = append(s.members[user], member)
+
+	return nil
+}
+
+func (s *StubTaskStore) RemoveMember(user string, email string) error {
+	for i, member := range s.members[user] {
+		if member.Email == email {
+			s.members[user] = append(s.members[user][:i], s.members[user][i+1:]...)
+			return nil
+		}
+	}
+
+	return stores.ErrMemberNotFound
+}
+
+func (s *StubTaskStore) GetSharedLists(email string) ([]models.SharedList, error) {
+	var sharedLists []models.SharedList
+
+	for owner, members := range s.members {
+		for _, member := range members {
+			if member.Email == email {
+				sharedLists = append(sharedLists, models.SharedList{Owner: owner, Role: member.Role})
+			}
+		}
+	}
+
+	return sharedLists, nil
+}
+
+func (s *StubTaskStore) SetAssignees(id uint64, assignees []string) ([]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for user, tasks := range s.store {
+		for i := range tasks {
+			if tasks[i].ID != id {
+				continue
+			}
+
+			var added []string
+
+			for _, assignee := range assignees {
+				if assignee != user && !slices.ContainsFunc(s.members[user], func(member models.Member) bool {
+					return member.Email == assignee
+				}) {
+					return nil, stores.ErrInvalidAssignee
+				}
+
+				if !slices.Contains(tasks[i].Assignees, assignee) {
+					added = append(added, assignee)
+				}
+			}
+
+			tasks[i].Assignees = assignees
+
+			return added, nil
+		}
+	}
+
+	return nil, stores.ErrTaskNotFound
+}
+
+func (s *StubTaskStore) GetAssignedTasks(email string) ([]models.Task, error) {
+	var assigned []models.Task
+
+	for _, tasks := range s.store {
+		for _, task := range tasks {
+			if !task.Done && slices.Contains(task.Assignees, email) {
+				assigned = append(assigned, task)
+			}
+		}
+	}
+
+	return assigned, nil
+}
+
+func (s *StubTaskStore) AddNotification(user string, notification models.Notification) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.addNotificationErr != nil {
+		return s.addNotificationErr
+	}
+
+	if s.notifications == nil {
+		s.notifications = map[string][]models.Notification{}
+	}
+
+	notification.ID = uint64(len(s.notifications[user]) + 1)
+	s.notifications[user] = append(s.notifications[user], notification)
+
+	return nil
+}
+
+func (s *StubTaskStore) GetNotifications(user string) ([]models.Notification, error) {
+	notifications := slices.Clone(s.notifications[user])
+	slices.Reverse(notifications)
+
+	return notifications, nil
+}
+
+func (s *StubTaskStore) MarkNotificationsRead(user string) error {
+	for i := range s.notifications[user] {
+		s.notifications[user][i].Read = true
+	}
+
+	return s.err
+}
+
+func (s *StubTaskStore) AddWebhook(user string, webhook models.Webhook) (*models.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", stores.ErrInvalidWebhook, err)
+	}
+
+	if s.webhooks == nil {
+		s.webhooks = map[string][]models.Webhook{}
+	}
+
+	webhook.ID = uint64(len(s.webhooks[user]) + 1)
+	s.webhooks[user] = append(s.webhooks[user], webhook)
+
+	return &webhook, nil
+}
+
+func (s *StubTaskStore) GetWebhooks(user string) ([]models.Webhook, error) {
+	return s.webhooks[user], nil
+}
+
+func (s *StubTaskStore) DeleteWebhook(user string, id uint64) error {
+	for i, webhook := range s.webhooks[user] {
+		if webhook.ID == id {
+			s.webhooks[user] = slices.Delete(s.webhooks[user], i, i+1)
+			return nil
+		}
+	}
+
+	return stores.ErrWebhookNotFound
+}
+
+func (s *StubTaskStore) GetDeliveries(user string) ([]models.Delivery, error) {
+	return nil, nil
+}
+
+func (s *StubTaskStore) GetDueDeliveries(now time.Time) ([]models.Delivery, error) {
+	return nil, nil
+}
+
+func (s *StubTaskStore) RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error {
+	return stores.ErrWebhookNotFound
+}
+
+func (s *StubTaskStore) GetInboundToken(user string) (string, error) {
+	return s.inboundTokens[user], nil
+}
+
+func (s *StubTaskStore) ResetInboundToken(user string) (string, error) {
+	if s.inboundTokens == nil {
+		s.inboundTokens = map[string]string{}
+	}
+
+	s.inboundTokens[user] = fmt.Sprintf("token%d", len(s.inboundTokens)+1)
+
+	return s.inboundTokens[user], nil
+}
+
+func (s *StubTaskStore) GetInboundOwner(token string) (string, error) {
+	for user, inboundToken := range s.inboundTokens {
+		if token != "" && token == inboundToken {
+			return user, nil
+		}
+	}
+
+	return "", stores.ErrInboundTokenNotFound
+}
+
+func (s *StubTaskStore) GetFeedToken(user string) (string, error) {
+	return s.feedTokens[user], nil
+}
+
+func (s *StubTaskStore) ResetFeedToken(user string) (string, error) {
+	if s.feedTokens == nil {
+		s.feedTokens = map[string]string{}
+	}
+
+	s.feedTokens[user] = fmt.Sprintf("feed%d", len(s.feedTokens)+1)
+
+	return s.feedTokens[user], nil
+}
+
+func (s *StubTaskStore) GetFeedOwner(token string) (string, error) {
+	for user, feedToken := range s.feedTokens {
+		if token != "" && token == feedToken {
+			return user, nil
+		}
+	}
+
+	return "", stores.ErrFeedTokenNotFound
+}
+
+func (s *StubTaskStore) UseTemplate(user string, id uint64, anchor time.Time, values map[string]string) (uint64, error) {
+	s.useTemplateCalls = append(s.useTemplateCalls, useTemplateCall{user, id, anchor, values})
+
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	return 42, nil
+}
+
+type DummyUserStore struct{}
+
+func (d *DummyUserStore) AddUser(email string, password *models.PasswordHash) error {
+	return nil
+}
+
+func (d *DummyUserStore) GetUser(id uint64) (*models.User, error) {
+	return nil, nil
+}
+
+func (d *DummyUserStore) GetUserByEmail(email string) (*models.User, error) {
+	return nil, nil
+}
+
+func (d *DummyUserStore) GetUsers() ([]models.User, error) {
+	return nil, nil
+}
+
+func (d *DummyUserStore) AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error) {
+	return nil, nil
+}
+
+func (d *DummyUserStore) DeleteAppPassword(email string, id uint64) error {
+	return nil
+}
+
+type DummyTaskStore struct{}
+
+func (d *DummyTaskStore) GetTask(id uint64) (*models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) GetTasks(user string) ([]models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) AddTask(user string, description string) error {
+	return nil
+}
+
+func (d *DummyTaskStore) AddTaskWithDetails(user string, description string, details models.TaskUpdate) (uint64, error) {
+	return 0, nil
+}
+
+func (d *DummyTaskStore) GetSubtasks(id uint64) ([]models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) AddSubtask(parentID uint64, description string) (uint64, error) {
+	return 0, nil
+}
+
+func (d *DummyTaskStore) SetParent(id uint64, parentID uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) SetDone(id uint64, done bool) error {
+	return nil
+}
+
+func (d *DummyTaskStore) AddDependency(id uint64, blockerID uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) RemoveDependency(id uint64, blockerID uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetReadyTasks(user string) ([]models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) GetNextActions(user string) ([]models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) SearchTasks(user string, query string) ([]models.Task, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) UpdateTask(id uint64, update models.TaskUpdate) error {
+	return nil
+}
+
+func (d *DummyTaskStore) AddSavedSearch(user string, name string, query string) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetSavedSearches(user string) ([]models.SavedSearch, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) DeleteSavedSearch(user string, id uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetColumns(user string) ([]models.Column, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) SetColumns(user string, columns []models.Column) error {
+	return nil
+}
+
+func (d *DummyTaskStore) MoveTask(user string, id uint64, columnID uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) AddTemplate(taskID uint64, name string) (*models.Template, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) GetTemplates(user string) ([]models.Template, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) DeleteTemplate(user string, id uint64) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetOwner(id uint64) (string, error) {
+	return "", stores.ErrTaskNotFound
+}
+
+func (d *DummyTaskStore) GetMembers(user string) ([]models.Member, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) SetMember(user string, member models.Member) error {
+	return nil
+}
+
+func (d *DummyTaskStore) RemoveMember(user string, email string) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetSharedLists(email string) ([]models.SharedList, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) SetAssignees(id uint64, assignees []string) ([]string, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) GetAssignedTasks(email string) ([]models.Task, error) {
+	return nil, nil
+}
 
-			server := mustCreateServer(t, new(DummyTaskStore), store, new(DummyRenderer))
-			request := newCreateUserRequest(t, c)
-			response := httptest.NewRecorder()
+func (d *DummyTaskStore) AddNotification(user string, notification models.Notification) error {
+	return nil
+}
 
-			server.ServeHTTP(response, request)
+func (d *DummyTaskStore) GetNotifications(user string) ([]models.Notification, error) {
+	return nil, nil
+}
 
-			assertStatus(t, response, http.StatusAccepted)
-			assertAddUserCalls(t, store, c)
-		}
-	})
+func (d *DummyTaskStore) MarkNotificationsRead(user string) error {
+	return nil
+}
 
-	server := mustCreateServer(t, new(DummyTaskStore), new(DummyUserStore), new(DummyRenderer))
+func (d *DummyTaskStore) AddWebhook(user string, webhook models.Webhook) (*models.Webhook, error) {
+	return nil, nil
+}
 
-	t.Run("wrong content type returns HTTP status unsupported media type", func(t *testing.T) {
-		request := httptest.NewRequest(http.MethodPost, "/users", nil)
-		response := httptest.NewRecorder()
+func (d *DummyTaskStore) GetWebhooks(user string) ([]models.Webhook, error) {
+	return nil, nil
+}
 
-		server.ServeHTTP(response, request)
+func (d *DummyTaskStore) DeleteWebhook(user string, id uint64) error {
+	return nil
+}
 
-		assertStatus(t, response, http.StatusUnsupportedMediaType)
-	})
+func (d *DummyTaskStore) GetDeliveries(user string) ([]models.Delivery, error) {
+	return nil, nil
+}
 
-	t.Run("invalid form returns HTTP status bad request", func(t *testing.T) {
-		cases := []io.Reader{
-			nil,
-			strings.NewReader(""),
-			strings.NewReader("email="),
-			strings.NewReader("password="),
-		}
+func (d *DummyTaskStore) GetDueDeliveries(now time.Time) ([]models.Delivery, error) {
+	return nil, nil
+}
 
-		for _, requestBody := range cases {
-			request := httptest.NewRequest(http.MethodPost, "/users", requestBody)
-			request.Header.Add("Content-Type", formContentType)
-			response := httptest.NewRecorder()
+func (d *DummyTaskStore) RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error {
+	return nil
+}
 
-			server.ServeHTTP(response, request)
+func (d *DummyTaskStore) GetInboundToken(user string) (string, error) {
+	return "", nil
+}
 
-			assertStatus(t, response, http.StatusBadRequest)
-		}
-	})
+func (d *DummyTaskStore) ResetInboundToken(user string) (string, error) {
+	return "", nil
+}
 
-	// TODO: validate emails for formatting and uniqueness, and passwords for strength.
+func (d *DummyTaskStore) GetInboundOwner(token string) (string, error) {
+	return "", nil
 }
 
-const htmlContentType = "text/html"
-const formContentType = "application/x-www-form-urlencoded"
+func (d *DummyTaskStore) GetFeedToken(user string) (string, error) {
+	return "", nil
+}
 
-type addTaskCall struct {
-	user string
-	task string
+func (d *DummyTaskStore) ResetFeedToken(user string) (string, error) {
+	return "", nil
 }
 
-type getTasksCall struct {
-	user  string
-	tasks []models.Task
+func (d *DummyTaskStore) GetFeedOwner(token string) (string, error) {
+	return "", nil
 }
 
-type getTaskCall struct {
-	id   uint64
-	task *models.Task
+func (d *DummyTaskStore) UseTemplate(user string, id uint64, anchor time.Time, values map[string]string) (uint64, error) {
+	return 0, nil
 }
 
-type StubTaskStore struct {
-	store         map[string][]models.Task
-	addCalls      []addTaskCall
-	getTasksCalls []getTasksCall
-	getTaskCalls  []getTaskCall
+func (d *DummyTaskStore) DeleteTask(id uint64) error {
+	return nil
 }
 
-func (s *StubTaskStore) GetTasks(user string) ([]models.Task, error) {
-	tasks := s.store[user]
+func (d *DummyTaskStore) GetTrash(user string) ([]models.Task, error) {
+	return nil, nil
+}
 
-	s.getTasksCalls = append(s.getTasksCalls, getTasksCall{user, tasks})
+func (d *DummyTaskStore) RestoreTask(id uint64) error {
+	return nil
+}
 
-	return tasks, nil
+func (d *DummyTaskStore) PurgeTask(id uint64) error {
+	return nil
 }
 
-func (s *StubTaskStore) GetTask(id uint64) (*models.Task, error) {
-	for _, tasks := range s.store {
-		for _, task := range tasks {
-			if task.ID == id {
-				s.getTaskCalls = append(s.getTaskCalls, getTaskCall{id: id, task: &task})
-				return &task, nil
-			}
-		}
-	}
+func (d *DummyTaskStore) PurgeTrash(before time.Time) error {
+	return nil
+}
 
-	s.getTaskCalls = append(s.getTaskCalls, getTaskCall{id: id, task: nil})
+func (d *DummyTaskStore) ArchiveCompletedTasks(before time.Time) error {
+	return nil
+}
+
+func (d *DummyTaskStore) GetArchivedTasks(user string) ([]models.Task, error) {
 	return nil, nil
 }
 
-type SpyRenderer struct {
-	renderIndexCalls [][]models.User
-	renderTasksCalls [][]models.Task
-	renderTaskCalls  []models.Task
+func (d *DummyTaskStore) AddComment(taskID uint64, author string, body string) (*models.Comment, error) {
+	return nil, nil
 }
 
-func (s *SpyRenderer) RenderIndex(users []models.User) ([]byte, error) {
-	s.renderIndexCalls = append(s.renderIndexCalls, users)
+func (d *DummyTaskStore) GetComments(taskID uint64) ([]models.Comment, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) UpdateComment(id uint64, author string, body string) (*models.Comment, error) {
 	return nil, nil
 }
 
-func (s *SpyRenderer) RenderTaskList(tasks []models.Task) ([]byte, error) {
-	s.renderTasksCalls = append(s.renderTasksCalls, tasks)
+func (d *DummyTaskStore) DeleteComment(id uint64, author string) error {
+	return nil
+}
 
+func (d *DummyTaskStore) GetActivity(taskID uint64) ([]models.Activity, error) {
 	return nil, nil
 }
 
-func (s *SpyRenderer) RenderTask(task models.Task) ([]byte, error) {
-	s.renderTaskCalls = append(s.renderTaskCalls, task)
+func (d *DummyTaskStore) AddAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	return nil, nil
+}
 
+func (d *DummyTaskStore) GetAttachments(taskID uint64) ([]models.Attachment, error) {
 	return nil, nil
 }
 
-func (s *StubTaskStore) AddTask(user string, task string) error {
-	s.addCalls = append(s.addCalls, addTaskCall{user, task})
+func (d *DummyTaskStore) GetAttachment(id uint64) (*models.Attachment, error) {
+	return nil, nil
+}
 
+func (d *DummyTaskStore) DeleteAttachment(id uint64) error {
 	return nil
 }
 
-type DummyUserStore struct{}
+func (d *DummyTaskStore) GetAttachmentKeys() ([]string, error) {
+	return nil, nil
+}
 
-func (d *DummyUserStore) AddUser(email string, password *models.PasswordHash) error {
-	return nil
+func (d *DummyTaskStore) GetCompletionHistory(user string) ([]models.Activity, error) {
+	return nil, nil
 }
 
-func (d *DummyUserStore) GetUser(id uint64) (*models.User, error) {
+func (d *DummyTaskStore) StartTimer(taskID uint64, user string) (*models.TimeEntry, error) {
 	return nil, nil
 }
 
-func (d *DummyUserStore) GetUsers() ([]models.User, error) {
+func (d *DummyTaskStore) StopTimer(user string) (*models.TimeEntry, error) {
 	return nil, nil
 }
 
-type DummyTaskStore struct{}
+func (d *DummyTaskStore) GetRunningTimer(user string) (*models.TimeEntry, error) {
+	return nil, nil
+}
 
-func (d *DummyTaskStore) GetTask(id uint64) (*models.Task, error) {
+func (d *DummyTaskStore) AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error) {
 	return nil, nil
 }
 
-func (d *DummyTaskStore) GetTasks(user string) ([]models.Task, error) {
+func (d *DummyTaskStore) GetTimeEntries(taskID uint64) ([]models.TimeEntry, error) {
 	return nil, nil
 }
 
-func (d *DummyTaskStore) AddTask(user string, description string) error {
+func (d *DummyTaskStore) GetTimesheet(user string, from time.Time, to time.Time) ([]models.TimeEntry, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) DeleteTimeEntry(id uint64, user string) error {
 	return nil
 }
 
+func (d *DummyTaskStore) GetComment(id uint64) (*models.Comment, error) {
+	return nil, nil
+}
+
+func (d *DummyTaskStore) GetTimeEntry(id uint64) (*models.TimeEntry, error) {
+	return nil, nil
+}
+
 type DummyRenderer struct{}
 
 func (d *DummyRenderer) RenderIndex(users []models.User) ([]byte, error) {
 	return nil, nil
 }
 
-func (d *DummyRenderer) RenderTask(task models.Task) ([]byte, error) {
+func (d *DummyRenderer) RenderTask(page yatta.TaskPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderComment(comment models.Comment, user string) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderTaskList(page yatta.TaskListPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderQuickAddPreview(result quickadd.Result) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderNextActions(tasks []models.Task) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderSearch(query string, results []models.Task) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderSearchResults(query string, results []models.Task) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderTrash(user string, tasks []models.Task) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderTimesheet(page yatta.TimesheetPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderReport(page yatta.ReportPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderBoard(page yatta.BoardPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderCalendar(page yatta.CalendarPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderTemplates(page yatta.TemplatesPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderMembers(page yatta.MembersPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderAssigned(page yatta.AssignedPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderNotifications(page yatta.NotificationsPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderNotificationBell(unread int) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderWebhooks(page yatta.WebhooksPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderInbound(page yatta.InboundPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderFeed(page yatta.FeedPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderAppPasswords(page yatta.AppPasswordsPage) ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DummyRenderer) RenderImport(page yatta.ImportPage) ([]byte, error) {
 	return nil, nil
 }
 
-func (d *DummyRenderer) RenderTaskList(tasks []models.Task) ([]byte, error) {
+func (d *DummyRenderer) RenderWorkspace(page yatta.WorkspacePage) ([]byte, error) {
 	return nil, nil
 }
 
@@ -376,6 +5453,8 @@ type addUserCall struct {
 
 type StubUserStore struct {
 	users []models.User
+	// How many times GetUserByEmail was called, which is how often a request's credentials were checked.
+	getUserByEmailCalls int
 }
 
 func (s *StubUserStore) AddUser(email string, password *models.PasswordHash) error {
@@ -386,10 +5465,48 @@ func (s *StubUserStore) GetUser(id uint64) (*models.User, error) {
 	return &s.users[id], nil
 }
 
+func (s *StubUserStore) GetUserByEmail(email string) (*models.User, error) {
+	s.getUserByEmailCalls++
+
+	for _, user := range s.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func (s *StubUserStore) GetUsers() ([]models.User, error) {
 	return s.users, nil
 }
 
+func (s *StubUserStore) AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error) {
+	for i, user := range s.users {
+		if user.Email == email {
+			appPassword := models.AppPassword{ID: uint64(len(user.AppPasswords) + 1), Name: name, Hash: hash, CreatedAt: time.Now()}
+			s.users[i].AppPasswords = append(s.users[i].AppPasswords, appPassword)
+
+			return &appPassword, nil
+		}
+	}
+
+	return nil, stores.ErrUserNotFound
+}
+
+func (s *StubUserStore) DeleteAppPassword(email string, id uint64) error {
+	for i, user := range s.users {
+		for j, appPassword := range user.AppPasswords {
+			if user.Email == email && appPassword.ID == id {
+				s.users[i].AppPasswords = slices.Delete(s.users[i].AppPasswords, j, j+1)
+				return nil
+			}
+		}
+	}
+
+	return stores.ErrAppPasswordNotFound
+}
+
 type SpyUserStore struct {
 	createUserCalls []addUserCall
 }
@@ -403,14 +5520,62 @@ func (s *SpyUserStore) GetUser(id uint64) (*models.User, error) {
 	return nil, nil
 }
 
+func (s *SpyUserStore) GetUserByEmail(email string) (*models.User, error) {
+	return nil, nil
+}
+
 func (s *SpyUserStore) GetUsers() ([]models.User, error) {
 	return nil, nil
 }
 
-func mustCreateServer(t *testing.T, taskStore stores.TaskStore, userStore stores.UserStore, renderer yatta.Renderer) *yatta.Server {
+func (s *SpyUserStore) AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error) {
+	return nil, nil
+}
+
+func (s *SpyUserStore) DeleteAppPassword(email string, id uint64) error {
+	return nil
+}
+
+// A testServer is a [yatta.Server] backed by stubs, whose users sign in with their name as their password, see
+// [newTestServer].
+type testServer struct {
+	*yatta.Server
+	userStore *StubUserStore
+	renderer  *SpyRenderer
+}
+
+// Create a server for the tasks in `store` with an account for each of `users`, e.g., "alice" for alice@example.com.
+func newTestServer(t *testing.T, users []string, store *StubTaskStore, options ...yatta.ServerOption) *testServer {
+	t.Helper()
+
+	server := &testServer{userStore: &StubUserStore{}, renderer: new(SpyRenderer)}
+
+	for _, name := range users {
+		email := name + "@example.com"
+		server.userStore.users = append(server.userStore.users, models.User{ID: uint64(len(server.userStore.users)), Email: email, Password: yattatest.MustCreatePasswordHash(t, name)})
+	}
+
+	server.Server = mustCreateServer(t, store, server.userStore, server.renderer, options...)
+
+	return server
+}
+
+// Serve `request` signed in as the user with `name`, e.g., "alice", or signed out if `name` is empty.
+func (s *testServer) serve(request *http.Request, name string) *httptest.ResponseRecorder {
+	if name != "" {
+		request.SetBasicAuth(name+"@example.com", name)
+	}
+
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, request)
+
+	return response
+}
+
+func mustCreateServer(t *testing.T, taskStore stores.TaskStore, userStore stores.UserStore, renderer yatta.Renderer, options ...yatta.ServerOption) *yatta.Server {
 	t.Helper()
 
-	server, err := yatta.NewServer(taskStore, userStore, renderer)
+	server, err := yatta.NewServer(taskStore, userStore, renderer, options...)
 
 	if err != nil {
 		t.Errorf("an ocurred while creating the server: %v", err)
@@ -419,6 +5584,14 @@ func mustCreateServer(t *testing.T, taskStore stores.TaskStore, userStore stores
 	return server
 }
 
+// Create a user store with an account for `owner`, e.g., "Alice", who signs in with their name as their password. This
+// lets tests for lists named after their owner do what only the owner of a list may do.
+func newOwnerStore(t *testing.T, owner string) *StubUserStore {
+	t.Helper()
+
+	return &StubUserStore{users: []models.User{{Email: owner, Password: yattatest.MustCreatePasswordHash(t, owner)}}}
+}
+
 func newCreateUserRequest(t *testing.T, user createUserRequestData) *http.Request {
 	t.Helper()
 
@@ -517,7 +5690,7 @@ func assertGetTaskCall(t *testing.T, store *StubTaskStore, want models.Task) {
 
 	if got == nil {
 		t.Errorf("got nil task, want %v", want)
-	} else if *got != want {
+	} else if !reflect.DeepEqual(*got, want) {
 		t.Errorf("got task %v want %v", *got, want)
 	}
 }
@@ -534,7 +5707,7 @@ func assertGetTasksCall(t *testing.T, store *StubTaskStore, want getTasksCall) {
 	got := calls[0]
 
 	if !reflect.DeepEqual(got, want) {
-		t.Errorf("got calls to GetTasks %q, want %q", got, want)
+		t.Errorf("got calls to GetTasks %v, want %v", got, want)
 	}
 }
 
@@ -566,7 +5739,7 @@ func assertRenderIndexCall(t *testing.T, renderer *SpyRenderer, want []models.Us
 	}
 }
 
-func assertRenderTaskCall(t *testing.T, renderer *SpyRenderer, want models.Task) {
+func assertRenderTaskCall(t *testing.T, renderer *SpyRenderer, want models.Task, wantSubtasks []models.Task) {
 	t.Helper()
 
 	if len(renderer.renderTaskCalls) != 1 {
@@ -575,8 +5748,12 @@ func assertRenderTaskCall(t *testing.T, renderer *SpyRenderer, want models.Task)
 
 	got := renderer.renderTaskCalls[0]
 
-	if got != want {
-		t.Errorf("got call to RenderTask with task %q, want call with task %q", got, want)
+	if !reflect.DeepEqual(got.Task, want) {
+		t.Errorf("got call to RenderTask with task %v, want call with task %v", got.Task, want)
+	}
+
+	if !reflect.DeepEqual(got.Subtasks, wantSubtasks) {
+		t.Errorf("got call to RenderTask with subtasks %v, want subtasks %v", got.Subtasks, wantSubtasks)
 	}
 }
 
@@ -587,9 +5764,23 @@ func assertRenderTasksCall(t *testing.T, renderer *SpyRenderer, want []models.Ta
 		t.Fatalf("got %d calls to RenderTasksList, want 1", len(renderer.renderTasksCalls))
 	}
 
-	got := renderer.renderTasksCalls[0]
+	got := renderer.renderTasksCalls[0].Tasks
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls to RenderTasksList %v, want %v", got, want)
+	}
+}
+
+func assertRenderSearchCall(t *testing.T, renderer *SpyRenderer, want renderSearchCall) {
+	t.Helper()
+
+	if len(renderer.renderSearchCalls) != 1 {
+		t.Fatalf("got %d calls to render search, want 1", len(renderer.renderSearchCalls))
+	}
+
+	got := renderer.renderSearchCalls[0]
 
 	if !reflect.DeepEqual(got, want) {
-		t.Errorf("got calls to RenderTasksList %q, want %q", got, want)
+		t.Errorf("got call to render search %v, want %v", got, want)
 	}
 }
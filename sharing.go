@@ -0,0 +1,343 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// Only let the request through to `handler` if whoever made it has at least `role` in the task list of the user in
+// the path, see [Server.checkAccess].
+func (s *Server) forList(role models.Role, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.checkAccess(w, r, r.PathValue("user"), role) {
+			handler(w, r)
+		}
+	})
+}
+
+// Only let the request through to `handler` if whoever made it has at least `role` in the task list that holds the
+// task with the ID in the path, see [Server.checkAccess].
+//
+// Requests for tasks that do not exist are let through so that the handler can respond as it usually would.
+func (s *Server) forTask(role models.Role, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseTaskID(r)
+
+		if err != nil {
+			handler(w, r)
+			return
+		}
+
+		owner, err := s.taskStore.GetOwner(id)
+
+		switch {
+		case errors.Is(err, stores.ErrTaskNotFound):
+			handler(w, r)
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the owner of task %d: %v", id, err))
+		case s.checkAccess(w, r, owner, role):
+			handler(w, r)
+		}
+	})
+}
+
+// Only let the request through to `handler` if whoever made it has at least `role` in the task list that holds the
+// task that the attachment with the ID in the path is attached to, see [Server.checkAccess].
+func (s *Server) forAttachment(role models.Role, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+		if err != nil {
+			handler(w, r)
+			return
+		}
+
+		attachment, err := s.taskStore.GetAttachment(id)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get attachment %d: %v", id, err))
+			return
+		}
+
+		if attachment == nil {
+			handler(w, r)
+			return
+		}
+
+		owner, err := s.taskStore.GetOwner(attachment.TaskID)
+
+		switch {
+		case errors.Is(err, stores.ErrTaskNotFound):
+			handler(w, r)
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error(fmt.Sprintf("could not get the owner of task %d: %v", attachment.TaskID, err))
+		case s.checkAccess(w, r, owner, role):
+			handler(w, r)
+		}
+	})
+}
+
+// Check that whoever made the request may act on the task list of `owner` with `role`, writing an error response if
+// they may not. Lists in other workspaces are never open, see [Server.checkWorkspace].
+//
+// Lists that have not been shared are open to everyone to view and edit, as all lists were before lists could be
+// shared, but only their owner may do what admins do, e.g., change the saved searches or the board. Once a list is
+// shared, only its owner and the members with at least `role` may use it.
+func (s *Server) checkAccess(w http.ResponseWriter, r *http.Request, owner string, role models.Role) bool {
+	if !s.checkWorkspace(w, r, owner) {
+		return false
+	}
+
+	members, err := s.taskStore.GetMembers(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return false
+	}
+
+	if len(members) == 0 && role != models.RoleAdmin {
+		return true
+	}
+
+	return s.requireRole(w, r, owner, role, members) != nil
+}
+
+// Get the signed in user if they are `owner` or one of the `members` of the task list of `owner` with at least `role`.
+//
+// Returns nil if a response has already been written.
+func (s *Server) requireRole(w http.ResponseWriter, r *http.Request, owner string, role models.Role, members []models.Member) *models.User {
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return nil
+	}
+
+	if user.Email == owner {
+		return user
+	}
+
+	for _, member := range members {
+		if member.Email == user.Email && member.Role.Allows(role) {
+			return user
+		}
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+
+	return nil
+}
+
+// Get the signed in user if they are the owner or an admin of the task list of `owner`, even if the list is not shared,
+// writing an error response otherwise. Lists in other workspaces are answered as if they did not exist, see
+// [Server.checkWorkspace].
+//
+// Returns nil if a response has already been written.
+func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request, owner string) *models.User {
+	if !s.checkWorkspace(w, r, owner) {
+		return nil
+	}
+
+	members, err := s.taskStore.GetMembers(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return nil
+	}
+
+	return s.requireRole(w, r, owner, models.RoleAdmin, members)
+}
+
+// Check whether `user`, who is nil if nobody is signed in, may see the task with `id`, see [Server.canSeeList].
+func (s *Server) canView(user *models.User, id uint64) (bool, error) {
+	owner, err := s.taskStore.GetOwner(id)
+
+	if err != nil {
+		return false, err
+	}
+
+	return s.canSeeList(user, owner)
+}
+
+// Check whether `user`, who is nil if nobody is signed in, may see the task list of `owner`, see [Server.checkAccess].
+//
+// The list must be in the same workspace as the user, e.g., because it was found in the store from [Server.tasks].
+func (s *Server) canSeeList(user *models.User, owner string) (bool, error) {
+	members, err := s.taskStore.GetMembers(owner)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(members) == 0 || (user != nil && user.Email == owner) {
+		return true, nil
+	}
+
+	for _, member := range members {
+		if user != nil && member.Email == user.Email {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Show who the user's task list is shared with. Only the owner and admins of the list may see its members.
+func (s *Server) getMembers(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if !s.checkWorkspace(w, r, owner) {
+		return
+	}
+
+	members, err := taskStore.GetMembers(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return
+	}
+
+	if s.requireRole(w, r, owner, models.RoleAdmin, members) == nil {
+		return
+	}
+
+	body, err := s.renderer.RenderMembers(MembersPage{User: owner, Members: members})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Share the user's task list with the yatta user with the email in the "email" field of the form, as the role in the
+// "role" field. Sharing the list with an existing member changes their role.
+func (s *Server) setMember(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	userStore := s.users(w, r)
+
+	if userStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if !s.checkWorkspace(w, r, owner) {
+		return
+	}
+
+	members, err := taskStore.GetMembers(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return
+	}
+
+	if s.requireRole(w, r, owner, models.RoleAdmin, members) == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(r.Form.Get("email"))
+	role, err := models.ParseRole(r.Form.Get("role"))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := userStore.GetUserByEmail(email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get user %q: %v", email, err))
+		return
+	}
+
+	if user == nil {
+		http.Error(w, fmt.Sprintf("there is no user with the email %q", email), http.StatusBadRequest)
+		return
+	}
+
+	if err := taskStore.SetMember(owner, models.Member{Email: email, Role: role}); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not share the task list of %q with %q", owner, email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Stop sharing the user's task list with a member. Members may also remove themselves to leave the list.
+func (s *Server) removeMember(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+	email := r.PathValue("email")
+
+	if !s.checkWorkspace(w, r, owner) {
+		return
+	}
+
+	members, err := taskStore.GetMembers(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the members of the task list of %q: %v", owner, err))
+		return
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return
+	}
+
+	leaving := user != nil && user.Email == email
+
+	if !leaving && s.requireRole(w, r, owner, models.RoleAdmin, members) == nil {
+		return
+	}
+
+	if err := taskStore.RemoveMember(owner, email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not stop sharing the task list of %q with %q", owner, email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
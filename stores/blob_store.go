@@ -0,0 +1,37 @@
+package stores
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrBlobNotFound is returned when an operation refers to a blob that does not exist.
+	ErrBlobNotFound = errors.New("blob not found")
+
+	// ErrBlobTooLarge is returned when adding a blob that is larger than the store allows.
+	ErrBlobTooLarge = errors.New("blob is too large")
+)
+
+// Handles the storage of file contents, e.g., task attachments.
+//
+// Blobs are identified by a key derived from their contents, so storing the same contents twice only stores one
+// copy.
+type BlobStore interface {
+	// Store the contents of `r`, returning the key for the blob and its size in bytes.
+	//
+	// Returns [ErrBlobTooLarge] if the contents are larger than the store allows.
+	Put(r io.Reader) (key string, size int64, err error)
+
+	// Open the blob with `key` for reading. The caller must close the blob when finished.
+	//
+	// Returns [ErrBlobNotFound] if there is no blob with `key`.
+	Open(key string) (io.ReadCloser, error)
+
+	// Delete the blob with `key`, if it exists.
+	Delete(key string) error
+
+	// Get the keys of the blobs that were stored before `before`.
+	GetKeys(before time.Time) ([]string, error)
+}
@@ -0,0 +1,149 @@
+package stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// The pattern of the keys of blobs, a hex-encoded SHA-256 hash.
+var blobKeyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Stores blobs as files on disk, named after the SHA-256 hash of their contents.
+type FileBlobStore struct {
+	// The directory that contains the blobs.
+	dir string
+	// The maximum size of a blob in bytes.
+	maxSize int64
+}
+
+// Create a blob store that keeps blobs of up to `maxSize` bytes in `dir`, creating the directory if needed.
+func NewFileBlobStore(dir string, maxSize int64) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create blob directory %q: %v", dir, err)
+	}
+
+	return &FileBlobStore{dir: dir, maxSize: maxSize}, nil
+}
+
+func (f *FileBlobStore) Put(r io.Reader) (string, int64, error) {
+	// Write to a temporary file first since the key is not known until all of the contents have been read.
+	temp, err := os.CreateTemp(f.dir, "upload-*")
+
+	if err != nil {
+		return "", 0, fmt.Errorf("could not create temporary file: %v", err)
+	}
+
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	hash := sha256.New()
+	// Read one byte more than the limit to tell whether the contents are too large.
+	size, err := io.Copy(io.MultiWriter(temp, hash), io.LimitReader(r, f.maxSize+1))
+
+	if err != nil {
+		return "", 0, fmt.Errorf("could not write blob: %v", err)
+	}
+
+	if size > f.maxSize {
+		return "", 0, ErrBlobTooLarge
+	}
+
+	if err := temp.Close(); err != nil {
+		return "", 0, fmt.Errorf("could not write blob: %v", err)
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	blobPath := f.path(key)
+
+	if _, err := os.Stat(blobPath); err == nil {
+		// The contents are already stored. Mark the blob as new so that it is not garbage collected before the caller
+		// has a chance to refer to it.
+		now := time.Now()
+
+		if err := os.Chtimes(blobPath, now, now); err != nil {
+			return "", 0, fmt.Errorf("could not update blob: %v", err)
+		}
+
+		return key, size, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+		return "", 0, fmt.Errorf("could not create blob directory: %v", err)
+	}
+
+	if err := os.Rename(temp.Name(), blobPath); err != nil {
+		return "", 0, fmt.Errorf("could not move blob into place: %v", err)
+	}
+
+	return key, size, nil
+}
+
+func (f *FileBlobStore) Open(key string) (io.ReadCloser, error) {
+	if !blobKeyPattern.MatchString(key) {
+		return nil, ErrBlobNotFound
+	}
+
+	file, err := os.Open(f.path(key))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	return file, err
+}
+
+func (f *FileBlobStore) Delete(key string) error {
+	if !blobKeyPattern.MatchString(key) {
+		return nil
+	}
+
+	err := os.Remove(f.path(key))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (f *FileBlobStore) GetKeys(before time.Time) ([]string, error) {
+	var keys []string
+
+	err := filepath.WalkDir(f.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !blobKeyPattern.MatchString(entry.Name()) {
+			return err
+		}
+
+		info, err := entry.Info()
+
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().Before(before) {
+			keys = append(keys, entry.Name())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not list blobs in %q: %v", f.dir, err)
+	}
+
+	return keys, nil
+}
+
+// Get the path to the blob with `key`.
+// Blobs are split into subdirectories by the first two characters of the key to keep directories small.
+func (f *FileBlobStore) path(key string) string {
+	return filepath.Join(f.dir, key[:2], key)
+}
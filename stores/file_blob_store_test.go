@@ -0,0 +1,125 @@
+package stores_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/stores"
+	"github.com/AnthonyDickson/yatta/yattatest"
+)
+
+func TestFileBlobStore(t *testing.T) {
+	t.Run("stores identical contents once", func(t *testing.T) {
+		store := mustCreateFileBlobStore(t, 1024)
+
+		key, size, err := store.Put(strings.NewReader("hello, world"))
+		yattatest.AssertNoError(t, err)
+		sameKey, _, err := store.Put(strings.NewReader("hello, world"))
+		yattatest.AssertNoError(t, err)
+		otherKey, _, err := store.Put(strings.NewReader("goodbye"))
+		yattatest.AssertNoError(t, err)
+
+		// The SHA-256 hash of "hello, world".
+		const want = "09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b"
+
+		if key != want || sameKey != want || size != 12 {
+			t.Errorf("got keys %q and %q with size %d, want key %q with size 12", key, sameKey, size, want)
+		}
+
+		if otherKey == key {
+			t.Errorf("got the same key %q for different contents", key)
+		}
+
+		assertBlobContents(t, store, key, "hello, world")
+		assertBlobKeys(t, store, time.Now().Add(time.Minute), 2)
+	})
+
+	t.Run("rejects contents over the size limit", func(t *testing.T) {
+		store := mustCreateFileBlobStore(t, 4)
+
+		_, _, err := store.Put(strings.NewReader("12345"))
+		assertError(t, err, stores.ErrBlobTooLarge)
+
+		_, size, err := store.Put(strings.NewReader("1234"))
+		yattatest.AssertNoError(t, err)
+
+		if size != 4 {
+			t.Errorf("got size %d, want 4", size)
+		}
+
+		assertBlobKeys(t, store, time.Now().Add(time.Minute), 1)
+	})
+
+	t.Run("lists blobs stored before a time", func(t *testing.T) {
+		store := mustCreateFileBlobStore(t, 1024)
+
+		_, _, err := store.Put(strings.NewReader("hello"))
+		yattatest.AssertNoError(t, err)
+
+		assertBlobKeys(t, store, time.Now().Add(-time.Minute), 0)
+		assertBlobKeys(t, store, time.Now().Add(time.Minute), 1)
+	})
+
+	t.Run("deletes blobs", func(t *testing.T) {
+		store := mustCreateFileBlobStore(t, 1024)
+
+		key, _, err := store.Put(strings.NewReader("hello"))
+		yattatest.AssertNoError(t, err)
+
+		yattatest.AssertNoError(t, store.Delete(key))
+		yattatest.AssertNoError(t, store.Delete(key))
+
+		_, err = store.Open(key)
+		assertError(t, err, stores.ErrBlobNotFound)
+		assertBlobKeys(t, store, time.Now().Add(time.Minute), 0)
+	})
+
+	t.Run("missing and invalid keys", func(t *testing.T) {
+		store := mustCreateFileBlobStore(t, 1024)
+
+		_, err := store.Open(strings.Repeat("a", 64))
+		assertError(t, err, stores.ErrBlobNotFound)
+		_, err = store.Open("../../etc/passwd")
+		assertError(t, err, stores.ErrBlobNotFound)
+	})
+}
+
+func mustCreateFileBlobStore(t testing.TB, maxSize int64) *stores.FileBlobStore {
+	t.Helper()
+
+	store, err := stores.NewFileBlobStore(t.TempDir(), maxSize)
+
+	if err != nil {
+		t.Fatalf("could not create blob store: %v", err)
+	}
+
+	return store
+}
+
+func assertBlobContents(t *testing.T, store *stores.FileBlobStore, key, want string) {
+	t.Helper()
+
+	blob, err := store.Open(key)
+	yattatest.AssertNoError(t, err)
+	defer blob.Close()
+
+	got, err := io.ReadAll(blob)
+	yattatest.AssertNoError(t, err)
+
+	if string(got) != want {
+		t.Errorf("got contents %q, want %q", got, want)
+	}
+}
+
+func assertBlobKeys(t *testing.T, store *stores.FileBlobStore, before time.Time, want int) {
+	t.Helper()
+
+	keys, err := store.GetKeys(before)
+	yattatest.AssertNoError(t, err)
+
+	if len(keys) != want {
+		t.Errorf("got %d blobs stored before %v, want %d", len(keys), before, want)
+	}
+}
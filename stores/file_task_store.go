@@ -1,75 +1,1918 @@
 package stores
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/AnthonyDickson/yatta/events"
 	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/search"
 )
 
 // Persists tasks to disk.
+//
+// It is safe to use a FileTaskStore from multiple goroutines, e.g., from HTTP handlers and background jobs.
 type FileTaskStore struct {
+	// Guards all other fields.
+	mu        sync.RWMutex
 	database  *json.Encoder
 	taskLists taskLists
+	// A full-text index of the descriptions of the tasks that are not archived or in the trash.
+	index *search.Index
+	// Where changes to tasks are published, or nil if they are not published.
+	events *events.Bus
 }
 
-func NewFileTaskStore(database *os.File) (*FileTaskStore, error) {
+// A FileTaskStoreOption configures an optional feature of a [FileTaskStore].
+type FileTaskStoreOption func(*FileTaskStore)
+
+// WithEventBus publishes the tasks that are added to, changed in or removed from task lists to `bus`.
+func WithEventBus(bus *events.Bus) FileTaskStoreOption {
+	return func(f *FileTaskStore) {
+		f.events = bus
+	}
+}
+
+func NewFileTaskStore(database *os.File, options ...FileTaskStoreOption) (*FileTaskStore, error) {
 	taskLists, err := newTaskLists(database)
 
-	if err != nil {
-		return nil, fmt.Errorf("could not parse task lists: %v", err)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse task lists: %v", err)
+	}
+
+	if err := taskLists.validate(); err != nil {
+		return nil, fmt.Errorf("invalid task lists: %v", err)
+	}
+
+	store := &FileTaskStore{
+		database:  json.NewEncoder(&tape{database}),
+		taskLists: taskLists,
+		index:     search.NewIndex(),
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	now := time.Now()
+
+	for _, taskList := range taskLists {
+		for i := range taskList.Tasks {
+			task := &taskList.Tasks[i]
+			store.index.Add(task.ID, task.Description)
+
+			// Tasks completed before completion times were recorded are treated as if they were just completed, so
+			// that they are archived eventually rather than never.
+			if task.Done && task.CompletedAt == nil {
+				task.CompletedAt = &now
+			}
+		}
+	}
+
+	return store, nil
+}
+
+// Tell the subscribers of the task list of `user` that the task with `id` changed, if changes are published.
+func (f *FileTaskStore) publish(user string, kind events.Kind, id uint64) {
+	if f.events != nil {
+		f.events.Publish(user, kind, id)
+	}
+}
+
+func (f *FileTaskStore) GetTasks(user string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	taskList := f.taskLists.find(user)
+
+	if taskList != nil {
+		return taskList.withStatus(taskList.Tasks), nil
+	}
+
+	return nil, nil
+}
+
+func (f *FileTaskStore) GetTask(id uint64) (*models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findViewableTask(id)
+
+	if task == nil {
+		return nil, nil
+	}
+
+	return &list.withStatus([]models.Task{*task})[0], nil
+}
+
+func (f *FileTaskStore) GetOwner(id uint64) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findViewableTask(id)
+
+	if task == nil {
+		list, task = f.taskLists.findTrashedTask(id)
+	}
+
+	if task == nil {
+		return "", ErrTaskNotFound
+	}
+
+	return list.User, nil
+}
+
+func (f *FileTaskStore) AddTask(user string, description string) error {
+	_, err := f.AddTaskWithDetails(user, description, models.TaskUpdate{})
+
+	return err
+}
+
+func (f *FileTaskStore) AddTaskWithDetails(user string, description string, details models.TaskUpdate) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	userTaskList := f.taskLists.find(user)
+
+	if userTaskList == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		userTaskList = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	id := f.taskLists.nextID(userTaskList)
+
+	task := models.Task{ID: id, Description: description}
+	applyDetails(&task, details)
+
+	now := time.Now()
+	userTaskList.Tasks = append(userTaskList.Tasks, task)
+	userTaskList.record(models.Activity{TaskID: id, Kind: models.ActivityCreated, At: now})
+	userTaskList.trigger(models.WebhookTaskCreated, task, now)
+	f.index.Add(id, description)
+
+	f.publish(userTaskList.User, events.TaskAdded, id)
+
+	return id, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetSubtasks(id uint64) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findTask(id)
+	var tasks []models.Task
+
+	if task != nil {
+		tasks = list.Tasks
+	} else if list, task = f.taskLists.findArchivedTask(id); task != nil {
+		tasks = list.Archive
+	} else {
+		return nil, ErrTaskNotFound
+	}
+
+	var subtasks []models.Task
+
+	for _, subtaskID := range descendantsIn(tasks, id) {
+		subtasks = append(subtasks, *findTaskIn(tasks, subtaskID))
+	}
+
+	return list.withStatus(subtasks), nil
+}
+
+func (f *FileTaskStore) AddSubtask(parentID uint64, description string) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, parent := f.taskLists.findTask(parentID)
+
+	if parent == nil {
+		return 0, ErrTaskNotFound
+	}
+
+	if list.depth(parentID)+1 > MaxTaskDepth {
+		return 0, ErrMaxDepthExceeded
+	}
+
+	id := f.taskLists.nextID(list)
+	now := time.Now()
+	list.Tasks = append(list.Tasks, models.Task{ID: id, Description: description, ParentID: parentID})
+	list.record(models.Activity{TaskID: id, Kind: models.ActivityCreated, At: now})
+
+	// An open subtask means the parent is no longer finished.
+	list.reopenAncestors(id, now)
+	list.trigger(models.WebhookTaskCreated, *list.findTask(id), now)
+	f.index.Add(id, description)
+
+	f.publish(list.User, events.TaskAdded, id)
+
+	return id, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) SetParent(id uint64, parentID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	if parentID != 0 {
+		parentList, parent := f.taskLists.findTask(parentID)
+
+		if parent == nil {
+			return ErrTaskNotFound
+		}
+
+		if parentList != list {
+			return ErrDifferentList
+		}
+
+		if parentID == id || slices.Contains(list.descendants(id), parentID) {
+			return ErrTaskCycle
+		}
+
+		if list.depth(parentID)+list.height(id) > MaxTaskDepth {
+			return ErrMaxDepthExceeded
+		}
+	}
+
+	now := time.Now()
+	task.ParentID = parentID
+
+	if !task.Done {
+		list.reopenAncestors(id, now)
+	}
+
+	list.trigger(models.WebhookTaskUpdated, *task, now)
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) SetDone(id uint64, done bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	now := time.Now()
+
+	if done {
+		list.complete(task, now)
+
+		for _, subtaskID := range list.descendants(id) {
+			list.complete(list.findTask(subtaskID), now)
+		}
+	} else {
+		list.reopen(task, now)
+		list.reopenAncestors(id, now)
+	}
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) AddDependency(id uint64, blockerID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	blockerList, blocker := f.taskLists.findTask(blockerID)
+
+	if blocker == nil {
+		return ErrTaskNotFound
+	}
+
+	if blockerList != list {
+		return ErrDifferentList
+	}
+
+	if blockerID == id || list.waitsOn(blockerID, id) {
+		return ErrDependencyCycle
+	}
+
+	if slices.Contains(task.BlockedBy, blockerID) {
+		return nil
+	}
+
+	task.BlockedBy = append(task.BlockedBy, blockerID)
+	list.trigger(models.WebhookTaskUpdated, *task, time.Now())
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) RemoveDependency(id uint64, blockerID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	task.BlockedBy = slices.DeleteFunc(task.BlockedBy, func(otherID uint64) bool {
+		return otherID == blockerID
+	})
+	list.trigger(models.WebhookTaskUpdated, *task, time.Now())
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetReadyTasks(user string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return nil, nil
+	}
+
+	var ready []models.Task
+
+	for _, task := range list.withStatus(models.HideSnoozed(list.Tasks, time.Now())) {
+		if !task.Done && !task.Blocked {
+			ready = append(ready, task)
+		}
+	}
+
+	return ready, nil
+}
+
+func (f *FileTaskStore) GetNextActions(user string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return nil, nil
+	}
+
+	var open []models.Task
+
+	for _, task := range list.withStatus(models.HideSnoozed(list.Tasks, time.Now())) {
+		if !task.Done {
+			open = append(open, task)
+		}
+	}
+
+	// Kahn's algorithm, always picking the earliest task with no open blockers to keep the ordering stable.
+	waitingOn := make(map[uint64]int, len(open))
+	blocks := make(map[uint64][]uint64)
+	isOpen := make(map[uint64]bool, len(open))
+
+	for _, task := range open {
+		isOpen[task.ID] = true
+	}
+
+	for _, task := range open {
+		for _, blockerID := range task.BlockedBy {
+			// Snoozed blockers are not in the list, so there is nothing to order the task after.
+			if isOpen[blockerID] {
+				waitingOn[task.ID]++
+				blocks[blockerID] = append(blocks[blockerID], task.ID)
+			}
+		}
+	}
+
+	ordered := make([]models.Task, 0, len(open))
+	scheduled := make(map[uint64]bool, len(open))
+
+	for len(ordered) < len(open) {
+		progressed := false
+
+		for _, task := range open {
+			if scheduled[task.ID] || waitingOn[task.ID] > 0 {
+				continue
+			}
+
+			ordered = append(ordered, task)
+			scheduled[task.ID] = true
+			progressed = true
+
+			for _, blockedID := range blocks[task.ID] {
+				waitingOn[blockedID]--
+			}
+
+			break
+		}
+
+		if !progressed {
+			return nil, fmt.Errorf("could not order tasks for %q: %v", user, ErrDependencyCycle)
+		}
+	}
+
+	return ordered, nil
+}
+
+func (f *FileTaskStore) SearchTasks(user string, query string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var tasks []models.Task
+
+	for _, result := range f.index.Search(query) {
+		list, task := f.taskLists.findTask(result.ID)
+
+		if task != nil && (user == "" || list.User == user) {
+			tasks = append(tasks, list.withStatus([]models.Task{*task})[0])
+		}
+	}
+
+	return tasks, nil
+}
+
+func (f *FileTaskStore) UpdateTask(id uint64, update models.TaskUpdate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	if update.Description != nil && *update.Description != task.Description {
+		list.record(models.Activity{
+			TaskID: id,
+			Kind:   models.ActivityRenamed,
+			From:   task.Description,
+			To:     *update.Description,
+			At:     time.Now(),
+		})
+		task.Description = *update.Description
+		f.index.Add(id, task.Description)
+	}
+
+	applyDetails(task, update)
+	list.trigger(models.WebhookTaskUpdated, *task, time.Now())
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) AddSavedSearch(user string, name string, query string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	var id uint64 = 0
+
+	for _, savedSearch := range list.SavedSearches {
+		id = max(id, savedSearch.ID)
+	}
+
+	list.SavedSearches = append(list.SavedSearches, models.SavedSearch{ID: id + 1, Name: name, Query: query})
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetSavedSearches(user string) ([]models.SavedSearch, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return []models.SavedSearch{}, nil
+	}
+
+	return slices.Clone(list.SavedSearches), nil
+}
+
+func (f *FileTaskStore) DeleteSavedSearch(user string, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return ErrSavedSearchNotFound
+	}
+
+	index := slices.IndexFunc(list.SavedSearches, func(savedSearch models.SavedSearch) bool {
+		return savedSearch.ID == id
+	})
+
+	if index == -1 {
+		return ErrSavedSearchNotFound
+	}
+
+	list.SavedSearches = slices.Delete(list.SavedSearches, index, index+1)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) DeleteTask(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	now := time.Now()
+	ids := append([]uint64{id}, list.descendants(id)...)
+
+	for _, deleted := range takeTasks(&list.Tasks, ids) {
+		deleted.DeletedAt = &now
+		list.Trash = append(list.Trash, deleted)
+		list.record(models.Activity{TaskID: deleted.ID, Kind: models.ActivityDeleted, At: now})
+		list.trigger(models.WebhookTaskDeleted, deleted, now)
+		f.index.Remove(deleted.ID)
+	}
+
+	f.publish(list.User, events.TaskRemoved, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetTrash(user string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return []models.Task{}, nil
+	}
+
+	return list.withStatus(list.Trash), nil
+}
+
+func (f *FileTaskStore) RestoreTask(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTrashedTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	ids := append([]uint64{id}, descendantsIn(list.Trash, id)...)
+	now := time.Now()
+
+	for _, restored := range takeTasks(&list.Trash, ids) {
+		restored.DeletedAt = nil
+		list.Tasks = append(list.Tasks, restored)
+		list.record(models.Activity{TaskID: restored.ID, Kind: models.ActivityRestored, At: now})
+		f.index.Add(restored.ID, restored.Description)
+	}
+
+	task = list.findTask(id)
+
+	// The task's old place in the tree may no longer exist, so fall back to making it a top-level task.
+	if parent := list.parent(task); parent == nil || list.depth(parent.ID)+list.height(id) > MaxTaskDepth {
+		task.ParentID = 0
+	}
+
+	if !task.Done {
+		list.reopenAncestors(id, now)
+	}
+
+	for _, restoredID := range ids {
+		list.trigger(models.WebhookTaskCreated, *list.findTask(restoredID), now)
+	}
+
+	f.publish(list.User, events.TaskAdded, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) PurgeTask(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTrashedTask(id)
+
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	ids := append([]uint64{id}, descendantsIn(list.Trash, id)...)
+	takeTasks(&list.Trash, ids)
+	list.forgetTasks(ids)
+	f.taskLists.forgetNotifications(ids)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) PurgeTrash(before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.taskLists {
+		list := &f.taskLists[i]
+		var ids []uint64
+
+		for _, task := range list.Trash {
+			if task.DeletedAt.Before(before) {
+				ids = append(ids, task.ID)
+			}
+		}
+
+		takeTasks(&list.Trash, ids)
+		list.forgetTasks(ids)
+		f.taskLists.forgetNotifications(ids)
+	}
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) ArchiveCompletedTasks(before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	for i := range f.taskLists {
+		list := &f.taskLists[i]
+		var ids []uint64
+
+		for _, task := range list.Tasks {
+			if task.ParentID == 0 && task.Done && task.CompletedAt != nil && task.CompletedAt.Before(before) {
+				ids = append(ids, task.ID)
+				ids = append(ids, list.descendants(task.ID)...)
+			}
+		}
+
+		for _, archived := range takeTasks(&list.Tasks, ids) {
+			list.Archive = append(list.Archive, archived)
+			list.record(models.Activity{TaskID: archived.ID, Kind: models.ActivityArchived, At: now})
+			f.index.Remove(archived.ID)
+			f.publish(list.User, events.TaskRemoved, archived.ID)
+		}
+	}
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetArchivedTasks(user string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return []models.Task{}, nil
+	}
+
+	return list.withStatus(list.Archive), nil
+}
+
+func (f *FileTaskStore) AddComment(taskID uint64, author string, body string) (*models.Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	comment := models.Comment{
+		ID:        f.taskLists.nextCommentID(list),
+		TaskID:    taskID,
+		Author:    author,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	list.Comments = append(list.Comments, comment)
+
+	return &comment, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetComments(taskID uint64) ([]models.Comment, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	comments := []models.Comment{}
+
+	for _, comment := range list.Comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+
+	return comments, nil
+}
+
+func (f *FileTaskStore) GetComment(id uint64) (*models.Comment, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, comment := f.taskLists.findComment(id)
+
+	if comment == nil {
+		return nil, nil
+	}
+
+	found := *comment
+
+	return &found, nil
+}
+
+func (f *FileTaskStore) UpdateComment(id uint64, author string, body string) (*models.Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, comment := f.taskLists.findComment(id)
+
+	if comment == nil {
+		return nil, ErrCommentNotFound
+	}
+
+	if comment.Author != author {
+		return nil, ErrNotCommentAuthor
+	}
+
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	updated := *comment
+
+	return &updated, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) DeleteComment(id uint64, author string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, comment := f.taskLists.findComment(id)
+
+	if comment == nil {
+		return ErrCommentNotFound
+	}
+
+	if comment.Author != author {
+		return ErrNotCommentAuthor
+	}
+
+	list.Comments = slices.DeleteFunc(list.Comments, func(comment models.Comment) bool {
+		return comment.ID == id
+	})
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetActivity(taskID uint64) ([]models.Activity, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	activity := []models.Activity{}
+
+	for _, change := range list.Activity {
+		if change.TaskID == taskID {
+			activity = append(activity, change)
+		}
+	}
+
+	return activity, nil
+}
+
+func (f *FileTaskStore) GetColumns(user string) ([]models.Column, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return slices.Clone(models.DefaultColumns), nil
+	}
+
+	return list.columns(), nil
+}
+
+func (f *FileTaskStore) SetColumns(user string, columns []models.Column) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hasOpen := slices.ContainsFunc(columns, func(column models.Column) bool { return !column.Done })
+	hasDone := slices.ContainsFunc(columns, func(column models.Column) bool { return column.Done })
+	invalid := slices.ContainsFunc(columns, func(column models.Column) bool {
+		return strings.TrimSpace(column.Name) == "" || column.WIPLimit < 0
+	})
+
+	if !hasOpen || !hasDone || invalid {
+		return ErrInvalidColumns
+	}
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	current := list.columns()
+	var nextID uint64 = 0
+
+	for _, column := range current {
+		nextID = max(nextID, column.ID)
+	}
+
+	updated := make([]models.Column, len(columns))
+
+	for i, column := range columns {
+		column.Name = strings.TrimSpace(column.Name)
+
+		// Only keep the IDs of existing columns so that new columns do not take the IDs of removed columns.
+		if !slices.ContainsFunc(current, func(existing models.Column) bool { return existing.ID == column.ID }) ||
+			slices.ContainsFunc(updated[:i], func(previous models.Column) bool { return previous.ID == column.ID }) {
+			nextID++
+			column.ID = nextID
+		}
+
+		updated[i] = column
+	}
+
+	for i := range list.Tasks {
+		status := list.Tasks[i].Status
+
+		if !slices.ContainsFunc(updated, func(column models.Column) bool { return column.ID == status }) {
+			list.Tasks[i].Status = 0
+		}
+	}
+
+	list.Columns = updated
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) MoveTask(user string, id uint64, columnID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil || list.User != user {
+		return ErrTaskNotFound
+	}
+
+	columns := list.columns()
+	i := slices.IndexFunc(columns, func(column models.Column) bool { return column.ID == columnID })
+
+	if i == -1 {
+		return ErrColumnNotFound
+	}
+
+	column := columns[i]
+
+	if column.WIPLimit > 0 && models.ColumnOf(*task, columns) != columnID {
+		count := 0
+
+		for _, other := range list.Tasks {
+			if models.ColumnOf(other, columns) == columnID {
+				count++
+			}
+		}
+
+		if count >= column.WIPLimit {
+			return ErrWIPLimitReached
+		}
+	}
+
+	now := time.Now()
+	task.Status = columnID
+
+	if column.Done {
+		list.complete(task, now)
+
+		for _, subtaskID := range list.descendants(id) {
+			list.complete(list.findTask(subtaskID), now)
+		}
+	} else {
+		// Reopening a task that is done tells the webhooks about the move.
+		if !task.Done {
+			list.trigger(models.WebhookTaskUpdated, *task, now)
+		}
+
+		list.reopen(task, now)
+		list.reopenAncestors(id, now)
+	}
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) AddTemplate(taskID uint64, name string) (*models.Template, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	var subtasks []models.Task
+
+	for _, id := range list.descendants(taskID) {
+		subtasks = append(subtasks, *list.findTask(id))
+	}
+
+	template := models.NewTemplate(name, *task, subtasks)
+
+	for _, existing := range list.Templates {
+		template.ID = max(template.ID, existing.ID)
+	}
+
+	template.ID++
+	list.Templates = append(list.Templates, template)
+
+	return &template, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetTemplates(user string) ([]models.Template, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return []models.Template{}, nil
+	}
+
+	return slices.Clone(list.Templates), nil
+}
+
+func (f *FileTaskStore) DeleteTemplate(user string, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return ErrTemplateNotFound
+	}
+
+	index := slices.IndexFunc(list.Templates, func(template models.Template) bool {
+		return template.ID == id
+	})
+
+	if index == -1 {
+		return ErrTemplateNotFound
+	}
+
+	list.Templates = slices.Delete(list.Templates, index, index+1)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) UseTemplate(user string, id uint64, anchor time.Time, values map[string]string) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return 0, ErrTemplateNotFound
+	}
+
+	index := slices.IndexFunc(list.Templates, func(template models.Template) bool {
+		return template.ID == id
+	})
+
+	if index == -1 {
+		return 0, ErrTemplateNotFound
+	}
+
+	template := list.Templates[index]
+
+	for _, name := range template.Placeholders() {
+		if strings.TrimSpace(values[name]) == "" {
+			return 0, fmt.Errorf("%w: %s", ErrMissingValue, name)
+		}
+	}
+
+	now := time.Now()
+
+	var add func(templateTask models.TemplateTask, parentID uint64) uint64
+	add = func(templateTask models.TemplateTask, parentID uint64) uint64 {
+		task := models.Task{
+			ID:          f.taskLists.nextID(list),
+			Description: models.FillPlaceholders(templateTask.Description, values),
+			Notes:       models.FillPlaceholders(templateTask.Notes, values),
+			Tags:        slices.Clone(templateTask.Tags),
+			Priority:    templateTask.Priority,
+			Estimate:    templateTask.Estimate,
+			Due:         templateTask.Due(anchor),
+			ParentID:    parentID,
+		}
+
+		if templateTask.Recurrence != nil {
+			recurrence := *templateTask.Recurrence
+			task.Recurrence = &recurrence
+		}
+
+		list.Tasks = append(list.Tasks, task)
+		list.record(models.Activity{TaskID: task.ID, Kind: models.ActivityCreated, At: now})
+		list.trigger(models.WebhookTaskCreated, task, now)
+		f.index.Add(task.ID, task.Description)
+
+		for _, subtask := range templateTask.Subtasks {
+			add(subtask, task.ID)
+		}
+
+		return task.ID
+	}
+
+	rootID := add(template.Task, 0)
+
+	f.publish(list.User, events.TaskAdded, rootID)
+
+	return rootID, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetMembers(user string) ([]models.Member, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return []models.Member{}, nil
+	}
+
+	return slices.Clone(list.Members), nil
+}
+
+func (f *FileTaskStore) SetMember(user string, member models.Member) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	member.Email = strings.TrimSpace(member.Email)
+
+	if member.Email == "" || member.Email == user || !slices.Contains(models.Roles, member.Role) {
+		return ErrInvalidMember
+	}
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	index := slices.IndexFunc(list.Members, func(existing models.Member) bool {
+		return existing.Email == member.Email
+	})
+
+	if index == -1 {
+		list.Members = append(list.Members, member)
+	} else {
+		list.Members[index] = member
+	}
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) RemoveMember(user string, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return ErrMemberNotFound
+	}
+
+	index := slices.IndexFunc(list.Members, func(member models.Member) bool {
+		return member.Email == email
+	})
+
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+
+	list.Members = slices.Delete(list.Members, index, index+1)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetSharedLists(email string) ([]models.SharedList, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	sharedLists := []models.SharedList{}
+
+	for _, list := range f.taskLists {
+		for _, member := range list.Members {
+			if member.Email == email {
+				sharedLists = append(sharedLists, models.SharedList{Owner: list.User, Role: member.Role})
+			}
+		}
+	}
+
+	return sharedLists, nil
+}
+
+func (f *FileTaskStore) SetAssignees(id uint64, assignees []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findTask(id)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	var cleaned []string
+
+	for _, assignee := range assignees {
+		assignee = strings.TrimSpace(assignee)
+
+		if assignee == "" || slices.Contains(cleaned, assignee) {
+			continue
+		}
+
+		if assignee != list.User && !slices.ContainsFunc(list.Members, func(member models.Member) bool {
+			return member.Email == assignee
+		}) {
+			return nil, ErrInvalidAssignee
+		}
+
+		cleaned = append(cleaned, assignee)
+	}
+
+	var added []string
+
+	for _, assignee := range cleaned {
+		if !slices.Contains(task.Assignees, assignee) {
+			added = append(added, assignee)
+		}
+	}
+
+	if len(added) == 0 && len(cleaned) == len(task.Assignees) {
+		return nil, nil
+	}
+
+	list.record(models.Activity{
+		TaskID: id,
+		Kind:   models.ActivityAssigned,
+		From:   strings.Join(task.Assignees, ", "),
+		To:     strings.Join(cleaned, ", "),
+		At:     time.Now(),
+	})
+	task.Assignees = cleaned
+	list.trigger(models.WebhookTaskUpdated, *task, time.Now())
+
+	f.publish(list.User, events.TaskChanged, id)
+
+	return added, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetAssignedTasks(email string) ([]models.Task, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	assigned := []models.Task{}
+
+	for _, list := range f.taskLists {
+		for _, task := range list.withStatus(list.Tasks) {
+			if !task.Done && slices.Contains(task.Assignees, email) {
+				assigned = append(assigned, task)
+			}
+		}
+	}
+
+	return assigned, nil
+}
+
+func (f *FileTaskStore) AddNotification(user string, notification models.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	var maxID uint64
+
+	for _, existing := range list.Notifications {
+		maxID = max(maxID, existing.ID)
+	}
+
+	notification.ID = maxID + 1
+	notification.CreatedAt = time.Now()
+	notification.Read = false
+	list.Notifications = append(list.Notifications, notification)
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetNotifications(user string) ([]models.Notification, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	notifications := []models.Notification{}
+
+	if list := f.taskLists.find(user); list != nil {
+		notifications = append(notifications, list.Notifications...)
+		slices.Reverse(notifications)
+	}
+
+	return notifications, nil
+}
+
+func (f *FileTaskStore) MarkNotificationsRead(user string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return nil
+	}
+
+	for i := range list.Notifications {
+		list.Notifications[i].Read = true
+	}
+
+	return f.database.Encode(f.taskLists)
+}
+
+// The number of deliveries that have been received or given up on that are kept for each task list.
+const deliveryLogSize = 100
+
+func (f *FileTaskStore) AddWebhook(user string, webhook models.Webhook) (*models.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	var maxID uint64
+
+	for _, existing := range list.Webhooks {
+		maxID = max(maxID, existing.ID)
+	}
+
+	webhook.ID = maxID + 1
+	webhook.Events = slices.Clone(webhook.Events)
+	list.Webhooks = append(list.Webhooks, webhook)
+
+	return &webhook, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetWebhooks(user string) ([]models.Webhook, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+
+	if list := f.taskLists.find(user); list != nil {
+		for _, webhook := range list.Webhooks {
+			webhook.Events = slices.Clone(webhook.Events)
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (f *FileTaskStore) DeleteWebhook(user string, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return ErrWebhookNotFound
+	}
+
+	index := slices.IndexFunc(list.Webhooks, func(webhook models.Webhook) bool {
+		return webhook.ID == id
+	})
+
+	if index == -1 {
+		return ErrWebhookNotFound
+	}
+
+	list.Webhooks = slices.Delete(list.Webhooks, index, index+1)
+	list.Deliveries = slices.DeleteFunc(list.Deliveries, func(delivery models.Delivery) bool {
+		return delivery.WebhookID == id
+	})
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetDeliveries(user string) ([]models.Delivery, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	deliveries := []models.Delivery{}
+
+	if list := f.taskLists.find(user); list != nil {
+		deliveries = append(deliveries, list.Deliveries...)
+		slices.Reverse(deliveries)
+	}
+
+	return deliveries, nil
+}
+
+func (f *FileTaskStore) GetDueDeliveries(now time.Time) ([]models.Delivery, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	due := []models.Delivery{}
+
+	for _, list := range f.taskLists {
+		for _, delivery := range list.Deliveries {
+			if delivery.Pending() && !delivery.NextAttemptAt.After(now) {
+				due = append(due, delivery)
+			}
+		}
+	}
+
+	slices.SortStableFunc(due, func(a, b models.Delivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return due, nil
+}
+
+func (f *FileTaskStore) RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return ErrWebhookNotFound
+	}
+
+	index := slices.IndexFunc(list.Deliveries, func(delivery models.Delivery) bool {
+		return delivery.ID == id
+	})
+
+	if index == -1 {
+		return ErrWebhookNotFound
+	}
+
+	list.Deliveries[index].RecordAttempt(statusCode, err, at)
+
+	// Forget the oldest finished deliveries so that the log does not grow forever.
+	finished := 0
+
+	for i := len(list.Deliveries) - 1; i >= 0; i-- {
+		if list.Deliveries[i].Pending() {
+			continue
+		}
+
+		if finished++; finished > deliveryLogSize {
+			list.Deliveries = slices.Delete(list.Deliveries, i, i+1)
+		}
+	}
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetInboundToken(user string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if list := f.taskLists.find(user); list != nil {
+		return list.InboundToken, nil
+	}
+
+	return "", nil
+}
+
+func (f *FileTaskStore) ResetInboundToken(user string) (string, error) {
+	return f.resetToken(user, func(list *taskList) *string { return &list.InboundToken })
+}
+
+func (f *FileTaskStore) GetInboundOwner(token string) (string, error) {
+	return f.getTokenOwner(token, func(list *taskList) *string { return &list.InboundToken }, ErrInboundTokenNotFound)
+}
+
+func (f *FileTaskStore) GetFeedToken(user string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if list := f.taskLists.find(user); list != nil {
+		return list.FeedToken, nil
+	}
+
+	return "", nil
+}
+
+func (f *FileTaskStore) ResetFeedToken(user string) (string, error) {
+	return f.resetToken(user, func(list *taskList) *string { return &list.FeedToken })
+}
+
+func (f *FileTaskStore) GetFeedOwner(token string) (string, error) {
+	return f.getTokenOwner(token, func(list *taskList) *string { return &list.FeedToken }, ErrFeedTokenNotFound)
+}
+
+// Replace the secret token, given by `field`, of the task list of `user` with a new random token.
+//
+// Returns the new token.
+func (f *FileTaskStore) resetToken(user string, field func(list *taskList) *string) (string, error) {
+	secret := make([]byte, 16)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate a token: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		f.taskLists = append(f.taskLists, taskList{User: user, Tasks: []models.Task{}})
+		list = &f.taskLists[len(f.taskLists)-1]
+	}
+
+	token := field(list)
+	*token = hex.EncodeToString(secret)
+
+	return *token, f.database.Encode(f.taskLists)
+}
+
+// Get the user whose task list has `token` as the secret token given by `field`.
+//
+// Returns `notFound` if no list has the token.
+func (f *FileTaskStore) getTokenOwner(token string, field func(list *taskList) *string, notFound error) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if token == "" {
+		return "", notFound
+	}
+
+	for i := range f.taskLists {
+		// Compare in constant time so that the token cannot be guessed from how long the comparison takes.
+		if subtle.ConstantTimeCompare([]byte(*field(&f.taskLists[i])), []byte(token)) == 1 {
+			return f.taskLists[i].User, nil
+		}
+	}
+
+	return "", notFound
+}
+
+func (f *FileTaskStore) GetCompletionHistory(user string) ([]models.Activity, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	history := []models.Activity{}
+	list := f.taskLists.find(user)
+
+	if list == nil {
+		return history, nil
+	}
+
+	for _, activity := range list.Activity {
+		switch activity.Kind {
+		case models.ActivityCreated, models.ActivityCompleted, models.ActivityReopened:
+			history = append(history, activity)
+		}
+	}
+
+	return history, nil
+}
+
+func (f *FileTaskStore) AddAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findViewableTask(attachment.TaskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	attachment.ID = f.taskLists.nextAttachmentID(list)
+	list.Attachments = append(list.Attachments, attachment)
+
+	return &attachment, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetAttachments(taskID uint64) ([]models.Attachment, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	attachments := []models.Attachment{}
+
+	for _, attachment := range list.Attachments {
+		if attachment.TaskID == taskID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	return attachments, nil
+}
+
+func (f *FileTaskStore) GetAttachment(id uint64) (*models.Attachment, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, attachment := f.taskLists.findAttachment(id)
+
+	if attachment == nil {
+		return nil, nil
+	}
+
+	if _, task := f.taskLists.findViewableTask(attachment.TaskID); task == nil {
+		return nil, nil
+	}
+
+	found := *attachment
+
+	return &found, nil
+}
+
+func (f *FileTaskStore) DeleteAttachment(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, attachment := f.taskLists.findAttachment(id)
+
+	if attachment == nil {
+		return ErrAttachmentNotFound
+	}
+
+	list.Attachments = slices.DeleteFunc(list.Attachments, func(attachment models.Attachment) bool {
+		return attachment.ID == id
+	})
+
+	return f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetAttachmentKeys() ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var keys []string
+
+	for _, list := range f.taskLists {
+		for _, attachment := range list.Attachments {
+			if !slices.Contains(keys, attachment.Key) {
+				keys = append(keys, attachment.Key)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys, nil
+}
+
+func (f *FileTaskStore) StartTimer(taskID uint64, user string) (*models.TimeEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	if f.taskLists.findRunningTimer(user) != nil {
+		return nil, ErrTimerRunning
+	}
+
+	entry := models.TimeEntry{
+		ID:     f.taskLists.nextTimeEntryID(list),
+		TaskID: taskID,
+		User:   user,
+		Start:  time.Now(),
+	}
+	list.TimeEntries = append(list.TimeEntries, entry)
+
+	return &entry, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) StopTimer(user string) (*models.TimeEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	timer := f.taskLists.findRunningTimer(user)
+
+	if timer == nil {
+		return nil, ErrNoTimerRunning
+	}
+
+	now := time.Now()
+	timer.End = &now
+	stopped := *timer
+
+	return &stopped, f.database.Encode(f.taskLists)
+}
+
+func (f *FileTaskStore) GetRunningTimer(user string) (*models.TimeEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	timer := f.taskLists.findRunningTimer(user)
+
+	if timer == nil {
+		return nil, nil
+	}
+
+	found := *timer
+
+	return &found, nil
+}
+
+func (f *FileTaskStore) AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, task := f.taskLists.findViewableTask(entry.TaskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
 	}
 
-	store := &FileTaskStore{
-		database:  json.NewEncoder(&tape{database}),
-		taskLists: taskLists,
+	if entry.End == nil || !entry.End.After(entry.Start) {
+		return nil, ErrInvalidTimeEntry
 	}
 
-	return store, nil
+	entry.ID = f.taskLists.nextTimeEntryID(list)
+	list.TimeEntries = append(list.TimeEntries, entry)
+
+	return &entry, f.database.Encode(f.taskLists)
 }
 
-func (f *FileTaskStore) GetTasks(user string) ([]models.Task, error) {
-	taskList := f.taskLists.find(user)
+func (f *FileTaskStore) GetTimeEntries(taskID uint64) ([]models.TimeEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 
-	if taskList != nil {
-		return taskList.Tasks, nil
+	list, task := f.taskLists.findViewableTask(taskID)
+
+	if task == nil {
+		return nil, ErrTaskNotFound
 	}
 
-	return nil, nil
+	entries := []models.TimeEntry{}
+
+	for _, entry := range list.TimeEntries {
+		if entry.TaskID == taskID {
+			entries = append(entries, entry)
+		}
+	}
+
+	// Time entered by hand may have been spent before the time already recorded.
+	sortByStart(entries)
+
+	return entries, nil
 }
 
-func (f *FileTaskStore) GetTask(id uint64) (*models.Task, error) {
-	for _, taskList := range f.taskLists {
-		for _, task := range taskList.Tasks {
-			if task.ID == id {
-				return &task, nil
+func (f *FileTaskStore) GetTimesheet(user string, from time.Time, to time.Time) ([]models.TimeEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	entries := []models.TimeEntry{}
+
+	for _, list := range f.taskLists {
+		for _, entry := range list.TimeEntries {
+			if entry.User == user && !entry.Start.Before(from) && entry.Start.Before(to) {
+				entries = append(entries, entry)
 			}
 		}
 	}
 
-	return nil, nil
+	sortByStart(entries)
+
+	return entries, nil
 }
 
-func (f *FileTaskStore) AddTask(user string, description string) error {
-	userTaskList := f.taskLists.find(user)
-	id := f.taskLists.nextID()
-	task := models.Task{ID: id, Description: description}
+// Sort `entries` by the time they started, oldest first.
+func sortByStart(entries []models.TimeEntry) {
+	slices.SortStableFunc(entries, func(a, b models.TimeEntry) int {
+		return a.Start.Compare(b.Start)
+	})
+}
 
-	if userTaskList != nil {
-		userTaskList.Tasks = append(userTaskList.Tasks, task)
-	} else {
-		f.taskLists = append(f.taskLists, taskList{user, []models.Task{task}})
+func (f *FileTaskStore) GetTimeEntry(id uint64) (*models.TimeEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, entry := f.taskLists.findTimeEntry(id)
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	found := *entry
+
+	return &found, nil
+}
+
+func (f *FileTaskStore) DeleteTimeEntry(id uint64, user string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, entry := f.taskLists.findTimeEntry(id)
+
+	if entry == nil {
+		return ErrTimeEntryNotFound
+	}
+
+	if entry.User != user {
+		return ErrNotTimeEntryOwner
 	}
 
+	list.TimeEntries = slices.DeleteFunc(list.TimeEntries, func(entry models.TimeEntry) bool {
+		return entry.ID == id
+	})
+
 	return f.database.Encode(f.taskLists)
 }
 
+// Remove the tasks with `ids` from `tasks`, returning the removed tasks in their original order.
+func takeTasks(tasks *[]models.Task, ids []uint64) []models.Task {
+	var taken []models.Task
+
+	*tasks = slices.DeleteFunc(*tasks, func(task models.Task) bool {
+		if slices.Contains(ids, task.ID) {
+			taken = append(taken, task)
+			return true
+		}
+
+		return false
+	})
+
+	return taken
+}
+
+// Apply the changes to everything but the description of a task in `update` to `task`.
+func applyDetails(task *models.Task, update models.TaskUpdate) {
+	if update.Notes != nil {
+		task.Notes = *update.Notes
+	}
+
+	if update.Tags != nil {
+		task.Tags = normaliseTags(update.Tags)
+	}
+
+	if update.Priority != nil {
+		task.Priority = *update.Priority
+	}
+
+	if update.ClearDue {
+		task.Due = nil
+	} else if update.Due != nil {
+		due := *update.Due
+		task.Due = &due
+	}
+
+	if update.Estimate != nil {
+		task.Estimate = *update.Estimate
+	}
+
+	if update.ClearHiddenUntil {
+		task.HiddenUntil = nil
+	} else if update.HiddenUntil != nil {
+		hiddenUntil := *update.HiddenUntil
+		task.HiddenUntil = &hiddenUntil
+	}
+
+	if update.ClearRecurrence {
+		task.Recurrence = nil
+	} else if update.Recurrence != nil {
+		recurrence := *update.Recurrence
+		task.Recurrence = &recurrence
+	}
+
+	if update.CalendarObject != nil {
+		object := *update.CalendarObject
+		task.CalendarObject = &object
+	}
+}
+
+// Trim whitespace and leading hashes from tags, and remove empty and duplicate tags.
+func normaliseTags(tags []string) []string {
+	var normalised []string
+
+	for _, tag := range tags {
+		tag = strings.TrimLeft(strings.TrimSpace(tag), "#")
+
+		if tag != "" && !slices.Contains(normalised, tag) {
+			normalised = append(normalised, tag)
+		}
+	}
+
+	return normalised
+}
+
 // A list of tasks for a user.
 type taskList struct {
-	User  string
-	Tasks []models.Task
+	User          string
+	Tasks         []models.Task
+	SavedSearches []models.SavedSearch `json:",omitempty"`
+	// The columns of the user's board, or nil if the board has not been set up.
+	Columns []models.Column `json:",omitempty"`
+	// Deleted tasks that can still be restored.
+	Trash []models.Task `json:",omitempty"`
+	// Completed tasks that are kept out of the way of the task list.
+	Archive []models.Task `json:",omitempty"`
+	// The ID of the last task that was added to the list. Task IDs are unique across all lists, so new tasks get IDs
+	// after the largest of these, even once the task with it has been purged.
+	LastTaskID uint64 `json:",omitempty"`
+	// The comments on the tasks in the list, oldest first.
+	Comments []models.Comment `json:",omitempty"`
+	// The ID of the last comment that was added to the list, see [taskList.LastTaskID].
+	LastCommentID uint64 `json:",omitempty"`
+	// The changes made to the tasks in the list, oldest first.
+	Activity []models.Activity `json:",omitempty"`
+	// The files attached to the tasks in the list, oldest first.
+	Attachments []models.Attachment `json:",omitempty"`
+	// The ID of the last attachment that was added to the list, see [taskList.LastTaskID].
+	LastAttachmentID uint64 `json:",omitempty"`
+	// The time spent on the tasks in the list, oldest first.
+	TimeEntries []models.TimeEntry `json:",omitempty"`
+	// The ID of the last time entry that was added to the list, see [taskList.LastTaskID].
+	LastTimeEntryID uint64 `json:",omitempty"`
+	// Trees of tasks that can be added to the list over and over.
+	Templates []models.Template `json:",omitempty"`
+	// The other users that the list is shared with.
+	Members []models.Member `json:",omitempty"`
+	// The notifications of the user, oldest first.
+	Notifications []models.Notification `json:",omitempty"`
+	// The services that are sent the changes to the tasks in the list.
+	Webhooks []models.Webhook `json:",omitempty"`
+	// The changes that are being sent, or were sent, to the webhooks, oldest first.
+	Deliveries []models.Delivery `json:",omitempty"`
+	// The ID of the last delivery that was queued, so that the IDs of deliveries that have been trimmed from the log
+	// or deleted along with their webhook are not used again.
+	LastDeliveryID uint64 `json:",omitempty"`
+	// The secret that lets other services add tasks to the list, or empty if the list does not have one yet.
+	InboundToken string `json:",omitempty"`
+	// The secret that lets calendar apps subscribe to the tasks in the list, or empty if the list does not have one
+	// yet.
+	FeedToken string `json:",omitempty"`
 }
 
 type taskLists []taskList
@@ -115,15 +1958,458 @@ func (t taskLists) find(user string) *taskList {
 	return nil
 }
 
-// Use this function when setting the ID of a new task to ensure that the ID is auto-incremented and unique.
-func (t taskLists) nextID() (id uint64) {
+// Search all task lists for the task with `id`.
+// Returns `nil` for both the list and the task if not found.
+func (t taskLists) findTask(id uint64) (*taskList, *models.Task) {
+	for i := range t {
+		if task := t[i].findTask(id); task != nil {
+			return &t[i], task
+		}
+	}
+
+	return nil, nil
+}
+
+// Search the trash of all task lists for the task with `id`.
+// Returns `nil` for both the list and the task if not found.
+func (t taskLists) findTrashedTask(id uint64) (*taskList, *models.Task) {
+	for i := range t {
+		if task := findTaskIn(t[i].Trash, id); task != nil {
+			return &t[i], task
+		}
+	}
+
+	return nil, nil
+}
+
+// Search the archives of all task lists for the task with `id`.
+// Returns `nil` for both the list and the task if not found.
+func (t taskLists) findArchivedTask(id uint64) (*taskList, *models.Task) {
+	for i := range t {
+		if task := findTaskIn(t[i].Archive, id); task != nil {
+			return &t[i], task
+		}
+	}
+
+	return nil, nil
+}
+
+// Search the tasks and archives of all task lists for the task with `id`.
+// Returns `nil` for both the list and the task if not found.
+func (t taskLists) findViewableTask(id uint64) (*taskList, *models.Task) {
+	if list, task := t.findTask(id); task != nil {
+		return list, task
+	}
+
+	return t.findArchivedTask(id)
+}
+
+// Search all task lists for the comment with `id`.
+// Returns `nil` for both the list and the comment if not found.
+func (t taskLists) findComment(id uint64) (*taskList, *models.Comment) {
+	for i := range t {
+		for j := range t[i].Comments {
+			if t[i].Comments[j].ID == id {
+				return &t[i], &t[i].Comments[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// Search all task lists for the attachment with `id`.
+// Returns `nil` for both the list and the attachment if not found.
+func (t taskLists) findAttachment(id uint64) (*taskList, *models.Attachment) {
+	for i := range t {
+		for j := range t[i].Attachments {
+			if t[i].Attachments[j].ID == id {
+				return &t[i], &t[i].Attachments[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// Use this function when setting the ID of a new attachment in `list` to ensure that the ID is auto-incremented and
+// unique, even after attachments have been deleted.
+func (t taskLists) nextAttachmentID(list *taskList) uint64 {
+	var id uint64 = 0
+
+	for _, taskList := range t {
+		// Lists saved before the last attachment ID was kept only have the IDs of their attachments.
+		id = max(id, taskList.LastAttachmentID)
+
+		for _, attachment := range taskList.Attachments {
+			id = max(id, attachment.ID)
+		}
+	}
+
+	list.LastAttachmentID = id + 1
+
+	return list.LastAttachmentID
+}
+
+// Search all task lists for the time entry with `id`.
+// Returns `nil` for both the list and the time entry if not found.
+func (t taskLists) findTimeEntry(id uint64) (*taskList, *models.TimeEntry) {
+	for i := range t {
+		for j := range t[i].TimeEntries {
+			if t[i].TimeEntries[j].ID == id {
+				return &t[i], &t[i].TimeEntries[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// Search all task lists for the running timer of `user`.
+// Returns `nil` if `user` does not have a timer running.
+func (t taskLists) findRunningTimer(user string) *models.TimeEntry {
+	for i := range t {
+		for j := range t[i].TimeEntries {
+			if entry := &t[i].TimeEntries[j]; entry.User == user && entry.Running() {
+				return entry
+			}
+		}
+	}
+
+	return nil
+}
+
+// Use this function when setting the ID of a new time entry in `list` to ensure that the ID is auto-incremented and
+// unique, even after time entries have been deleted.
+func (t taskLists) nextTimeEntryID(list *taskList) uint64 {
+	var id uint64 = 0
+
+	for _, taskList := range t {
+		// Lists saved before the last time entry ID was kept only have the IDs of their time entries.
+		id = max(id, taskList.LastTimeEntryID)
+
+		for _, entry := range taskList.TimeEntries {
+			id = max(id, entry.ID)
+		}
+	}
+
+	list.LastTimeEntryID = id + 1
+
+	return list.LastTimeEntryID
+}
+
+// Use this function when setting the ID of a new comment in `list` to ensure that the ID is auto-incremented and
+// unique, even after comments have been deleted.
+func (t taskLists) nextCommentID(list *taskList) uint64 {
+	var id uint64 = 0
+
+	for _, taskList := range t {
+		// Lists saved before the last comment ID was kept only have the IDs of their comments.
+		id = max(id, taskList.LastCommentID)
+
+		for _, comment := range taskList.Comments {
+			id = max(id, comment.ID)
+		}
+	}
+
+	list.LastCommentID = id + 1
+
+	return list.LastCommentID
+}
+
+// Check that every list forms valid trees of subtasks, i.e., there are no cycles and subtasks belong to the same
+// list as their parent, and that no task depends on itself.
+func (t taskLists) validate() error {
+	for _, list := range t {
+		for _, task := range list.Tasks {
+			seen := map[uint64]bool{task.ID: true}
+
+			for parentID := task.ParentID; parentID != 0; {
+				if seen[parentID] {
+					return fmt.Errorf("task %d: %v", task.ID, ErrTaskCycle)
+				}
+
+				seen[parentID] = true
+				parent := list.findTask(parentID)
+
+				if parent == nil {
+					return fmt.Errorf("task %d: parent %d: %v", task.ID, parentID, ErrTaskNotFound)
+				}
+
+				parentID = parent.ParentID
+			}
+
+			if list.waitsOn(task.ID, task.ID) {
+				return fmt.Errorf("task %d: %v", task.ID, ErrDependencyCycle)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Copy `tasks` from the list and compute their [models.Task.Blocked] status.
+func (l *taskList) withStatus(tasks []models.Task) []models.Task {
+	withStatus := make([]models.Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		task.BlockedBy = slices.Clone(task.BlockedBy)
+		task.Blocked = false
+
+		for _, blockerID := range task.BlockedBy {
+			if blocker := l.findTask(blockerID); blocker != nil && !blocker.Done {
+				task.Blocked = true
+				break
+			}
+		}
+
+		withStatus = append(withStatus, task)
+	}
+
+	return withStatus
+}
+
+// Whether the task with `id` has to wait on the task with `blockerID`, either directly or through other tasks.
+func (l *taskList) waitsOn(id uint64, blockerID uint64) bool {
+	visited := make(map[uint64]bool)
+
+	var visit func(id uint64) bool
+	visit = func(id uint64) bool {
+		if visited[id] {
+			return false
+		}
+
+		visited[id] = true
+		task := l.findTask(id)
+
+		if task == nil {
+			return false
+		}
+
+		for _, otherID := range task.BlockedBy {
+			if otherID == blockerID || visit(otherID) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return visit(id)
+}
+
+// Search the list for the task with `id`, ignoring archived tasks and tasks in the trash.
+// Returns `nil` if not found.
+func (l *taskList) findTask(id uint64) *models.Task {
+	return findTaskIn(l.Tasks, id)
+}
+
+// Search `tasks` for the task with `id`.
+// Returns `nil` if not found.
+func findTaskIn(tasks []models.Task, id uint64) *models.Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+
+	return nil
+}
+
+// Get the IDs of all tasks below the task with `id`, in depth-first order.
+func (l *taskList) descendants(id uint64) []uint64 {
+	return descendantsIn(l.Tasks, id)
+}
+
+// Get the IDs of all tasks in `tasks` below the task with `id`, in depth-first order.
+func descendantsIn(tasks []models.Task, id uint64) []uint64 {
+	var ids []uint64
+
+	for _, task := range tasks {
+		if task.ParentID == id {
+			ids = append(ids, task.ID)
+			ids = append(ids, descendantsIn(tasks, task.ID)...)
+		}
+	}
+
+	return ids
+}
+
+// Remove references to the permanently deleted tasks with `ids` from the other tasks in the list, so that the
+// references do not point to new tasks that reuse the IDs, along with the comments, activity, attachments, time entries
+// and webhook deliveries of the tasks. Notifications are kept by the users they are for, see
+// [taskLists.forgetNotifications].
+func (l *taskList) forgetTasks(ids []uint64) {
+	for _, tasks := range [][]models.Task{l.Tasks, l.Trash, l.Archive} {
+		for i := range tasks {
+			tasks[i].BlockedBy = slices.DeleteFunc(tasks[i].BlockedBy, func(id uint64) bool {
+				return slices.Contains(ids, id)
+			})
+
+			if slices.Contains(ids, tasks[i].ParentID) {
+				tasks[i].ParentID = 0
+			}
+		}
+	}
+
+	l.Comments = slices.DeleteFunc(l.Comments, func(comment models.Comment) bool {
+		return slices.Contains(ids, comment.TaskID)
+	})
+	l.Activity = slices.DeleteFunc(l.Activity, func(activity models.Activity) bool {
+		return slices.Contains(ids, activity.TaskID)
+	})
+	l.Attachments = slices.DeleteFunc(l.Attachments, func(attachment models.Attachment) bool {
+		return slices.Contains(ids, attachment.TaskID)
+	})
+	l.TimeEntries = slices.DeleteFunc(l.TimeEntries, func(entry models.TimeEntry) bool {
+		return slices.Contains(ids, entry.TaskID)
+	})
+	l.Deliveries = slices.DeleteFunc(l.Deliveries, func(delivery models.Delivery) bool {
+		return slices.Contains(ids, delivery.TaskID)
+	})
+}
+
+// Remove the notifications about the permanently deleted tasks with `ids` from every user, since users are notified
+// about tasks in lists that are shared with them as well as their own.
+func (t taskLists) forgetNotifications(ids []uint64) {
+	for i := range t {
+		t[i].Notifications = slices.DeleteFunc(t[i].Notifications, func(notification models.Notification) bool {
+			return slices.Contains(ids, notification.TaskID)
+		})
+	}
+}
+
+// Get the number of levels from the top-level task down to the task with `id`, where a top-level task has depth 1.
+func (l *taskList) depth(id uint64) int {
+	depth := 0
+
+	for task := l.findTask(id); task != nil; task = l.parent(task) {
+		depth++
+	}
+
+	return depth
+}
+
+// Get the number of levels in the tree of subtasks rooted at the task with `id`, where a task without subtasks has
+// height 1.
+func (l *taskList) height(id uint64) int {
+	height := 0
+
+	for _, task := range l.Tasks {
+		if task.ParentID == id {
+			height = max(height, l.height(task.ID))
+		}
+	}
+
+	return height + 1
+}
+
+// Mark every task above the task with `id` as not done.
+func (l *taskList) reopenAncestors(id uint64, now time.Time) {
+	for parent := l.parent(l.findTask(id)); parent != nil; parent = l.parent(parent) {
+		l.reopen(parent, now)
+	}
+}
+
+// Mark `task` as done at `now`, keeping the original completion time if it is already done.
+func (l *taskList) complete(task *models.Task, now time.Time) {
+	if !task.Done {
+		task.Done = true
+		task.CompletedAt = &now
+		l.record(models.Activity{TaskID: task.ID, Kind: models.ActivityCompleted, At: now})
+		l.trigger(models.WebhookTaskCompleted, *task, now)
+	}
+}
+
+// Mark `task` as not done.
+func (l *taskList) reopen(task *models.Task, now time.Time) {
+	if task.Done {
+		task.Done = false
+		task.CompletedAt = nil
+		l.record(models.Activity{TaskID: task.ID, Kind: models.ActivityReopened, At: now})
+		l.trigger(models.WebhookTaskUpdated, *task, now)
+	}
+}
+
+// Get the columns of the list's board, which are the default columns if the board has not been set up.
+func (l *taskList) columns() []models.Column {
+	if l.Columns == nil {
+		return slices.Clone(models.DefaultColumns)
+	}
+
+	return slices.Clone(l.Columns)
+}
+
+// Add `activity` to the history of changes to the list's tasks.
+func (l *taskList) record(activity models.Activity) {
+	l.Activity = append(l.Activity, activity)
+}
+
+// Queue a delivery of `event`, which happened to `task` at `now`, to each of the list's webhooks that subscribes to it.
+func (l *taskList) trigger(event models.WebhookEvent, task models.Task, now time.Time) {
+	var payload []byte
+
+	for _, webhook := range l.Webhooks {
+		if !slices.Contains(webhook.Events, event) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(models.WebhookPayload{Event: event, List: l.User, Task: task, OccurredAt: now})
+
+			// Tasks in the store can always be encoded, otherwise the store could not be saved either.
+			if err != nil {
+				return
+			}
+		}
+
+		// Lists saved before the last delivery ID was kept only have the IDs in the log.
+		for _, delivery := range l.Deliveries {
+			l.LastDeliveryID = max(l.LastDeliveryID, delivery.ID)
+		}
+
+		l.LastDeliveryID++
+		l.Deliveries = append(l.Deliveries, models.Delivery{
+			ID:            l.LastDeliveryID,
+			WebhookID:     webhook.ID,
+			List:          l.User,
+			Event:         event,
+			TaskID:        task.ID,
+			URL:           webhook.URL,
+			Payload:       payload,
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+	}
+}
+
+// Get the parent of `task`, or `nil` if it is a top-level task.
+func (l *taskList) parent(task *models.Task) *models.Task {
+	if task.ParentID == 0 {
+		return nil
+	}
+
+	return l.findTask(task.ParentID)
+}
+
+// Use this function when setting the ID of a new task in `list` to ensure that the ID is auto-incremented and unique,
+// even after tasks have been purged.
+func (t taskLists) nextID(list *taskList) (id uint64) {
 	id = 0
 
 	for _, taskList := range t {
-		for _, task := range taskList.Tasks {
-			id = max(id, task.ID)
+		// Lists saved before the last task ID was kept only have the IDs of their tasks.
+		id = max(id, taskList.LastTaskID)
+
+		for _, tasks := range [][]models.Task{taskList.Tasks, taskList.Trash, taskList.Archive} {
+			for _, task := range tasks {
+				id = max(id, task.ID)
+			}
 		}
 	}
 
-	return id + 1
+	list.LastTaskID = id + 1
+
+	return list.LastTaskID
 }
@@ -1,10 +1,16 @@
 package stores_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/AnthonyDickson/yatta/events"
 	"github.com/AnthonyDickson/yatta/models"
 	"github.com/AnthonyDickson/yatta/stores"
 	"github.com/AnthonyDickson/yatta/yattatest"
@@ -91,6 +97,38 @@ func TestFileTaskStore(t *testing.T) {
 		assertTasks(t, store, "Alice", []models.Task{{ID: 1, Description: "find the keys"}})
 	})
 
+	t.Run("add task with details", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		priority := 3
+		due := time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC)
+		recurrence := models.Recurrence{Frequency: models.Monthly, Interval: 1}
+
+		id, err := store.AddTaskWithDetails("Alice", "pay rent", models.TaskUpdate{
+			Tags:       []string{"#home", "home"},
+			Priority:   &priority,
+			Due:        &due,
+			Recurrence: &recurrence,
+		})
+
+		yattatest.AssertNoError(t, err)
+
+		if id != 1 {
+			t.Errorf("got ID %d for the new task, want 1", id)
+		}
+
+		assertTasks(t, store, "Alice", []models.Task{{
+			ID:          1,
+			Description: "pay rent",
+			Tags:        []string{"home"},
+			Priority:    3,
+			Due:         &due,
+			Recurrence:  &recurrence,
+		}})
+	})
+
 	t.Run("adding multiple tasks increments ID", func(t *testing.T) {
 		database, cleanup := yattatest.CreateTempFile(t, `[]`)
 		defer cleanup()
@@ -139,7 +177,7 @@ func assertGetTask(t *testing.T, store *stores.FileTaskStore, id uint64, want mo
 		t.Fatalf("got nil for task when calling GetTask, want %v", want)
 	}
 
-	if *got != want {
+	if !reflect.DeepEqual(*got, want) {
 		t.Errorf("got task %v want %v", *got, want)
 	}
 }
@@ -154,6 +192,2094 @@ func assertTasks(t *testing.T, store *stores.FileTaskStore, user string, want []
 	}
 
 	if !reflect.DeepEqual(got, want) {
-		t.Errorf("got tasks %q, want %q", got, want)
+		t.Errorf("got tasks %v, want %v", got, want)
+	}
+}
+
+func TestFileTaskStore_Subtasks(t *testing.T) {
+	// Alice has the tree 1 -> 2 -> 3 and the task 4, Bob has the task 5.
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "prepare release"},
+            {"ID": 2, "Description": "bump version", "ParentID": 1},
+            {"ID": 3, "Description": "update go.mod", "ParentID": 2},
+            {"ID": 4, "Description": "water plants"}
+          ]
+        },
+        {
+          "user": "Bob",
+          "tasks": [
+            {"ID": 5, "Description": "review release"}
+          ]
+        }
+      ]`
+
+	t.Run("get subtasks includes nested subtasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		got, err := store.GetSubtasks(1)
+		yattatest.AssertNoError(t, err)
+
+		want := []models.Task{
+			{ID: 2, Description: "bump version", ParentID: 1},
+			{ID: 3, Description: "update go.mod", ParentID: 2},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got subtasks %v, want %v", got, want)
+		}
+	})
+
+	t.Run("get subtasks of missing task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.GetSubtasks(42)
+		assertError(t, err, stores.ErrTaskNotFound)
+	})
+
+	t.Run("add subtask to the parent's list", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		id, err := store.AddSubtask(4, "fill watering can")
+		yattatest.AssertNoError(t, err)
+
+		if id != 6 {
+			t.Errorf("got ID %d for the new subtask, want 6", id)
+		}
+
+		assertGetTask(t, store, 6, models.Task{ID: 6, Description: "fill watering can", ParentID: 4})
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertGetTask(t, reloaded, 6, models.Task{ID: 6, Description: "fill watering can", ParentID: 4})
+	})
+
+	t.Run("adding a subtask reopens finished parents", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetDone(1, true))
+		mustAddSubtask(t, store, 2, "tag commit")
+
+		assertDone(t, store, map[uint64]bool{1: false, 2: false, 3: true, 6: false})
+	})
+
+	t.Run("cannot add subtask beyond the maximum depth", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.AddTask("Alice", "level 1"))
+
+		for id := uint64(1); id < stores.MaxTaskDepth; id++ {
+			mustAddSubtask(t, store, id, fmt.Sprintf("level %d", id+1))
+		}
+
+		_, err := store.AddSubtask(stores.MaxTaskDepth, "one level too many")
+		assertError(t, err, stores.ErrMaxDepthExceeded)
+	})
+
+	t.Run("move task under another task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.SetParent(4, 1)
+		yattatest.AssertNoError(t, err)
+
+		assertGetTask(t, store, 4, models.Task{ID: 4, Description: "water plants", ParentID: 1})
+	})
+
+	t.Run("move task to the top level", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.SetParent(3, 0)
+		yattatest.AssertNoError(t, err)
+
+		assertGetTask(t, store, 3, models.Task{ID: 3, Description: "update go.mod"})
+	})
+
+	t.Run("reject moves that create cycles", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.SetParent(1, 1), stores.ErrTaskCycle)
+		assertError(t, store.SetParent(1, 3), stores.ErrTaskCycle)
+		assertGetTask(t, store, 1, models.Task{ID: 1, Description: "prepare release"})
+	})
+
+	t.Run("reject moves between lists", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.SetParent(5, 1), stores.ErrDifferentList)
+	})
+
+	t.Run("reject moves beyond the maximum depth", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		// Create two chains of tasks that together are one level too deep.
+		yattatest.AssertNoError(t, store.AddTask("Alice", "first chain"))
+		firstChainLength := uint64(stores.MaxTaskDepth / 2)
+
+		for id := uint64(1); id < firstChainLength; id++ {
+			mustAddSubtask(t, store, id, "first chain")
+		}
+
+		yattatest.AssertNoError(t, store.AddTask("Alice", "second chain"))
+		secondChainRoot := firstChainLength + 1
+
+		for id := secondChainRoot; id < secondChainRoot+stores.MaxTaskDepth-firstChainLength; id++ {
+			mustAddSubtask(t, store, id, "second chain")
+		}
+
+		err := store.SetParent(secondChainRoot, firstChainLength)
+		assertError(t, err, stores.ErrMaxDepthExceeded)
+	})
+
+	t.Run("completing a task completes its subtasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.SetDone(1, true)
+		yattatest.AssertNoError(t, err)
+
+		assertDone(t, store, map[uint64]bool{1: true, 2: true, 3: true, 4: false})
+	})
+
+	t.Run("reopening a subtask reopens its parents", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetDone(1, true))
+		yattatest.AssertNoError(t, store.SetDone(3, false))
+
+		assertDone(t, store, map[uint64]bool{1: false, 2: false, 3: false})
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertDone(t, reloaded, map[uint64]bool{1: false, 2: false, 3: false})
+	})
+
+	t.Run("set done on missing task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.SetDone(42, true), stores.ErrTaskNotFound)
+	})
+
+	t.Run("reject database with a cycle", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "chicken", "ParentID": 2},
+            {"ID": 2, "Description": "egg", "ParentID": 1}
+          ]
+        }
+      ]`)
+		defer cleanup()
+
+		_, err := stores.NewFileTaskStore(database)
+
+		if err == nil {
+			t.Error("got nil error, want error for cyclic subtasks")
+		}
+	})
+}
+
+func assertDone(t *testing.T, store *stores.FileTaskStore, want map[uint64]bool) {
+	t.Helper()
+
+	for id, wantDone := range want {
+		task, err := store.GetTask(id)
+		yattatest.AssertNoError(t, err)
+
+		if task == nil {
+			t.Fatalf("got nil for task %d, want task", id)
+		}
+
+		if task.Done != wantDone {
+			t.Errorf("got done %t for task %d, want %t", task.Done, id, wantDone)
+		}
+	}
+}
+
+func mustAddSubtask(t *testing.T, store *stores.FileTaskStore, parentID uint64, description string) {
+	t.Helper()
+
+	if _, err := store.AddSubtask(parentID, description); err != nil {
+		t.Fatalf("could not add subtask %q to task %d: %v", description, parentID, err)
+	}
+}
+
+func assertError(t *testing.T, got error, want error) {
+	t.Helper()
+
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+}
+
+func TestFileTaskStore_Dependencies(t *testing.T) {
+	// Alice has to get approval (1) before she can deploy (2), and has an unrelated task (3).
+	// Bob has a single task (4).
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "get approval"},
+            {"ID": 2, "Description": "deploy", "BlockedBy": [1]},
+            {"ID": 3, "Description": "water plants"}
+          ]
+        },
+        {
+          "user": "Bob",
+          "tasks": [
+            {"ID": 4, "Description": "approve deployment"}
+          ]
+        }
+      ]`
+
+	t.Run("tasks with open blockers are blocked", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertTasks(t, store, "Alice", []models.Task{
+			{ID: 1, Description: "get approval"},
+			{ID: 2, Description: "deploy", BlockedBy: []uint64{1}, Blocked: true},
+			{ID: 3, Description: "water plants"},
+		})
+	})
+
+	t.Run("tasks are unblocked when their blockers are done", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetDone(1, true))
+
+		assertGetTask(t, store, 2, models.Task{ID: 2, Description: "deploy", BlockedBy: []uint64{1}})
+	})
+
+	t.Run("add dependency", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.AddDependency(3, 2)
+		yattatest.AssertNoError(t, err)
+
+		want := models.Task{ID: 3, Description: "water plants", BlockedBy: []uint64{2}, Blocked: true}
+		assertGetTask(t, store, 3, want)
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertGetTask(t, reloaded, 3, want)
+	})
+
+	t.Run("adding an existing dependency does nothing", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.AddDependency(2, 1)
+		yattatest.AssertNoError(t, err)
+
+		assertGetTask(t, store, 2, models.Task{ID: 2, Description: "deploy", BlockedBy: []uint64{1}, Blocked: true})
+	})
+
+	t.Run("reject dependency cycles", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.AddDependency(1, 1), stores.ErrDependencyCycle)
+		assertError(t, store.AddDependency(1, 2), stores.ErrDependencyCycle)
+
+		yattatest.AssertNoError(t, store.AddDependency(3, 2))
+		assertError(t, store.AddDependency(1, 3), stores.ErrDependencyCycle)
+	})
+
+	t.Run("reject dependencies between lists", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.AddDependency(2, 4), stores.ErrDifferentList)
+	})
+
+	t.Run("reject dependencies on missing tasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.AddDependency(2, 42), stores.ErrTaskNotFound)
+		assertError(t, store.AddDependency(42, 2), stores.ErrTaskNotFound)
+	})
+
+	t.Run("remove dependency", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.RemoveDependency(2, 1)
+		yattatest.AssertNoError(t, err)
+
+		assertGetTask(t, store, 2, models.Task{ID: 2, Description: "deploy", BlockedBy: []uint64{}})
+	})
+
+	t.Run("ready tasks exclude blocked and done tasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetDone(3, true))
+
+		got, err := store.GetReadyTasks("Alice")
+		yattatest.AssertNoError(t, err)
+
+		want := []models.Task{{ID: 1, Description: "get approval"}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got ready tasks %v, want %v", got, want)
+		}
+	})
+
+	t.Run("snoozed tasks are left out until they show up again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		tomorrow := time.Now().Add(24 * time.Hour)
+		yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{HiddenUntil: &tomorrow}))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertTaskIDs(t, reloaded.GetReadyTasks, "Alice", []uint64{3})
+		// The blocked task is still shown even though its blocker is snoozed.
+		assertTaskIDs(t, reloaded.GetNextActions, "Alice", []uint64{2, 3})
+
+		yesterday := time.Now().Add(-24 * time.Hour)
+		yattatest.AssertNoError(t, reloaded.UpdateTask(1, models.TaskUpdate{HiddenUntil: &yesterday}))
+		assertTaskIDs(t, reloaded.GetReadyTasks, "Alice", []uint64{1, 3})
+
+		yattatest.AssertNoError(t, reloaded.UpdateTask(1, models.TaskUpdate{HiddenUntil: &tomorrow}))
+		yattatest.AssertNoError(t, reloaded.UpdateTask(1, models.TaskUpdate{ClearHiddenUntil: true}))
+		assertGetTask(t, reloaded, 1, models.Task{ID: 1, Description: "get approval"})
+	})
+
+	t.Run("next actions come after their blockers", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "deploy", "BlockedBy": [3]},
+            {"ID": 2, "Description": "water plants"},
+            {"ID": 3, "Description": "get approval", "BlockedBy": [4]},
+            {"ID": 4, "Description": "write proposal"},
+            {"ID": 5, "Description": "celebrate", "BlockedBy": [1, 2]},
+            {"ID": 6, "Description": "old news", "Done": true}
+          ]
+        }
+      ]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		got, err := store.GetNextActions("Alice")
+		yattatest.AssertNoError(t, err)
+
+		var gotIDs []uint64
+
+		for _, task := range got {
+			gotIDs = append(gotIDs, task.ID)
+		}
+
+		wantIDs := []uint64{2, 4, 3, 1, 5}
+
+		if !reflect.DeepEqual(gotIDs, wantIDs) {
+			t.Errorf("got next actions %v, want %v", gotIDs, wantIDs)
+		}
+	})
+
+	t.Run("reject database with a dependency cycle", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "chicken", "BlockedBy": [2]},
+            {"ID": 2, "Description": "egg", "BlockedBy": [1]}
+          ]
+        }
+      ]`)
+		defer cleanup()
+
+		_, err := stores.NewFileTaskStore(database)
+
+		if err == nil {
+			t.Error("got nil error, want error for cyclic dependencies")
+		}
+	})
+}
+
+func TestFileTaskStore_Search(t *testing.T) {
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "Deploy the website"},
+            {"ID": 2, "Description": "Water the plants"}
+          ]
+        },
+        {
+          "user": "Bob",
+          "tasks": [
+            {"ID": 3, "Description": "Review the deployment"}
+          ]
+        }
+      ]`
+
+	t.Run("search tasks loaded from the database", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertSearchResults(t, store, "Alice", "deploy", []uint64{1})
+		assertSearchResults(t, store, "Bob", "deploy", []uint64{3})
+		assertSearchResults(t, store, "", "deploy", []uint64{1, 3})
+	})
+
+	t.Run("search new tasks and subtasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.AddTask("Alice", "Buy plant food"))
+		mustAddSubtask(t, store, 4, "Find a garden centre")
+
+		assertSearchResults(t, store, "Alice", "plant", []uint64{2, 4})
+		assertSearchResults(t, store, "Alice", "garden", []uint64{5})
+	})
+}
+
+func assertSearchResults(t *testing.T, store *stores.FileTaskStore, user string, query string, want []uint64) {
+	t.Helper()
+
+	results, err := store.SearchTasks(user, query)
+	yattatest.AssertNoError(t, err)
+
+	var got []uint64
+
+	for _, task := range results {
+		got = append(got, task.ID)
+	}
+
+	slices.Sort(got)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %v for %q, want %v", got, query, want)
+	}
+}
+
+func TestFileTaskStore_UpdateTask(t *testing.T) {
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "deploy", "Tags": ["work"], "Priority": 1}
+          ]
+        }
+      ]`
+
+	t.Run("update details", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		description := "deploy the website"
+		notes := "- [ ] run `make release`"
+		priority := 3
+		due := time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC)
+		estimate := 2.5
+		recurrence := models.Recurrence{Frequency: models.Weekly, Interval: 2}
+
+		err := store.UpdateTask(1, models.TaskUpdate{
+			Description: &description,
+			Notes:       &notes,
+			Tags:        []string{"#work", " urgent ", "work", ""},
+			Priority:    &priority,
+			Due:         &due,
+			Estimate:    &estimate,
+			Recurrence:  &recurrence,
+		})
+		yattatest.AssertNoError(t, err)
+
+		want := models.Task{ID: 1, Description: description, Notes: notes, Tags: []string{"work", "urgent"}, Priority: 3, Due: &due, Estimate: 2.5, Recurrence: &recurrence}
+		assertGetTask(t, store, 1, want)
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertGetTask(t, reloaded, 1, want)
+		assertSearchResults(t, reloaded, "Alice", "website", []uint64{1})
+	})
+
+	t.Run("only change the given details", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		due := time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC)
+		yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Due: &due}))
+
+		assertGetTask(t, store, 1, models.Task{ID: 1, Description: "deploy", Tags: []string{"work"}, Priority: 1, Due: &due})
+
+		recurrence := models.Recurrence{Frequency: models.Daily, Interval: 1}
+		yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Recurrence: &recurrence}))
+
+		assertGetTask(t, store, 1, models.Task{ID: 1, Description: "deploy", Tags: []string{"work"}, Priority: 1, Due: &due, Recurrence: &recurrence})
+
+		yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Tags: []string{}, ClearDue: true, ClearRecurrence: true}))
+
+		assertGetTask(t, store, 1, models.Task{ID: 1, Description: "deploy", Priority: 1})
+	})
+
+	t.Run("update missing task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.UpdateTask(42, models.TaskUpdate{}), stores.ErrTaskNotFound)
+	})
+}
+
+func TestFileTaskStore_SavedSearches(t *testing.T) {
+	t.Run("add, get and delete saved searches", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[{"user": "Alice", "tasks": []}]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.AddSavedSearch("Alice", "Urgent work", "tag:work priority>=2"))
+		yattatest.AssertNoError(t, store.AddSavedSearch("Alice", "Overdue", "due<today -done"))
+		yattatest.AssertNoError(t, store.AddSavedSearch("Bob", "Everything", ""))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertSavedSearches(t, reloaded, "Alice", []models.SavedSearch{
+			{ID: 1, Name: "Urgent work", Query: "tag:work priority>=2"},
+			{ID: 2, Name: "Overdue", Query: "due<today -done"},
+		})
+		assertSavedSearches(t, reloaded, "Bob", []models.SavedSearch{{ID: 1, Name: "Everything", Query: ""}})
+
+		yattatest.AssertNoError(t, reloaded.DeleteSavedSearch("Alice", 1))
+		assertSavedSearches(t, reloaded, "Alice", []models.SavedSearch{{ID: 2, Name: "Overdue", Query: "due<today -done"}})
+	})
+
+	t.Run("delete missing saved search", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.DeleteSavedSearch("Alice", 1), stores.ErrSavedSearchNotFound)
+	})
+}
+
+func assertSavedSearches(t *testing.T, store *stores.FileTaskStore, user string, want []models.SavedSearch) {
+	t.Helper()
+
+	got, err := store.GetSavedSearches(user)
+	yattatest.AssertNoError(t, err)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got saved searches %v for %q, want %v", got, user, want)
+	}
+}
+
+func TestFileTaskStore_Trash(t *testing.T) {
+	// Alice has the tree 1 -> 2 -> 3 and the task 4, which is blocked by 3.
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "prepare release"},
+            {"ID": 2, "Description": "bump version", "ParentID": 1},
+            {"ID": 3, "Description": "update go.mod", "ParentID": 2},
+            {"ID": 4, "Description": "announce release", "BlockedBy": [3]}
+          ]
+        }
+      ]`
+
+	t.Run("delete moves the task and its subtasks to the trash", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.DeleteTask(2))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertTaskIDs(t, reloaded.GetTasks, "Alice", []uint64{1, 4})
+		assertTaskIDs(t, reloaded.GetTrash, "Alice", []uint64{2, 3})
+		assertSearchResults(t, reloaded, "Alice", "version", nil)
+
+		task, err := reloaded.GetTask(2)
+		yattatest.AssertNoError(t, err)
+
+		if task != nil {
+			t.Errorf("got task %v from GetTask, want nil for a task in the trash", *task)
+		}
+
+		trash, err := reloaded.GetTrash("Alice")
+		yattatest.AssertNoError(t, err)
+
+		for _, task := range trash {
+			if task.DeletedAt == nil {
+				t.Errorf("task %d in the trash has no deletion time", task.ID)
+			}
+		}
+
+		// Tasks cannot be blocked by tasks in the trash.
+		assertTaskIDs(t, reloaded.GetReadyTasks, "Alice", []uint64{1, 4})
+		assertError(t, reloaded.DeleteTask(2), stores.ErrTaskNotFound)
+		assertError(t, reloaded.SetDone(3, true), stores.ErrTaskNotFound)
+	})
+
+	t.Run("restore the task and its subtasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.DeleteTask(2))
+		yattatest.AssertNoError(t, store.RestoreTask(2))
+
+		assertTaskIDs(t, store.GetTasks, "Alice", []uint64{1, 4, 2, 3})
+		assertTaskIDs(t, store.GetTrash, "Alice", []uint64{})
+		assertGetTask(t, store, 2, models.Task{ID: 2, Description: "bump version", ParentID: 1})
+		assertSearchResults(t, store, "Alice", "version", []uint64{2})
+		assertTaskIDs(t, store.GetReadyTasks, "Alice", []uint64{1, 2, 3})
+	})
+
+	t.Run("restored subtask becomes a top-level task if its parent is in the trash", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+		yattatest.AssertNoError(t, store.RestoreTask(3))
+
+		assertTaskIDs(t, store.GetTasks, "Alice", []uint64{4, 3})
+		assertTaskIDs(t, store.GetTrash, "Alice", []uint64{1, 2})
+		assertGetTask(t, store, 3, models.Task{ID: 3, Description: "update go.mod"})
+	})
+
+	t.Run("permanently delete a task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.DeleteTask(2))
+		assertError(t, store.PurgeTask(4), stores.ErrTaskNotFound)
+		yattatest.AssertNoError(t, store.PurgeTask(2))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertTaskIDs(t, reloaded.GetTrash, "Alice", []uint64{})
+		assertError(t, reloaded.RestoreTask(2), stores.ErrTaskNotFound)
+		// The dependency on the deleted task is forgotten so that it cannot refer to a new task with the same ID.
+		assertGetTask(t, reloaded, 4, models.Task{ID: 4, Description: "announce release"})
+	})
+
+	t.Run("IDs of purged tasks are not used again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		id, err := store.AddTaskWithDetails("Alice", "tag release", models.TaskUpdate{})
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteTask(id))
+		yattatest.AssertNoError(t, store.PurgeTask(id))
+
+		// Task IDs are unique across lists, so the ID is not used in other lists either.
+		reloaded := mustCreateFileTaskStore(t, database)
+		yattatest.AssertNoError(t, reloaded.AddTask("Bob", "publish notes"))
+		assertTaskIDs(t, reloaded.GetTasks, "Bob", []uint64{6})
+	})
+
+	t.Run("notifications and webhook deliveries are forgotten with their task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}]}]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		webhook := models.Webhook{URL: "https://example.com/hooks", Secret: "s3cret", Events: []models.WebhookEvent{models.WebhookTaskCompleted}}
+		_, err := store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.SetDone(1, true))
+		yattatest.AssertNoError(t, store.AddNotification("bob@example.com", models.Notification{TaskID: 1, Kind: models.NotificationAssigned, Task: "deploy"}))
+
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+		yattatest.AssertNoError(t, store.PurgeTask(1))
+
+		// Lists saved before the last task ID was kept can hand out the ID of a purged task again.
+		id, err := store.AddTaskWithDetails("alice@example.com", "reuses the ID", models.TaskUpdate{})
+		yattatest.AssertNoError(t, err)
+
+		if id != 1 {
+			t.Fatalf("got task ID %d, want the purged ID 1 to be reused", id)
+		}
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		notifications, err := reloaded.GetNotifications("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(notifications) != 0 {
+			t.Errorf("got notifications %+v, want the notification about the purged task to be forgotten", notifications)
+		}
+
+		deliveries, err := reloaded.GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(deliveries) != 0 {
+			t.Errorf("got deliveries %+v, want the delivery about the purged task to be forgotten", deliveries)
+		}
+	})
+
+	t.Run("purge tasks that have been in the trash too long", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[
+          {
+            "user": "Alice",
+            "tasks": [{"ID": 3, "Description": "water plants"}],
+            "trash": [
+              {"ID": 1, "Description": "old", "DeletedAt": "2025-01-01T00:00:00Z"},
+              {"ID": 2, "Description": "new", "DeletedAt": "2025-03-01T00:00:00Z"}
+            ]
+          }
+        ]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.PurgeTrash(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertTaskIDs(t, reloaded.GetTrash, "Alice", []uint64{2})
+
+		// IDs of tasks in the trash are not reused.
+		yattatest.AssertNoError(t, reloaded.AddTask("Alice", "feed cat"))
+		assertTaskIDs(t, reloaded.GetTasks, "Alice", []uint64{3, 4})
+	})
+}
+
+func TestFileTaskStore_Archive(t *testing.T) {
+	// Alice has the completed tree 1 -> 2, the open task 3 with the completed subtask 4, and the completed task 5.
+	const initialData = `[
+        {
+          "user": "Alice",
+          "tasks": [
+            {"ID": 1, "Description": "prepare release", "Done": true, "CompletedAt": "2025-01-01T00:00:00Z"},
+            {"ID": 2, "Description": "bump version", "ParentID": 1, "Done": true, "CompletedAt": "2025-01-01T00:00:00Z"},
+            {"ID": 3, "Description": "plan party"},
+            {"ID": 4, "Description": "book venue", "ParentID": 3, "Done": true, "CompletedAt": "2025-01-01T00:00:00Z"},
+            {"ID": 5, "Description": "water plants", "Done": true, "CompletedAt": "2025-03-01T00:00:00Z"}
+          ]
+        }
+      ]`
+
+	t.Run("archive top-level tasks completed before the cutoff", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.ArchiveCompletedTasks(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertTaskIDs(t, reloaded.GetTasks, "Alice", []uint64{3, 4, 5})
+		assertTaskIDs(t, reloaded.GetArchivedTasks, "Alice", []uint64{1, 2})
+		assertSearchResults(t, reloaded, "Alice", "release", nil)
+
+		// Archived tasks can still be viewed.
+		task, err := reloaded.GetTask(1)
+		yattatest.AssertNoError(t, err)
+
+		if task == nil || task.Description != "prepare release" {
+			t.Errorf("got task %v from GetTask, want the archived task 1", task)
+		}
+
+		subtasks, err := reloaded.GetSubtasks(1)
+		yattatest.AssertNoError(t, err)
+
+		if len(subtasks) != 1 || subtasks[0].ID != 2 {
+			t.Errorf("got subtasks %v of the archived task 1, want task 2", subtasks)
+		}
+	})
+
+	t.Run("record when tasks are completed", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, `[{"user": "Alice", "tasks": [
+          {"ID": 1, "Description": "prepare release"},
+          {"ID": 2, "Description": "bump version", "ParentID": 1}
+        ]}]`)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		before := time.Now()
+		yattatest.AssertNoError(t, store.SetDone(1, true))
+
+		for _, id := range []uint64{1, 2} {
+			task, err := store.GetTask(id)
+			yattatest.AssertNoError(t, err)
+
+			if task.CompletedAt == nil || task.CompletedAt.Before(before) {
+				t.Errorf("got completion time %v for task %d, want a time after %v", task.CompletedAt, id, before)
+			}
+		}
+
+		yattatest.AssertNoError(t, store.SetDone(2, false))
+
+		for _, id := range []uint64{1, 2} {
+			task, err := store.GetTask(id)
+			yattatest.AssertNoError(t, err)
+
+			if task.CompletedAt != nil {
+				t.Errorf("got completion time %v for reopened task %d, want nil", *task.CompletedAt, id)
+			}
+		}
+	})
+}
+
+func assertTaskIDs(t *testing.T, getTasks func(user string) ([]models.Task, error), user string, want []uint64) {
+	t.Helper()
+
+	tasks, err := getTasks(user)
+	yattatest.AssertNoError(t, err)
+
+	got := []uint64{}
+
+	for _, task := range tasks {
+		got = append(got, task.ID)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got task IDs %v for %q, want %v", got, user, want)
+	}
+}
+
+func TestFileTaskStore_Comments(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}, {"ID": 2, "Description": "test"}]}]`
+
+	t.Run("add, edit and delete comments", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		first, err := store.AddComment(1, "alice@example.com", "Needs a **staging** run first")
+		yattatest.AssertNoError(t, err)
+		_, err = store.AddComment(2, "bob@example.com", "Flaky on CI")
+		yattatest.AssertNoError(t, err)
+		second, err := store.AddComment(1, "bob@example.com", "Done, looks good")
+		yattatest.AssertNoError(t, err)
+
+		if first.ID != 1 || second.ID != 3 || first.CreatedAt.IsZero() {
+			t.Errorf("got comments %v and %v, want IDs 1 and 3 with creation times", *first, *second)
+		}
+
+		edited, err := store.UpdateComment(first.ID, "alice@example.com", "Needs a staging run")
+		yattatest.AssertNoError(t, err)
+
+		if edited.Body != "Needs a staging run" || edited.EditedAt == nil {
+			t.Errorf("got edited comment %v, want new body and edit time", *edited)
+		}
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertCommentBodies(t, reloaded, 1, []string{"Needs a staging run", "Done, looks good"})
+
+		if got, err := reloaded.GetComment(first.ID); err != nil || got == nil || got.TaskID != 1 || got.Body != "Needs a staging run" {
+			t.Errorf("got comment %v and error %v, want the edited comment on task 1", got, err)
+		}
+
+		yattatest.AssertNoError(t, reloaded.DeleteComment(second.ID, "bob@example.com"))
+		assertCommentBodies(t, reloaded, 1, []string{"Needs a staging run"})
+		assertCommentBodies(t, reloaded, 2, []string{"Flaky on CI"})
+	})
+
+	t.Run("IDs of deleted comments are not used again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		comment, err := store.AddComment(1, "alice@example.com", "Flaky on CI")
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteComment(comment.ID, "alice@example.com"))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		added, err := reloaded.AddComment(1, "alice@example.com", "Fixed on CI")
+		yattatest.AssertNoError(t, err)
+
+		if added.ID != 2 {
+			t.Errorf("got comment ID %d, want 2", added.ID)
+		}
+	})
+
+	t.Run("only the author can change a comment", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		comment, err := store.AddComment(1, "alice@example.com", "mine")
+		yattatest.AssertNoError(t, err)
+
+		_, err = store.UpdateComment(comment.ID, "bob@example.com", "not mine")
+		assertError(t, err, stores.ErrNotCommentAuthor)
+		assertError(t, store.DeleteComment(comment.ID, "bob@example.com"), stores.ErrNotCommentAuthor)
+		assertCommentBodies(t, store, 1, []string{"mine"})
+	})
+
+	t.Run("missing tasks and comments", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddComment(42, "alice@example.com", "hello")
+		assertError(t, err, stores.ErrTaskNotFound)
+		_, err = store.GetComments(42)
+		assertError(t, err, stores.ErrTaskNotFound)
+		_, err = store.UpdateComment(42, "alice@example.com", "hello")
+		assertError(t, err, stores.ErrCommentNotFound)
+		assertError(t, store.DeleteComment(42, "alice@example.com"), stores.ErrCommentNotFound)
+	})
+
+	t.Run("comments are permanently deleted with their task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddComment(1, "alice@example.com", "hello")
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+
+		_, err = store.GetComments(1)
+		assertError(t, err, stores.ErrTaskNotFound)
+
+		yattatest.AssertNoError(t, store.RestoreTask(1))
+		assertCommentBodies(t, store, 1, []string{"hello"})
+
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+		yattatest.AssertNoError(t, store.PurgeTask(1))
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "reuses the ID"))
+		assertCommentBodies(t, store, 3, []string{})
+	})
+}
+
+func assertCommentBodies(t *testing.T, store *stores.FileTaskStore, taskID uint64, want []string) {
+	t.Helper()
+
+	comments, err := store.GetComments(taskID)
+	yattatest.AssertNoError(t, err)
+
+	got := []string{}
+
+	for _, comment := range comments {
+		got = append(got, comment.Body)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got comments %q on task %d, want %q", got, taskID, want)
+	}
+}
+
+func TestFileTaskStore_Activity(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileTaskStore(t, database)
+
+	yattatest.AssertNoError(t, store.AddTask("Alice", "deploy"))
+	mustAddSubtask(t, store, 1, "run tests")
+
+	description := "deploy the website"
+	yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Description: &description}))
+	// Setting the same description is not a change.
+	yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Description: &description}))
+	yattatest.AssertNoError(t, store.SetDone(1, true))
+	yattatest.AssertNoError(t, store.SetDone(2, false))
+	yattatest.AssertNoError(t, store.DeleteTask(1))
+	yattatest.AssertNoError(t, store.RestoreTask(1))
+
+	reloaded := mustCreateFileTaskStore(t, database)
+
+	assertActivity(t, reloaded, 1, []string{
+		"created",
+		`renamed from "deploy" to "deploy the website"`,
+		"completed",
+		"reopened",
+		"moved to the trash",
+		"restored from the trash",
+	})
+	assertActivity(t, reloaded, 2, []string{
+		"created",
+		"completed",
+		"reopened",
+		"moved to the trash",
+		"restored from the trash",
+	})
+}
+
+func TestFileTaskStore_CompletionHistory(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileTaskStore(t, database)
+
+	yattatest.AssertNoError(t, store.AddTask("Alice", "deploy"))
+	yattatest.AssertNoError(t, store.AddTask("Bob", "review"))
+
+	description := "deploy the website"
+	yattatest.AssertNoError(t, store.UpdateTask(1, models.TaskUpdate{Description: &description}))
+	yattatest.AssertNoError(t, store.SetDone(1, true))
+	yattatest.AssertNoError(t, store.SetDone(1, false))
+
+	history, err := store.GetCompletionHistory("Alice")
+	yattatest.AssertNoError(t, err)
+
+	var got []models.ActivityKind
+
+	for _, activity := range history {
+		got = append(got, activity.Kind)
+
+		if activity.TaskID != 1 {
+			t.Errorf("got activity %v on task %d, want only Alice's task 1", activity, activity.TaskID)
+		}
+	}
+
+	want := []models.ActivityKind{models.ActivityCreated, models.ActivityCompleted, models.ActivityReopened}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got completion history %q, want %q", got, want)
+	}
+
+	history, err = store.GetCompletionHistory("Carol")
+	yattatest.AssertNoError(t, err)
+
+	if history == nil || len(history) != 0 {
+		t.Errorf("got completion history %v for a user without tasks, want an empty slice", history)
+	}
+}
+
+func assertActivity(t *testing.T, store *stores.FileTaskStore, taskID uint64, want []string) {
+	t.Helper()
+
+	activity, err := store.GetActivity(taskID)
+	yattatest.AssertNoError(t, err)
+
+	got := []string{}
+
+	for _, change := range activity {
+		got = append(got, change.String())
+
+		if change.At.IsZero() {
+			t.Errorf("got activity %q on task %d without a time", change, taskID)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got activity %q on task %d, want %q", got, taskID, want)
+	}
+}
+
+func TestFileTaskStore_Attachments(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}, {"ID": 2, "Description": "test"}]}]`
+
+	t.Run("add, get and delete attachments", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		screenshot, err := store.AddAttachment(models.Attachment{TaskID: 1, Name: "screenshot.png", Key: "aaaa"})
+		yattatest.AssertNoError(t, err)
+		_, err = store.AddAttachment(models.Attachment{TaskID: 2, Name: "log.txt", Key: "bbbb"})
+		yattatest.AssertNoError(t, err)
+		// The same file attached twice is stored once.
+		copied, err := store.AddAttachment(models.Attachment{TaskID: 1, Name: "copy.png", Key: "aaaa"})
+		yattatest.AssertNoError(t, err)
+
+		if screenshot.ID != 1 || copied.ID != 3 {
+			t.Errorf("got attachment IDs %d and %d, want 1 and 3", screenshot.ID, copied.ID)
+		}
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		assertAttachmentNames(t, reloaded, 1, []string{"screenshot.png", "copy.png"})
+		assertAttachmentKeys(t, reloaded, []string{"aaaa", "bbbb"})
+
+		got, err := reloaded.GetAttachment(screenshot.ID)
+		yattatest.AssertNoError(t, err)
+
+		if got == nil || got.Name != "screenshot.png" {
+			t.Errorf("got attachment %v, want screenshot.png", got)
+		}
+
+		yattatest.AssertNoError(t, reloaded.DeleteAttachment(screenshot.ID))
+		assertError(t, reloaded.DeleteAttachment(screenshot.ID), stores.ErrAttachmentNotFound)
+		assertAttachmentNames(t, reloaded, 1, []string{"copy.png"})
+		assertAttachmentKeys(t, reloaded, []string{"aaaa", "bbbb"})
+	})
+
+	t.Run("IDs of deleted attachments are not used again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		attachment, err := store.AddAttachment(models.Attachment{TaskID: 1, Name: "screenshot.png", Key: "aaaa"})
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteAttachment(attachment.ID))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		added, err := reloaded.AddAttachment(models.Attachment{TaskID: 1, Name: "log.txt", Key: "bbbb"})
+		yattatest.AssertNoError(t, err)
+
+		if added.ID != 2 {
+			t.Errorf("got attachment ID %d, want 2", added.ID)
+		}
+	})
+
+	t.Run("missing tasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddAttachment(models.Attachment{TaskID: 42, Name: "screenshot.png", Key: "aaaa"})
+		assertError(t, err, stores.ErrTaskNotFound)
+		_, err = store.GetAttachments(42)
+		assertError(t, err, stores.ErrTaskNotFound)
+
+		attachment, err := store.GetAttachment(42)
+		yattatest.AssertNoError(t, err)
+
+		if attachment != nil {
+			t.Errorf("got attachment %v, want nil", *attachment)
+		}
+	})
+
+	t.Run("attachments of trashed tasks are hidden until purged", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		attachment, err := store.AddAttachment(models.Attachment{TaskID: 1, Name: "screenshot.png", Key: "aaaa"})
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+
+		got, err := store.GetAttachment(attachment.ID)
+		yattatest.AssertNoError(t, err)
+
+		if got != nil {
+			t.Errorf("got attachment %v of a trashed task, want nil", *got)
+		}
+
+		// Restoring the task must find the file's contents again.
+		assertAttachmentKeys(t, store, []string{"aaaa"})
+
+		yattatest.AssertNoError(t, store.PurgeTask(1))
+		assertAttachmentKeys(t, store, nil)
+	})
+}
+
+func assertAttachmentNames(t *testing.T, store *stores.FileTaskStore, taskID uint64, want []string) {
+	t.Helper()
+
+	attachments, err := store.GetAttachments(taskID)
+	yattatest.AssertNoError(t, err)
+
+	got := []string{}
+
+	for _, attachment := range attachments {
+		got = append(got, attachment.Name)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got attachments %q on task %d, want %q", got, taskID, want)
+	}
+}
+
+func assertAttachmentKeys(t *testing.T, store *stores.FileTaskStore, want []string) {
+	t.Helper()
+
+	got, err := store.GetAttachmentKeys()
+	yattatest.AssertNoError(t, err)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got attachment keys %q, want %q", got, want)
+	}
+}
+
+func TestFileTaskStore_TimeTracking(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}, {"ID": 2, "Description": "test"}]},
+		{"user": "bob@example.com", "tasks": [{"ID": 3, "Description": "review"}]}]`
+
+	t.Run("start and stop timers", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		started, err := store.StartTimer(1, "alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		// Each user can only have one timer running at a time, even on tasks in other lists.
+		_, err = store.StartTimer(3, "alice@example.com")
+		assertError(t, err, stores.ErrTimerRunning)
+		_, err = store.StartTimer(1, "bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		running, err := reloaded.GetRunningTimer("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if running == nil || running.ID != started.ID || running.TaskID != 1 {
+			t.Errorf("got running timer %v, want %v", running, *started)
+		}
+
+		stopped, err := reloaded.StopTimer("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if stopped.ID != started.ID || stopped.Running() {
+			t.Errorf("got stopped timer %v, want timer %d with an end time", *stopped, started.ID)
+		}
+
+		if got, err := reloaded.GetTimeEntry(started.ID); err != nil || got == nil || got.TaskID != 1 || got.Running() {
+			t.Errorf("got time entry %v and error %v, want the stopped timer on task 1", got, err)
+		}
+
+		if got, err := reloaded.GetTimeEntry(100); err != nil || got != nil {
+			t.Errorf("got time entry %v and error %v, want nil", got, err)
+		}
+
+		_, err = reloaded.StopTimer("alice@example.com")
+		assertError(t, err, stores.ErrNoTimerRunning)
+
+		running, err = reloaded.GetRunningTimer("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if running != nil {
+			t.Errorf("got running timer %v, want nil", *running)
+		}
+
+		_, err = reloaded.StartTimer(3, "alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		entries, err := reloaded.GetTimeEntries(1)
+		yattatest.AssertNoError(t, err)
+
+		if len(entries) != 2 {
+			t.Errorf("got %d time entries on task 1, want 2", len(entries))
+		}
+	})
+
+	t.Run("enter time by hand", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+		addTimeEntry(t, store, 1, "alice@example.com", start.Add(24*time.Hour), time.Hour)
+		addTimeEntry(t, store, 1, "bob@example.com", start, 30*time.Minute)
+
+		entries, err := store.GetTimeEntries(1)
+		yattatest.AssertNoError(t, err)
+
+		if len(entries) != 2 || entries[0].User != "bob@example.com" || entries[1].User != "alice@example.com" {
+			t.Errorf("got time entries %v, want Bob's entry then Alice's", entries)
+		}
+
+		end := start.Add(-time.Minute)
+		_, err = store.AddTimeEntry(models.TimeEntry{TaskID: 1, User: "alice@example.com", Start: start, End: &end})
+		assertError(t, err, stores.ErrInvalidTimeEntry)
+		_, err = store.AddTimeEntry(models.TimeEntry{TaskID: 1, User: "alice@example.com", Start: start})
+		assertError(t, err, stores.ErrInvalidTimeEntry)
+		_, err = store.AddTimeEntry(models.TimeEntry{TaskID: 42, User: "alice@example.com", Start: start, End: &end})
+		assertError(t, err, stores.ErrTaskNotFound)
+	})
+
+	t.Run("get a timesheet across task lists", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+		monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+		addTimeEntry(t, store, 3, "alice@example.com", monday.Add(26*time.Hour), time.Hour)
+		addTimeEntry(t, store, 1, "alice@example.com", monday.Add(9*time.Hour), time.Hour)
+		addTimeEntry(t, store, 2, "alice@example.com", monday.Add(-time.Hour), time.Hour)
+		addTimeEntry(t, store, 1, "alice@example.com", monday.Add(7*24*time.Hour), time.Hour)
+		addTimeEntry(t, store, 1, "bob@example.com", monday.Add(9*time.Hour), time.Hour)
+
+		entries, err := store.GetTimesheet("alice@example.com", monday, monday.AddDate(0, 0, 7))
+		yattatest.AssertNoError(t, err)
+
+		var got []uint64
+
+		for _, entry := range entries {
+			got = append(got, entry.TaskID)
+		}
+
+		if want := []uint64{1, 3}; !slices.Equal(got, want) {
+			t.Errorf("got time entries for tasks %v, want %v", got, want)
+		}
+	})
+
+	t.Run("only the user that spent the time can delete it", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		entry := addTimeEntry(t, store, 1, "alice@example.com", time.Now(), time.Hour)
+
+		assertError(t, store.DeleteTimeEntry(entry.ID, "bob@example.com"), stores.ErrNotTimeEntryOwner)
+		yattatest.AssertNoError(t, store.DeleteTimeEntry(entry.ID, "alice@example.com"))
+		assertError(t, store.DeleteTimeEntry(entry.ID, "alice@example.com"), stores.ErrTimeEntryNotFound)
+	})
+
+	t.Run("IDs of deleted time entries are not used again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		entry := addTimeEntry(t, store, 1, "alice@example.com", time.Now(), time.Hour)
+		yattatest.AssertNoError(t, store.DeleteTimeEntry(entry.ID, "alice@example.com"))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+
+		if added := addTimeEntry(t, reloaded, 3, "bob@example.com", time.Now(), time.Hour); added.ID != 2 {
+			t.Errorf("got time entry ID %d, want 2", added.ID)
+		}
+	})
+
+	t.Run("purging a task forgets its time entries", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+		start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+		addTimeEntry(t, store, 1, "alice@example.com", start, time.Hour)
+		yattatest.AssertNoError(t, store.DeleteTask(1))
+		yattatest.AssertNoError(t, store.PurgeTask(1))
+
+		entries, err := store.GetTimesheet("alice@example.com", start, start.Add(time.Hour))
+		yattatest.AssertNoError(t, err)
+
+		if len(entries) != 0 {
+			t.Errorf("got time entries %v, want none", entries)
+		}
+	})
+}
+
+func addTimeEntry(t *testing.T, store *stores.FileTaskStore, taskID uint64, user string, start time.Time, duration time.Duration) *models.TimeEntry {
+	t.Helper()
+
+	end := start.Add(duration)
+	entry, err := store.AddTimeEntry(models.TimeEntry{TaskID: taskID, User: user, Start: start, End: &end})
+	yattatest.AssertNoError(t, err)
+
+	return entry
+}
+
+func TestFileTaskStore_Board(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"},
+		{"ID": 2, "Description": "test", "ParentID": 1}, {"ID": 3, "Description": "build", "Status": 2}]},
+		{"user": "bob@example.com", "tasks": [{"ID": 4, "Description": "review"}]}]`
+
+	t.Run("boards start with the default columns", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		columns, err := store.GetColumns("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(columns, models.DefaultColumns) {
+			t.Errorf("got columns %v, want %v", columns, models.DefaultColumns)
+		}
+	})
+
+	t.Run("set columns", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.SetColumns("alice@example.com", []models.Column{
+			{ID: 1, Name: " To do "},
+			{ID: 0, Name: "Blocked", WIPLimit: 2},
+			// Duplicate IDs are given new IDs.
+			{ID: 1, Name: "Testing"},
+			{ID: 4, Name: "Done", Done: true},
+		})
+		yattatest.AssertNoError(t, err)
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		columns, err := reloaded.GetColumns("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		want := []models.Column{
+			{ID: 1, Name: "To do"},
+			{ID: 5, Name: "Blocked", WIPLimit: 2},
+			{ID: 6, Name: "Testing"},
+			{ID: 4, Name: "Done", Done: true},
+		}
+
+		if !reflect.DeepEqual(columns, want) {
+			t.Errorf("got columns %v, want %v", columns, want)
+		}
+
+		// Task 3 was in the "Doing" column, which was removed.
+		task, err := reloaded.GetTask(3)
+		yattatest.AssertNoError(t, err)
+
+		if task.Status != 0 {
+			t.Errorf("got status %d for a task in a removed column, want 0", task.Status)
+		}
+	})
+
+	t.Run("reject invalid columns", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		cases := map[string][]models.Column{
+			"no columns":     nil,
+			"no done column": {{ID: 1, Name: "To do"}},
+			"no open column": {{ID: 4, Name: "Done", Done: true}},
+			"blank name":     {{ID: 1, Name: " "}, {ID: 4, Name: "Done", Done: true}},
+			"negative limit": {{ID: 1, Name: "To do", WIPLimit: -1}, {ID: 4, Name: "Done", Done: true}},
+		}
+
+		for name, columns := range cases {
+			t.Run(name, func(t *testing.T) {
+				assertError(t, store.SetColumns("alice@example.com", columns), stores.ErrInvalidColumns)
+			})
+		}
+	})
+
+	t.Run("moving a task to a done column completes it and its subtasks", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.MoveTask("alice@example.com", 1, 4))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+
+		for _, id := range []uint64{1, 2} {
+			task, err := reloaded.GetTask(id)
+			yattatest.AssertNoError(t, err)
+
+			if !task.Done {
+				t.Errorf("got open task %d, want it completed", id)
+			}
+		}
+
+		// Moving the subtask back reopens it along with its parent.
+		yattatest.AssertNoError(t, reloaded.MoveTask("alice@example.com", 2, 3))
+
+		for _, id := range []uint64{1, 2} {
+			task, err := reloaded.GetTask(id)
+			yattatest.AssertNoError(t, err)
+
+			if task.Done {
+				t.Errorf("got completed task %d, want it reopened", id)
+			}
+		}
+
+		task, err := reloaded.GetTask(2)
+		yattatest.AssertNoError(t, err)
+
+		if task.Status != 3 {
+			t.Errorf("got status %d, want 3", task.Status)
+		}
+	})
+
+	t.Run("columns cannot hold more tasks than their WIP limit", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		err := store.SetColumns("alice@example.com", []models.Column{
+			{ID: 1, Name: "Backlog"},
+			{ID: 2, Name: "Doing", WIPLimit: 1},
+			{ID: 4, Name: "Done", Done: true},
+		})
+		yattatest.AssertNoError(t, err)
+
+		assertError(t, store.MoveTask("alice@example.com", 1, 2), stores.ErrWIPLimitReached)
+		// Tasks already in a full column can stay there.
+		yattatest.AssertNoError(t, store.MoveTask("alice@example.com", 3, 2))
+	})
+
+	t.Run("moving a task fails for missing tasks and columns", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		assertError(t, store.MoveTask("alice@example.com", 9, 2), stores.ErrTaskNotFound)
+		assertError(t, store.MoveTask("alice@example.com", 4, 2), stores.ErrTaskNotFound)
+		assertError(t, store.MoveTask("alice@example.com", 1, 9), stores.ErrColumnNotFound)
+	})
+}
+
+func TestFileTaskStore_Templates(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [
+		{"ID": 1, "Description": "release {{version}}", "Due": "2025-03-14T00:00:00Z", "Done": true},
+		{"ID": 2, "Description": "write changelog for {{version}}", "ParentID": 1, "Due": "2025-03-12T00:00:00Z", "Tags": ["docs"]},
+		{"ID": 3, "Description": "tag release", "ParentID": 2}]},
+		{"user": "bob@example.com", "tasks": [{"ID": 4, "Description": "review"}]}]`
+
+	t.Run("save and use a template", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		template, err := store.AddTemplate(1, "Release")
+		yattatest.AssertNoError(t, err)
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		templates, err := reloaded.GetTemplates("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(templates) != 1 || !reflect.DeepEqual(templates[0], *template) || template.ID != 1 {
+			t.Fatalf("got templates %v, want just the template %v with ID 1", templates, *template)
+		}
+
+		anchor := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+		id, err := reloaded.UseTemplate("alice@example.com", 1, anchor, map[string]string{"version": "1.2.0"})
+		yattatest.AssertNoError(t, err)
+
+		if id != 5 {
+			t.Errorf("got ID %d for the new task, want 5", id)
+		}
+
+		due := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+		subtaskDue := time.Date(2025, time.May, 31, 0, 0, 0, 0, time.UTC)
+		assertGetTask(t, reloaded, 5, models.Task{ID: 5, Description: "release 1.2.0", Due: &due})
+		assertGetTask(t, reloaded, 6, models.Task{ID: 6, Description: "write changelog for 1.2.0", ParentID: 5, Due: &subtaskDue, Tags: []string{"docs"}})
+		assertGetTask(t, reloaded, 7, models.Task{ID: 7, Description: "tag release", ParentID: 6})
+
+		if results, _ := reloaded.SearchTasks("alice@example.com", "1.2.0"); len(results) != 2 {
+			t.Errorf("got %d search results for the new tasks, want 2", len(results))
+		}
+	})
+
+	t.Run("every placeholder needs a value", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddTemplate(1, "Release")
+		yattatest.AssertNoError(t, err)
+
+		_, err = store.UseTemplate("alice@example.com", 1, time.Now(), map[string]string{"version": " "})
+		assertError(t, err, stores.ErrMissingValue)
+		assertTaskIDs(t, store.GetReadyTasks, "alice@example.com", []uint64{2, 3})
+	})
+
+	t.Run("templates belong to the user of the task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddTemplate(1, "Release")
+		yattatest.AssertNoError(t, err)
+
+		templates, err := store.GetTemplates("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(templates) != 0 {
+			t.Errorf("got templates %v for Bob, want none", templates)
+		}
+
+		_, err = store.UseTemplate("bob@example.com", 1, time.Now(), map[string]string{"version": "1.2.0"})
+		assertError(t, err, stores.ErrTemplateNotFound)
+		assertError(t, store.DeleteTemplate("bob@example.com", 1), stores.ErrTemplateNotFound)
+	})
+
+	t.Run("delete a template", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddTemplate(1, "Release")
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.DeleteTemplate("alice@example.com", 1))
+
+		templates, err := mustCreateFileTaskStore(t, database).GetTemplates("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(templates) != 0 {
+			t.Errorf("got templates %v, want none", templates)
+		}
+
+		assertError(t, store.DeleteTemplate("alice@example.com", 1), stores.ErrTemplateNotFound)
+	})
+
+	t.Run("cannot save a missing task as a template", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddTemplate(9, "Release")
+		assertError(t, err, stores.ErrTaskNotFound)
+	})
+}
+
+func TestFileTaskStore_Sharing(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}],
+		"Trash": [{"ID": 2, "Description": "build"}]},
+		{"user": "bob@example.com", "tasks": [{"ID": 3, "Description": "review"}]}]`
+
+	t.Run("share a list", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetMember("alice@example.com", models.Member{Email: "bob@example.com", Role: models.RoleViewer}))
+		yattatest.AssertNoError(t, store.SetMember("alice@example.com", models.Member{Email: " carol@example.com ", Role: models.RoleAdmin}))
+		// Sharing the list again changes the member's role.
+		yattatest.AssertNoError(t, store.SetMember("alice@example.com", models.Member{Email: "bob@example.com", Role: models.RoleEditor}))
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		members, err := reloaded.GetMembers("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		want := []models.Member{{Email: "bob@example.com", Role: models.RoleEditor}, {Email: "carol@example.com", Role: models.RoleAdmin}}
+
+		if !reflect.DeepEqual(members, want) {
+			t.Errorf("got members %v, want %v", members, want)
+		}
+
+		sharedLists, err := reloaded.GetSharedLists("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if want := []models.SharedList{{Owner: "alice@example.com", Role: models.RoleEditor}}; !reflect.DeepEqual(sharedLists, want) {
+			t.Errorf("got shared lists %v, want %v", sharedLists, want)
+		}
+
+		if sharedLists, _ := reloaded.GetSharedLists("alice@example.com"); len(sharedLists) != 0 {
+			t.Errorf("got shared lists %v for the owner, want none", sharedLists)
+		}
+	})
+
+	t.Run("reject invalid members", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		for _, member := range []models.Member{
+			{Email: "alice@example.com", Role: models.RoleAdmin},
+			{Email: "", Role: models.RoleViewer},
+			{Email: "bob@example.com", Role: "owner"},
+		} {
+			assertError(t, store.SetMember("alice@example.com", member), stores.ErrInvalidMember)
+		}
+
+		if members, _ := store.GetMembers("alice@example.com"); len(members) != 0 {
+			t.Errorf("got members %v, want none", members)
+		}
+	})
+
+	t.Run("remove a member", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		yattatest.AssertNoError(t, store.SetMember("alice@example.com", models.Member{Email: "bob@example.com", Role: models.RoleViewer}))
+		yattatest.AssertNoError(t, store.RemoveMember("alice@example.com", "bob@example.com"))
+
+		if members, _ := mustCreateFileTaskStore(t, database).GetMembers("alice@example.com"); len(members) != 0 {
+			t.Errorf("got members %v, want none", members)
+		}
+
+		assertError(t, store.RemoveMember("alice@example.com", "bob@example.com"), stores.ErrMemberNotFound)
+		assertError(t, store.RemoveMember("carol@example.com", "bob@example.com"), stores.ErrMemberNotFound)
+	})
+
+	t.Run("get the owner of a task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		for id, want := range map[uint64]string{1: "alice@example.com", 2: "alice@example.com", 3: "bob@example.com"} {
+			owner, err := store.GetOwner(id)
+			yattatest.AssertNoError(t, err)
+
+			if owner != want {
+				t.Errorf("got owner %q of task %d, want %q", owner, id, want)
+			}
+		}
+
+		_, err := store.GetOwner(9)
+		assertError(t, err, stores.ErrTaskNotFound)
+	})
+}
+
+func TestFileTaskStore_Assignment(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "Members": [{"Email": "bob@example.com", "Role": "editor"}],
+		"tasks": [{"ID": 1, "Description": "deploy"}, {"ID": 2, "Description": "test", "Done": true, "Assignees": ["bob@example.com"]}]},
+		{"user": "carol@example.com", "tasks": [{"ID": 3, "Description": "review", "Assignees": ["bob@example.com"]}],
+		"Trash": [{"ID": 4, "Description": "lint", "Assignees": ["bob@example.com"]}]}]`
+
+	t.Run("assign a task", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		added, err := store.SetAssignees(1, []string{"bob@example.com", " alice@example.com", "bob@example.com", ""})
+		yattatest.AssertNoError(t, err)
+
+		if want := []string{"bob@example.com", "alice@example.com"}; !reflect.DeepEqual(added, want) {
+			t.Errorf("got added assignees %v, want %v", added, want)
+		}
+
+		added, err = store.SetAssignees(1, []string{"alice@example.com"})
+		yattatest.AssertNoError(t, err)
+
+		if len(added) != 0 {
+			t.Errorf("got added assignees %v, want none", added)
+		}
+
+		reloaded := mustCreateFileTaskStore(t, database)
+		task, err := reloaded.GetTask(1)
+		yattatest.AssertNoError(t, err)
+
+		if want := []string{"alice@example.com"}; !reflect.DeepEqual(task.Assignees, want) {
+			t.Errorf("got assignees %v, want %v", task.Assignees, want)
+		}
+
+		activity, err := reloaded.GetActivity(1)
+		yattatest.AssertNoError(t, err)
+
+		var got []string
+
+		for _, change := range activity {
+			if change.Kind == models.ActivityAssigned {
+				got = append(got, change.String())
+			}
+		}
+
+		if want := []string{"assigned to bob@example.com, alice@example.com", "assigned to alice@example.com"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got activity %q, want %q", got, want)
+		}
+	})
+
+	t.Run("reject assignees that are not members of the list", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.SetAssignees(3, []string{"alice@example.com"})
+		assertError(t, err, stores.ErrInvalidAssignee)
+
+		_, err = store.SetAssignees(4, []string{"carol@example.com"})
+		assertError(t, err, stores.ErrTaskNotFound)
+	})
+
+	t.Run("get the open tasks assigned to a user", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		tasks, err := store.GetAssignedTasks("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(tasks) != 1 || tasks[0].ID != 3 {
+			t.Errorf("got tasks %v, want only task 3", tasks)
+		}
+	})
+
+	t.Run("notify a user", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		for _, id := range []uint64{1, 3} {
+			notification := models.Notification{Kind: models.NotificationAssigned, TaskID: id, Actor: "alice@example.com"}
+			yattatest.AssertNoError(t, store.AddNotification("bob@example.com", notification))
+		}
+
+		notifications, err := mustCreateFileTaskStore(t, database).GetNotifications("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(notifications) != 2 || notifications[0].ID != 2 || notifications[0].TaskID != 3 || notifications[0].Read {
+			t.Fatalf("got notifications %+v, want 2 unread notifications, newest first", notifications)
+		}
+
+		if notifications[0].CreatedAt.IsZero() {
+			t.Errorf("got no creation time for notification %+v", notifications[0])
+		}
+
+		yattatest.AssertNoError(t, store.MarkNotificationsRead("bob@example.com"))
+		notifications, err = mustCreateFileTaskStore(t, database).GetNotifications("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		for _, notification := range notifications {
+			if !notification.Read {
+				t.Errorf("got unread notification %+v, want all notifications to be read", notification)
+			}
+		}
+	})
+}
+
+func TestFileTaskStore_Events(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy", "Done": true, "CompletedAt": "2024-01-01T00:00:00Z"}, {"ID": 2, "Description": "test"}]},
+		{"user": "bob@example.com", "tasks": [{"ID": 3, "Description": "review"}]}]`
+
+	database, cleanup := yattatest.CreateTempFile(t, initialData)
+	defer cleanup()
+
+	bus := events.NewBus(0)
+	store, err := stores.NewFileTaskStore(database, stores.WithEventBus(bus))
+	yattatest.AssertNoError(t, err)
+
+	alice, stop := bus.Subscribe("alice@example.com", 0)
+	defer stop()
+
+	id, err := store.AddTaskWithDetails("alice@example.com", "lint", models.TaskUpdate{})
+	yattatest.AssertNoError(t, err)
+	yattatest.AssertNoError(t, store.SetDone(2, true))
+	yattatest.AssertNoError(t, store.SetDone(3, true))
+	yattatest.AssertNoError(t, store.DeleteTask(id))
+	yattatest.AssertNoError(t, store.ArchiveCompletedTasks(time.Now().Add(-time.Hour)))
+
+	want := []events.Event{
+		{ID: 1, List: "alice@example.com", Kind: events.TaskAdded, TaskID: id},
+		{ID: 2, List: "alice@example.com", Kind: events.TaskChanged, TaskID: 2},
+		{ID: 4, List: "alice@example.com", Kind: events.TaskRemoved, TaskID: id},
+		{ID: 5, List: "alice@example.com", Kind: events.TaskRemoved, TaskID: 1},
+	}
+	var got []events.Event
+
+	for len(alice) > 0 {
+		got = append(got, <-alice)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %+v, want %+v", got, want)
+	}
+}
+
+func TestFileTaskStore_Webhooks(t *testing.T) {
+	const initialData = `[{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}]}]`
+	webhook := models.Webhook{
+		URL:    "https://example.com/hooks",
+		Secret: "s3cret",
+		Events: []models.WebhookEvent{models.WebhookTaskCreated, models.WebhookTaskCompleted},
+	}
+
+	t.Run("invalid webhooks are rejected", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		invalid := webhook
+		invalid.URL = "example.com"
+
+		if _, err := store.AddWebhook("alice@example.com", invalid); !errors.Is(err, stores.ErrInvalidWebhook) {
+			t.Errorf("got error %v, want %v", err, stores.ErrInvalidWebhook)
+		}
+	})
+
+	t.Run("changes are queued for the webhooks that subscribe to them", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		added, err := store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+
+		id, err := store.AddTaskWithDetails("alice@example.com", "test", models.TaskUpdate{})
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.UpdateTask(id, models.TaskUpdate{Tags: []string{"ci"}}))
+		yattatest.AssertNoError(t, store.SetDone(id, true))
+		yattatest.AssertNoError(t, store.AddTask("bob@example.com", "review"))
+
+		due, err := mustCreateFileTaskStore(t, database).GetDueDeliveries(time.Now())
+		yattatest.AssertNoError(t, err)
+
+		if len(due) != 2 {
+			t.Fatalf("got %d due deliveries, want 2: %+v", len(due), due)
+		}
+
+		for i, want := range []models.WebhookEvent{models.WebhookTaskCreated, models.WebhookTaskCompleted} {
+			delivery := due[i]
+
+			if delivery.Event != want || delivery.WebhookID != added.ID || delivery.List != "alice@example.com" || delivery.TaskID != id || delivery.URL != webhook.URL {
+				t.Errorf("got delivery %+v, want a delivery of %s for task %d", delivery, want, id)
+			}
+
+			var payload models.WebhookPayload
+			yattatest.AssertNoError(t, json.Unmarshal(delivery.Payload, &payload))
+
+			if payload.Event != want || payload.Task.ID != id || payload.Task.Done != (want == models.WebhookTaskCompleted) {
+				t.Errorf("got payload %+v, want the task as it was after the change", payload)
+			}
+		}
+	})
+
+	t.Run("deliveries are retried until they are received", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		_, err := store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "test"))
+
+		now := time.Now()
+		yattatest.AssertNoError(t, store.RecordDeliveryAttempt("alice@example.com", 1, 503, nil, now))
+
+		if due, _ := store.GetDueDeliveries(now); len(due) != 0 {
+			t.Errorf("got due deliveries %+v, want the failed delivery to wait before it is retried", due)
+		}
+
+		later := now.Add(models.FirstRetryDelay)
+
+		if due, _ := store.GetDueDeliveries(later); len(due) != 1 {
+			t.Fatalf("got due deliveries %+v, want the failed delivery to be retried", due)
+		}
+
+		yattatest.AssertNoError(t, store.RecordDeliveryAttempt("alice@example.com", 1, 200, nil, later))
+
+		deliveries, err := mustCreateFileTaskStore(t, database).GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(deliveries) != 1 || deliveries[0].Pending() || deliveries[0].Attempts != 2 || deliveries[0].StatusCode != 200 {
+			t.Errorf("got deliveries %+v, want one delivery received on the second attempt", deliveries)
+		}
+
+		if err := store.RecordDeliveryAttempt("alice@example.com", 2, 200, nil, later); !errors.Is(err, stores.ErrWebhookNotFound) {
+			t.Errorf("got error %v, want %v", err, stores.ErrWebhookNotFound)
+		}
+	})
+
+	t.Run("deleting a webhook deletes its deliveries", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		added, err := store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "test"))
+		yattatest.AssertNoError(t, store.DeleteWebhook("alice@example.com", added.ID))
+
+		if err := store.DeleteWebhook("alice@example.com", added.ID); !errors.Is(err, stores.ErrWebhookNotFound) {
+			t.Errorf("got error %v, want %v", err, stores.ErrWebhookNotFound)
+		}
+
+		webhooks, _ := store.GetWebhooks("alice@example.com")
+		deliveries, _ := store.GetDeliveries("alice@example.com")
+
+		if len(webhooks) != 0 || len(deliveries) != 0 {
+			t.Errorf("got webhooks %+v and deliveries %+v, want none", webhooks, deliveries)
+		}
+	})
+
+	t.Run("delivery IDs are not used again", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileTaskStore(t, database)
+
+		added, err := store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "test"))
+		yattatest.AssertNoError(t, store.DeleteWebhook("alice@example.com", added.ID))
+
+		store = mustCreateFileTaskStore(t, database)
+		_, err = store.AddWebhook("alice@example.com", webhook)
+		yattatest.AssertNoError(t, err)
+		yattatest.AssertNoError(t, store.AddTask("alice@example.com", "lint"))
+
+		deliveries, err := store.GetDeliveries("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(deliveries) != 1 || deliveries[0].ID != 2 {
+			t.Errorf("got deliveries %+v, want one delivery with the ID 2", deliveries)
+		}
+	})
+}
+
+func TestFileTaskStore_InboundTokens(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, `[{"user": "alice@example.com", "tasks": []}]`)
+	defer cleanup()
+	store := mustCreateFileTaskStore(t, database)
+
+	token, err := store.GetInboundToken("alice@example.com")
+	yattatest.AssertNoError(t, err)
+
+	if token != "" {
+		t.Errorf("got token %q, want none before the token is reset", token)
+	}
+
+	if _, err := store.GetInboundOwner(""); !errors.Is(err, stores.ErrInboundTokenNotFound) {
+		t.Errorf("got error %v, want %v for lists without a token", err, stores.ErrInboundTokenNotFound)
+	}
+
+	first, err := store.ResetInboundToken("alice@example.com")
+	yattatest.AssertNoError(t, err)
+	second, err := store.ResetInboundToken("alice@example.com")
+	yattatest.AssertNoError(t, err)
+
+	if len(second) != 32 || first == second {
+		t.Errorf("got tokens %q and %q, want two different 32 character tokens", first, second)
+	}
+
+	reloaded := mustCreateFileTaskStore(t, database)
+	owner, err := reloaded.GetInboundOwner(second)
+	yattatest.AssertNoError(t, err)
+
+	if owner != "alice@example.com" {
+		t.Errorf("got owner %q, want alice@example.com", owner)
+	}
+
+	if _, err := reloaded.GetInboundOwner(first); !errors.Is(err, stores.ErrInboundTokenNotFound) {
+		t.Errorf("got error %v, want %v for the old token", err, stores.ErrInboundTokenNotFound)
+	}
+}
+
+func TestFileTaskStore_FeedTokens(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, `[{"user": "alice@example.com", "tasks": []}]`)
+	defer cleanup()
+	store := mustCreateFileTaskStore(t, database)
+
+	inbound, err := store.ResetInboundToken("alice@example.com")
+	yattatest.AssertNoError(t, err)
+
+	if _, err := store.GetFeedOwner(inbound); !errors.Is(err, stores.ErrFeedTokenNotFound) {
+		t.Errorf("got error %v, want %v for an inbound token", err, stores.ErrFeedTokenNotFound)
+	}
+
+	first, err := store.ResetFeedToken("bob@example.com")
+	yattatest.AssertNoError(t, err)
+	second, err := store.ResetFeedToken("bob@example.com")
+	yattatest.AssertNoError(t, err)
+
+	reloaded := mustCreateFileTaskStore(t, database)
+
+	if token, err := reloaded.GetFeedToken("bob@example.com"); err != nil || token != second {
+		t.Errorf("got token %q and error %v, want %q", token, err, second)
+	}
+
+	owner, err := reloaded.GetFeedOwner(second)
+	yattatest.AssertNoError(t, err)
+
+	if owner != "bob@example.com" {
+		t.Errorf("got owner %q, want bob@example.com", owner)
+	}
+
+	if _, err := reloaded.GetFeedOwner(first); !errors.Is(err, stores.ErrFeedTokenNotFound) {
+		t.Errorf("got error %v, want %v for the old token", err, stores.ErrFeedTokenNotFound)
+	}
+
+	// The feed token is separate from the inbound token, which still works.
+	if owner, err := reloaded.GetInboundOwner(inbound); err != nil || owner != "alice@example.com" {
+		t.Errorf("got owner %q and error %v for the inbound token, want alice@example.com", owner, err)
 	}
 }
@@ -5,11 +5,20 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/AnthonyDickson/yatta/models"
 )
 
+// Persists users to disk.
+//
+// It is safe to use a FileUserStore from multiple goroutines, e.g., from HTTP handlers that sign users in while others
+// change their app passwords.
 type FileUserStore struct {
+	// Guards all other fields.
+	mu       sync.RWMutex
 	database *json.Encoder
 	users    userList
 }
@@ -58,6 +67,9 @@ func loadUserStore(database *os.File) (userList, error) {
 }
 
 func (f *FileUserStore) AddUser(email string, password *models.PasswordHash) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	nextID := f.users.nextID()
 
 	f.users = append(f.users, models.User{ID: nextID, Email: email, Password: password})
@@ -66,19 +78,99 @@ func (f *FileUserStore) AddUser(email string, password *models.PasswordHash) err
 }
 
 func (f *FileUserStore) GetUser(id uint64) (*models.User, error) {
-	return f.users.find(id), nil
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, user := range f.users {
+		if user.ID == id {
+			return cloneUser(user), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (f *FileUserStore) GetUserByEmail(email string) (*models.User, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if user := f.users.findByEmail(email); user != nil {
+		return cloneUser(*user), nil
+	}
+
+	return nil, nil
 }
 
 func (f *FileUserStore) GetUsers() ([]models.User, error) {
-	return f.users, nil
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	users := make([]models.User, len(f.users))
+
+	for i, user := range f.users {
+		users[i] = *cloneUser(user)
+	}
+
+	return users, nil
+}
+
+func (f *FileUserStore) AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user := f.users.findByEmail(email)
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	var id uint64 = 0
+
+	for _, appPassword := range user.AppPasswords {
+		id = max(id, appPassword.ID)
+	}
+
+	appPassword := models.AppPassword{ID: id + 1, Name: name, Hash: hash, CreatedAt: time.Now()}
+	user.AppPasswords = append(user.AppPasswords, appPassword)
+
+	return &appPassword, f.database.Encode(f.users)
+}
+
+func (f *FileUserStore) DeleteAppPassword(email string, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user := f.users.findByEmail(email)
+
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	i := slices.IndexFunc(user.AppPasswords, func(appPassword models.AppPassword) bool { return appPassword.ID == id })
+
+	if i == -1 {
+		return ErrAppPasswordNotFound
+	}
+
+	user.AppPasswords = slices.Delete(user.AppPasswords, i, i+1)
+
+	return f.database.Encode(f.users)
+}
+
+// Copy `user` so that callers cannot see, or make, changes to the app passwords in the store.
+func cloneUser(user models.User) *models.User {
+	user.AppPasswords = slices.Clone(user.AppPasswords)
+
+	return &user
 }
 
 type userList []models.User
 
-func (u userList) find(id uint64) *models.User {
-	for _, user := range u {
-		if user.ID == id {
-			return &user
+// Find the user with `email` so that they can be changed in place, or nil if there is no such user.
+func (u userList) findByEmail(email string) *models.User {
+	for i := range u {
+		if u[i].Email == email {
+			return &u[i]
 		}
 	}
 
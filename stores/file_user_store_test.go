@@ -1,8 +1,12 @@
 package stores_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/AnthonyDickson/yatta/models"
@@ -72,6 +76,127 @@ func TestFileUserStore_Add(t *testing.T) {
 	})
 }
 
+func TestFileUserStore_GetUserByEmail(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileUserStore(t, database)
+
+	yattatest.AssertNoError(t, store.AddUser("alice@example.com", yattatest.MustCreatePasswordHash(t, "averysecretpassword")))
+	yattatest.AssertNoError(t, store.AddUser("bob@example.com", yattatest.MustCreatePasswordHash(t, "anotherverysecretpassword")))
+
+	t.Run("get user by email", func(t *testing.T) {
+		got, err := store.GetUserByEmail("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if got == nil || got.ID != 2 {
+			t.Errorf("got user %v, want user 2", got)
+		}
+	})
+
+	t.Run("unknown email returns nil", func(t *testing.T) {
+		got, err := store.GetUserByEmail("carol@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if got != nil {
+			t.Errorf("got user %v, want nil", *got)
+		}
+	})
+}
+
+func TestFileUserStore_AppPasswords(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileUserStore(t, database)
+
+	yattatest.AssertNoError(t, store.AddUser("alice@example.com", yattatest.MustCreatePasswordHash(t, "averysecretpassword")))
+
+	phone, err := store.AddAppPassword("alice@example.com", "Phone", yattatest.MustCreatePasswordHash(t, "phone-secret"))
+	yattatest.AssertNoError(t, err)
+	laptop, err := store.AddAppPassword("alice@example.com", "Laptop", yattatest.MustCreatePasswordHash(t, "laptop-secret"))
+	yattatest.AssertNoError(t, err)
+
+	if phone.ID != 1 || laptop.ID != 2 || laptop.Name != "Laptop" || laptop.CreatedAt.IsZero() {
+		t.Errorf("got app passwords %+v and %+v, want IDs 1 and 2 with names and creation times", phone, laptop)
+	}
+
+	yattatest.AssertNoError(t, store.DeleteAppPassword("alice@example.com", phone.ID))
+
+	reloaded := mustCreateFileUserStore(t, database)
+	user, err := reloaded.GetUserByEmail("alice@example.com")
+	yattatest.AssertNoError(t, err)
+
+	if len(user.AppPasswords) != 1 || user.MatchAppPassword("laptop-secret") == nil || user.MatchAppPassword("phone-secret") != nil {
+		t.Errorf("got app passwords %+v, want only the laptop's", user.AppPasswords)
+	}
+
+	if _, err := store.AddAppPassword("bob@example.com", "Phone", yattatest.MustCreatePasswordHash(t, "secret")); !errors.Is(err, stores.ErrUserNotFound) {
+		t.Errorf("got error %v, want %v", err, stores.ErrUserNotFound)
+	}
+
+	if err := store.DeleteAppPassword("alice@example.com", phone.ID); !errors.Is(err, stores.ErrAppPasswordNotFound) {
+		t.Errorf("got error %v, want %v", err, stores.ErrAppPasswordNotFound)
+	}
+}
+
+func TestFileUserStore_ConcurrentAppPasswords(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileUserStore(t, database)
+	hash := yattatest.MustCreatePasswordHash(t, "secret")
+
+	yattatest.AssertNoError(t, store.AddUser("alice@example.com", hash))
+
+	for _, name := range []string{"Phone", "Laptop", "Tablet"} {
+		_, err := store.AddAppPassword("alice@example.com", name, hash)
+		yattatest.AssertNoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Revoke and recreate the first app password while other goroutines sign in, as a CalDAV client would.
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			user, _ := store.GetUserByEmail("alice@example.com")
+			_ = store.DeleteAppPassword("alice@example.com", user.AppPasswords[0].ID)
+			_, _ = store.AddAppPassword("alice@example.com", "Phone", hash)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			user, err := store.GetUserByEmail("alice@example.com")
+
+			if err != nil || user == nil {
+				t.Errorf("got user %v and error %v, want Alice", user, err)
+				return
+			}
+
+			snapshot := slices.Clone(user.AppPasswords)
+			// Give the writer a chance to change the store while this copy is being read.
+			runtime.Gosched()
+
+			if !slices.Equal(snapshot, user.AppPasswords) {
+				t.Errorf("got app passwords %+v, then %+v, want the copy to stay the same", snapshot, user.AppPasswords)
+				return
+			}
+
+			for _, appPassword := range user.AppPasswords {
+				if appPassword.ID == 0 || appPassword.Hash == nil {
+					t.Errorf("got app password %+v, want an ID and a hash", appPassword)
+					return
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
 func mustCreateFileUserStore(t *testing.T, database *os.File) *stores.FileUserStore {
 	t.Helper()
 
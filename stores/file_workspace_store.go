@@ -0,0 +1,319 @@
+package stores
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+type FileWorkspaceStore struct {
+	// Guards all other fields.
+	mu         sync.RWMutex
+	database   *json.Encoder
+	workspaces workspaceList
+}
+
+func NewFileWorkspaceStore(database *os.File) (*FileWorkspaceStore, error) {
+	workspaces, err := loadWorkspaceStore(database)
+
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileWorkspaceStore{
+		database:   json.NewEncoder(&tape{database}),
+		workspaces: workspaces,
+	}
+
+	return store, nil
+}
+
+func loadWorkspaceStore(database *os.File) (workspaceList, error) {
+	_, err := database.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not seek database file: %v", err)
+	}
+
+	data, err := io.ReadAll(database)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read database: %v", err)
+	}
+
+	// returning an empty slice avoids errors when decoding a new, empty file.
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var workspaces workspaceList
+	err = json.Unmarshal(data, &workspaces)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not decode JSON database: %v", err)
+	}
+
+	return workspaces, nil
+}
+
+func (f *FileWorkspaceStore) AddWorkspace(name string, owner string) (models.Workspace, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return models.Workspace{}, ErrInvalidWorkspace
+	}
+
+	if f.workspaces.findMember(owner) != nil {
+		return models.Workspace{}, ErrAlreadyInWorkspace
+	}
+
+	workspace := models.Workspace{
+		ID:      f.workspaces.nextID(),
+		Name:    name,
+		Members: []models.Member{{Email: owner, Role: models.RoleAdmin}},
+	}
+	f.workspaces = append(f.workspaces, workspace)
+
+	return cloneWorkspace(workspace), f.database.Encode(f.workspaces)
+}
+
+func (f *FileWorkspaceStore) GetWorkspace(id uint64) (models.Workspace, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return models.Workspace{}, ErrWorkspaceNotFound
+	}
+
+	return cloneWorkspace(*workspace), nil
+}
+
+func (f *FileWorkspaceStore) GetWorkspaceOf(email string) (*models.Workspace, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	workspace := f.workspaces.findMember(email)
+
+	if workspace == nil {
+		return nil, nil
+	}
+
+	found := cloneWorkspace(*workspace)
+
+	return &found, nil
+}
+
+func (f *FileWorkspaceStore) GetInvitations(email string) ([]models.Workspace, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	invitations := []models.Workspace{}
+
+	for _, workspace := range f.workspaces {
+		if workspace.IsInvited(email) {
+			invitations = append(invitations, cloneWorkspace(workspace))
+		}
+	}
+
+	return invitations, nil
+}
+
+func (f *FileWorkspaceStore) Invite(id uint64, invitation models.Member) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+
+	invitation.Email = strings.TrimSpace(invitation.Email)
+
+	if invitation.Email == "" || !slices.Contains(models.Roles, invitation.Role) {
+		return ErrInvalidMember
+	}
+
+	if f.workspaces.findMember(invitation.Email) != nil {
+		return ErrAlreadyInWorkspace
+	}
+
+	index := slices.IndexFunc(workspace.Invitations, func(existing models.Member) bool {
+		return existing.Email == invitation.Email
+	})
+
+	if index == -1 {
+		workspace.Invitations = append(workspace.Invitations, invitation)
+	} else {
+		workspace.Invitations[index] = invitation
+	}
+
+	return f.database.Encode(f.workspaces)
+}
+
+func (f *FileWorkspaceStore) AcceptInvitation(id uint64, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+
+	index := invitationIndex(*workspace, email)
+
+	if index == -1 {
+		return ErrInvitationNotFound
+	}
+
+	if f.workspaces.findMember(email) != nil {
+		return ErrAlreadyInWorkspace
+	}
+
+	workspace.Members = append(workspace.Members, workspace.Invitations[index])
+	workspace.Invitations = slices.Delete(workspace.Invitations, index, index+1)
+
+	return f.database.Encode(f.workspaces)
+}
+
+func (f *FileWorkspaceStore) DeleteInvitation(id uint64, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+
+	index := invitationIndex(*workspace, email)
+
+	if index == -1 {
+		return ErrInvitationNotFound
+	}
+
+	workspace.Invitations = slices.Delete(workspace.Invitations, index, index+1)
+
+	return f.database.Encode(f.workspaces)
+}
+
+func (f *FileWorkspaceStore) UpdateSettings(id uint64, settings models.WorkspaceSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+
+	workspace.Settings = settings
+
+	return f.database.Encode(f.workspaces)
+}
+
+func (f *FileWorkspaceStore) RemoveWorkspaceMember(id uint64, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := f.workspaces.find(id)
+
+	if workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+
+	index := slices.IndexFunc(workspace.Members, func(member models.Member) bool {
+		return member.Email == email
+	})
+
+	if index == -1 {
+		return ErrMemberNotFound
+	}
+
+	admins := 0
+
+	for _, member := range workspace.Members {
+		if member.Role == models.RoleAdmin {
+			admins++
+		}
+	}
+
+	if workspace.Members[index].Role == models.RoleAdmin && admins == 1 && len(workspace.Members) > 1 {
+		return ErrLastAdmin
+	}
+
+	workspace.Members = slices.Delete(workspace.Members, index, index+1)
+
+	// Nobody is left to use the workspace.
+	if len(workspace.Members) == 0 {
+		f.workspaces = slices.DeleteFunc(f.workspaces, func(workspace models.Workspace) bool {
+			return workspace.ID == id
+		})
+	}
+
+	return f.database.Encode(f.workspaces)
+}
+
+// Copy `workspace` so that callers cannot change the workspaces in the store.
+func cloneWorkspace(workspace models.Workspace) models.Workspace {
+	workspace.Members = slices.Clone(workspace.Members)
+	workspace.Invitations = slices.Clone(workspace.Invitations)
+
+	return workspace
+}
+
+type workspaceList []models.Workspace
+
+func (w workspaceList) find(id uint64) *models.Workspace {
+	for i := range w {
+		if w[i].ID == id {
+			return &w[i]
+		}
+	}
+
+	return nil
+}
+
+// Find the workspace that the user with `email` belongs to.
+func (w workspaceList) findMember(email string) *models.Workspace {
+	for i := range w {
+		if w[i].MemberRole(email) != "" {
+			return &w[i]
+		}
+	}
+
+	return nil
+}
+
+func (w workspaceList) nextID() uint64 {
+	var maxID uint64 = 0
+
+	for _, workspace := range w {
+		maxID = max(maxID, workspace.ID)
+	}
+
+	return maxID + 1
+}
+
+// Find the index of the invitation for the user with `email`, or -1 if they have not been invited.
+func invitationIndex(workspace models.Workspace, email string) int {
+	return slices.IndexFunc(workspace.Invitations, func(invitation models.Member) bool {
+		return invitation.Email == email
+	})
+}
@@ -0,0 +1,217 @@
+package stores_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+	"github.com/AnthonyDickson/yatta/yattatest"
+)
+
+func TestFileWorkspaceStore_AddWorkspace(t *testing.T) {
+	t.Run("create a workspace with its owner as an admin", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, "")
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		got, err := store.AddWorkspace(" Acme ", "alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		want := models.Workspace{ID: 1, Name: "Acme", Members: []models.Member{{Email: "alice@example.com", Role: models.RoleAdmin}}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got workspace %+v, want %+v", got, want)
+		}
+
+		workspace, err := mustCreateFileWorkspaceStore(t, database).GetWorkspaceOf("alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if workspace == nil || !reflect.DeepEqual(*workspace, want) {
+			t.Errorf("got workspace %+v after reloading the store, want %+v", workspace, want)
+		}
+
+		if got, _ := store.AddWorkspace("Globex", "bob@example.com"); got.ID != 2 {
+			t.Errorf("got ID %d for the second workspace, want 2", got.ID)
+		}
+	})
+
+	t.Run("reject workspaces without a name", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, "")
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		_, err := store.AddWorkspace(" ", "alice@example.com")
+		assertError(t, err, stores.ErrInvalidWorkspace)
+	})
+
+	t.Run("users belong to at most one workspace", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, "")
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		_, err := store.AddWorkspace("Acme", "alice@example.com")
+		yattatest.AssertNoError(t, err)
+
+		_, err = store.AddWorkspace("Globex", "alice@example.com")
+		assertError(t, err, stores.ErrAlreadyInWorkspace)
+	})
+}
+
+func TestFileWorkspaceStore_Invitations(t *testing.T) {
+	const initialData = `[
+		{"ID": 1, "Name": "Acme", "Members": [{"Email": "alice@example.com", "Role": "admin"}]},
+		{"ID": 2, "Name": "Globex", "Members": [{"Email": "erin@example.com", "Role": "admin"}]}
+	]`
+
+	t.Run("invited users can join", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		yattatest.AssertNoError(t, store.Invite(1, models.Member{Email: " bob@example.com", Role: models.RoleViewer}))
+		yattatest.AssertNoError(t, store.Invite(2, models.Member{Email: "bob@example.com", Role: models.RoleEditor}))
+		// Inviting a user again changes their role.
+		yattatest.AssertNoError(t, store.Invite(1, models.Member{Email: "bob@example.com", Role: models.RoleEditor}))
+
+		invitations, err := mustCreateFileWorkspaceStore(t, database).GetInvitations("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(invitations) != 2 || invitations[0].Name != "Acme" || invitations[1].Name != "Globex" {
+			t.Fatalf("got invitations %+v, want invitations to Acme and Globex", invitations)
+		}
+
+		yattatest.AssertNoError(t, store.AcceptInvitation(1, "bob@example.com"))
+
+		reloaded := mustCreateFileWorkspaceStore(t, database)
+		workspace, err := reloaded.GetWorkspace(1)
+		yattatest.AssertNoError(t, err)
+
+		want := []models.Member{{Email: "alice@example.com", Role: models.RoleAdmin}, {Email: "bob@example.com", Role: models.RoleEditor}}
+
+		if !reflect.DeepEqual(workspace.Members, want) || len(workspace.Invitations) != 0 {
+			t.Errorf("got workspace %+v, want members %v and no invitations", workspace, want)
+		}
+
+		assertError(t, store.AcceptInvitation(2, "bob@example.com"), stores.ErrAlreadyInWorkspace)
+		assertError(t, store.Invite(2, models.Member{Email: "alice@example.com", Role: models.RoleViewer}), stores.ErrAlreadyInWorkspace)
+	})
+
+	t.Run("decline an invitation", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		yattatest.AssertNoError(t, store.Invite(1, models.Member{Email: "bob@example.com", Role: models.RoleViewer}))
+		yattatest.AssertNoError(t, store.DeleteInvitation(1, "bob@example.com"))
+
+		if invitations, _ := mustCreateFileWorkspaceStore(t, database).GetInvitations("bob@example.com"); len(invitations) != 0 {
+			t.Errorf("got invitations %+v, want none", invitations)
+		}
+
+		assertError(t, store.DeleteInvitation(1, "bob@example.com"), stores.ErrInvitationNotFound)
+		assertError(t, store.AcceptInvitation(1, "bob@example.com"), stores.ErrInvitationNotFound)
+	})
+
+	t.Run("reject invalid invitations", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		assertError(t, store.Invite(1, models.Member{Email: "", Role: models.RoleViewer}), stores.ErrInvalidMember)
+		assertError(t, store.Invite(1, models.Member{Email: "bob@example.com", Role: "owner"}), stores.ErrInvalidMember)
+		assertError(t, store.Invite(3, models.Member{Email: "bob@example.com", Role: models.RoleViewer}), stores.ErrWorkspaceNotFound)
+		assertError(t, store.AcceptInvitation(3, "bob@example.com"), stores.ErrWorkspaceNotFound)
+	})
+}
+
+func TestFileWorkspaceStore_RemoveWorkspaceMember(t *testing.T) {
+	const initialData = `[{"ID": 1, "Name": "Acme", "Members": [
+		{"Email": "alice@example.com", "Role": "admin"},
+		{"Email": "bob@example.com", "Role": "editor"}
+	]}]`
+
+	t.Run("remove a member", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		yattatest.AssertNoError(t, store.RemoveWorkspaceMember(1, "bob@example.com"))
+
+		if workspace, _ := mustCreateFileWorkspaceStore(t, database).GetWorkspaceOf("bob@example.com"); workspace != nil {
+			t.Errorf("got workspace %+v for a removed member, want nil", workspace)
+		}
+
+		assertError(t, store.RemoveWorkspaceMember(1, "bob@example.com"), stores.ErrMemberNotFound)
+		assertError(t, store.RemoveWorkspaceMember(2, "alice@example.com"), stores.ErrWorkspaceNotFound)
+	})
+
+	t.Run("workspaces with other members keep an admin", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		assertError(t, store.RemoveWorkspaceMember(1, "alice@example.com"), stores.ErrLastAdmin)
+	})
+
+	t.Run("workspaces without members are deleted", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		yattatest.AssertNoError(t, store.RemoveWorkspaceMember(1, "bob@example.com"))
+		yattatest.AssertNoError(t, store.RemoveWorkspaceMember(1, "alice@example.com"))
+
+		_, err := mustCreateFileWorkspaceStore(t, database).GetWorkspace(1)
+		assertError(t, err, stores.ErrWorkspaceNotFound)
+	})
+
+	t.Run("changing a workspace that was got does not change the store", func(t *testing.T) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		defer cleanup()
+		store := mustCreateFileWorkspaceStore(t, database)
+
+		workspace, err := store.GetWorkspace(1)
+		yattatest.AssertNoError(t, err)
+		workspace.Members[0].Role = models.RoleViewer
+
+		if workspace, _ := store.GetWorkspace(1); workspace.Members[0].Role != models.RoleAdmin {
+			t.Errorf("got role %q, want the store to keep %q", workspace.Members[0].Role, models.RoleAdmin)
+		}
+	})
+}
+
+func TestFileWorkspaceStore_UpdateSettings(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	store := mustCreateFileWorkspaceStore(t, database)
+
+	workspace, err := store.AddWorkspace("Acme", "alice@example.com")
+	yattatest.AssertNoError(t, err)
+
+	want := models.WorkspaceSettings{TimeZone: "Pacific/Auckland", DisableSecretLinks: true}
+	yattatest.AssertNoError(t, store.UpdateSettings(workspace.ID, want))
+
+	reloaded, err := mustCreateFileWorkspaceStore(t, database).GetWorkspace(workspace.ID)
+	yattatest.AssertNoError(t, err)
+
+	if reloaded.Settings != want {
+		t.Errorf("got settings %+v after reloading the store, want %+v", reloaded.Settings, want)
+	}
+
+	assertError(t, store.UpdateSettings(workspace.ID, models.WorkspaceSettings{TimeZone: "Middle/Earth"}), stores.ErrInvalidSettings)
+	assertError(t, store.UpdateSettings(2, want), stores.ErrWorkspaceNotFound)
+}
+
+func mustCreateFileWorkspaceStore(t *testing.T, database *os.File) *stores.FileWorkspaceStore {
+	t.Helper()
+
+	store, err := stores.NewFileWorkspaceStore(database)
+
+	if err != nil {
+		t.Fatalf("could not load workspace store: %v", err)
+	}
+
+	return store
+}
@@ -1,23 +1,462 @@
 package stores
 
-import "github.com/AnthonyDickson/yatta/models"
+import (
+	"errors"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// The maximum number of levels in a tree of subtasks, including the top-level task.
+const MaxTaskDepth = 8
+
+var (
+	// ErrTaskNotFound is returned when an operation refers to a task that does not exist.
+	ErrTaskNotFound = errors.New("task not found")
+
+	// ErrTaskCycle is returned when making a task a subtask of itself or one of its own subtasks.
+	ErrTaskCycle = errors.New("a task cannot be a subtask of itself")
+
+	// ErrMaxDepthExceeded is returned when a subtask would be nested deeper than [MaxTaskDepth].
+	ErrMaxDepthExceeded = errors.New("subtasks are nested too deeply")
+
+	// ErrDifferentList is returned when relating tasks that belong to different task lists.
+	ErrDifferentList = errors.New("tasks belong to different lists")
+
+	// ErrDependencyCycle is returned when a dependency would make a task (indirectly) wait on itself.
+	ErrDependencyCycle = errors.New("a task cannot depend on itself")
+
+	// ErrSavedSearchNotFound is returned when an operation refers to a saved search that does not exist.
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+
+	// ErrCommentNotFound is returned when an operation refers to a comment that does not exist.
+	ErrCommentNotFound = errors.New("comment not found")
+
+	// ErrAttachmentNotFound is returned when an operation refers to an attachment that does not exist.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrNotCommentAuthor is returned when someone other than the author of a comment tries to change it.
+	ErrNotCommentAuthor = errors.New("only the author of a comment can change it")
+
+	// ErrTimeEntryNotFound is returned when an operation refers to a time entry that does not exist.
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+
+	// ErrNotTimeEntryOwner is returned when someone other than the user that spent the time tries to change a time
+	// entry.
+	ErrNotTimeEntryOwner = errors.New("only the user that spent the time can change a time entry")
+
+	// ErrTimerRunning is returned when starting a timer for a user that already has a timer running.
+	ErrTimerRunning = errors.New("a timer is already running")
+
+	// ErrNoTimerRunning is returned when stopping the timer of a user that does not have a timer running.
+	ErrNoTimerRunning = errors.New("no timer is running")
+
+	// ErrInvalidTimeEntry is returned when adding a time entry that does not end after it starts.
+	ErrInvalidTimeEntry = errors.New("a time entry must end after it starts")
+
+	// ErrColumnNotFound is returned when an operation refers to a board column that does not exist.
+	ErrColumnNotFound = errors.New("column not found")
+
+	// ErrInvalidColumns is returned when setting up a board with a column that has no name or a negative WIP limit, or
+	// without both a column for open tasks and a column for done tasks.
+	ErrInvalidColumns = errors.New("a board needs named columns for both open and done tasks")
+
+	// ErrWIPLimitReached is returned when moving a task into a column that already holds as many tasks as its WIP
+	// limit allows.
+	ErrWIPLimitReached = errors.New("the column has reached its WIP limit")
+
+	// ErrTemplateNotFound is returned when an operation refers to a template that does not exist.
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrMissingValue is returned when using a template without giving a value for each of its placeholders.
+	ErrMissingValue = errors.New("every placeholder in the template needs a value")
+
+	// ErrMemberNotFound is returned when an operation refers to a member that a task list is not shared with.
+	ErrMemberNotFound = errors.New("member not found")
+
+	// ErrInvalidMember is returned when sharing a task list with its owner or with a role that does not exist.
+	ErrInvalidMember = errors.New("a list can only be shared with other users as a viewer, editor or admin")
+
+	// ErrInvalidAssignee is returned when assigning a task to a user that is not the owner or a member of its list.
+	ErrInvalidAssignee = errors.New("tasks can only be assigned to the owner and members of their list")
+
+	// ErrWebhookNotFound is returned when an operation refers to a webhook or delivery that does not exist.
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidWebhook is returned when adding a webhook that fails [models.Webhook.Validate].
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrInboundTokenNotFound is returned when looking up the task list of an inbound token that no list has.
+	ErrInboundTokenNotFound = errors.New("inbound token not found")
+
+	// ErrFeedTokenNotFound is returned when looking up the task list of a calendar feed token that no list has.
+	ErrFeedTokenNotFound = errors.New("feed token not found")
+)
 
 // Handles the creation and retrieval of tasks.
 type TaskStore interface {
 	// Get all tasks (possibly an empty slice) for `user`.
 	//
+	// The [models.Task.Blocked] status of each task is computed from its dependencies.
+	//
 	// Returns an empty slice and error if something prevented the tasks from being retrieved from the store.
 	GetTasks(user string) ([]models.Task, error)
 
-	// Get a single task by its `id`.
+	// Get a single task by its `id`, which may be an archived task.
 	//
-	// Returns `nil` if a task with `id` was not found.
+	// Returns `nil` if a task with `id` was not found or is in the trash.
 	//
 	// Returns `nil` and an error if something prevented the tasks from being retrieved from the store.
 	GetTask(id uint64) (*models.Task, error)
 
+	// Get the user whose task list holds the task with `id`, which may be archived or in the trash.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist.
+	GetOwner(id uint64) (string, error)
+
 	// Create and add a new task for `user`.
 	//
 	// Returns an error if something prevented the task from being created or added to the store.
 	AddTask(user string, description string) error
+
+	// Create and add a new task for `user` with the notes, tags, priority, due date and other details in `details`,
+	// returning the ID of the new task. The description in `details` is ignored.
+	//
+	// Returns an error if something prevented the task from being created or added to the store.
+	AddTaskWithDetails(user string, description string, details models.TaskUpdate) (uint64, error)
+
+	// Get all the subtasks of the task with `id`, including subtasks of subtasks. The task may be archived.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	GetSubtasks(id uint64) ([]models.Task, error)
+
+	// Create a new task as a subtask of the task with `parentID` in the same list as the parent, returning the ID of
+	// the new task.
+	//
+	// Returns [ErrTaskNotFound] if the parent does not exist or [ErrMaxDepthExceeded] if the parent is already at
+	// the maximum depth.
+	AddSubtask(parentID uint64, description string) (uint64, error)
+
+	// Move the task with `id` (and its subtasks) under the task with `parentID`.
+	// A `parentID` of zero makes the task a top-level task.
+	//
+	// Returns [ErrTaskCycle] if the parent is the task itself or one of its subtasks, [ErrMaxDepthExceeded] if the
+	// tree would become too deep, and [ErrDifferentList] if the tasks are in different lists.
+	SetParent(id uint64, parentID uint64) error
+
+	// Mark the task with `id` as done or not done, recording when it was completed.
+	//
+	// Completing a task also completes all of its subtasks, and reopening a task also reopens all of its parents,
+	// so that a task is never done while one of its subtasks is still open.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist.
+	SetDone(id uint64, done bool) error
+
+	// Record that the task with `id` cannot be worked on until the task with `blockerID` is done.
+	//
+	// Returns [ErrTaskNotFound] if either task does not exist, [ErrDifferentList] if the tasks are in different lists
+	// and [ErrDependencyCycle] if the blocker already (indirectly) depends on the task.
+	AddDependency(id uint64, blockerID uint64) error
+
+	// Remove the dependency of the task with `id` on the task with `blockerID`, if there is one.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist.
+	RemoveDependency(id uint64, blockerID uint64) error
+
+	// Get the open tasks for `user` that are not blocked by any other open task.
+	// Snoozed tasks and their subtasks are left out until they are due to show up again.
+	GetReadyTasks(user string) ([]models.Task, error)
+
+	// Get the open tasks for `user` ordered so that every task comes after the tasks blocking it.
+	// Tasks that do not depend on each other keep the order they were added in.
+	// Snoozed tasks and their subtasks are left out until they are due to show up again.
+	GetNextActions(user string) ([]models.Task, error)
+
+	// Find the tasks for `user` whose descriptions contain the words in `query`, with the most relevant tasks first.
+	// An empty `user` searches the tasks of every user.
+	//
+	// See [search.Index.Search] for how the query is matched.
+	SearchTasks(user string, query string) ([]models.Task, error)
+
+	// Change the details of the task with `id`, see [models.TaskUpdate].
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist.
+	UpdateTask(id uint64, update models.TaskUpdate) error
+
+	// Save a task query under `name` for `user`.
+	//
+	// The store does not check that the query is valid.
+	AddSavedSearch(user string, name string, query string) error
+
+	// Get the saved searches (possibly an empty slice) for `user`.
+	GetSavedSearches(user string) ([]models.SavedSearch, error)
+
+	// Delete the saved search with `id` for `user`.
+	//
+	// Returns [ErrSavedSearchNotFound] if `user` does not have a saved search with `id`.
+	DeleteSavedSearch(user string, id uint64) error
+
+	// Get the columns of the board for `user`, from left to right. Returns [models.DefaultColumns] if `user` has not
+	// set up their board.
+	GetColumns(user string) ([]models.Column, error)
+
+	// Replace the columns of the board for `user`, assigning IDs to new columns, i.e., columns with the ID zero.
+	// Tasks in removed columns move to the first column that matches whether they are done.
+	//
+	// Returns [ErrInvalidColumns] if a column has no name or a negative WIP limit, or if there is not both a column
+	// for open tasks and a column for done tasks.
+	SetColumns(user string, columns []models.Column) error
+
+	// Move the task with `id` to the column with `columnID` on the board for `user`, completing or reopening the task
+	// if needed.
+	//
+	// Returns [ErrTaskNotFound] if `user` does not have an active task with `id`, [ErrColumnNotFound] if the board
+	// does not have the column and [ErrWIPLimitReached] if the column is full.
+	MoveTask(user string, id uint64, columnID uint64) error
+
+	// Save the task with `taskID` and its subtasks as a template called `name` for the user the task belongs to.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is not active.
+	AddTemplate(taskID uint64, name string) (*models.Template, error)
+
+	// Get the templates (possibly an empty slice) for `user`.
+	GetTemplates(user string) ([]models.Template, error)
+
+	// Delete the template with `id` for `user`.
+	//
+	// Returns [ErrTemplateNotFound] if `user` does not have a template with `id`.
+	DeleteTemplate(user string, id uint64) error
+
+	// Add the tasks in the template with `id` to the task list of `user`, with the placeholders filled in from
+	// `values` and the due dates counted from `anchor`.
+	//
+	// Returns the ID of the new top-level task. Returns [ErrTemplateNotFound] if `user` does not have a template with
+	// `id` and [ErrMissingValue] if `values` does not have a value for each placeholder in the template.
+	UseTemplate(user string, id uint64, anchor time.Time, values map[string]string) (uint64, error)
+
+	// Get the members (possibly an empty slice) that the task list of `user` is shared with.
+	GetMembers(user string) ([]models.Member, error)
+
+	// Share the task list of `user` with `member`, or change the role of a member the list is already shared with.
+	//
+	// Returns [ErrInvalidMember] if `member` is `user` or does not have a valid role.
+	SetMember(user string, member models.Member) error
+
+	// Stop sharing the task list of `user` with the member with `email`.
+	//
+	// Returns [ErrMemberNotFound] if the list is not shared with `email`.
+	RemoveMember(user string, email string) error
+
+	// Get the task lists (possibly an empty slice) that other users have shared with the user with `email`.
+	GetSharedLists(email string) ([]models.SharedList, error)
+
+	// Replace the users that the task with `id` is assigned to with `assignees`, which may be empty to unassign the
+	// task. Returns the assignees that the task was not assigned to before.
+	//
+	// Returns [ErrTaskNotFound] if the task is not in a task list and [ErrInvalidAssignee] if an assignee is not the
+	// owner or a member of the list.
+	SetAssignees(id uint64, assignees []string) ([]string, error)
+
+	// Get the open tasks (possibly an empty slice) that are assigned to the user with `email`, across all task lists.
+	GetAssignedTasks(email string) ([]models.Task, error)
+
+	// Tell `user` about a change to a task that concerns them.
+	AddNotification(user string, notification models.Notification) error
+
+	// Get the notifications (possibly an empty slice) of `user`, newest first.
+	GetNotifications(user string) ([]models.Notification, error)
+
+	// Mark all the notifications of `user` as read.
+	MarkNotificationsRead(user string) error
+
+	// Add a webhook to the task list of `user`. From then on, a delivery is queued whenever one of the webhook's
+	// events happens to a task in the list.
+	//
+	// Returns [ErrInvalidWebhook] if the webhook is not valid.
+	AddWebhook(user string, webhook models.Webhook) (*models.Webhook, error)
+
+	// Get the webhooks (possibly an empty slice) of the task list of `user`, oldest first.
+	GetWebhooks(user string) ([]models.Webhook, error)
+
+	// Delete the webhook with `id` from the task list of `user`, along with its deliveries.
+	//
+	// Returns [ErrWebhookNotFound] if the list does not have the webhook.
+	DeleteWebhook(user string, id uint64) error
+
+	// Get the deliveries (possibly an empty slice) of the webhooks of the task list of `user`, newest first.
+	//
+	// Only the most recent deliveries that have been received or given up on are kept.
+	GetDeliveries(user string) ([]models.Delivery, error)
+
+	// Get the pending deliveries (possibly an empty slice) of every task list that should be attempted at or before
+	// `now`, oldest first.
+	GetDueDeliveries(now time.Time) ([]models.Delivery, error)
+
+	// Record an attempt at `at` to send the delivery with `id` from the task list of `user`, see
+	// [models.Delivery.RecordAttempt].
+	//
+	// Returns [ErrWebhookNotFound] if the list does not have the delivery.
+	RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error
+
+	// Get the secret token that lets other services add tasks to the task list of `user`, or an empty string if the
+	// list does not have one yet.
+	GetInboundToken(user string) (string, error)
+
+	// Replace the inbound token of the task list of `user` with a new random token, so that the old token stops
+	// working. Returns the new token.
+	ResetInboundToken(user string) (string, error)
+
+	// Get the user whose task list has the inbound `token`.
+	//
+	// Returns [ErrInboundTokenNotFound] if no list has the token.
+	GetInboundOwner(token string) (string, error)
+
+	// Get the secret token that lets calendar apps subscribe to the tasks in the task list of `user`, or an empty
+	// string if the list does not have one yet.
+	GetFeedToken(user string) (string, error)
+
+	// Replace the feed token of the task list of `user` with a new random token, so that the old token stops working.
+	// Returns the new token.
+	ResetFeedToken(user string) (string, error)
+
+	// Get the user whose task list has the feed `token`.
+	//
+	// Returns [ErrFeedTokenNotFound] if no list has the token.
+	GetFeedOwner(token string) (string, error)
+
+	// Move the task with `id` and its subtasks to the trash, hiding them from the task list and search.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is already in the trash.
+	DeleteTask(id uint64) error
+
+	// Get the tasks (possibly an empty slice) in the trash for `user`.
+	GetTrash(user string) ([]models.Task, error)
+
+	// Move the task with `id` and the subtasks that are in the trash with it out of the trash.
+	// If the task's parent is still in the trash, the task becomes a top-level task.
+	//
+	// Returns [ErrTaskNotFound] if the task is not in the trash.
+	RestoreTask(id uint64) error
+
+	// Permanently delete the task with `id` and the subtasks that are in the trash with it.
+	//
+	// Returns [ErrTaskNotFound] if the task is not in the trash.
+	PurgeTask(id uint64) error
+
+	// Permanently delete the tasks of every user that were moved to the trash before `before`.
+	PurgeTrash(before time.Time) error
+
+	// Move the top-level tasks of every user that were completed before `before`, and their subtasks, to the archive.
+	//
+	// Archived tasks are no longer shown in the task list or search, but can still be viewed with [TaskStore.GetTask].
+	ArchiveCompletedTasks(before time.Time) error
+
+	// Get the archived tasks (possibly an empty slice) for `user`.
+	GetArchivedTasks(user string) ([]models.Task, error)
+
+	// Add a comment written by `author` to the task with `taskID`.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	AddComment(taskID uint64, author string, body string) (*models.Comment, error)
+
+	// Get the comments (possibly an empty slice) on the task with `taskID`, oldest first.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	GetComments(taskID uint64) ([]models.Comment, error)
+
+	// Get the comment with `id`.
+	//
+	// Returns `nil` if the comment does not exist.
+	GetComment(id uint64) (*models.Comment, error)
+
+	// Replace the body of the comment with `id`, recording when it was edited.
+	//
+	// Returns [ErrCommentNotFound] if the comment does not exist and [ErrNotCommentAuthor] if `author` did not
+	// write the comment.
+	UpdateComment(id uint64, author string, body string) (*models.Comment, error)
+
+	// Delete the comment with `id`.
+	//
+	// Returns [ErrCommentNotFound] if the comment does not exist and [ErrNotCommentAuthor] if `author` did not
+	// write the comment.
+	DeleteComment(id uint64, author string) error
+
+	// Get the changes (possibly an empty slice) made to the task with `taskID`, oldest first.
+	// Changes are recorded automatically when tasks are created, renamed, completed, reopened, deleted, restored and
+	// archived.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	GetActivity(taskID uint64) ([]models.Activity, error)
+
+	// Get the times (possibly an empty slice) that the tasks of `user` were created, completed and reopened, oldest
+	// first, for reporting progress over time.
+	GetCompletionHistory(user string) ([]models.Activity, error)
+
+	// Attach the file described by `attachment` to the task with `attachment.TaskID`, assigning the attachment an ID.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	AddAttachment(attachment models.Attachment) (*models.Attachment, error)
+
+	// Get the attachments (possibly an empty slice) of the task with `taskID`, oldest first.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	GetAttachments(taskID uint64) ([]models.Attachment, error)
+
+	// Get the attachment with `id`.
+	//
+	// Returns `nil` if the attachment was not found or its task is in the trash.
+	GetAttachment(id uint64) (*models.Attachment, error)
+
+	// Remove the attachment with `id` from its task. The contents of the file are left in the blob store, see
+	// [TaskStore.GetAttachmentKeys].
+	//
+	// Returns [ErrAttachmentNotFound] if the attachment does not exist.
+	DeleteAttachment(id uint64) error
+
+	// Get the blob store keys of the files attached to any task, including tasks in the trash.
+	// Blobs with other keys are no longer needed.
+	GetAttachmentKeys() ([]string, error)
+
+	// Start a timer for `user` working on the task with `taskID`.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash, and [ErrTimerRunning] if `user`
+	// already has a timer running, since a user can only work on one task at a time.
+	StartTimer(taskID uint64, user string) (*models.TimeEntry, error)
+
+	// Stop the running timer of `user`.
+	//
+	// Returns [ErrNoTimerRunning] if `user` does not have a timer running.
+	StopTimer(user string) (*models.TimeEntry, error)
+
+	// Get the running timer of `user`.
+	//
+	// Returns `nil` if `user` does not have a timer running.
+	GetRunningTimer(user string) (*models.TimeEntry, error)
+
+	// Record the time described by `entry`, which was entered by hand, against the task with `entry.TaskID`,
+	// assigning the entry an ID.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash, and [ErrInvalidTimeEntry] if the entry
+	// does not end after it starts.
+	AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error)
+
+	// Get the time entries (possibly an empty slice) of every user for the task with `taskID`, oldest first.
+	//
+	// Returns [ErrTaskNotFound] if the task does not exist or is in the trash.
+	GetTimeEntries(taskID uint64) ([]models.TimeEntry, error)
+
+	// Get the time entry with `id`.
+	//
+	// Returns `nil` if the time entry does not exist.
+	GetTimeEntry(id uint64) (*models.TimeEntry, error)
+
+	// Get the time entries (possibly an empty slice) of `user` that started at or after `from` and before `to`, across
+	// all tasks, oldest first.
+	GetTimesheet(user string, from time.Time, to time.Time) ([]models.TimeEntry, error)
+
+	// Delete the time entry with `id`.
+	//
+	// Returns [ErrTimeEntryNotFound] if the time entry does not exist and [ErrNotTimeEntryOwner] if the time was not
+	// spent by `user`.
+	DeleteTimeEntry(id uint64, user string) error
 }
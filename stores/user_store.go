@@ -1,9 +1,19 @@
 package stores
 
 import (
+	"errors"
+
 	"github.com/AnthonyDickson/yatta/models"
 )
 
+var (
+	// ErrUserNotFound is returned when an operation refers to a user that does not exist.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrAppPasswordNotFound is returned when an operation refers to an app password that a user does not have.
+	ErrAppPasswordNotFound = errors.New("app password not found")
+)
+
 // UserStore is an interface for storing and retrieving users.
 type UserStore interface {
 	// AddUser adds a new user to the store.
@@ -12,6 +22,20 @@ type UserStore interface {
 	// GetUser retrieves a user by their ID.
 	GetUser(id uint64) (*models.User, error)
 
+	// GetUserByEmail retrieves a user by their email, returning nil if there is no user with the email.
+	GetUserByEmail(email string) (*models.User, error)
+
 	// GetUsers retrieves all users.
 	GetUsers() ([]models.User, error)
+
+	// AddAppPassword gives the user with `email` an app password with `name` and `hash`.
+	//
+	// Returns [ErrUserNotFound] if there is no user with the email.
+	AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error)
+
+	// DeleteAppPassword removes the app password with `id` from the user with `email`, so that it can no longer be
+	// used to sign in.
+	//
+	// Returns [ErrUserNotFound] or [ErrAppPasswordNotFound] if the user or the app password does not exist.
+	DeleteAppPassword(email string, id uint64) error
 }
@@ -0,0 +1,87 @@
+package stores
+
+import (
+	"errors"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+var (
+	// ErrWorkspaceNotFound is returned when an operation refers to a workspace that does not exist.
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+
+	// ErrInvalidWorkspace is returned when creating a workspace without a name.
+	ErrInvalidWorkspace = errors.New("a workspace needs a name")
+
+	// ErrAlreadyInWorkspace is returned when adding a user to a workspace when they already belong to one.
+	ErrAlreadyInWorkspace = errors.New("the user already belongs to a workspace")
+
+	// ErrInvitationNotFound is returned when an operation refers to an invitation that does not exist.
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// ErrLastAdmin is returned when removing the only admin of a workspace.
+	ErrLastAdmin = errors.New("a workspace needs at least one admin")
+
+	// ErrInvalidSettings is returned when changing the settings of a workspace to settings that fail
+	// [models.WorkspaceSettings.Validate].
+	ErrInvalidSettings = errors.New("invalid workspace settings")
+
+	// ErrListNotFound is returned by a [WorkspaceTaskStore] when an operation refers to a task list in another
+	// workspace.
+	ErrListNotFound = errors.New("task list not found")
+
+	// ErrAcrossWorkspaces is returned by a [WorkspaceTaskStore] for operations that work on the task lists of every
+	// workspace, which only the whole task store may do.
+	ErrAcrossWorkspaces = errors.New("cannot work on the task lists of every workspace from one workspace")
+)
+
+// Handles the storage of workspaces and who belongs to them.
+type WorkspaceStore interface {
+	// Create a workspace called `name` with `owner` as its first admin, returning the new workspace.
+	//
+	// Returns [ErrInvalidWorkspace] if `name` is blank and [ErrAlreadyInWorkspace] if `owner` already belongs to a
+	// workspace.
+	AddWorkspace(name string, owner string) (models.Workspace, error)
+
+	// Get the workspace with `id`.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id`.
+	GetWorkspace(id uint64) (models.Workspace, error)
+
+	// Get the workspace that the user with `email` belongs to, or nil if they do not belong to one.
+	GetWorkspaceOf(email string) (*models.Workspace, error)
+
+	// Get the workspaces (possibly an empty slice) that the user with `email` has been asked to join.
+	GetInvitations(email string) ([]models.Workspace, error)
+
+	// Ask the user in `invitation` to join the workspace with `id` with the role in `invitation`. Inviting a user
+	// again changes the role they will have.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id`, [ErrInvalidMember] if the invitation has no
+	// email or an invalid role and [ErrAlreadyInWorkspace] if the user already belongs to a workspace.
+	Invite(id uint64, invitation models.Member) error
+
+	// Add the user with `email` to the workspace with `id` that they have been invited to.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id`, [ErrInvitationNotFound] if the user has not
+	// been invited and [ErrAlreadyInWorkspace] if the user already belongs to a workspace.
+	AcceptInvitation(id uint64, email string) error
+
+	// Withdraw the invitation for the user with `email` to join the workspace with `id`.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id` and [ErrInvitationNotFound] if the user has
+	// not been invited.
+	DeleteInvitation(id uint64, email string) error
+
+	// Replace the settings of the workspace with `id`.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id` and [ErrInvalidSettings] if the settings are
+	// not valid.
+	UpdateSettings(id uint64, settings models.WorkspaceSettings) error
+
+	// Remove the user with `email` from the workspace with `id`.
+	//
+	// Returns [ErrWorkspaceNotFound] if there is no workspace with `id`, [ErrMemberNotFound] if the user is not a
+	// member and [ErrLastAdmin] if the user is the only admin of a workspace that has other members.
+	RemoveWorkspaceMember(id uint64, email string) error
+}
@@ -0,0 +1,870 @@
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// WorkspaceTaskStore is the part of a [TaskStore] that can be seen from one workspace: the task lists of the users in
+// the workspace, and everything in them.
+//
+// Task lists, tasks, comments, attachments and time entries outside the workspace are treated as if they did not
+// exist. Operations that work on the lists of every workspace at once, such as [TaskStore.PurgeTrash], return
+// [ErrAcrossWorkspaces].
+type WorkspaceTaskStore struct {
+	store      TaskStore
+	workspaces WorkspaceStore
+	// The ID of the workspace, or zero for the users that do not belong to a workspace.
+	workspace uint64
+}
+
+// NewWorkspaceTaskStore creates a view of `store` that only holds the task lists of the users in the workspace with
+// the ID `workspace`, where `workspaces` says who belongs to which workspace. The users that do not belong to a
+// workspace share the workspace with the ID zero.
+func NewWorkspaceTaskStore(store TaskStore, workspaces WorkspaceStore, workspace uint64) *WorkspaceTaskStore {
+	return &WorkspaceTaskStore{store: store, workspaces: workspaces, workspace: workspace}
+}
+
+// Check whether `email` belongs to the workspace, where every user that does not belong to a workspace belongs to the
+// workspace with the ID zero.
+func inWorkspace(workspaces WorkspaceStore, workspace uint64, email string) (bool, error) {
+	theirs, err := workspaces.GetWorkspaceOf(email)
+
+	if err != nil {
+		return false, err
+	}
+
+	if theirs == nil {
+		return workspace == 0, nil
+	}
+
+	return theirs.ID == workspace, nil
+}
+
+// Check that the task list of `user` is in the workspace.
+//
+// Returns [ErrListNotFound] if it is not.
+func (w *WorkspaceTaskStore) checkList(user string) error {
+	ok, err := inWorkspace(w.workspaces, w.workspace, user)
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrListNotFound
+	}
+
+	return nil
+}
+
+// Check that the task with `id` is in a task list in the workspace.
+//
+// Returns [ErrTaskNotFound] if the task does not exist or is in another workspace.
+func (w *WorkspaceTaskStore) checkTask(id uint64) error {
+	owner, err := w.store.GetOwner(id)
+
+	if err != nil {
+		return err
+	}
+
+	if err := w.checkList(owner); errors.Is(err, ErrListNotFound) {
+		return ErrTaskNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Keep the `items` whose task, given by `taskID`, is in a task list in the workspace. Items whose task no longer
+// exists are left out.
+func keepInWorkspace[T any](w *WorkspaceTaskStore, items []T, taskID func(T) uint64) ([]T, error) {
+	// Whether the list of each owner is in the workspace, since items often come from the same few lists.
+	owners := make(map[string]bool)
+	kept := []T{}
+
+	for _, item := range items {
+		owner, err := w.store.GetOwner(taskID(item))
+
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ok, seen := owners[owner]
+
+		if !seen {
+			if ok, err = inWorkspace(w.workspaces, w.workspace, owner); err != nil {
+				return nil, err
+			}
+
+			owners[owner] = ok
+		}
+
+		if ok {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept, nil
+}
+
+func (w *WorkspaceTaskStore) GetTasks(user string) ([]models.Task, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetTasks(user)
+}
+
+func (w *WorkspaceTaskStore) GetTask(id uint64) (*models.Task, error) {
+	if err := w.checkTask(id); errors.Is(err, ErrTaskNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return w.store.GetTask(id)
+}
+
+func (w *WorkspaceTaskStore) GetOwner(id uint64) (string, error) {
+	if err := w.checkTask(id); err != nil {
+		return "", err
+	}
+
+	return w.store.GetOwner(id)
+}
+
+func (w *WorkspaceTaskStore) AddTask(user string, description string) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.AddTask(user, description)
+}
+
+func (w *WorkspaceTaskStore) AddTaskWithDetails(user string, description string, details models.TaskUpdate) (uint64, error) {
+	if err := w.checkList(user); err != nil {
+		return 0, err
+	}
+
+	return w.store.AddTaskWithDetails(user, description, details)
+}
+
+func (w *WorkspaceTaskStore) GetSubtasks(id uint64) ([]models.Task, error) {
+	if err := w.checkTask(id); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetSubtasks(id)
+}
+
+func (w *WorkspaceTaskStore) AddSubtask(parentID uint64, description string) (uint64, error) {
+	if err := w.checkTask(parentID); err != nil {
+		return 0, err
+	}
+
+	return w.store.AddSubtask(parentID, description)
+}
+
+func (w *WorkspaceTaskStore) SetParent(id uint64, parentID uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	if parentID != 0 {
+		if err := w.checkTask(parentID); err != nil {
+			return err
+		}
+	}
+
+	return w.store.SetParent(id, parentID)
+}
+
+func (w *WorkspaceTaskStore) SetDone(id uint64, done bool) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.SetDone(id, done)
+}
+
+func (w *WorkspaceTaskStore) AddDependency(id uint64, blockerID uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	if err := w.checkTask(blockerID); err != nil {
+		return err
+	}
+
+	return w.store.AddDependency(id, blockerID)
+}
+
+func (w *WorkspaceTaskStore) RemoveDependency(id uint64, blockerID uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.RemoveDependency(id, blockerID)
+}
+
+func (w *WorkspaceTaskStore) GetReadyTasks(user string) ([]models.Task, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetReadyTasks(user)
+}
+
+func (w *WorkspaceTaskStore) GetNextActions(user string) ([]models.Task, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetNextActions(user)
+}
+
+// SearchTasks finds the tasks for `user` that match `query`, see [TaskStore.SearchTasks]. An empty `user` searches
+// the tasks of every user in the workspace.
+func (w *WorkspaceTaskStore) SearchTasks(user string, query string) ([]models.Task, error) {
+	if user != "" {
+		if err := w.checkList(user); err != nil {
+			return nil, err
+		}
+
+		return w.store.SearchTasks(user, query)
+	}
+
+	tasks, err := w.store.SearchTasks("", query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keepInWorkspace(w, tasks, func(task models.Task) uint64 { return task.ID })
+}
+
+func (w *WorkspaceTaskStore) UpdateTask(id uint64, update models.TaskUpdate) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.UpdateTask(id, update)
+}
+
+func (w *WorkspaceTaskStore) AddSavedSearch(user string, name string, query string) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.AddSavedSearch(user, name, query)
+}
+
+func (w *WorkspaceTaskStore) GetSavedSearches(user string) ([]models.SavedSearch, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetSavedSearches(user)
+}
+
+func (w *WorkspaceTaskStore) DeleteSavedSearch(user string, id uint64) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.DeleteSavedSearch(user, id)
+}
+
+func (w *WorkspaceTaskStore) GetColumns(user string) ([]models.Column, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetColumns(user)
+}
+
+func (w *WorkspaceTaskStore) SetColumns(user string, columns []models.Column) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.SetColumns(user, columns)
+}
+
+func (w *WorkspaceTaskStore) MoveTask(user string, id uint64, columnID uint64) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.MoveTask(user, id, columnID)
+}
+
+func (w *WorkspaceTaskStore) AddTemplate(taskID uint64, name string) (*models.Template, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.AddTemplate(taskID, name)
+}
+
+func (w *WorkspaceTaskStore) GetTemplates(user string) ([]models.Template, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetTemplates(user)
+}
+
+func (w *WorkspaceTaskStore) DeleteTemplate(user string, id uint64) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.DeleteTemplate(user, id)
+}
+
+func (w *WorkspaceTaskStore) UseTemplate(user string, id uint64, anchor time.Time, values map[string]string) (uint64, error) {
+	if err := w.checkList(user); err != nil {
+		return 0, err
+	}
+
+	return w.store.UseTemplate(user, id, anchor, values)
+}
+
+func (w *WorkspaceTaskStore) GetMembers(user string) ([]models.Member, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetMembers(user)
+}
+
+// SetMember shares the task list of `user` with `member`, see [TaskStore.SetMember].
+//
+// Returns [ErrInvalidMember] if `member` does not belong to the workspace, since lists cannot be shared with other
+// workspaces.
+func (w *WorkspaceTaskStore) SetMember(user string, member models.Member) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	if ok, err := inWorkspace(w.workspaces, w.workspace, member.Email); err != nil {
+		return err
+	} else if !ok {
+		return ErrInvalidMember
+	}
+
+	return w.store.SetMember(user, member)
+}
+
+func (w *WorkspaceTaskStore) RemoveMember(user string, email string) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.RemoveMember(user, email)
+}
+
+// GetSharedLists gets the task lists in the workspace that other users have shared with the user with `email`, see
+// [TaskStore.GetSharedLists].
+func (w *WorkspaceTaskStore) GetSharedLists(email string) ([]models.SharedList, error) {
+	if err := w.checkList(email); err != nil {
+		return nil, err
+	}
+
+	lists, err := w.store.GetSharedLists(email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []models.SharedList{}
+
+	for _, list := range lists {
+		if ok, err := inWorkspace(w.workspaces, w.workspace, list.Owner); err != nil {
+			return nil, err
+		} else if ok {
+			shared = append(shared, list)
+		}
+	}
+
+	return shared, nil
+}
+
+func (w *WorkspaceTaskStore) SetAssignees(id uint64, assignees []string) ([]string, error) {
+	if err := w.checkTask(id); err != nil {
+		return nil, err
+	}
+
+	return w.store.SetAssignees(id, assignees)
+}
+
+// GetAssignedTasks gets the open tasks in the workspace that are assigned to the user with `email`, see
+// [TaskStore.GetAssignedTasks].
+func (w *WorkspaceTaskStore) GetAssignedTasks(email string) ([]models.Task, error) {
+	if err := w.checkList(email); err != nil {
+		return nil, err
+	}
+
+	tasks, err := w.store.GetAssignedTasks(email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keepInWorkspace(w, tasks, func(task models.Task) uint64 { return task.ID })
+}
+
+func (w *WorkspaceTaskStore) AddNotification(user string, notification models.Notification) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	if err := w.checkTask(notification.TaskID); err != nil {
+		return err
+	}
+
+	return w.store.AddNotification(user, notification)
+}
+
+// GetNotifications gets the notifications of `user` about tasks in the workspace, see [TaskStore.GetNotifications].
+func (w *WorkspaceTaskStore) GetNotifications(user string) ([]models.Notification, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	notifications, err := w.store.GetNotifications(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keepInWorkspace(w, notifications, func(notification models.Notification) uint64 { return notification.TaskID })
+}
+
+func (w *WorkspaceTaskStore) MarkNotificationsRead(user string) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.MarkNotificationsRead(user)
+}
+
+func (w *WorkspaceTaskStore) AddWebhook(user string, webhook models.Webhook) (*models.Webhook, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.AddWebhook(user, webhook)
+}
+
+func (w *WorkspaceTaskStore) GetWebhooks(user string) ([]models.Webhook, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetWebhooks(user)
+}
+
+func (w *WorkspaceTaskStore) DeleteWebhook(user string, id uint64) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.DeleteWebhook(user, id)
+}
+
+func (w *WorkspaceTaskStore) GetDeliveries(user string) ([]models.Delivery, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetDeliveries(user)
+}
+
+// GetDueDeliveries gets the pending deliveries of the task lists in the workspace, see [TaskStore.GetDueDeliveries].
+func (w *WorkspaceTaskStore) GetDueDeliveries(now time.Time) ([]models.Delivery, error) {
+	deliveries, err := w.store.GetDueDeliveries(now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	due := []models.Delivery{}
+
+	for _, delivery := range deliveries {
+		if ok, err := inWorkspace(w.workspaces, w.workspace, delivery.List); err != nil {
+			return nil, err
+		} else if ok {
+			due = append(due, delivery)
+		}
+	}
+
+	return due, nil
+}
+
+func (w *WorkspaceTaskStore) RecordDeliveryAttempt(user string, id uint64, statusCode int, err error, at time.Time) error {
+	if err := w.checkList(user); err != nil {
+		return err
+	}
+
+	return w.store.RecordDeliveryAttempt(user, id, statusCode, err, at)
+}
+
+func (w *WorkspaceTaskStore) GetInboundToken(user string) (string, error) {
+	if err := w.checkList(user); err != nil {
+		return "", err
+	}
+
+	return w.store.GetInboundToken(user)
+}
+
+func (w *WorkspaceTaskStore) ResetInboundToken(user string) (string, error) {
+	if err := w.checkList(user); err != nil {
+		return "", err
+	}
+
+	return w.store.ResetInboundToken(user)
+}
+
+func (w *WorkspaceTaskStore) GetInboundOwner(token string) (string, error) {
+	owner, err := w.store.GetInboundOwner(token)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.checkList(owner); errors.Is(err, ErrListNotFound) {
+		return "", ErrInboundTokenNotFound
+	} else if err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
+
+func (w *WorkspaceTaskStore) GetFeedToken(user string) (string, error) {
+	if err := w.checkList(user); err != nil {
+		return "", err
+	}
+
+	return w.store.GetFeedToken(user)
+}
+
+func (w *WorkspaceTaskStore) ResetFeedToken(user string) (string, error) {
+	if err := w.checkList(user); err != nil {
+		return "", err
+	}
+
+	return w.store.ResetFeedToken(user)
+}
+
+func (w *WorkspaceTaskStore) GetFeedOwner(token string) (string, error) {
+	owner, err := w.store.GetFeedOwner(token)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.checkList(owner); errors.Is(err, ErrListNotFound) {
+		return "", ErrFeedTokenNotFound
+	} else if err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
+
+func (w *WorkspaceTaskStore) DeleteTask(id uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.DeleteTask(id)
+}
+
+func (w *WorkspaceTaskStore) GetTrash(user string) ([]models.Task, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetTrash(user)
+}
+
+func (w *WorkspaceTaskStore) RestoreTask(id uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.RestoreTask(id)
+}
+
+func (w *WorkspaceTaskStore) PurgeTask(id uint64) error {
+	if err := w.checkTask(id); err != nil {
+		return err
+	}
+
+	return w.store.PurgeTask(id)
+}
+
+func (w *WorkspaceTaskStore) PurgeTrash(before time.Time) error {
+	return ErrAcrossWorkspaces
+}
+
+func (w *WorkspaceTaskStore) ArchiveCompletedTasks(before time.Time) error {
+	return ErrAcrossWorkspaces
+}
+
+func (w *WorkspaceTaskStore) GetArchivedTasks(user string) ([]models.Task, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetArchivedTasks(user)
+}
+
+func (w *WorkspaceTaskStore) AddComment(taskID uint64, author string, body string) (*models.Comment, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.AddComment(taskID, author, body)
+}
+
+func (w *WorkspaceTaskStore) GetComments(taskID uint64) ([]models.Comment, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetComments(taskID)
+}
+
+// Check that the comment with `id` is on a task in the workspace.
+//
+// Returns [ErrCommentNotFound] if the comment does not exist or is in another workspace.
+func (w *WorkspaceTaskStore) checkComment(id uint64) error {
+	comment, err := w.store.GetComment(id)
+
+	if err != nil {
+		return err
+	}
+
+	if comment == nil {
+		return ErrCommentNotFound
+	}
+
+	if err := w.checkTask(comment.TaskID); errors.Is(err, ErrTaskNotFound) {
+		return ErrCommentNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WorkspaceTaskStore) GetComment(id uint64) (*models.Comment, error) {
+	if err := w.checkComment(id); errors.Is(err, ErrCommentNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return w.store.GetComment(id)
+}
+
+func (w *WorkspaceTaskStore) UpdateComment(id uint64, author string, body string) (*models.Comment, error) {
+	if err := w.checkComment(id); err != nil {
+		return nil, err
+	}
+
+	return w.store.UpdateComment(id, author, body)
+}
+
+func (w *WorkspaceTaskStore) DeleteComment(id uint64, author string) error {
+	if err := w.checkComment(id); err != nil {
+		return err
+	}
+
+	return w.store.DeleteComment(id, author)
+}
+
+func (w *WorkspaceTaskStore) GetActivity(taskID uint64) ([]models.Activity, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetActivity(taskID)
+}
+
+func (w *WorkspaceTaskStore) GetCompletionHistory(user string) ([]models.Activity, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetCompletionHistory(user)
+}
+
+func (w *WorkspaceTaskStore) AddAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	if err := w.checkTask(attachment.TaskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.AddAttachment(attachment)
+}
+
+func (w *WorkspaceTaskStore) GetAttachments(taskID uint64) ([]models.Attachment, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetAttachments(taskID)
+}
+
+func (w *WorkspaceTaskStore) GetAttachment(id uint64) (*models.Attachment, error) {
+	attachment, err := w.store.GetAttachment(id)
+
+	if err != nil || attachment == nil {
+		return nil, err
+	}
+
+	if err := w.checkTask(attachment.TaskID); errors.Is(err, ErrTaskNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (w *WorkspaceTaskStore) DeleteAttachment(id uint64) error {
+	attachment, err := w.GetAttachment(id)
+
+	if err != nil {
+		return err
+	}
+
+	if attachment == nil {
+		return ErrAttachmentNotFound
+	}
+
+	return w.store.DeleteAttachment(id)
+}
+
+func (w *WorkspaceTaskStore) GetAttachmentKeys() ([]string, error) {
+	return nil, ErrAcrossWorkspaces
+}
+
+func (w *WorkspaceTaskStore) StartTimer(taskID uint64, user string) (*models.TimeEntry, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.StartTimer(taskID, user)
+}
+
+func (w *WorkspaceTaskStore) StopTimer(user string) (*models.TimeEntry, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	return w.store.StopTimer(user)
+}
+
+// GetRunningTimer gets the running timer of `user` if it is on a task in the workspace, see
+// [TaskStore.GetRunningTimer].
+func (w *WorkspaceTaskStore) GetRunningTimer(user string) (*models.TimeEntry, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	entry, err := w.store.GetRunningTimer(user)
+
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	if err := w.checkTask(entry.TaskID); errors.Is(err, ErrTaskNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (w *WorkspaceTaskStore) AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error) {
+	if err := w.checkList(entry.User); err != nil {
+		return nil, err
+	}
+
+	if err := w.checkTask(entry.TaskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.AddTimeEntry(entry)
+}
+
+func (w *WorkspaceTaskStore) GetTimeEntries(taskID uint64) ([]models.TimeEntry, error) {
+	if err := w.checkTask(taskID); err != nil {
+		return nil, err
+	}
+
+	return w.store.GetTimeEntries(taskID)
+}
+
+func (w *WorkspaceTaskStore) GetTimeEntry(id uint64) (*models.TimeEntry, error) {
+	entry, err := w.store.GetTimeEntry(id)
+
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	if err := w.checkTask(entry.TaskID); errors.Is(err, ErrTaskNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetTimesheet gets the time entries of `user` on tasks in the workspace, see [TaskStore.GetTimesheet].
+func (w *WorkspaceTaskStore) GetTimesheet(user string, from time.Time, to time.Time) ([]models.TimeEntry, error) {
+	if err := w.checkList(user); err != nil {
+		return nil, err
+	}
+
+	entries, err := w.store.GetTimesheet(user, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keepInWorkspace(w, entries, func(entry models.TimeEntry) uint64 { return entry.TaskID })
+}
+
+func (w *WorkspaceTaskStore) DeleteTimeEntry(id uint64, user string) error {
+	entry, err := w.GetTimeEntry(id)
+
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return ErrTimeEntryNotFound
+	}
+
+	return w.store.DeleteTimeEntry(id, user)
+}
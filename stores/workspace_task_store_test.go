@@ -0,0 +1,184 @@
+package stores_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+	"github.com/AnthonyDickson/yatta/yattatest"
+)
+
+func TestWorkspaceTaskStore(t *testing.T) {
+	// Alice works at Acme, Erin works at Globex and Zed does not belong to a workspace. Each has one task, and Alice
+	// shares her list with Bob, who also works at Acme.
+	const initialData = `[
+		{"user": "alice@example.com", "tasks": [{"ID": 1, "Description": "deploy"}], "members": [{"Email": "bob@example.com", "Role": "editor"}]},
+		{"user": "erin@example.com", "tasks": [{"ID": 2, "Description": "deploy"}], "members": [{"Email": "bob@example.com", "Role": "viewer"}]},
+		{"user": "zed@example.com", "tasks": [{"ID": 3, "Description": "deploy"}]}
+	]`
+	newStores := func(t *testing.T) (*stores.FileTaskStore, *stores.FileWorkspaceStore) {
+		database, cleanup := yattatest.CreateTempFile(t, initialData)
+		t.Cleanup(cleanup)
+		workspaceDatabase, cleanupWorkspaces := yattatest.CreateTempFile(t, `[
+			{"ID": 1, "Name": "Acme", "Members": [{"Email": "alice@example.com", "Role": "admin"}, {"Email": "bob@example.com", "Role": "editor"}]},
+			{"ID": 2, "Name": "Globex", "Members": [{"Email": "erin@example.com", "Role": "admin"}]}
+		]`)
+		t.Cleanup(cleanupWorkspaces)
+
+		return mustCreateFileTaskStore(t, database), mustCreateFileWorkspaceStore(t, workspaceDatabase)
+	}
+
+	t.Run("lists in other workspaces do not exist", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		acme := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 1)
+
+		if tasks, err := acme.GetTasks("alice@example.com"); err != nil || len(tasks) != 1 {
+			t.Errorf("got tasks %v and error %v, want Alice's task", tasks, err)
+		}
+
+		for _, user := range []string{"erin@example.com", "zed@example.com"} {
+			_, err := acme.GetTasks(user)
+			assertError(t, err, stores.ErrListNotFound)
+			assertError(t, acme.AddTask(user, "leak"), stores.ErrListNotFound)
+			_, err = acme.GetWebhooks(user)
+			assertError(t, err, stores.ErrListNotFound)
+			_, err = acme.GetNotifications(user)
+			assertError(t, err, stores.ErrListNotFound)
+		}
+
+		if task, err := acme.GetTask(2); err != nil || task != nil {
+			t.Errorf("got task %v and error %v, want nil for a task in another workspace", task, err)
+		}
+
+		assertError(t, acme.SetDone(2, true), stores.ErrTaskNotFound)
+		assertError(t, acme.DeleteTask(3), stores.ErrTaskNotFound)
+		_, err := acme.AddComment(2, "alice@example.com", "leak")
+		assertError(t, err, stores.ErrTaskNotFound)
+		_, err = acme.GetOwner(3)
+		assertError(t, err, stores.ErrTaskNotFound)
+
+		if tasks, _ := taskStore.GetTasks("erin@example.com"); len(tasks) != 1 || tasks[0].Done {
+			t.Errorf("got Erin's tasks %v, want her task to be untouched", tasks)
+		}
+	})
+
+	t.Run("users without a workspace only see each other", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		outside := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 0)
+
+		if tasks, err := outside.GetTasks("zed@example.com"); err != nil || len(tasks) != 1 {
+			t.Errorf("got tasks %v and error %v, want Zed's task", tasks, err)
+		}
+
+		_, err := outside.GetTasks("alice@example.com")
+		assertError(t, err, stores.ErrListNotFound)
+	})
+
+	t.Run("searches and lists across task lists stay in the workspace", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		acme := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 1)
+
+		results, err := acme.SearchTasks("", "deploy")
+		yattatest.AssertNoError(t, err)
+
+		if len(results) != 1 || results[0].ID != 1 {
+			t.Errorf("got results %v, want only Alice's task", results)
+		}
+
+		shared, err := acme.GetSharedLists("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(shared) != 1 || shared[0].Owner != "alice@example.com" {
+			t.Errorf("got shared lists %v, want only Alice's list", shared)
+		}
+
+		_, err = taskStore.SetAssignees(2, []string{"bob@example.com"})
+		yattatest.AssertNoError(t, err)
+		assigned, err := acme.GetAssignedTasks("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(assigned) != 0 {
+			t.Errorf("got assigned tasks %v, want none from other workspaces", assigned)
+		}
+
+		yattatest.AssertNoError(t, taskStore.AddNotification("bob@example.com", models.Notification{TaskID: 2, Kind: models.NotificationAssigned}))
+		notifications, err := acme.GetNotifications("bob@example.com")
+		yattatest.AssertNoError(t, err)
+
+		if len(notifications) != 0 {
+			t.Errorf("got notifications %v, want none about tasks in other workspaces", notifications)
+		}
+	})
+
+	t.Run("comments and time entries in other workspaces do not exist", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		globex := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 2)
+
+		comment, err := taskStore.AddComment(1, "bob@example.com", "mine")
+		yattatest.AssertNoError(t, err)
+		end := time.Now()
+		entry, err := taskStore.AddTimeEntry(models.TimeEntry{TaskID: 1, User: "bob@example.com", Start: end.Add(-time.Hour), End: &end})
+		yattatest.AssertNoError(t, err)
+
+		_, err = globex.UpdateComment(comment.ID, "bob@example.com", "changed")
+		assertError(t, err, stores.ErrCommentNotFound)
+		assertError(t, globex.DeleteComment(comment.ID, "bob@example.com"), stores.ErrCommentNotFound)
+		assertError(t, globex.DeleteTimeEntry(entry.ID, "bob@example.com"), stores.ErrTimeEntryNotFound)
+
+		if comments, _ := taskStore.GetComments(1); len(comments) != 1 || comments[0].Body != "mine" {
+			t.Errorf("got comments %v, want the comment to be untouched", comments)
+		}
+	})
+
+	t.Run("lists cannot be shared with other workspaces", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		acme := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 1)
+
+		err := acme.SetMember("alice@example.com", models.Member{Email: "erin@example.com", Role: models.RoleViewer})
+		assertError(t, err, stores.ErrInvalidMember)
+		yattatest.AssertNoError(t, acme.SetMember("alice@example.com", models.Member{Email: "bob@example.com", Role: models.RoleAdmin}))
+	})
+
+	t.Run("housekeeping needs the whole store", func(t *testing.T) {
+		taskStore, workspaceStore := newStores(t)
+		acme := stores.NewWorkspaceTaskStore(taskStore, workspaceStore, 1)
+
+		assertError(t, acme.PurgeTrash(time.Now()), stores.ErrAcrossWorkspaces)
+		assertError(t, acme.ArchiveCompletedTasks(time.Now()), stores.ErrAcrossWorkspaces)
+		_, err := acme.GetAttachmentKeys()
+		assertError(t, err, stores.ErrAcrossWorkspaces)
+	})
+}
+
+func TestWorkspaceUserStore(t *testing.T) {
+	database, cleanup := yattatest.CreateTempFile(t, "")
+	defer cleanup()
+	userStore := mustCreateFileUserStore(t, database)
+	hash := yattatest.MustCreatePasswordHash(t, "secret")
+
+	for _, email := range []string{"alice@example.com", "erin@example.com", "zed@example.com"} {
+		yattatest.AssertNoError(t, userStore.AddUser(email, hash))
+	}
+
+	workspaceDatabase, cleanupWorkspaces := yattatest.CreateTempFile(t, `[
+		{"ID": 1, "Name": "Acme", "Members": [{"Email": "alice@example.com", "Role": "admin"}]},
+		{"ID": 2, "Name": "Globex", "Members": [{"Email": "erin@example.com", "Role": "admin"}]}
+	]`)
+	defer cleanupWorkspaces()
+	acme := stores.NewWorkspaceUserStore(userStore, mustCreateFileWorkspaceStore(t, workspaceDatabase), 1)
+
+	users, err := acme.GetUsers()
+	yattatest.AssertNoError(t, err)
+
+	if len(users) != 1 || users[0].Email != "alice@example.com" {
+		t.Errorf("got users %v, want only Alice", users)
+	}
+
+	if user, err := acme.GetUserByEmail("erin@example.com"); err != nil || user != nil {
+		t.Errorf("got user %v and error %v, want nil for a user in another workspace", user, err)
+	}
+
+	_, err = acme.AddAppPassword("zed@example.com", "Phone", hash)
+	assertError(t, err, stores.ErrUserNotFound)
+}
@@ -0,0 +1,99 @@
+package stores
+
+import (
+	"github.com/AnthonyDickson/yatta/models"
+)
+
+// WorkspaceUserStore is the part of a [UserStore] that can be seen from one workspace: the users in the workspace.
+// Users in other workspaces are treated as if they did not exist.
+type WorkspaceUserStore struct {
+	store      UserStore
+	workspaces WorkspaceStore
+	// The ID of the workspace, or zero for the users that do not belong to a workspace.
+	workspace uint64
+}
+
+// NewWorkspaceUserStore creates a view of `store` that only holds the users in the workspace with the ID `workspace`,
+// see [NewWorkspaceTaskStore].
+func NewWorkspaceUserStore(store UserStore, workspaces WorkspaceStore, workspace uint64) *WorkspaceUserStore {
+	return &WorkspaceUserStore{store: store, workspaces: workspaces, workspace: workspace}
+}
+
+// Get `user` if they are in the workspace, or nil if they are not.
+func (w *WorkspaceUserStore) keep(user *models.User) (*models.User, error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	if ok, err := inWorkspace(w.workspaces, w.workspace, user.Email); err != nil || !ok {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// AddUser adds a new user to the store. New users do not belong to a workspace until they are invited to one.
+func (w *WorkspaceUserStore) AddUser(email string, password *models.PasswordHash) error {
+	return w.store.AddUser(email, password)
+}
+
+func (w *WorkspaceUserStore) GetUser(id uint64) (*models.User, error) {
+	user, err := w.store.GetUser(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return w.keep(user)
+}
+
+func (w *WorkspaceUserStore) GetUserByEmail(email string) (*models.User, error) {
+	user, err := w.store.GetUserByEmail(email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return w.keep(user)
+}
+
+// GetUsers gets the users in the workspace.
+func (w *WorkspaceUserStore) GetUsers() ([]models.User, error) {
+	users, err := w.store.GetUsers()
+
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []models.User{}
+
+	for _, user := range users {
+		if ok, err := inWorkspace(w.workspaces, w.workspace, user.Email); err != nil {
+			return nil, err
+		} else if ok {
+			kept = append(kept, user)
+		}
+	}
+
+	return kept, nil
+}
+
+func (w *WorkspaceUserStore) AddAppPassword(email string, name string, hash *models.PasswordHash) (*models.AppPassword, error) {
+	if ok, err := inWorkspace(w.workspaces, w.workspace, email); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return w.store.AddAppPassword(email, name, hash)
+}
+
+func (w *WorkspaceUserStore) DeleteAppPassword(email string, id uint64) error {
+	if ok, err := inWorkspace(w.workspaces, w.workspace, email); err != nil {
+		return err
+	} else if !ok {
+		return ErrUserNotFound
+	}
+
+	return w.store.DeleteAppPassword(email, id)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// The headers sent with each delivery to a webhook.
+const (
+	// The kind of change, e.g., task.created.
+	webhookEventHeader = "X-Yatta-Event"
+	// The ID of the delivery, which stays the same when the delivery is retried.
+	webhookDeliveryHeader = "X-Yatta-Delivery"
+	// The hex-encoded HMAC-SHA256 of the body keyed with the webhook's secret, prefixed with "sha256=".
+	webhookSignatureHeader = "X-Yatta-Signature"
+)
+
+// Show the webhooks of the user's task list along with the log of their deliveries. Only the owner and admins of the
+// list may see its webhooks, since they include the secrets used to sign deliveries.
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	webhooks, err := taskStore.GetWebhooks(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the webhooks of the task list of %q: %v", owner, err))
+		return
+	}
+
+	deliveries, err := taskStore.GetDeliveries(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the deliveries of the task list of %q: %v", owner, err))
+		return
+	}
+
+	body, err := s.renderer.RenderWebhooks(WebhooksPage{User: owner, Webhooks: webhooks, Deliveries: deliveries})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Add a webhook to the user's task list that sends the events in the "event" fields of the form to the URL in the "url"
+// field, signed with the "secret" field.
+func (s *Server) addWebhook(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	webhook := models.Webhook{URL: r.Form.Get("url"), Secret: r.Form.Get("secret")}
+
+	for _, name := range r.Form["event"] {
+		event, err := models.ParseWebhookEvent(name)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook.Events = append(webhook.Events, event)
+	}
+
+	if _, err := taskStore.AddWebhook(owner, webhook); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add a webhook to the task list of %q", owner))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Delete a webhook from the user's task list.
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	taskStore := s.tasks(w, r)
+
+	if taskStore == nil {
+		return
+	}
+
+	owner := r.PathValue("user")
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.requireAdmin(w, r, owner) == nil {
+		return
+	}
+
+	if err := taskStore.DeleteWebhook(owner, id); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not delete webhook %d of the task list of %q", id, owner))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// WebhookDispatcher is a background job that sends the queued deliveries of webhooks, see [models.Delivery].
+type WebhookDispatcher struct {
+	Store stores.TaskStore
+	// The client that sends the deliveries, which should have a timeout so that a slow receiver cannot hold up the
+	// other deliveries. [http.DefaultClient] is used if it is nil.
+	Client *http.Client
+}
+
+// Run attempts each delivery that is due at `now` once.
+//
+// Deliveries that fail are retried by later runs, so only errors from getting the deliveries and webhooks are
+// returned. Attempts that cannot be recorded are logged so that they do not hold up the other deliveries. Deliveries
+// of webhooks that have since been deleted are skipped.
+func (d WebhookDispatcher) Run(now time.Time) error {
+	deliveries, err := d.Store.GetDueDeliveries(now)
+
+	if err != nil {
+		return fmt.Errorf("could not get the due deliveries: %v", err)
+	}
+
+	// The webhooks of each list that has due deliveries.
+	webhooks := make(map[string][]models.Webhook)
+
+	for _, delivery := range deliveries {
+		if _, ok := webhooks[delivery.List]; !ok {
+			if webhooks[delivery.List], err = d.Store.GetWebhooks(delivery.List); err != nil {
+				return fmt.Errorf("could not get the webhooks of the task list of %q: %v", delivery.List, err)
+			}
+		}
+
+		index := slices.IndexFunc(webhooks[delivery.List], func(webhook models.Webhook) bool {
+			return webhook.ID == delivery.WebhookID
+		})
+
+		// The webhook was deleted after the deliveries were got, and its deliveries with it, so there is no secret to
+		// sign the payload with and nothing to record the attempt in.
+		if index == -1 {
+			continue
+		}
+
+		statusCode, sendErr := d.send(delivery, webhooks[delivery.List][index].Secret)
+
+		// The other deliveries can still be sent. This one is sent again on the next run, since it is still due.
+		if err := d.Store.RecordDeliveryAttempt(delivery.List, delivery.ID, statusCode, sendErr, now); err != nil {
+			slog.Error(fmt.Sprintf("could not record the attempt at delivery %d of the task list of %q: %v", delivery.ID, delivery.List, err))
+		}
+	}
+
+	return nil
+}
+
+// POST the payload of `delivery` to its URL, signed with `secret`.
+//
+// Returns the status code of the response, or an error if there was no response.
+func (d WebhookDispatcher) send(delivery models.Delivery, secret string) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookEventHeader, string(delivery.Event))
+	request.Header.Set(webhookDeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set(webhookSignatureHeader, "sha256="+signPayload(secret, delivery.Payload))
+
+	client := d.Client
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	response.Body.Close()
+
+	return response.StatusCode, nil
+}
+
+// Get the hex-encoded HMAC-SHA256 of `payload` keyed with `secret`.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Start runs the job immediately and then every `interval` until `ctx` is cancelled.
+//
+// Errors are logged rather than stopping the job, so that a failed run is retried on the next tick.
+func (d WebhookDispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Run(time.Now()); err != nil {
+			slog.Error(fmt.Sprintf("delivering webhooks failed: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AnthonyDickson/yatta/models"
+	"github.com/AnthonyDickson/yatta/stores"
+)
+
+// Get the ID of the workspace that the user with `email` belongs to, or 0 if they do not belong to one or workspaces
+// are disabled.
+func (s *Server) workspaceID(email string) (uint64, error) {
+	if s.workspaceStore == nil {
+		return 0, nil
+	}
+
+	workspace, err := s.workspaceStore.GetWorkspaceOf(email)
+
+	if err != nil || workspace == nil {
+		return 0, err
+	}
+
+	return workspace.ID, nil
+}
+
+// Check whether `user`, who is nil if nobody is signed in, is in the same workspace as the user with `email`.
+//
+// Users that do not belong to a workspace, as well as anyone that is not signed in, are in the same workspace.
+func (s *Server) sameWorkspace(user *models.User, email string) (bool, error) {
+	if s.workspaceStore == nil {
+		return true, nil
+	}
+
+	theirs, err := s.workspaceID(email)
+
+	if err != nil {
+		return false, err
+	}
+
+	var ours uint64
+
+	if user != nil {
+		if ours, err = s.workspaceID(user.Email); err != nil {
+			return false, err
+		}
+	}
+
+	return ours == theirs, nil
+}
+
+// Check that whoever made the request is in the same workspace as `owner`, writing an error response if they are not.
+//
+// Requests for task lists in other workspaces are answered as if the list did not exist so that nothing about other
+// workspaces is given away.
+func (s *Server) checkWorkspace(w http.ResponseWriter, r *http.Request, owner string) bool {
+	if s.workspaceStore == nil {
+		return true
+	}
+
+	user, err := s.authenticate(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not authenticate the request for %q: %v", r.URL, err))
+		return false
+	}
+
+	same, err := s.sameWorkspace(user, owner)
+
+	switch {
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace of %q: %v", owner, err))
+		return false
+	case same:
+		return true
+	case user == nil:
+		// The list may be in the workspace of whoever made the request once they sign in.
+		return s.requireUser(w, r) != nil
+	default:
+		http.NotFound(w, r)
+		return false
+	}
+}
+
+// Show the workspace that the signed in user belongs to, or the workspaces they have been invited to.
+func (s *Server) getWorkspace(w http.ResponseWriter, r *http.Request) {
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	workspace, err := s.workspaceStore.GetWorkspaceOf(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace of %q: %v", user.Email, err))
+		return
+	}
+
+	invitations, err := s.workspaceStore.GetInvitations(user.Email)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the invitations for %q: %v", user.Email, err))
+		return
+	}
+
+	body, err := s.renderer.RenderWorkspace(WorkspacePage{User: user.Email, Workspace: workspace, Invitations: invitations})
+	writeResponse(w, body, err, r.URL)
+}
+
+// Create a workspace with the name in the "name" field of the form, with the signed in user as its first admin.
+func (s *Server) addWorkspace(w http.ResponseWriter, r *http.Request) {
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	name := r.Form.Get("name")
+
+	if _, err := s.workspaceStore.AddWorkspace(name, user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not create the workspace %q for %q", name, user.Email))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Get the signed in user and the workspace with the ID in the path, if the user is a member of the workspace or has
+// been invited to it.
+//
+// Returns nil if a response has already been written. Workspaces that the user has nothing to do with are treated as
+// if they did not exist.
+func (s *Server) requireWorkspace(w http.ResponseWriter, r *http.Request) (*models.User, *models.Workspace) {
+	user := s.requireUser(w, r)
+
+	if user == nil {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, nil
+	}
+
+	workspace, err := s.workspaceStore.GetWorkspace(id)
+
+	if err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not get workspace %d", id))
+		return nil, nil
+	}
+
+	if workspace.MemberRole(user.Email) == "" && !workspace.IsInvited(user.Email) {
+		http.NotFound(w, r)
+		return nil, nil
+	}
+
+	return user, &workspace
+}
+
+// Ask the user with the email in the "email" field of the form to join the workspace with the role in the "role"
+// field. Only admins of the workspace may invite users.
+func (s *Server) inviteToWorkspace(w http.ResponseWriter, r *http.Request) {
+	user, workspace := s.requireWorkspace(w, r)
+
+	if workspace == nil {
+		return
+	}
+
+	if !workspace.MemberRole(user.Email).Allows(models.RoleAdmin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(r.Form.Get("email"))
+	role, err := models.ParseRole(r.Form.Get("role"))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.workspaceStore.Invite(workspace.ID, models.Member{Email: email, Role: role}); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not invite %q to workspace %d", email, workspace.ID))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Add the signed in user to the workspace that they have been invited to.
+func (s *Server) joinWorkspace(w http.ResponseWriter, r *http.Request) {
+	user, workspace := s.requireWorkspace(w, r)
+
+	if workspace == nil {
+		return
+	}
+
+	if err := s.workspaceStore.AcceptInvitation(workspace.ID, user.Email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not add %q to workspace %d", user.Email, workspace.ID))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Withdraw the invitation for a user to join the workspace. Admins may withdraw any invitation, while invited users
+// may decline their own.
+func (s *Server) deleteInvitation(w http.ResponseWriter, r *http.Request) {
+	user, workspace := s.requireWorkspace(w, r)
+
+	if workspace == nil {
+		return
+	}
+
+	email := r.PathValue("email")
+
+	if email != user.Email && !workspace.MemberRole(user.Email).Allows(models.RoleAdmin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := s.workspaceStore.DeleteInvitation(workspace.ID, email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not withdraw the invitation for %q to workspace %d", email, workspace.ID))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Remove a user from the workspace. Admins may remove any member, while other members may only leave.
+func (s *Server) removeWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	user, workspace := s.requireWorkspace(w, r)
+
+	if workspace == nil {
+		return
+	}
+
+	email := r.PathValue("email")
+	role := workspace.MemberRole(user.Email)
+
+	if role == "" || (email != user.Email && !role.Allows(models.RoleAdmin)) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := s.workspaceStore.RemoveWorkspaceMember(workspace.ID, email); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not remove %q from workspace %d", email, workspace.ID))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Change the settings of the workspace to the time zone in the "time-zone" field of the form and whether secret links
+// are turned off in the "disable-secret-links" field. Only admins of the workspace may change its settings.
+func (s *Server) updateWorkspaceSettings(w http.ResponseWriter, r *http.Request) {
+	user, workspace := s.requireWorkspace(w, r)
+
+	if workspace == nil {
+		return
+	}
+
+	if !workspace.MemberRole(user.Email).Allows(models.RoleAdmin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != formContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	settings := models.WorkspaceSettings{
+		TimeZone:           strings.TrimSpace(r.Form.Get("time-zone")),
+		DisableSecretLinks: r.Form.Get("disable-secret-links") != "",
+	}
+
+	if err := s.workspaceStore.UpdateSettings(workspace.ID, settings); err != nil {
+		writeTaskStoreError(w, err, fmt.Sprintf("could not change the settings of workspace %d", workspace.ID))
+		return
+	}
+
+	refreshHTMXPage(w, r)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Get the settings of the workspace that the user with `email` belongs to, or the default settings if they do not
+// belong to one or workspaces are disabled.
+func (s *Server) workspaceSettings(email string) (models.WorkspaceSettings, error) {
+	if s.workspaceStore == nil {
+		return models.WorkspaceSettings{}, nil
+	}
+
+	workspace, err := s.workspaceStore.GetWorkspaceOf(email)
+
+	if err != nil || workspace == nil {
+		return models.WorkspaceSettings{}, err
+	}
+
+	return workspace.Settings, nil
+}
+
+// Get the ID of the workspace of whoever made the request, or 0 if nobody is signed in, see [Server.workspaceID].
+func (s *Server) requestWorkspaceID(r *http.Request) (uint64, error) {
+	user, err := s.authenticate(r)
+
+	if err != nil || user == nil {
+		return 0, err
+	}
+
+	return s.workspaceID(user.Email)
+}
+
+// Get the task lists and users in the workspace with `id`, see [stores.NewWorkspaceTaskStore] and
+// [stores.NewWorkspaceUserStore]. All task lists and users are in the same workspace if workspaces are disabled.
+func (s *Server) storesOf(id uint64) (stores.TaskStore, stores.UserStore) {
+	if s.workspaceStore == nil {
+		return s.taskStore, s.userStore
+	}
+
+	return stores.NewWorkspaceTaskStore(s.taskStore, s.workspaceStore, id),
+		stores.NewWorkspaceUserStore(s.userStore, s.workspaceStore, id)
+}
+
+// Get the task lists in the workspace of whoever made the request.
+//
+// Returns nil if a response has already been written.
+func (s *Server) tasks(w http.ResponseWriter, r *http.Request) stores.TaskStore {
+	id, err := s.requestWorkspaceID(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace for %q: %v", r.URL, err))
+		return nil
+	}
+
+	taskStore, _ := s.storesOf(id)
+
+	return taskStore
+}
+
+// Get the task lists in the workspace of the user with `email`, for requests that act for a user without them signing
+// in, such as those made with the secret link of a feed.
+func (s *Server) tasksOf(email string) (stores.TaskStore, error) {
+	id, err := s.workspaceID(email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	taskStore, _ := s.storesOf(id)
+
+	return taskStore, nil
+}
+
+// Get the task lists in the workspace of `owner` for a request made with one of the secret links to their task list,
+// such as a calendar feed, writing an error response if the admins of the workspace have turned secret links off.
+//
+// Returns nil if a response has already been written.
+func (s *Server) tasksForSecretLink(w http.ResponseWriter, r *http.Request, owner string) stores.TaskStore {
+	settings, err := s.workspaceSettings(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace settings of %q: %v", owner, err))
+		return nil
+	}
+
+	if settings.DisableSecretLinks {
+		http.Error(w, "secret links are turned off in this workspace", http.StatusForbidden)
+		return nil
+	}
+
+	taskStore, err := s.tasksOf(owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace of %q: %v", owner, err))
+		return nil
+	}
+
+	return taskStore
+}
+
+// Get the users in the workspace of whoever made the request.
+//
+// Returns nil if a response has already been written.
+func (s *Server) users(w http.ResponseWriter, r *http.Request) stores.UserStore {
+	id, err := s.requestWorkspaceID(r)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(fmt.Sprintf("could not get the workspace for %q: %v", r.URL, err))
+		return nil
+	}
+
+	_, userStore := s.storesOf(id)
+
+	return userStore
+}